@@ -0,0 +1,35 @@
+package api
+
+import (
+	"context"
+	"embed"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// FS holds the OpenAPI document and the Swagger UI page so the binary can serve them
+// without the source tree.
+//
+//go:embed openapi.yaml swagger.html
+var FS embed.FS
+
+// Load parses the OpenAPI document and checks that it is itself valid.
+func Load(ctx context.Context) (*openapi3.T, error) {
+	data, err := FS.ReadFile("openapi.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read openapi document: %v", err)
+	}
+
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load openapi document: %v", err)
+	}
+
+	err = doc.Validate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid openapi document: %v", err)
+	}
+
+	return doc, nil
+}
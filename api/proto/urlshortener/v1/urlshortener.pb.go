@@ -0,0 +1,505 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: urlshortener/v1/urlshortener.proto
+
+package urlshortenerv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ShortenRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	LongUrl string                 `protobuf:"bytes,1,opt,name=long_url,json=longUrl,proto3" json:"long_url,omitempty"`
+	// Custom key, 3 to 64 letters, digits, "-" or "_".
+	Alias string `protobuf:"bytes,2,opt,name=alias,proto3" json:"alias,omitempty"`
+	// At most one of expires_at and ttl may be set.
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Ttl           *durationpb.Duration   `protobuf:"bytes,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShortenRequest) Reset() {
+	*x = ShortenRequest{}
+	mi := &file_urlshortener_v1_urlshortener_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShortenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShortenRequest) ProtoMessage() {}
+
+func (x *ShortenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_urlshortener_v1_urlshortener_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShortenRequest.ProtoReflect.Descriptor instead.
+func (*ShortenRequest) Descriptor() ([]byte, []int) {
+	return file_urlshortener_v1_urlshortener_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ShortenRequest) GetLongUrl() string {
+	if x != nil {
+		return x.LongUrl
+	}
+	return ""
+}
+
+func (x *ShortenRequest) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
+}
+
+func (x *ShortenRequest) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *ShortenRequest) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
+	}
+	return nil
+}
+
+type ShortenResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	// Only set when a new link was created, it is never shown again.
+	ManagementToken string `protobuf:"bytes,2,opt,name=management_token,json=managementToken,proto3" json:"management_token,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ShortenResponse) Reset() {
+	*x = ShortenResponse{}
+	mi := &file_urlshortener_v1_urlshortener_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShortenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShortenResponse) ProtoMessage() {}
+
+func (x *ShortenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_urlshortener_v1_urlshortener_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShortenResponse.ProtoReflect.Descriptor instead.
+func (*ShortenResponse) Descriptor() ([]byte, []int) {
+	return file_urlshortener_v1_urlshortener_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ShortenResponse) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *ShortenResponse) GetManagementToken() string {
+	if x != nil {
+		return x.ManagementToken
+	}
+	return ""
+}
+
+type ResolveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EncodedKey    string                 `protobuf:"bytes,1,opt,name=encoded_key,json=encodedKey,proto3" json:"encoded_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveRequest) Reset() {
+	*x = ResolveRequest{}
+	mi := &file_urlshortener_v1_urlshortener_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveRequest) ProtoMessage() {}
+
+func (x *ResolveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_urlshortener_v1_urlshortener_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveRequest.ProtoReflect.Descriptor instead.
+func (*ResolveRequest) Descriptor() ([]byte, []int) {
+	return file_urlshortener_v1_urlshortener_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ResolveRequest) GetEncodedKey() string {
+	if x != nil {
+		return x.EncodedKey
+	}
+	return ""
+}
+
+type ResolveResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LongUrl       string                 `protobuf:"bytes,1,opt,name=long_url,json=longUrl,proto3" json:"long_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveResponse) Reset() {
+	*x = ResolveResponse{}
+	mi := &file_urlshortener_v1_urlshortener_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveResponse) ProtoMessage() {}
+
+func (x *ResolveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_urlshortener_v1_urlshortener_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveResponse.ProtoReflect.Descriptor instead.
+func (*ResolveResponse) Descriptor() ([]byte, []int) {
+	return file_urlshortener_v1_urlshortener_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ResolveResponse) GetLongUrl() string {
+	if x != nil {
+		return x.LongUrl
+	}
+	return ""
+}
+
+type GetLinkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EncodedKey    string                 `protobuf:"bytes,1,opt,name=encoded_key,json=encodedKey,proto3" json:"encoded_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLinkRequest) Reset() {
+	*x = GetLinkRequest{}
+	mi := &file_urlshortener_v1_urlshortener_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLinkRequest) ProtoMessage() {}
+
+func (x *GetLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_urlshortener_v1_urlshortener_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLinkRequest.ProtoReflect.Descriptor instead.
+func (*GetLinkRequest) Descriptor() ([]byte, []int) {
+	return file_urlshortener_v1_urlshortener_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetLinkRequest) GetEncodedKey() string {
+	if x != nil {
+		return x.EncodedKey
+	}
+	return ""
+}
+
+type GetLinkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Link          *Link                  `protobuf:"bytes,1,opt,name=link,proto3" json:"link,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLinkResponse) Reset() {
+	*x = GetLinkResponse{}
+	mi := &file_urlshortener_v1_urlshortener_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLinkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLinkResponse) ProtoMessage() {}
+
+func (x *GetLinkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_urlshortener_v1_urlshortener_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLinkResponse.ProtoReflect.Descriptor instead.
+func (*GetLinkResponse) Descriptor() ([]byte, []int) {
+	return file_urlshortener_v1_urlshortener_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetLinkResponse) GetLink() *Link {
+	if x != nil {
+		return x.Link
+	}
+	return nil
+}
+
+type Link struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	EncodedKey string                 `protobuf:"bytes,1,opt,name=encoded_key,json=encodedKey,proto3" json:"encoded_key,omitempty"`
+	LongUrl    string                 `protobuf:"bytes,2,opt,name=long_url,json=longUrl,proto3" json:"long_url,omitempty"`
+	CreatedAt  *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// Unset for links that never expire.
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	TotalClicks   int64                  `protobuf:"varint,5,opt,name=total_clicks,json=totalClicks,proto3" json:"total_clicks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Link) Reset() {
+	*x = Link{}
+	mi := &file_urlshortener_v1_urlshortener_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Link) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Link) ProtoMessage() {}
+
+func (x *Link) ProtoReflect() protoreflect.Message {
+	mi := &file_urlshortener_v1_urlshortener_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Link.ProtoReflect.Descriptor instead.
+func (*Link) Descriptor() ([]byte, []int) {
+	return file_urlshortener_v1_urlshortener_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Link) GetEncodedKey() string {
+	if x != nil {
+		return x.EncodedKey
+	}
+	return ""
+}
+
+func (x *Link) GetLongUrl() string {
+	if x != nil {
+		return x.LongUrl
+	}
+	return ""
+}
+
+func (x *Link) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Link) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *Link) GetTotalClicks() int64 {
+	if x != nil {
+		return x.TotalClicks
+	}
+	return 0
+}
+
+var File_urlshortener_v1_urlshortener_proto protoreflect.FileDescriptor
+
+const file_urlshortener_v1_urlshortener_proto_rawDesc = "" +
+	"\n" +
+	"\"urlshortener/v1/urlshortener.proto\x12\x0furlshortener.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa9\x01\n" +
+	"\x0eShortenRequest\x12\x19\n" +
+	"\blong_url\x18\x01 \x01(\tR\alongUrl\x12\x14\n" +
+	"\x05alias\x18\x02 \x01(\tR\x05alias\x129\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12+\n" +
+	"\x03ttl\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\x03ttl\"Y\n" +
+	"\x0fShortenResponse\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12)\n" +
+	"\x10management_token\x18\x02 \x01(\tR\x0fmanagementToken\"1\n" +
+	"\x0eResolveRequest\x12\x1f\n" +
+	"\vencoded_key\x18\x01 \x01(\tR\n" +
+	"encodedKey\",\n" +
+	"\x0fResolveResponse\x12\x19\n" +
+	"\blong_url\x18\x01 \x01(\tR\alongUrl\"1\n" +
+	"\x0eGetLinkRequest\x12\x1f\n" +
+	"\vencoded_key\x18\x01 \x01(\tR\n" +
+	"encodedKey\"<\n" +
+	"\x0fGetLinkResponse\x12)\n" +
+	"\x04link\x18\x01 \x01(\v2\x15.urlshortener.v1.LinkR\x04link\"\xdb\x01\n" +
+	"\x04Link\x12\x1f\n" +
+	"\vencoded_key\x18\x01 \x01(\tR\n" +
+	"encodedKey\x12\x19\n" +
+	"\blong_url\x18\x02 \x01(\tR\alongUrl\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12!\n" +
+	"\ftotal_clicks\x18\x05 \x01(\x03R\vtotalClicks2\xf8\x01\n" +
+	"\fUrlShortener\x12L\n" +
+	"\aShorten\x12\x1f.urlshortener.v1.ShortenRequest\x1a .urlshortener.v1.ShortenResponse\x12L\n" +
+	"\aResolve\x12\x1f.urlshortener.v1.ResolveRequest\x1a .urlshortener.v1.ResolveResponse\x12L\n" +
+	"\aGetLink\x12\x1f.urlshortener.v1.GetLinkRequest\x1a .urlshortener.v1.GetLinkResponseBLZJgithub.com/ggoulart/url-shortener/api/proto/urlshortener/v1;urlshortenerv1b\x06proto3"
+
+var (
+	file_urlshortener_v1_urlshortener_proto_rawDescOnce sync.Once
+	file_urlshortener_v1_urlshortener_proto_rawDescData []byte
+)
+
+func file_urlshortener_v1_urlshortener_proto_rawDescGZIP() []byte {
+	file_urlshortener_v1_urlshortener_proto_rawDescOnce.Do(func() {
+		file_urlshortener_v1_urlshortener_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_urlshortener_v1_urlshortener_proto_rawDesc), len(file_urlshortener_v1_urlshortener_proto_rawDesc)))
+	})
+	return file_urlshortener_v1_urlshortener_proto_rawDescData
+}
+
+var file_urlshortener_v1_urlshortener_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_urlshortener_v1_urlshortener_proto_goTypes = []any{
+	(*ShortenRequest)(nil),        // 0: urlshortener.v1.ShortenRequest
+	(*ShortenResponse)(nil),       // 1: urlshortener.v1.ShortenResponse
+	(*ResolveRequest)(nil),        // 2: urlshortener.v1.ResolveRequest
+	(*ResolveResponse)(nil),       // 3: urlshortener.v1.ResolveResponse
+	(*GetLinkRequest)(nil),        // 4: urlshortener.v1.GetLinkRequest
+	(*GetLinkResponse)(nil),       // 5: urlshortener.v1.GetLinkResponse
+	(*Link)(nil),                  // 6: urlshortener.v1.Link
+	(*timestamppb.Timestamp)(nil), // 7: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 8: google.protobuf.Duration
+}
+var file_urlshortener_v1_urlshortener_proto_depIdxs = []int32{
+	7, // 0: urlshortener.v1.ShortenRequest.expires_at:type_name -> google.protobuf.Timestamp
+	8, // 1: urlshortener.v1.ShortenRequest.ttl:type_name -> google.protobuf.Duration
+	6, // 2: urlshortener.v1.GetLinkResponse.link:type_name -> urlshortener.v1.Link
+	7, // 3: urlshortener.v1.Link.created_at:type_name -> google.protobuf.Timestamp
+	7, // 4: urlshortener.v1.Link.expires_at:type_name -> google.protobuf.Timestamp
+	0, // 5: urlshortener.v1.UrlShortener.Shorten:input_type -> urlshortener.v1.ShortenRequest
+	2, // 6: urlshortener.v1.UrlShortener.Resolve:input_type -> urlshortener.v1.ResolveRequest
+	4, // 7: urlshortener.v1.UrlShortener.GetLink:input_type -> urlshortener.v1.GetLinkRequest
+	1, // 8: urlshortener.v1.UrlShortener.Shorten:output_type -> urlshortener.v1.ShortenResponse
+	3, // 9: urlshortener.v1.UrlShortener.Resolve:output_type -> urlshortener.v1.ResolveResponse
+	5, // 10: urlshortener.v1.UrlShortener.GetLink:output_type -> urlshortener.v1.GetLinkResponse
+	8, // [8:11] is the sub-list for method output_type
+	5, // [5:8] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_urlshortener_v1_urlshortener_proto_init() }
+func file_urlshortener_v1_urlshortener_proto_init() {
+	if File_urlshortener_v1_urlshortener_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_urlshortener_v1_urlshortener_proto_rawDesc), len(file_urlshortener_v1_urlshortener_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_urlshortener_v1_urlshortener_proto_goTypes,
+		DependencyIndexes: file_urlshortener_v1_urlshortener_proto_depIdxs,
+		MessageInfos:      file_urlshortener_v1_urlshortener_proto_msgTypes,
+	}.Build()
+	File_urlshortener_v1_urlshortener_proto = out.File
+	file_urlshortener_v1_urlshortener_proto_goTypes = nil
+	file_urlshortener_v1_urlshortener_proto_depIdxs = nil
+}
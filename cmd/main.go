@@ -1,52 +1,253 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
+	"github.com/ggoulart/url-shortener/api"
+	urlshortenerv1 "github.com/ggoulart/url-shortener/api/proto/urlshortener/v1"
 	"github.com/ggoulart/url-shortener/internal/clients/postgres"
 	"github.com/ggoulart/url-shortener/internal/controller"
 	"github.com/ggoulart/url-shortener/internal/middleware"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/ggoulart/url-shortener/internal/ratelimit"
 	"github.com/ggoulart/url-shortener/internal/repository"
+	"github.com/ggoulart/url-shortener/internal/rpc"
+	"github.com/ggoulart/url-shortener/internal/server"
 	"github.com/ggoulart/url-shortener/internal/service"
+	"github.com/ggoulart/url-shortener/migrations"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"github.com/spf13/viper"
+	"google.golang.org/grpc"
 )
 
 func main() {
-	err, shortenerHost := loadConfigs()
+	storageDriver := loadConfigs()
 
-	postgresConfig, err := postgres.NewConfig()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		migrate(os.Args[2:])
+		return
+	}
+
+	serviceConfig, err := service.NewConfig()
 	if err != nil {
 		log.Panic(err)
 	}
 
-	postgresClient, err := postgres.NewClient(*postgresConfig)
+	reaperConfig, err := service.NewReaperConfig()
+	if err != nil {
+		log.Panic(err)
+	}
+
+	clickConfig, err := service.NewClickConfig()
+	if err != nil {
+		log.Panic(err)
+	}
+
+	cacheConfig, err := repository.NewCacheConfig()
+	if err != nil {
+		log.Panic(err)
+	}
+
+	policyConfig, err := service.NewPolicyConfig()
+	if err != nil {
+		log.Panic(err)
+	}
+
+	authConfig, err := service.NewAuthConfig()
+	if err != nil {
+		log.Panic(err)
+	}
+
+	sessionConfig, err := service.NewSessionConfig()
 	if err != nil {
 		log.Panic(err)
 	}
-	defer postgresClient.DB.Close()
 
-	shortenerRepository := repository.NewShortenerRepository(postgresClient.DB)
-	shortenerService := service.NewShortenerService(shortenerRepository, shortenerHost, uuid.New().String)
-	shortenerController := controller.NewShortenerController(shortenerService)
+	workspaceConfig, err := service.NewWorkspaceConfig()
+	if err != nil {
+		log.Panic(err)
+	}
 
-	healthService := service.NewHealthService(postgresClient)
+	quotaConfig, err := service.NewQuotaConfig()
+	if err != nil {
+		log.Panic(err)
+	}
+
+	rateLimitConfig, err := ratelimit.NewConfig()
+	if err != nil {
+		log.Panic(err)
+	}
+
+	scanConfig, err := ratelimit.NewScanConfig()
+	if err != nil {
+		log.Panic(err)
+	}
+
+	serverConfig, err := server.NewConfig()
+	if err != nil {
+		log.Panic(err)
+	}
+
+	store, err := newStorage(storageDriver)
+	if err != nil {
+		log.Panic(err)
+	}
+	defer store.close()
+
+	var links linkRepository = store.links
+	var linkCache service.LinkCache
+	if cacheConfig.Enabled {
+		cached := repository.NewCachedShortenerRepository(store.links, *cacheConfig)
+		links, linkCache = cached, cached
+	}
+
+	// workers run on their own context so they keep going while in-flight requests drain,
+	// the deferred calls stop them after the server is down and before the pool is closed
+	clickRecorder := service.NewClickRecorder(store.clicks, *clickConfig)
+	clickRecorder.Start(context.Background())
+	defer clickRecorder.Stop()
+
+	expirationReaper := service.NewExpirationReaper(links, *reaperConfig)
+	expirationReaper.Start(context.Background())
+	defer expirationReaper.Stop()
+
+	destinationPolicy, err := service.NewDestinationPolicy(*policyConfig, serviceConfig.ShortenerHost)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	workspacePolicy := service.NewWorkspacePolicy(store.workspaces)
+
+	quotaService := service.NewQuotaService(store.quotas, *quotaConfig)
+
+	shortenerService := service.NewShortenerService(links, service.NewBase62KeyGenerator(), destinationPolicy, workspacePolicy, quotaService, *serviceConfig)
+	shortenerController := controller.NewShortenerController(shortenerService, clickRecorder, serviceConfig.BatchMaxSize)
+
+	statsService := service.NewStatsService(links, store.clicks, workspacePolicy)
+	statsController := controller.NewStatsController(statsService)
+
+	apiKeyService := service.NewAPIKeyService(store.apiKeys, *authConfig)
+	apiKeyController := controller.NewAPIKeyController(apiKeyService)
+
+	userService := service.NewUserService(store.users, *sessionConfig)
+	authController := controller.NewAuthController(userService)
+
+	workspaceService := service.NewWorkspaceService(store.workspaces, workspacePolicy, *workspaceConfig)
+	workspaceController := controller.NewWorkspaceController(workspaceService)
+
+	credentials := service.NewCredentials(apiKeyService, userService)
+
+	healthService := service.NewHealthService(store.dbClients, linkCache)
 	healthController := controller.NewHealthController(healthService)
 
-	r := gin.Default()
+	spec, err := api.Load(context.Background())
+	if err != nil {
+		log.Panic(err)
+	}
 
-	routes(r, shortenerController, healthController)
+	requestValidator, err := middleware.ValidateRequests(spec)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	swaggerUI, err := api.FS.ReadFile("swagger.html")
+	if err != nil {
+		log.Panic(err)
+	}
 
-	err = r.Run(":8080")
+	docsController, err := controller.NewDocsController(spec, swaggerUI)
 	if err != nil {
+		log.Panic(err)
+	}
+
+	// the gRPC methods share the scan detector and limiters of their HTTP routes, a
+	// client has one budget for both, and scan detection comes first on both
+	var grpcInterceptors []grpc.UnaryServerInterceptor
+	detectScanning := noLimit
+	if scanConfig.Enabled {
+		scanDetector, err := ratelimit.NewScanDetector(*scanConfig)
+		if err != nil {
+			log.Panic(err)
+		}
+		detectScanning = middleware.DetectScanning(scanDetector)
+		grpcInterceptors = append(grpcInterceptors, rpc.ScanInterceptor(scanDetector))
+	}
+
+	createLimit, redirectLimit, authLimit := noLimit, noLimit, noLimit
+	if rateLimitConfig.Enabled {
+		createLimiter := ratelimit.NewLimiter(rateLimitConfig.Create, rateLimitConfig.Clients)
+		redirectLimiter := ratelimit.NewLimiter(rateLimitConfig.Redirect, rateLimitConfig.Clients)
+		createLimit = middleware.RateLimit(createLimiter)
+		redirectLimit = middleware.RateLimit(redirectLimiter)
+		authLimit = middleware.RateLimit(ratelimit.NewLimiter(rateLimitConfig.Auth, rateLimitConfig.Clients))
+		grpcInterceptors = append(grpcInterceptors, rpc.RateLimitInterceptor(map[string]middleware.Limiter{
+			urlshortenerv1.UrlShortener_Shorten_FullMethodName: createLimiter,
+			urlshortenerv1.UrlShortener_Resolve_FullMethodName: redirectLimiter,
+		}))
+	}
+
+	r, err := newEngine(*serverConfig)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	routes(r, middleware.Authenticate(credentials), requestValidator, createLimit, redirectLimit, authLimit, detectScanning, shortenerController, statsController, healthController, docsController, apiKeyController, authController, workspaceController)
+
+	httpServer := server.New(*serverConfig, r)
+	grpcServer := server.NewGRPC(rpc.NewUrlShortenerServer(shortenerService, statsService, clickRecorder), credentials, grpcInterceptors...)
+
+	grpcListener, err := net.Listen("tcp", serverConfig.GRPCAddress)
+	if err != nil {
+		log.Panic(fmt.Errorf("failed to listen for grpc: %v", err))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 2)
+	go func() {
+		serverErr <- httpServer.ListenAndServe()
+	}()
+	go func() {
+		serverErr <- grpcServer.Serve(grpcListener)
+	}()
+
+	select {
+	case err := <-serverErr:
 		log.Panic(fmt.Errorf("failed to start server: %v", err))
+	case <-ctx.Done():
+	}
+
+	// a second signal during the drain kills the process right away
+	stop()
+	slog.Info("shutting down server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serverConfig.ShutdownTimeout)
+	defer cancel()
+
+	err = httpServer.Shutdown(shutdownCtx)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error(fmt.Sprintf("failed to drain active requests: %v", err))
+	}
+
+	err = grpcServer.Shutdown(shutdownCtx)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to drain active grpc calls: %v", err))
 	}
 }
 
-func loadConfigs() (error, string) {
+func loadConfigs() string {
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath("./configs")
@@ -55,20 +256,213 @@ func loadConfigs() (error, string) {
 		log.Panic(fmt.Errorf("failed to load config file: %s", err))
 	}
 
-	shortenerHost := viper.GetString("service.SHORTENER_HOST")
-	return err, shortenerHost
+	// lets STORAGE_DRIVER=memory override the file without editing it
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+	viper.SetDefault("storage.DRIVER", "postgres")
+
+	return viper.GetString("storage.DRIVER")
+}
+
+type linkRepository interface {
+	service.ShortenerRepository
+	service.ExpiredLinkRepository
+}
+
+type clickRepository interface {
+	service.ClickRepository
+	service.StatsRepository
+}
+
+type storage struct {
+	links      linkRepository
+	clicks     clickRepository
+	apiKeys    service.APIKeyRepository
+	users      service.UserRepository
+	workspaces service.WorkspaceRepository
+	quotas     service.QuotaRepository
+	dbClients  map[string]service.DBClient
+	close      func() error
+}
+
+func newStorage(driver string) (*storage, error) {
+	switch driver {
+	case "postgres":
+		postgresConfig, err := postgres.NewConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		postgresClient, err := postgres.NewClient(*postgresConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		if postgresConfig.AutoMigrate {
+			migrator, err := postgres.NewMigrator(postgresClient.DB, migrations.FS)
+			if err != nil {
+				return nil, err
+			}
+
+			_, err = migrator.Up(context.Background())
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return &storage{
+			links:      repository.NewShortenerRepository(postgresClient.DB),
+			clicks:     repository.NewClickRepository(postgresClient.DB),
+			apiKeys:    repository.NewAPIKeyRepository(postgresClient.DB),
+			users:      repository.NewUserRepository(postgresClient.DB),
+			workspaces: repository.NewWorkspaceRepository(postgresClient.DB),
+			quotas:     repository.NewQuotaRepository(postgresClient.DB),
+			dbClients:  map[string]service.DBClient{"postgres": postgresClient},
+			close:      postgresClient.DB.Close,
+		}, nil
+	case "memory":
+		clicks := repository.NewMemoryClickRepository()
+		links := repository.NewMemoryShortenerRepository(clicks)
+
+		return &storage{
+			links:      links,
+			clicks:     clicks,
+			apiKeys:    repository.NewMemoryAPIKeyRepository(),
+			users:      repository.NewMemoryUserRepository(),
+			workspaces: repository.NewMemoryWorkspaceRepository(),
+			quotas:     repository.NewMemoryQuotaRepository(),
+			dbClients:  map[string]service.DBClient{"memory": links},
+			close:      func() error { return nil },
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %q", driver)
+	}
+}
+
+func migrate(args []string) {
+	if len(args) != 1 {
+		log.Panic("usage: migrate up|down|status")
+	}
+
+	postgresConfig, err := postgres.NewConfig()
+	if err != nil {
+		log.Panic(err)
+	}
+
+	postgresClient, err := postgres.NewClient(*postgresConfig)
+	if err != nil {
+		log.Panic(err)
+	}
+	defer postgresClient.DB.Close()
+
+	migrator, err := postgres.NewMigrator(postgresClient.DB, migrations.FS)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Panic(err)
+		}
+		fmt.Printf("applied %d migrations\n", applied)
+	case "down":
+		err := migrator.Down(ctx)
+		if err != nil {
+			log.Panic(err)
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Panic(err)
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied"
+			}
+			fmt.Printf("%06d_%s\t%s\n", status.Version, status.Name, state)
+		}
+	default:
+		log.Panic("usage: migrate up|down|status")
+	}
+}
+
+// noLimit stands in for a rate limit or scan detection that is disabled.
+func noLimit(*gin.Context) {}
+
+// newEngine only believes X-Forwarded-For from the configured proxies, otherwise
+// clients could pick the IP rate limits and scan detection tell them apart by.
+func newEngine(config server.Config) (*gin.Engine, error) {
+	r := gin.Default()
+	err := r.SetTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %v", err)
+	}
+	return r, nil
 }
 
-func routes(r *gin.Engine, shortenerController *controller.ShortenerController, healthController *controller.HealthController) {
+// routes checks credentials before the OpenAPI document, a client without access
+// learns nothing about what a valid request would have been. Rate limits come after
+// credentials, they are kept per key, and before the OpenAPI document so that
+// invalid requests count too. Scan detection comes first on redirects, a blocked
+// scanner doesn't use up the rate limit of its IP. Handlers pass the gin context on
+// to the services, the fallback lets them find the principal in it.
+func routes(r *gin.Engine, authenticate gin.HandlerFunc, requestValidator gin.HandlerFunc, createLimit gin.HandlerFunc, redirectLimit gin.HandlerFunc, authLimit gin.HandlerFunc, detectScanning gin.HandlerFunc, shortenerController *controller.ShortenerController, statsController *controller.StatsController, healthController *controller.HealthController, docsController *controller.DocsController, apiKeyController *controller.APIKeyController, authController *controller.AuthController, workspaceController *controller.WorkspaceController) {
+	r.ContextWithFallback = true
+
 	r.Use(cors.New(cors.Config{
 		AllowOrigins: []string{"http://localhost:5173"},
-		AllowMethods: []string{"GET", "POST"},
-		AllowHeaders: []string{"Origin", "Content-Type"},
+		AllowMethods: []string{"GET", "POST", "PATCH", "DELETE"},
+		AllowHeaders: []string{"Origin", "Content-Type", "Authorization", controller.ManagementTokenHeader, middleware.WorkspaceHeader},
 	}))
 
 	r.Use(middleware.ErrorHandler())
+	r.Use(authenticate)
+
+	public := r.Group("/api/v1", requestValidator)
+	public.GET("/openapi.json", docsController.OpenAPI)
+	public.GET("/docs", docsController.SwaggerUI)
+	public.GET("/health", healthController.Health)
+
+	sessions := r.Group("/api/v1", authLimit, requestValidator)
+	sessions.POST("/auth/register", authController.Register)
+	sessions.POST("/auth/login", authController.Login)
+	sessions.POST("/auth/refresh", authController.Refresh)
+	sessions.POST("/auth/logout", authController.Logout)
+
+	redirects := r.Group("/api/v1", detectScanning, redirectLimit, requestValidator)
+	redirects.GET("/:encodedKey", controller.PreviewOr(statsController.Preview, shortenerController.RetrieveURL))
+
+	shorteners := r.Group("/api/v1", middleware.RequireScope(model.ScopeCreate), createLimit, requestValidator)
+	shorteners.POST("/shorten", shortenerController.ShortenURL)
+	shorteners.POST("/shorten/batch", shortenerController.ShortenBatch)
+
+	creators := r.Group("/api/v1", middleware.RequireScope(model.ScopeCreate), requestValidator)
+	creators.PATCH("/links/:key", shortenerController.UpdateDestination)
+	creators.DELETE("/links/:key", shortenerController.DeleteLink)
+	creators.POST("/links/:key/disable", shortenerController.DisableLink)
+	creators.POST("/links/:key/rollback", shortenerController.Rollback)
+	creators.POST("/workspaces", workspaceController.Create)
+	creators.PATCH("/workspaces/:id/members/:userId", workspaceController.UpdateMember)
+	creators.DELETE("/workspaces/:id/members/:userId", workspaceController.RemoveMember)
+	creators.POST("/workspaces/:id/invitations", workspaceController.Invite)
+	creators.POST("/invitations/accept", workspaceController.AcceptInvitation)
+
+	readers := r.Group("/api/v1", middleware.RequireScope(model.ScopeRead), requestValidator)
+	readers.GET("/links", shortenerController.ListLinks)
+	readers.GET("/links/:key", statsController.Metadata)
+	readers.GET("/links/:key/stats", statsController.Stats)
+	readers.GET("/links/:key/history", shortenerController.History)
+	readers.GET("/workspaces", workspaceController.List)
+	readers.GET("/workspaces/:id/members", workspaceController.ListMembers)
 
-	r.POST("/api/v1/shorten", shortenerController.ShortenURL)
-	r.GET("/api/v1/:encodedKey", shortenerController.RetrieveURL)
-	r.GET("/api/v1/health", healthController.Health)
+	admins := r.Group("/api/v1/admin", middleware.RequireScope(model.ScopeAdmin), requestValidator)
+	admins.POST("/keys", apiKeyController.Mint)
+	admins.GET("/keys", apiKeyController.List)
+	admins.DELETE("/keys/:id", apiKeyController.Revoke)
+	admins.GET("/cache", healthController.Cache)
 }
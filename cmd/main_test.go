@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ggoulart/url-shortener/api"
+	"github.com/ggoulart/url-shortener/internal/controller"
+	"github.com/ggoulart/url-shortener/internal/middleware"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/ggoulart/url-shortener/internal/ratelimit"
+	"github.com/ggoulart/url-shortener/internal/repository"
+	"github.com/ggoulart/url-shortener/internal/server"
+	"github.com/ggoulart/url-shortener/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var ginParam = regexp.MustCompile(`:([^/]+)`)
+
+var openAPIParam = regexp.MustCompile(`{[^/]+}`)
+
+func TestRoutes_DescribedByOpenAPI(t *testing.T) {
+	doc, err := api.Load(context.Background())
+	assert.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	routes(r, func(*gin.Context) {}, func(*gin.Context) {}, noLimit, noLimit, noLimit, noLimit, &controller.ShortenerController{}, &controller.StatsController{}, &controller.HealthController{}, &controller.DocsController{}, &controller.APIKeyController{}, &controller.AuthController{}, &controller.WorkspaceController{})
+
+	described := map[string]bool{}
+	for _, route := range r.Routes() {
+		path := ginParam.ReplaceAllString(route.Path, "{$1}")
+		described[route.Method+" "+path] = true
+
+		pathItem := doc.Paths.Find(path)
+		if assert.NotNil(t, pathItem, "route %s %s is missing from the openapi document", route.Method, route.Path) {
+			assert.NotNil(t, pathItem.GetOperation(route.Method), "route %s %s is missing from the openapi document", route.Method, route.Path)
+		}
+	}
+
+	for path, pathItem := range doc.Paths.Map() {
+		for method := range pathItem.Operations() {
+			assert.True(t, described[method+" "+path], "openapi document describes %s %s but no route serves it", method, path)
+		}
+	}
+}
+
+func TestRoutes_ScopesDescribedByOpenAPI(t *testing.T) {
+	doc, err := api.Load(context.Background())
+	assert.NoError(t, err)
+
+	// the test principal holds the scopes listed in a header, the zero controllers
+	// behind the scope checks panic and are answered with a 500
+	authenticate := func(c *gin.Context) {
+		if header := c.GetHeader("X-Test-Scopes"); header != "" {
+			var scopes []model.Scope
+			for _, scope := range strings.Split(header, ",") {
+				scopes = append(scopes, model.Scope(scope))
+			}
+			c.Request = c.Request.WithContext(service.ContextWithPrincipal(c.Request.Context(), model.Principal{Scopes: scopes}))
+		}
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, _ any) { c.AbortWithStatus(http.StatusInternalServerError) }))
+	routes(r, authenticate, func(*gin.Context) {}, noLimit, noLimit, noLimit, noLimit, &controller.ShortenerController{}, &controller.StatsController{}, &controller.HealthController{}, &controller.DocsController{}, &controller.APIKeyController{}, &controller.AuthController{}, &controller.WorkspaceController{})
+
+	allScopes := []model.Scope{model.ScopeCreate, model.ScopeRead, model.ScopeAdmin}
+	for path, pathItem := range doc.Paths.Map() {
+		target := openAPIParam.ReplaceAllString(path, "abc1234")
+		for method, operation := range pathItem.Operations() {
+			scope, _ := operation.Extensions["x-scope"].(string)
+
+			anonymous := serve(r, method, target, "")
+			if scope == "" {
+				assert.NotEqual(t, http.StatusUnauthorized, anonymous, "%s %s has no x-scope but needs a key", method, path)
+				continue
+			}
+			assert.Equal(t, http.StatusUnauthorized, anonymous, "%s %s has x-scope but is public", method, path)
+
+			var others []string
+			for _, other := range allScopes {
+				if other != model.Scope(scope) && other != model.ScopeAdmin {
+					others = append(others, string(other))
+				}
+			}
+			assert.Equal(t, http.StatusForbidden, serve(r, method, target, strings.Join(others, ",")), "%s %s needs another scope than %s", method, path, scope)
+			assert.NotEqual(t, http.StatusForbidden, serve(r, method, target, scope), "%s %s needs another scope than %s", method, path, scope)
+		}
+	}
+}
+
+func serve(r *gin.Engine, method string, target string, scopes string) int {
+	req := httptest.NewRequest(method, target, nil)
+	if scopes != "" {
+		req.Header.Set("X-Test-Scopes", scopes)
+	}
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+
+	return recorder.Code
+}
+
+func TestRoutes_Limits(t *testing.T) {
+	limit := func(name string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, name)
+		}
+	}
+	authenticate := func(c *gin.Context) {
+		c.Request = c.Request.WithContext(service.ContextWithPrincipal(c.Request.Context(), model.Principal{Scopes: []model.Scope{model.ScopeAdmin}}))
+	}
+	detectScanning := func(c *gin.Context) {
+		c.Header("X-Scanning", "checked")
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, _ any) { c.AbortWithStatus(http.StatusInternalServerError) }))
+	routes(r, authenticate, func(*gin.Context) {}, limit("create"), limit("redirect"), limit("auth"), detectScanning, &controller.ShortenerController{}, &controller.StatsController{}, &controller.HealthController{}, &controller.DocsController{}, &controller.APIKeyController{}, &controller.AuthController{}, &controller.WorkspaceController{})
+
+	tests := []struct {
+		method           string
+		target           string
+		expected         string
+		expectedScanning string
+	}{
+		{method: http.MethodPost, target: "/api/v1/shorten", expected: `"create"`},
+		{method: http.MethodPost, target: "/api/v1/shorten/batch", expected: `"create"`},
+		{method: http.MethodGet, target: "/api/v1/abc1234", expected: `"redirect"`, expectedScanning: "checked"},
+		{method: http.MethodPost, target: "/api/v1/auth/register", expected: `"auth"`},
+		{method: http.MethodPost, target: "/api/v1/auth/login", expected: `"auth"`},
+		{method: http.MethodPost, target: "/api/v1/auth/refresh", expected: `"auth"`},
+		{method: http.MethodPost, target: "/api/v1/auth/logout", expected: `"auth"`},
+		{method: http.MethodGet, target: "/api/v1/health", expected: ""},
+		{method: http.MethodGet, target: "/api/v1/links", expected: ""},
+		{method: http.MethodPatch, target: "/api/v1/links/abc1234", expected: ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.target, nil)
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, req)
+
+		assert.Equal(t, tt.expectedScanning, recorder.Header().Get("X-Scanning"), "%s %s is checked for scanning", tt.method, tt.target)
+		if tt.expected == "" {
+			assert.NotEqual(t, http.StatusTooManyRequests, recorder.Code, "%s %s is not limited", tt.method, tt.target)
+			continue
+		}
+		assert.Equal(t, http.StatusTooManyRequests, recorder.Code, "%s %s is limited", tt.method, tt.target)
+		assert.Equal(t, tt.expected, recorder.Body.String(), "%s %s has the %s limit", tt.method, tt.target, tt.expected)
+	}
+}
+
+func TestNewEngine_ForgedForwardedFor(t *testing.T) {
+	detector, err := ratelimit.NewScanDetector(ratelimit.ScanConfig{Clients: 10, Window: time.Minute, MinRequests: 2, MissRatio: 0.5, BlockFor: time.Minute})
+	assert.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r, err := newEngine(server.Config{})
+	assert.NoError(t, err)
+	r.Use(middleware.ErrorHandler())
+	r.GET("/:encodedKey", middleware.DetectScanning(detector), func(c *gin.Context) {
+		c.Error(repository.ErrNotFound)
+	})
+
+	var codes []int
+	for _, forwardedFor := range []string{"203.0.113.1", "203.0.113.2", "203.0.113.3"} {
+		req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+		req.RemoteAddr = "192.0.2.1:4321"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, req)
+		codes = append(codes, recorder.Code)
+	}
+
+	assert.Equal(t, []int{http.StatusNotFound, http.StatusNotFound, http.StatusTooManyRequests}, codes, "a forged X-Forwarded-For doesn't make a new client")
+}
+
+func TestNewEngine_ForgedForwardedFor_RateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r, err := newEngine(server.Config{})
+	assert.NoError(t, err)
+	r.Use(middleware.ErrorHandler())
+	r.GET("/:encodedKey", middleware.RateLimit(ratelimit.NewLimiter(ratelimit.Limit{PerMinute: 1, Burst: 1}, 10)), func(c *gin.Context) {
+		c.Status(http.StatusFound)
+	})
+
+	var codes []int
+	for _, forwardedFor := range []string{"203.0.113.1", "203.0.113.2"} {
+		req := httptest.NewRequest(http.MethodGet, "/abc1234", nil)
+		req.RemoteAddr = "192.0.2.1:4321"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, req)
+		codes = append(codes, recorder.Code)
+	}
+
+	assert.Equal(t, []int{http.StatusFound, http.StatusTooManyRequests}, codes, "a forged X-Forwarded-For doesn't get a fresh bucket")
+}
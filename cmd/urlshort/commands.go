@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ggoulart/url-shortener/pkg/client"
+)
+
+type shortenOutput struct {
+	LongURL         string `json:"longUrl"`
+	ShortURL        string `json:"shortUrl,omitempty"`
+	ManagementToken string `json:"managementToken,omitempty"`
+	Error           string `json:"error,omitempty"`
+}
+
+type resolveOutput struct {
+	Key     string `json:"key"`
+	LongURL string `json:"longUrl,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+func (c *cli) shorten(args []string) int {
+	flags := c.flagSet("shorten")
+	alias := flags.String("alias", "", "custom key, only with a single url")
+	ttl := flags.Duration("ttl", 0, "time the link lives, such as 72h")
+	expiresAt := flags.String("expires-at", "", "time the link expires, RFC3339")
+	if flags.Parse(args) != nil {
+		return 2
+	}
+
+	req := client.ShortenRequest{Alias: *alias, TTL: *ttl}
+	if *expiresAt != "" {
+		t, err := time.Parse(time.RFC3339, *expiresAt)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "invalid --expires-at: %v\n", err)
+			return 2
+		}
+		req.ExpiresAt = &t
+	}
+
+	if *alias != "" && flags.NArg() != 1 {
+		fmt.Fprintln(c.stderr, "--alias needs exactly one url")
+		return 2
+	}
+
+	return c.each(flags.Args(), func(longURL string) bool {
+		ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+		defer cancel()
+
+		req.LongURL = longURL
+		resp, err := c.client.Shorten(ctx, req)
+		if err != nil {
+			c.fail(shortenOutput{LongURL: longURL, Error: err.Error()}, fmt.Sprintf("%s: %v", longURL, err))
+			return false
+		}
+
+		if c.config.Output == OutputJSON {
+			c.printJSON(shortenOutput{LongURL: longURL, ShortURL: resp.ShortURL, ManagementToken: resp.ManagementToken})
+			return true
+		}
+
+		fmt.Fprintln(c.stdout, resp.ShortURL)
+		// stdout only carries short urls so it can be piped, the token goes aside
+		if resp.ManagementToken != "" {
+			fmt.Fprintf(c.stderr, "management token for %s: %s\n", resp.ShortURL, resp.ManagementToken)
+		}
+		return true
+	})
+}
+
+func (c *cli) resolve(args []string) int {
+	flags := c.flagSet("resolve")
+	if flags.Parse(args) != nil {
+		return 2
+	}
+
+	return c.each(flags.Args(), func(arg string) bool {
+		ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+		defer cancel()
+
+		key := encodedKey(arg)
+		longURL, err := c.client.Resolve(ctx, key)
+		if err != nil {
+			c.fail(resolveOutput{Key: key, Error: err.Error()}, fmt.Sprintf("%s: %v", arg, err))
+			return false
+		}
+
+		if c.config.Output == OutputJSON {
+			c.printJSON(resolveOutput{Key: key, LongURL: longURL.String()})
+			return true
+		}
+
+		fmt.Fprintln(c.stdout, longURL)
+		return true
+	})
+}
+
+func (c *cli) stats(args []string) int {
+	flags := c.flagSet("stats")
+	interval := flags.String("interval", "", "bucket size, hour or day")
+	from := flags.String("from", "", "start of the range, RFC3339")
+	to := flags.String("to", "", "end of the range, RFC3339")
+	if flags.Parse(args) != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(c.stderr, "stats needs exactly one key or short url")
+		return 2
+	}
+
+	query := client.StatsQuery{Interval: *interval}
+	for _, bound := range []struct {
+		name  string
+		value string
+		t     *time.Time
+	}{{"from", *from, &query.From}, {"to", *to, &query.To}} {
+		if bound.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, bound.value)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "invalid --%s: %v\n", bound.name, err)
+			return 2
+		}
+		*bound.t = t
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+
+	stats, err := c.client.Stats(ctx, encodedKey(flags.Arg(0)), query)
+	if err != nil {
+		fmt.Fprintln(c.stderr, err)
+		return 1
+	}
+
+	if c.config.Output == OutputJSON {
+		c.printJSON(stats)
+		return 0
+	}
+
+	fmt.Fprintf(c.stdout, "%s: %d clicks\n", stats.EncodedKey, stats.TotalClicks)
+	for _, bucket := range stats.Series {
+		fmt.Fprintf(c.stdout, "%s\t%d\n", bucket.Start.Format(time.RFC3339), bucket.Clicks)
+	}
+	return 0
+}
+
+func (c *cli) health(args []string) int {
+	flags := c.flagSet("health")
+	if flags.Parse(args) != nil {
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+
+	health, err := c.client.Health(ctx)
+	if err != nil {
+		fmt.Fprintln(c.stderr, err)
+		return 1
+	}
+
+	if c.config.Output == OutputJSON {
+		c.printJSON(health)
+	}
+
+	names := make([]string, 0, len(health))
+	for name := range health {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	code := 0
+	for _, name := range names {
+		state := "ok"
+		if !health[name] {
+			state = "down"
+			code = 1
+		}
+		if c.config.Output == OutputHuman {
+			fmt.Fprintf(c.stdout, "%s: %s\n", name, state)
+		}
+	}
+	return code
+}
+
+func (c *cli) flagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	return flags
+}
+
+// each calls do with every argument, or with every non blank line of stdin when
+// there are none, and keeps going past failures so a bulk run reports all of them.
+func (c *cli) each(args []string, do func(string) bool) int {
+	code := 0
+	handle := func(arg string) {
+		if !do(arg) {
+			code = 1
+		}
+	}
+
+	if len(args) > 0 {
+		for _, arg := range args {
+			handle(arg)
+		}
+		return code
+	}
+
+	scanner := bufio.NewScanner(c.stdin)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		handle(line)
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(c.stderr, "failed to read stdin: %v\n", err)
+		return 1
+	}
+
+	return code
+}
+
+// fail reports a failed item, in json it stays on stdout next to the items that
+// went through so the output lines up with the input.
+func (c *cli) fail(output any, message string) {
+	if c.config.Output == OutputJSON {
+		c.printJSON(output)
+		return
+	}
+	fmt.Fprintln(c.stderr, message)
+}
+
+func (c *cli) printJSON(v any) {
+	encoder := json.NewEncoder(c.stdout)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(v)
+}
+
+// encodedKey accepts a short url as well as a bare key.
+func encodedKey(arg string) string {
+	if u, err := url.Parse(arg); err == nil && u.Host != "" {
+		return path.Base(u.Path)
+	}
+	return arg
+}
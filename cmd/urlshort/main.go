@@ -0,0 +1,166 @@
+// Command urlshort shortens and inspects links through the url shortener HTTP API.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ggoulart/url-shortener/pkg/client"
+	"github.com/spf13/viper"
+)
+
+const usage = `usage: urlshort [flags] <command> [command flags] [args]
+
+commands:
+  shorten [--alias A] [--ttl 72h | --expires-at RFC3339] [url...]
+  resolve [key or short url...]
+  stats [--interval hour|day] [--from RFC3339] [--to RFC3339] <key or short url>
+  health
+
+shorten and resolve read one argument per line from stdin when none are given.
+
+flags:
+`
+
+const (
+	OutputHuman = "human"
+	OutputJSON  = "json"
+)
+
+// Config is read from the dotfile, then URLSHORT_<KEY> environment variables,
+// then flags, each one overriding the one before.
+type Config struct {
+	Server  string        `mapstructure:"SERVER"`
+	Output  string        `mapstructure:"OUTPUT"`
+	Timeout time.Duration `mapstructure:"TIMEOUT"`
+	Retries int           `mapstructure:"RETRIES"`
+	// APIKey is needed by shorten and stats, resolve and health work without one
+	APIKey string `mapstructure:"API_KEY"`
+}
+
+var configKeys = []string{"SERVER", "OUTPUT", "TIMEOUT", "RETRIES", "API_KEY"}
+
+type cli struct {
+	config Config
+	client *client.Client
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr, os.Getenv))
+}
+
+// run returns the exit code: 0 when everything went through, 1 when something
+// failed and 2 when the command line is wrong.
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer, getenv func(string) string) int {
+	flags := flag.NewFlagSet("urlshort", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+	configFile := flags.String("config", "", "dotfile to read, defaults to $HOME/.urlshort.yaml")
+	flags.String("server", "", "base url of the server (SERVER)")
+	flags.String("output", "", "output format, human or json (OUTPUT)")
+	flags.Duration("timeout", 0, "timeout of each request (TIMEOUT)")
+	flags.Int("retries", 0, "retries of idempotent requests (RETRIES)")
+	flags.String("api-key", "", "api key to authenticate with, prefer URLSHORT_API_KEY (API_KEY)")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	overrides := map[string]string{}
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name != "config" {
+			overrides[strings.ReplaceAll(f.Name, "-", "_")] = f.Value.String()
+		}
+	})
+
+	config, err := loadConfig(*configFile, getenv, overrides)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	c, err := client.New(config.Server, client.WithRetries(config.Retries), client.WithAPIKey(config.APIKey))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	cli := &cli{config: *config, client: c, stdin: stdin, stdout: stdout, stderr: stderr}
+
+	command, commandArgs := flags.Arg(0), flags.Args()[1:]
+	switch command {
+	case "shorten":
+		return cli.shorten(commandArgs)
+	case "resolve":
+		return cli.resolve(commandArgs)
+	case "stats":
+		return cli.stats(commandArgs)
+	case "health":
+		return cli.health(commandArgs)
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n", command)
+		flags.Usage()
+		return 2
+	}
+}
+
+func loadConfig(configFile string, getenv func(string) string, flags map[string]string) (*Config, error) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.SetDefault("SERVER", "http://localhost:8080")
+	v.SetDefault("OUTPUT", OutputHuman)
+	v.SetDefault("TIMEOUT", 10*time.Second)
+	v.SetDefault("RETRIES", client.DefaultRetries)
+
+	if configFile == "" {
+		if home := getenv("HOME"); home != "" {
+			if _, err := os.Stat(filepath.Join(home, ".urlshort.yaml")); err == nil {
+				configFile = filepath.Join(home, ".urlshort.yaml")
+			}
+		}
+	}
+	if configFile != "" {
+		v.SetConfigFile(configFile)
+		err := v.ReadInConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config file: %v", err)
+		}
+	}
+
+	for _, key := range configKeys {
+		if value := getenv("URLSHORT_" + key); value != "" {
+			v.Set(key, value)
+		}
+	}
+	for name, value := range flags {
+		v.Set(name, value)
+	}
+
+	config := &Config{}
+	err := v.Unmarshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %v", err)
+	}
+
+	if config.Server == "" || config.Timeout <= 0 || config.Retries < 0 || (config.Output != OutputHuman && config.Output != OutputJSON) {
+		return nil, fmt.Errorf("invalid config: server %q, output %q, timeout %s, retries %d", config.Server, config.Output, config.Timeout, config.Retries)
+	}
+
+	return config, nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	dotfile := filepath.Join(dir, ".urlshort.yaml")
+	assert.NoError(t, os.WriteFile(dotfile, []byte("SERVER: http://dotfile:8080\nOUTPUT: json\nTIMEOUT: 3s\n"), 0o600))
+
+	tests := []struct {
+		name       string
+		configFile string
+		env        map[string]string
+		flags      map[string]string
+		want       *Config
+		wantErr    error
+	}{
+		{
+			name: "applies defaults without a dotfile",
+			env:  map[string]string{"HOME": t.TempDir()},
+			want: &Config{Server: "http://localhost:8080", Output: "human", Timeout: 10 * time.Second, Retries: 2},
+		},
+		{
+			name: "reads the dotfile in home",
+			env:  map[string]string{"HOME": dir},
+			want: &Config{Server: "http://dotfile:8080", Output: "json", Timeout: 3 * time.Second, Retries: 2},
+		},
+		{
+			name:  "environment overrides the dotfile and flags override both",
+			env:   map[string]string{"HOME": dir, "URLSHORT_SERVER": "http://env:8080", "URLSHORT_RETRIES": "5", "URLSHORT_API_KEY": "usk_env"},
+			flags: map[string]string{"retries": "0", "output": "human", "api_key": "usk_flag"},
+			want:  &Config{Server: "http://env:8080", Output: "human", Timeout: 3 * time.Second, Retries: 0, APIKey: "usk_flag"},
+		},
+		{
+			name:       "when config file is missing",
+			configFile: filepath.Join(dir, "missing.yaml"),
+			wantErr:    errors.New("failed to load config file: open " + filepath.Join(dir, "missing.yaml") + ": no such file or directory"),
+		},
+		{
+			name:    "when output is unknown",
+			env:     map[string]string{"URLSHORT_OUTPUT": "yaml"},
+			wantErr: errors.New(`invalid config: server "http://localhost:8080", output "yaml", timeout 10s, retries 2`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadConfig(tt.configFile, func(key string) string { return tt.env[key] }, tt.flags)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		stdin          string
+		handler        http.HandlerFunc
+		expectedCode   int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{
+			name: "shorten prints the short url and sets the token aside",
+			args: []string{"--api-key", "usk_creator", "shorten", "--ttl", "72h", "https://go.dev"},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer usk_creator" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"shortUrl":"http://localhost:8080/api/v1/abc1234","managementToken":"a-token"}`))
+			},
+			expectedStdout: "http://localhost:8080/api/v1/abc1234\n",
+			expectedStderr: "management token for http://localhost:8080/api/v1/abc1234: a-token\n",
+		},
+		{
+			name:  "shorten reads urls from stdin and keeps going past failures",
+			args:  []string{"--output", "json", "shorten"},
+			stdin: "https://go.dev\n\n# skipped\nnot a url\n",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				var body bytes.Buffer
+				body.ReadFrom(r.Body)
+				if strings.Contains(body.String(), "not a url") {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(`{"title":"invalid longUrl","status":400,"code":"invalid_long_url","errors":[{"field":"longUrl","reason":"must be an absolute url"}]}`))
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"shortUrl":"http://localhost:8080/api/v1/abc1234"}`))
+			},
+			expectedCode: 1,
+			expectedStdout: `{"longUrl":"https://go.dev","shortUrl":"http://localhost:8080/api/v1/abc1234"}` + "\n" +
+				`{"longUrl":"not a url","error":"bad request: invalid longUrl"}` + "\n",
+		},
+		{
+			name:           "shorten refuses an alias for several urls",
+			args:           []string{"shorten", "--alias", "go", "https://go.dev", "https://pkg.go.dev"},
+			handler:        func(w http.ResponseWriter, r *http.Request) {},
+			expectedCode:   2,
+			expectedStderr: "--alias needs exactly one url\n",
+		},
+		{
+			name: "resolve accepts short urls and keys",
+			args: []string{"resolve", "http://localhost:8080/api/v1/abc1234", "nope"},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/v1/abc1234" {
+					http.Redirect(w, r, "https://go.dev", http.StatusFound)
+					return
+				}
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"title":"record not found","status":404,"code":"not_found"}`))
+			},
+			expectedCode:   1,
+			expectedStdout: "https://go.dev\n",
+			expectedStderr: "nope: link not found: record not found\n",
+		},
+		{
+			name: "stats prints the series",
+			args: []string{"stats", "--interval", "hour", "abc1234"},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "interval=hour", r.URL.RawQuery)
+				w.Write([]byte(`{"encodedKey":"abc1234","totalClicks":3,"interval":"hour","series":[{"start":"2026-01-01T00:00:00Z","clicks":3}]}`))
+			},
+			expectedStdout: "abc1234: 3 clicks\n2026-01-01T00:00:00Z\t3\n",
+		},
+		{
+			name: "health fails when a backend is down",
+			args: []string{"health"},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"postgres":false,"memory":true}`))
+			},
+			expectedCode:   1,
+			expectedStdout: "memory: ok\npostgres: down\n",
+		},
+		{
+			name: "health in json",
+			args: []string{"--output", "json", "health"},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"postgres":true}`))
+			},
+			expectedStdout: `{"postgres":true}` + "\n",
+		},
+		{
+			name:           "unknown command",
+			args:           []string{"bogus"},
+			handler:        func(w http.ResponseWriter, r *http.Request) {},
+			expectedCode:   2,
+			expectedStderr: "unknown command \"bogus\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+
+			var stdout, stderr bytes.Buffer
+			env := map[string]string{"URLSHORT_SERVER": srv.URL, "URLSHORT_RETRIES": "0"}
+
+			code := run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr, func(key string) string { return env[key] })
+
+			assert.Equal(t, tt.expectedCode, code)
+			assert.Equal(t, tt.expectedStdout, stdout.String())
+			assert.True(t, strings.HasPrefix(stderr.String(), tt.expectedStderr), "stderr: %s", stderr.String())
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Package apperror describes the errors the API answers with. Each kind of error
+// is a package level *Error with a stable Code clients can branch on, more
+// specific errors are derived from it and still match it with errors.Is.
+package apperror
+
+import (
+	"errors"
+	"time"
+)
+
+type Error struct {
+	// Code is stable across releases, unlike Title and Detail
+	Code   string
+	Status int
+	Title  string
+	Detail string
+	Fields []FieldError
+	// RetryAfter tells clients when the request may succeed again, zero if unknown
+	RetryAfter time.Duration
+	kind       *Error
+}
+
+// FieldError tells which part of a request was wrong and why. Field is a query
+// parameter name or a dotted path into the JSON body.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+func New(code string, status int, title string) *Error {
+	return &Error{Code: code, Status: status, Title: title}
+}
+
+func (e *Error) Error() string {
+	if e.Detail == "" {
+		return e.Title
+	}
+	return e.Title + ": " + e.Detail
+}
+
+// Is matches the kind an error was derived from, so errors.Is(err, ErrX) holds
+// for ErrX.WithDetail(...) as well as for ErrX itself.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && e.kind != nil && e.kind == t
+}
+
+// WithDetail returns an error of the same kind that explains this occurrence.
+func (e *Error) WithDetail(detail string) *Error {
+	derived := e.derive()
+	derived.Detail = detail
+	return derived
+}
+
+// WithFields returns an error of the same kind that points at the fields at fault.
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	derived := e.derive()
+	derived.Fields = append(append([]FieldError{}, e.Fields...), fields...)
+	return derived
+}
+
+// WithRetryAfter returns an error of the same kind that tells when to come back.
+func (e *Error) WithRetryAfter(after time.Duration) *Error {
+	derived := e.derive()
+	derived.RetryAfter = after
+	return derived
+}
+
+func (e *Error) derive() *Error {
+	derived := *e
+	if derived.kind == nil {
+		derived.kind = e
+	}
+	return &derived
+}
+
+// As finds the application error in err's chain.
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
@@ -0,0 +1,90 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTest = New("test_error", http.StatusBadRequest, "test error")
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedIs     bool
+		expectedString string
+		expectedFields []FieldError
+		expectedRetry  time.Duration
+	}{
+		{
+			name:           "when error is the kind itself",
+			err:            errTest,
+			expectedIs:     true,
+			expectedString: "test error",
+		},
+		{
+			name:           "when error is derived with a detail",
+			err:            errTest.WithDetail("limit must be positive"),
+			expectedIs:     true,
+			expectedString: "test error: limit must be positive",
+		},
+		{
+			name:           "when error is derived twice",
+			err:            errTest.WithFields(FieldError{Field: "limit", Reason: "must be positive"}).WithFields(FieldError{Field: "cursor", Reason: "is malformed"}),
+			expectedIs:     true,
+			expectedString: "test error",
+			expectedFields: []FieldError{{Field: "limit", Reason: "must be positive"}, {Field: "cursor", Reason: "is malformed"}},
+		},
+		{
+			name:           "when error is derived with a retry after",
+			err:            errTest.WithDetail("slow down").WithRetryAfter(30 * time.Second),
+			expectedIs:     true,
+			expectedString: "test error: slow down",
+			expectedRetry:  30 * time.Second,
+		},
+		{
+			name:           "when error is wrapped",
+			err:            fmt.Errorf("%w: host", errTest),
+			expectedIs:     true,
+			expectedString: "test error: host",
+		},
+		{
+			name:           "when error is another kind",
+			err:            New("test_error", http.StatusBadRequest, "test error"),
+			expectedIs:     false,
+			expectedString: "test error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedIs, errors.Is(tt.err, errTest))
+			assert.Equal(t, tt.expectedString, tt.err.Error())
+
+			appErr, ok := As(tt.err)
+			assert.True(t, ok)
+			assert.Equal(t, "test_error", appErr.Code)
+			assert.Equal(t, http.StatusBadRequest, appErr.Status)
+			assert.Equal(t, tt.expectedFields, appErr.Fields)
+			assert.Equal(t, tt.expectedRetry, appErr.RetryAfter)
+		})
+	}
+}
+
+func TestError_DerivingLeavesKindUntouched(t *testing.T) {
+	errTest.WithDetail("detail").WithFields(FieldError{Field: "limit", Reason: "must be positive"}).WithRetryAfter(time.Minute)
+
+	assert.Equal(t, "", errTest.Detail)
+	assert.Nil(t, errTest.Fields)
+	assert.Zero(t, errTest.RetryAfter)
+}
+
+func TestAs(t *testing.T) {
+	_, ok := As(errors.New("plain error"))
+
+	assert.False(t, ok)
+}
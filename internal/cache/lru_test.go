@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU_Get(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		setup     func(*LRU[string, int])
+		elapsed   time.Duration
+		want      int
+		wantFound bool
+	}{
+		{
+			name:  "when key is missing",
+			setup: func(c *LRU[string, int]) {},
+		},
+		{
+			name: "when key is cached",
+			setup: func(c *LRU[string, int]) {
+				c.Set("a", 1, time.Minute)
+			},
+			want:      1,
+			wantFound: true,
+		},
+		{
+			name: "when key has expired",
+			setup: func(c *LRU[string, int]) {
+				c.Set("a", 1, time.Minute)
+			},
+			elapsed: time.Minute,
+		},
+		{
+			name: "when key was overwritten",
+			setup: func(c *LRU[string, int]) {
+				c.Set("a", 1, time.Second)
+				c.Set("a", 2, time.Minute)
+			},
+			elapsed:   time.Second,
+			want:      2,
+			wantFound: true,
+		},
+		{
+			name: "when key was deleted",
+			setup: func(c *LRU[string, int]) {
+				c.Set("a", 1, time.Minute)
+				c.Delete("a")
+			},
+		},
+		{
+			name: "when key was evicted as least recently used",
+			setup: func(c *LRU[string, int]) {
+				c.Set("a", 1, time.Minute)
+				c.Set("b", 2, time.Minute)
+				c.Set("c", 3, time.Minute)
+			},
+		},
+		{
+			name: "when key was used recently it survives eviction",
+			setup: func(c *LRU[string, int]) {
+				c.Set("a", 1, time.Minute)
+				c.Set("b", 2, time.Minute)
+				c.Get("a")
+				c.Set("c", 3, time.Minute)
+			},
+			want:      1,
+			wantFound: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := now
+			c := NewLRU[string, int](2)
+			c.now = func() time.Time { return current }
+			tt.setup(c)
+			current = current.Add(tt.elapsed)
+
+			got, found := c.Get("a")
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantFound, found)
+			assert.LessOrEqual(t, c.Len(), 2)
+		})
+	}
+}
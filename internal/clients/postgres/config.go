@@ -13,6 +13,8 @@ type Config struct {
 	Pass    string `mapstructure:"PASS"`
 	DBName  string `mapstructure:"NAME"`
 	SSLMode string `mapstructure:"SSL_MODE"`
+	// AutoMigrate applies pending migrations when the service starts.
+	AutoMigrate bool `mapstructure:"AUTO_MIGRATE"`
 }
 
 func NewConfig() (*Config, error) {
@@ -0,0 +1,252 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ggoulart/url-shortener/migrations"
+	"github.com/stretchr/testify/assert"
+)
+
+var testMigrations = fstest.MapFS{
+	"000001_create_urls.up.sql":     {Data: []byte("CREATE TABLE urls ()")},
+	"000001_create_urls.down.sql":   {Data: []byte("DROP TABLE urls")},
+	"000002_create_clicks.up.sql":   {Data: []byte("CREATE TABLE clicks ()")},
+	"000002_create_clicks.down.sql": {Data: []byte("DROP TABLE clicks")},
+}
+
+func TestNewMigrator(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []Migration
+		wantErr error
+	}{
+		{
+			name:    "when file name is invalid",
+			files:   fstest.MapFS{"create_urls.sql": {Data: []byte("")}},
+			wantErr: errors.New("invalid migration file name: create_urls.sql"),
+		},
+		{
+			name:    "when down file is missing",
+			files:   fstest.MapFS{"000001_create_urls.up.sql": {Data: []byte("CREATE TABLE urls ()")}},
+			wantErr: errors.New("migration 1_create_urls needs both up and down files"),
+		},
+		{
+			name:  "when migrations are valid they are sorted by version",
+			files: testMigrations,
+			want: []Migration{
+				{Version: 1, Name: "create_urls", Up: "CREATE TABLE urls ()", Down: "DROP TABLE urls"},
+				{Version: 2, Name: "create_clicks", Up: "CREATE TABLE clicks ()", Down: "DROP TABLE clicks"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewMigrator(nil, tt.files)
+
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr == nil {
+				assert.Equal(t, tt.want, got.migrations)
+			}
+		})
+	}
+}
+
+func TestNewMigrator_EmbeddedMigrations(t *testing.T) {
+	m, err := NewMigrator(nil, migrations.FS)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, m.migrations)
+	assert.Equal(t, int64(1), m.migrations[0].Version)
+}
+
+func TestMigrator_Up(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		want    int
+		wantErr error
+	}{
+		{
+			name: "when schema version is dirty",
+			setup: func(s sqlmock.Sqlmock) {
+				expectLock(s)
+				s.ExpectQuery(regexp.QuoteMeta(`SELECT version, dirty FROM schema_migrations LIMIT 1`)).
+					WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(1, true))
+				expectUnlock(s)
+			},
+			wantErr: errors.New("schema version 1 is dirty, fix it by hand before migrating"),
+		},
+		{
+			name: "when a migration fails it is rolled back",
+			setup: func(s sqlmock.Sqlmock) {
+				expectLock(s)
+				expectVersion(s, 0)
+				s.ExpectBegin()
+				s.ExpectExec(regexp.QuoteMeta(`CREATE TABLE urls ()`)).WillReturnError(errors.New("syntax error"))
+				s.ExpectRollback()
+				expectUnlock(s)
+			},
+			wantErr: errors.New("failed to apply migration 1_create_urls: syntax error"),
+		},
+		{
+			name: "when nothing is pending",
+			setup: func(s sqlmock.Sqlmock) {
+				expectLock(s)
+				expectVersion(s, 2)
+				expectUnlock(s)
+			},
+		},
+		{
+			name: "when pending migrations are applied",
+			setup: func(s sqlmock.Sqlmock) {
+				expectLock(s)
+				expectVersion(s, 1)
+				s.ExpectBegin()
+				s.ExpectExec(regexp.QuoteMeta(`CREATE TABLE clicks ()`)).WillReturnResult(sqlmock.NewResult(0, 0))
+				s.ExpectExec(regexp.QuoteMeta(`DELETE FROM schema_migrations`)).WillReturnResult(sqlmock.NewResult(0, 1))
+				s.ExpectExec(regexp.QuoteMeta(`INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`)).
+					WithArgs(2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				s.ExpectCommit()
+				expectUnlock(s)
+			},
+			want: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			m, err := NewMigrator(db, testMigrations)
+			assert.NoError(t, err)
+
+			got, err := m.Up(context.Background())
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMigrator_Down(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "when nothing was applied",
+			setup: func(s sqlmock.Sqlmock) {
+				expectLock(s)
+				expectVersion(s, 0)
+				expectUnlock(s)
+			},
+			wantErr: errors.New("no migration to roll back"),
+		},
+		{
+			name: "when current version is unknown",
+			setup: func(s sqlmock.Sqlmock) {
+				expectLock(s)
+				expectVersion(s, 7)
+				expectUnlock(s)
+			},
+			wantErr: errors.New("unknown migration version 7"),
+		},
+		{
+			name: "when latest migration is rolled back",
+			setup: func(s sqlmock.Sqlmock) {
+				expectLock(s)
+				expectVersion(s, 2)
+				s.ExpectBegin()
+				s.ExpectExec(regexp.QuoteMeta(`DROP TABLE clicks`)).WillReturnResult(sqlmock.NewResult(0, 0))
+				s.ExpectExec(regexp.QuoteMeta(`DELETE FROM schema_migrations`)).WillReturnResult(sqlmock.NewResult(0, 1))
+				s.ExpectExec(regexp.QuoteMeta(`INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`)).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				s.ExpectCommit()
+				expectUnlock(s)
+			},
+		},
+		{
+			name: "when first migration is rolled back no version is left",
+			setup: func(s sqlmock.Sqlmock) {
+				expectLock(s)
+				expectVersion(s, 1)
+				s.ExpectBegin()
+				s.ExpectExec(regexp.QuoteMeta(`DROP TABLE urls`)).WillReturnResult(sqlmock.NewResult(0, 0))
+				s.ExpectExec(regexp.QuoteMeta(`DELETE FROM schema_migrations`)).WillReturnResult(sqlmock.NewResult(0, 1))
+				s.ExpectCommit()
+				expectUnlock(s)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			m, err := NewMigrator(db, testMigrations)
+			assert.NoError(t, err)
+
+			err = m.Down(context.Background())
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMigrator_Status(t *testing.T) {
+	db, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	expectLock(dbMock)
+	expectVersion(dbMock, 1)
+	expectUnlock(dbMock)
+
+	m, err := NewMigrator(db, testMigrations)
+	assert.NoError(t, err)
+
+	got, err := m.Status(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []MigrationStatus{
+		{Migration: m.migrations[0], Applied: true},
+		{Migration: m.migrations[1], Applied: false},
+	}, got)
+}
+
+func expectLock(s sqlmock.Sqlmock) {
+	s.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_lock($1)`)).WithArgs(migrationLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+	s.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS schema_migrations`)).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func expectUnlock(s sqlmock.Sqlmock) {
+	s.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_unlock($1)`)).WithArgs(migrationLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func expectVersion(s sqlmock.Sqlmock, version int64) {
+	query := s.ExpectQuery(regexp.QuoteMeta(`SELECT version, dirty FROM schema_migrations LIMIT 1`))
+	if version == 0 {
+		query.WillReturnError(sql.ErrNoRows)
+		return
+	}
+
+	query.WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(version, false))
+}
@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyService interface {
+	Mint(ctx context.Context, name string, scopes []model.Scope) (model.MintedAPIKey, error)
+	ListAPIKeys(ctx context.Context) ([]model.APIKey, error)
+	Revoke(ctx context.Context, id string) error
+}
+
+type APIKeyController struct {
+	service APIKeyService
+}
+
+func NewAPIKeyController(service APIKeyService) *APIKeyController {
+	return &APIKeyController{service: service}
+}
+
+func (c *APIKeyController) Mint(ctx *gin.Context) {
+	var body MintAPIKeyRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("failed to parse body: %v", err))
+		ctx.Error(bindError(err, &body))
+		return
+	}
+
+	scopes := make([]model.Scope, 0, len(body.Scopes))
+	for _, scope := range body.Scopes {
+		scopes = append(scopes, model.Scope(scope))
+	}
+
+	key, err := c.service.Mint(ctx, body.Name, scopes)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, MintAPIKeyResponse{APIKeyResponse: apiKeyResponse(key.APIKey), Key: key.Secret})
+}
+
+func (c *APIKeyController) List(ctx *gin.Context) {
+	keys, err := c.service.ListAPIKeys(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response := ListAPIKeysResponse{Keys: make([]APIKeyResponse, 0, len(keys))}
+	for _, key := range keys {
+		response.Keys = append(response.Keys, apiKeyResponse(key))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *APIKeyController) Revoke(ctx *gin.Context) {
+	err := c.service.Revoke(ctx, ctx.Param("id"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func apiKeyResponse(key model.APIKey) APIKeyResponse {
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	return APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     scopes,
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
+
+type MintAPIKeyRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required"`
+}
+
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// MintAPIKeyResponse carries the secret, the only time it is ever shown.
+type MintAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+type ListAPIKeysResponse struct {
+	Keys []APIKeyResponse `json:"keys"`
+}
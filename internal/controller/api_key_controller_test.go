@@ -0,0 +1,205 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAPIKeyController_Mint(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		requestBody          string
+		setup                func(*MockAPIKeyService)
+		expectedStatusCode   int
+		expectedResponseBody string
+		expectedError        error
+	}{
+		{
+			name:          "when failed to parse request body",
+			requestBody:   "{",
+			setup:         func(*MockAPIKeyService) {},
+			expectedError: ErrBadRequest.WithDetail("body is not valid JSON"),
+		},
+		{
+			name:          "when scopes are missing",
+			requestBody:   `{"name": "ci"}`,
+			setup:         func(*MockAPIKeyService) {},
+			expectedError: ErrBadRequest.WithFields(apperror.FieldError{Field: "scopes", Reason: "is required"}),
+		},
+		{
+			name:        "when api key service failed",
+			requestBody: `{"name": "ci", "scopes": ["delete"]}`,
+			setup: func(m *MockAPIKeyService) {
+				m.On("Mint", mock.AnythingOfType("*gin.Context"), "ci", []model.Scope{"delete"}).Return(model.MintedAPIKey{}, errors.New("api key service failed"))
+			},
+			expectedError: errors.New("api key service failed"),
+		},
+		{
+			name:        "when successfully mints a key",
+			requestBody: `{"name": "ci", "scopes": ["create", "read"]}`,
+			setup: func(m *MockAPIKeyService) {
+				key := model.APIKey{ID: "a-key-id", Name: "ci", Prefix: "usk_aB3dE6gH", Hash: "a-hash", Scopes: []model.Scope{model.ScopeCreate, model.ScopeRead}, CreatedAt: createdAt}
+				m.On("Mint", mock.AnythingOfType("*gin.Context"), "ci", []model.Scope{model.ScopeCreate, model.ScopeRead}).Return(model.MintedAPIKey{APIKey: key, Secret: "usk_aB3dE6gH-secret"}, nil)
+			},
+			expectedStatusCode:   http.StatusCreated,
+			expectedResponseBody: `{"id":"a-key-id","name":"ci","prefix":"usk_aB3dE6gH","scopes":["create","read"],"createdAt":"2026-01-01T00:00:00Z","key":"usk_aB3dE6gH-secret"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockAPIKeyService{}
+			tt.setup(m)
+
+			c := NewAPIKeyController(m)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{
+				Body: io.NopCloser(strings.NewReader(tt.requestBody)),
+			}
+
+			c.Mint(ctx)
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
+			} else {
+				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+				assert.Equal(t, tt.expectedResponseBody, recorder.Body.String())
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAPIKeyController_List(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	revokedAt := createdAt.Add(time.Hour)
+
+	tests := []struct {
+		name                 string
+		setup                func(*MockAPIKeyService)
+		expectedStatusCode   int
+		expectedResponseBody string
+		expectedError        error
+	}{
+		{
+			name: "when api key service failed",
+			setup: func(m *MockAPIKeyService) {
+				m.On("ListAPIKeys", mock.AnythingOfType("*gin.Context")).Return([]model.APIKey(nil), errors.New("api key service failed"))
+			},
+			expectedError: errors.New("api key service failed"),
+		},
+		{
+			name: "when successfully lists keys without their hashes",
+			setup: func(m *MockAPIKeyService) {
+				m.On("ListAPIKeys", mock.AnythingOfType("*gin.Context")).Return([]model.APIKey{
+					{ID: "a-key-id", Name: "ci", Prefix: "usk_aB3dE6gH", Hash: "a-hash", Scopes: []model.Scope{model.ScopeAdmin}, CreatedAt: createdAt, RevokedAt: &revokedAt},
+				}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"keys":[{"id":"a-key-id","name":"ci","prefix":"usk_aB3dE6gH","scopes":["admin"],"createdAt":"2026-01-01T00:00:00Z","revokedAt":"2026-01-01T01:00:00Z"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockAPIKeyService{}
+			tt.setup(m)
+
+			c := NewAPIKeyController(m)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{}
+
+			c.List(ctx)
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
+			} else {
+				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+				assert.Equal(t, tt.expectedResponseBody, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestAPIKeyController_Revoke(t *testing.T) {
+	tests := []struct {
+		name               string
+		setup              func(*MockAPIKeyService)
+		expectedStatusCode int
+		expectedError      error
+	}{
+		{
+			name: "when api key service failed",
+			setup: func(m *MockAPIKeyService) {
+				m.On("Revoke", mock.AnythingOfType("*gin.Context"), "a-key-id").Return(errors.New("api key service failed"))
+			},
+			expectedError: errors.New("api key service failed"),
+		},
+		{
+			name: "when successfully revokes the key",
+			setup: func(m *MockAPIKeyService) {
+				m.On("Revoke", mock.AnythingOfType("*gin.Context"), "a-key-id").Return(nil)
+			},
+			expectedStatusCode: http.StatusNoContent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockAPIKeyService{}
+			tt.setup(m)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{}
+			ctx.Params = gin.Params{{Key: "id", Value: "a-key-id"}}
+
+			c := NewAPIKeyController(m)
+
+			c.Revoke(ctx)
+
+			ctx.Writer.WriteHeaderNow()
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
+			} else {
+				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) Mint(ctx context.Context, name string, scopes []model.Scope) (model.MintedAPIKey, error) {
+	args := m.Called(ctx, name, scopes)
+	return args.Get(0).(model.MintedAPIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) ListAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) Revoke(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+type UserService interface {
+	Register(ctx context.Context, email string, password string) (model.User, error)
+	Login(ctx context.Context, email string, password string) (model.SessionTokens, error)
+	Refresh(ctx context.Context, refreshToken string) (model.SessionTokens, error)
+	Logout(ctx context.Context, refreshToken string) error
+}
+
+type AuthController struct {
+	service UserService
+}
+
+func NewAuthController(service UserService) *AuthController {
+	return &AuthController{service: service}
+}
+
+func (c *AuthController) Register(ctx *gin.Context) {
+	var body CredentialsRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("failed to parse body: %v", err))
+		ctx.Error(bindError(err, &body))
+		return
+	}
+
+	user, err := c.service.Register(ctx, body.Email, body.Password)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, UserResponse{ID: user.ID, Email: user.Email, CreatedAt: user.CreatedAt})
+}
+
+func (c *AuthController) Login(ctx *gin.Context) {
+	var body CredentialsRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("failed to parse body: %v", err))
+		ctx.Error(bindError(err, &body))
+		return
+	}
+
+	tokens, err := c.service.Login(ctx, body.Email, body.Password)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sessionResponse(tokens))
+}
+
+func (c *AuthController) Refresh(ctx *gin.Context) {
+	var body RefreshRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("failed to parse body: %v", err))
+		ctx.Error(bindError(err, &body))
+		return
+	}
+
+	tokens, err := c.service.Refresh(ctx, body.RefreshToken)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sessionResponse(tokens))
+}
+
+// Logout takes the refresh token rather than the access token, so a session can still
+// be ended once its access token expired.
+func (c *AuthController) Logout(ctx *gin.Context) {
+	var body RefreshRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("failed to parse body: %v", err))
+		ctx.Error(bindError(err, &body))
+		return
+	}
+
+	err = c.service.Logout(ctx, body.RefreshToken)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func sessionResponse(tokens model.SessionTokens) SessionResponse {
+	return SessionResponse{
+		AccessToken:      tokens.AccessToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        tokens.AccessExpiresAt,
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+	}
+}
+
+type CredentialsRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+type UserResponse struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// SessionResponse carries the session tokens, the only time they are ever shown.
+type SessionResponse struct {
+	AccessToken      string    `json:"accessToken"`
+	TokenType        string    `json:"tokenType"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	RefreshToken     string    `json:"refreshToken"`
+	RefreshExpiresAt time.Time `json:"refreshExpiresAt"`
+}
@@ -0,0 +1,291 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuthController_Register(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		requestBody          string
+		setup                func(*MockUserService)
+		expectedStatusCode   int
+		expectedResponseBody string
+		expectedError        error
+	}{
+		{
+			name:          "when failed to parse request body",
+			requestBody:   "{",
+			setup:         func(*MockUserService) {},
+			expectedError: ErrBadRequest.WithDetail("body is not valid JSON"),
+		},
+		{
+			name:          "when password is missing",
+			requestBody:   `{"email": "ana@example.com"}`,
+			setup:         func(*MockUserService) {},
+			expectedError: ErrBadRequest.WithFields(apperror.FieldError{Field: "password", Reason: "is required"}),
+		},
+		{
+			name:        "when user service failed",
+			requestBody: `{"email": "ana@example.com", "password": "a-long-password"}`,
+			setup: func(m *MockUserService) {
+				m.On("Register", mock.AnythingOfType("*gin.Context"), "ana@example.com", "a-long-password").Return(model.User{}, errors.New("user service failed"))
+			},
+			expectedError: errors.New("user service failed"),
+		},
+		{
+			name:        "when successfully registers without the password hash",
+			requestBody: `{"email": "ana@example.com", "password": "a-long-password"}`,
+			setup: func(m *MockUserService) {
+				m.On("Register", mock.AnythingOfType("*gin.Context"), "ana@example.com", "a-long-password").Return(model.User{ID: "a-user-id", Email: "ana@example.com", PasswordHash: "a-hash", CreatedAt: createdAt}, nil)
+			},
+			expectedStatusCode:   http.StatusCreated,
+			expectedResponseBody: `{"id":"a-user-id","email":"ana@example.com","createdAt":"2026-01-01T00:00:00Z"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockUserService{}
+			tt.setup(m)
+
+			c := NewAuthController(m)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{
+				Body: io.NopCloser(strings.NewReader(tt.requestBody)),
+			}
+
+			c.Register(ctx)
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
+			} else {
+				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+				assert.Equal(t, tt.expectedResponseBody, recorder.Body.String())
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthController_Login(t *testing.T) {
+	expiresAt := time.Date(2026, 1, 1, 0, 15, 0, 0, time.UTC)
+	refreshExpiresAt := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		requestBody          string
+		setup                func(*MockUserService)
+		expectedStatusCode   int
+		expectedResponseBody string
+		expectedError        error
+	}{
+		{
+			name:          "when email is missing",
+			requestBody:   `{"password": "a-long-password"}`,
+			setup:         func(*MockUserService) {},
+			expectedError: ErrBadRequest.WithFields(apperror.FieldError{Field: "email", Reason: "is required"}),
+		},
+		{
+			name:        "when user service failed",
+			requestBody: `{"email": "ana@example.com", "password": "a-long-password"}`,
+			setup: func(m *MockUserService) {
+				m.On("Login", mock.AnythingOfType("*gin.Context"), "ana@example.com", "a-long-password").Return(model.SessionTokens{}, errors.New("user service failed"))
+			},
+			expectedError: errors.New("user service failed"),
+		},
+		{
+			name:        "when successfully logs in",
+			requestBody: `{"email": "ana@example.com", "password": "a-long-password"}`,
+			setup: func(m *MockUserService) {
+				m.On("Login", mock.AnythingOfType("*gin.Context"), "ana@example.com", "a-long-password").Return(model.SessionTokens{AccessToken: "uss_access", AccessExpiresAt: expiresAt, RefreshToken: "usr_refresh", RefreshExpiresAt: refreshExpiresAt}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"accessToken":"uss_access","tokenType":"Bearer","expiresAt":"2026-01-01T00:15:00Z","refreshToken":"usr_refresh","refreshExpiresAt":"2026-01-31T00:00:00Z"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockUserService{}
+			tt.setup(m)
+
+			c := NewAuthController(m)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{
+				Body: io.NopCloser(strings.NewReader(tt.requestBody)),
+			}
+
+			c.Login(ctx)
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
+			} else {
+				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+				assert.Equal(t, tt.expectedResponseBody, recorder.Body.String())
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthController_Refresh(t *testing.T) {
+	expiresAt := time.Date(2026, 1, 1, 0, 15, 0, 0, time.UTC)
+	refreshExpiresAt := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		requestBody          string
+		setup                func(*MockUserService)
+		expectedStatusCode   int
+		expectedResponseBody string
+		expectedError        error
+	}{
+		{
+			name:          "when refresh token is missing",
+			requestBody:   `{}`,
+			setup:         func(*MockUserService) {},
+			expectedError: ErrBadRequest.WithFields(apperror.FieldError{Field: "refreshToken", Reason: "is required"}),
+		},
+		{
+			name:        "when user service failed",
+			requestBody: `{"refreshToken": "usr_refresh"}`,
+			setup: func(m *MockUserService) {
+				m.On("Refresh", mock.AnythingOfType("*gin.Context"), "usr_refresh").Return(model.SessionTokens{}, errors.New("user service failed"))
+			},
+			expectedError: errors.New("user service failed"),
+		},
+		{
+			name:        "when successfully rotates the tokens",
+			requestBody: `{"refreshToken": "usr_refresh"}`,
+			setup: func(m *MockUserService) {
+				m.On("Refresh", mock.AnythingOfType("*gin.Context"), "usr_refresh").Return(model.SessionTokens{AccessToken: "uss_new-access", AccessExpiresAt: expiresAt, RefreshToken: "usr_new-refresh", RefreshExpiresAt: refreshExpiresAt}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"accessToken":"uss_new-access","tokenType":"Bearer","expiresAt":"2026-01-01T00:15:00Z","refreshToken":"usr_new-refresh","refreshExpiresAt":"2026-01-31T00:00:00Z"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockUserService{}
+			tt.setup(m)
+
+			c := NewAuthController(m)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{
+				Body: io.NopCloser(strings.NewReader(tt.requestBody)),
+			}
+
+			c.Refresh(ctx)
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
+			} else {
+				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+				assert.Equal(t, tt.expectedResponseBody, recorder.Body.String())
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthController_Logout(t *testing.T) {
+	tests := []struct {
+		name               string
+		requestBody        string
+		setup              func(*MockUserService)
+		expectedStatusCode int
+		expectedError      error
+	}{
+		{
+			name:          "when refresh token is missing",
+			requestBody:   `{}`,
+			setup:         func(*MockUserService) {},
+			expectedError: ErrBadRequest.WithFields(apperror.FieldError{Field: "refreshToken", Reason: "is required"}),
+		},
+		{
+			name:        "when user service failed",
+			requestBody: `{"refreshToken": "usr_refresh"}`,
+			setup: func(m *MockUserService) {
+				m.On("Logout", mock.AnythingOfType("*gin.Context"), "usr_refresh").Return(errors.New("user service failed"))
+			},
+			expectedError: errors.New("user service failed"),
+		},
+		{
+			name:        "when successfully revokes the session",
+			requestBody: `{"refreshToken": "usr_refresh"}`,
+			setup: func(m *MockUserService) {
+				m.On("Logout", mock.AnythingOfType("*gin.Context"), "usr_refresh").Return(nil)
+			},
+			expectedStatusCode: http.StatusNoContent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockUserService{}
+			tt.setup(m)
+
+			c := NewAuthController(m)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{
+				Body: io.NopCloser(strings.NewReader(tt.requestBody)),
+			}
+
+			c.Logout(ctx)
+
+			ctx.Writer.WriteHeaderNow()
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
+			} else {
+				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+type MockUserService struct {
+	mock.Mock
+}
+
+func (m *MockUserService) Register(ctx context.Context, email string, password string) (model.User, error) {
+	args := m.Called(ctx, email, password)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+func (m *MockUserService) Login(ctx context.Context, email string, password string) (model.SessionTokens, error) {
+	args := m.Called(ctx, email, password)
+	return args.Get(0).(model.SessionTokens), args.Error(1)
+}
+
+func (m *MockUserService) Refresh(ctx context.Context, refreshToken string) (model.SessionTokens, error) {
+	args := m.Called(ctx, refreshToken)
+	return args.Get(0).(model.SessionTokens), args.Error(1)
+}
+
+func (m *MockUserService) Logout(ctx context.Context, refreshToken string) error {
+	args := m.Called(ctx, refreshToken)
+	return args.Error(0)
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+)
+
+type DocsController struct {
+	spec      []byte
+	swaggerUI []byte
+}
+
+// NewDocsController renders doc once, it doesn't change while the server runs.
+func NewDocsController(doc *openapi3.T, swaggerUI []byte) (*DocsController, error) {
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render openapi document: %v", err)
+	}
+
+	return &DocsController{spec: spec, swaggerUI: swaggerUI}, nil
+}
+
+func (c *DocsController) OpenAPI(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "application/json; charset=utf-8", c.spec)
+}
+
+func (c *DocsController) SwaggerUI(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", c.swaggerUI)
+}
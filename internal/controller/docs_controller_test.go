@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocsController(t *testing.T) {
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info:    &openapi3.Info{Title: "test", Version: "1.0.0"},
+		Paths:   openapi3.NewPaths(),
+	}
+
+	tests := []struct {
+		name                 string
+		handler              func(*DocsController) gin.HandlerFunc
+		expectedContentType  string
+		expectedResponseBody string
+	}{
+		{
+			name:                 "when openapi document is requested",
+			handler:              func(c *DocsController) gin.HandlerFunc { return c.OpenAPI },
+			expectedContentType:  "application/json; charset=utf-8",
+			expectedResponseBody: `{"info":{"title":"test","version":"1.0.0"},"openapi":"3.0.3","paths":{}}`,
+		},
+		{
+			name:                 "when swagger ui is requested",
+			handler:              func(c *DocsController) gin.HandlerFunc { return c.SwaggerUI },
+			expectedContentType:  "text/html; charset=utf-8",
+			expectedResponseBody: `<html></html>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewDocsController(doc, []byte(`<html></html>`))
+			assert.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{}
+
+			tt.handler(c)(ctx)
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, tt.expectedContentType, recorder.Header().Get("Content-Type"))
+			assert.Equal(t, tt.expectedResponseBody, recorder.Body.String())
+		})
+	}
+}
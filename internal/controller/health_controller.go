@@ -4,11 +4,13 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/ggoulart/url-shortener/internal/model"
 	"github.com/gin-gonic/gin"
 )
 
 type HealthService interface {
 	Health(ctx context.Context) map[string]bool
+	CacheStats(ctx context.Context) (model.CacheStats, bool)
 }
 
 type HealthController struct {
@@ -24,3 +26,16 @@ func (h *HealthController) Health(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, health)
 }
+
+func (h *HealthController) Cache(ctx *gin.Context) {
+	stats, enabled := h.service.CacheStats(ctx)
+
+	ctx.JSON(http.StatusOK, CacheStatsResponse{Enabled: enabled, Hits: stats.Hits, Misses: stats.Misses, Size: stats.Size})
+}
+
+type CacheStatsResponse struct {
+	Enabled bool  `json:"enabled"`
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+	Size    int   `json:"size"`
+}
@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/ggoulart/url-shortener/internal/model"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -45,6 +46,45 @@ func TestHealthController_Health(t *testing.T) {
 	}
 }
 
+func TestHealthController_Cache(t *testing.T) {
+	tests := []struct {
+		name                 string
+		setup                func(*MockHealthService)
+		expectedResponseBody string
+	}{
+		{
+			name: "when links are cached",
+			setup: func(m *MockHealthService) {
+				m.On("CacheStats", mock.Anything).Return(model.CacheStats{Hits: 8, Misses: 2, Size: 2}, true)
+			},
+			expectedResponseBody: `{"enabled":true,"hits":8,"misses":2,"size":2}`,
+		},
+		{
+			name: "when the cache is disabled",
+			setup: func(m *MockHealthService) {
+				m.On("CacheStats", mock.Anything).Return(model.CacheStats{}, false)
+			},
+			expectedResponseBody: `{"enabled":false,"hits":0,"misses":0,"size":0}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockHealthService{}
+			tt.setup(m)
+			h := NewHealthController(m)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{}
+
+			h.Cache(ctx)
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, tt.expectedResponseBody, recorder.Body.String())
+		})
+	}
+}
+
 type MockHealthService struct {
 	mock.Mock
 }
@@ -53,3 +93,8 @@ func (h *MockHealthService) Health(ctx context.Context) map[string]bool {
 	args := h.Called(ctx)
 	return args.Get(0).(map[string]bool)
 }
+
+func (h *MockHealthService) CacheStats(ctx context.Context) (model.CacheStats, bool) {
+	args := h.Called(ctx)
+	return args.Get(0).(model.CacheStats), args.Bool(1)
+}
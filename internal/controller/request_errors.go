@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/go-playground/validator/v10"
+)
+
+var ErrBadRequest = apperror.New("invalid_body", http.StatusBadRequest, "invalid body")
+var ErrInvalidParameter = apperror.New("invalid_parameter", http.StatusBadRequest, "invalid parameter")
+var ErrInvalidLongURL = apperror.New("invalid_long_url", http.StatusBadRequest, "invalid longUrl")
+var ErrInvalidTTL = apperror.New("invalid_ttl", http.StatusBadRequest, "invalid ttl")
+var ErrBodyTooLarge = apperror.New("body_too_large", http.StatusRequestEntityTooLarge, "body too large")
+
+// bindError says what was wrong with a body that couldn't be bound into body.
+func bindError(err error, body any) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var timeErr *time.ParseError
+	var validationErrs validator.ValidationErrors
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return ErrBodyTooLarge.WithDetail(fmt.Sprintf("body must be at most %d bytes", maxBytesErr.Limit))
+	case errors.Is(err, io.EOF):
+		return ErrBadRequest.WithDetail("body is empty")
+	case errors.As(err, &syntaxErr):
+		return ErrBadRequest.WithDetail(fmt.Sprintf("body is not valid JSON at offset %d", syntaxErr.Offset))
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrBadRequest.WithDetail("body is not valid JSON")
+	case errors.As(err, &typeErr) && typeErr.Field == "":
+		return ErrBadRequest.WithDetail(fmt.Sprintf("body must be %s", jsonType(typeErr.Type)))
+	case errors.As(err, &typeErr):
+		return ErrBadRequest.WithFields(apperror.FieldError{Field: typeErr.Field, Reason: "must be " + jsonType(typeErr.Type)})
+	case errors.As(err, &timeErr):
+		return ErrBadRequest.WithDetail(fmt.Sprintf("times must be RFC 3339, such as 2030-01-02T15:04:05Z: %v", timeErr))
+	case errors.As(err, &validationErrs):
+		fields := make([]apperror.FieldError, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			reason := "must pass " + fieldErr.Tag()
+			if fieldErr.Tag() == "required" {
+				reason = "is required"
+			}
+			fields = append(fields, apperror.FieldError{Field: jsonField(body, fieldErr.StructField()), Reason: reason})
+		}
+		return ErrBadRequest.WithFields(fields...)
+	default:
+		return ErrBadRequest.WithDetail(err.Error())
+	}
+}
+
+func longURLError(field string) error {
+	return ErrInvalidLongURL.WithFields(apperror.FieldError{Field: field, Reason: "must be an absolute url, such as https://example.com/page"})
+}
+
+func ttlError(field string) error {
+	return ErrInvalidTTL.WithFields(apperror.FieldError{Field: field, Reason: "must be a duration, such as 72h"})
+}
+
+func timeParameterError(name string) error {
+	return ErrInvalidParameter.WithFields(apperror.FieldError{Field: name, Reason: "must be an RFC 3339 time, such as 2030-01-02T15:04:05Z"})
+}
+
+// jsonField names a field of body the way clients wrote it.
+func jsonField(body any, structField string) string {
+	t := reflect.TypeOf(body)
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return structField
+	}
+
+	field, ok := t.FieldByName(structField)
+	if !ok {
+		return structField
+	}
+
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return structField
+	}
+	return name
+}
+
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
@@ -2,53 +2,235 @@ package controller
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"reflect"
+	"strconv"
+	"time"
 
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/ggoulart/url-shortener/internal/service"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
-var ErrBadRequest = errors.New("invalid body")
+// ManagementTokenHeader carries the secret returned when a link was created.
+const ManagementTokenHeader = "X-Management-Token"
+
+const (
+	BatchStatusCreated  = "created"
+	BatchStatusExisting = "existing"
+	BatchStatusFailed   = "failed"
+)
 
 type ShortenerService interface {
-	Shortener(ctx context.Context, longURL url.URL) (url.URL, error)
+	Shortener(ctx context.Context, longURL url.URL, options model.ShortenOptions) (model.ShortenResult, error)
+	ShortenBatch(ctx context.Context, items []model.BatchItem) ([]model.BatchResult, error)
+	ListLinks(ctx context.Context, filter model.LinkFilter, cursor string) (model.LinkPage, error)
 	Retrieve(ctx context.Context, encodedKey string) (url.URL, error)
+	DeleteLink(ctx context.Context, encodedKey string, token string) error
+	DisableLink(ctx context.Context, encodedKey string, token string) error
+	UpdateDestination(ctx context.Context, encodedKey string, token string, longURL url.URL, actor string) error
+	History(ctx context.Context, encodedKey string, token string) ([]model.HistoryEntry, error)
+	Rollback(ctx context.Context, encodedKey string, token string, version int, actor string) error
 }
 
+type ClickRecorder interface {
+	Record(click model.Click) bool
+}
+
+// maxBatchItemBytes is how much body each url of a batch may take up.
+const maxBatchItemBytes = 8 << 10
+
 type ShortenerController struct {
-	service ShortenerService
+	service      ShortenerService
+	clicks       ClickRecorder
+	batchMaxSize int
 }
 
-func NewShortenerController(service ShortenerService) *ShortenerController {
-	return &ShortenerController{service: service}
+// NewShortenerController takes batches of up to batchMaxSize urls, larger ones are
+// refused while they are read.
+func NewShortenerController(service ShortenerService, clicks ClickRecorder, batchMaxSize int) *ShortenerController {
+	return &ShortenerController{service: service, clicks: clicks, batchMaxSize: batchMaxSize}
 }
 
 func (c *ShortenerController) ShortenURL(ctx *gin.Context) {
 	var body ShortenerRequest
-	err := ctx.BindJSON(&body)
+	err := ctx.ShouldBindJSON(&body)
 	if err != nil {
 		slog.Warn(fmt.Sprintf("failed to parse body: %v", err))
-		ctx.Error(ErrBadRequest)
+		ctx.Error(bindError(err, &body))
 		return
 	}
 
 	longURL, err := url.ParseRequestURI(body.LongURL)
 	if err != nil {
 		slog.Warn(fmt.Sprintf("failed to parse url: %v", err))
-		ctx.Error(ErrBadRequest)
+		ctx.Error(longURLError("longUrl"))
+		return
+	}
+
+	options := model.ShortenOptions{Alias: body.Alias, ExpiresAt: body.ExpiresAt}
+	if body.TTL != "" {
+		options.TTL, err = time.ParseDuration(body.TTL)
+		if err != nil {
+			slog.Warn(fmt.Sprintf("failed to parse ttl: %v", err))
+			ctx.Error(ttlError("ttl"))
+			return
+		}
+	}
+
+	result, err := c.service.Shortener(ctx, *longURL, options)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	// a link handed back for a retried alias or an already shortened url was not created now
+	status := http.StatusOK
+	if result.Created {
+		status = http.StatusCreated
+	}
+
+	ctx.JSON(status, ShortenerResponse{ShortURL: result.ShortURL.String(), ManagementToken: result.ManagementToken})
+}
+
+// ShortenBatch answers with one result per url in request order. Urls that can't be
+// parsed fail on their own instead of failing the whole batch.
+func (c *ShortenerController) ShortenBatch(ctx *gin.Context) {
+	body, err := c.decodeBatch(ctx)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("failed to parse body: %v", err))
+		ctx.Error(err)
+		return
+	}
+
+	if len(body) == 0 {
+		ctx.Error(ErrBadRequest.WithDetail("body must hold at least one url"))
+		return
+	}
+
+	response := BatchShortenResponse{Results: make([]BatchResultResponse, len(body))}
+	items := make([]model.BatchItem, 0, len(body))
+	// indexes maps every item sent to the service back to its position in the request
+	indexes := make([]int, 0, len(body))
+
+	for i, request := range body {
+		response.Results[i].Index = i
+
+		item, err := batchItem(request)
+		if err != nil {
+			response.Results[i] = failedBatchResult(i, err)
+			continue
+		}
+
+		items = append(items, item)
+		indexes = append(indexes, i)
+	}
+
+	if len(items) > 0 {
+		results, err := c.service.ShortenBatch(ctx, items)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+
+		for i, result := range results {
+			response.Results[indexes[i]] = batchResultResponse(indexes[i], result)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// decodeBatch reads the urls one at a time and stops at the first one past
+// batchMaxSize, an oversized batch is never held in memory whole.
+func (c *ShortenerController) decodeBatch(ctx *gin.Context) ([]ShortenerRequest, error) {
+	var body []ShortenerRequest
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, int64(c.batchMaxSize)*maxBatchItemBytes)
+	decoder := json.NewDecoder(ctx.Request.Body)
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, bindError(err, &body)
+	}
+	if token != json.Delim('[') {
+		return nil, bindError(&json.UnmarshalTypeError{Value: fmt.Sprint(token), Type: reflect.TypeOf(body)}, &body)
+	}
+
+	for decoder.More() {
+		if len(body) == c.batchMaxSize {
+			return nil, ErrBadRequest.WithDetail(fmt.Sprintf("body must hold at most %d urls", c.batchMaxSize))
+		}
+
+		var request ShortenerRequest
+		err = decoder.Decode(&request)
+		if err == nil {
+			err = binding.Validator.ValidateStruct(request)
+		}
+		if err != nil {
+			return nil, bindError(err, &request)
+		}
+		body = append(body, request)
+	}
+
+	_, err = decoder.Token()
+	if err != nil {
+		return nil, bindError(err, &body)
+	}
+
+	return body, nil
+}
+
+func (c *ShortenerController) ListLinks(ctx *gin.Context) {
+	filter := model.LinkFilter{Domain: ctx.Query("domain"), KeyPrefix: ctx.Query("prefix"), Search: ctx.Query("q")}
+
+	var err error
+	filter.CreatedFrom, err = parseTimeQuery(ctx, "createdFrom")
+	if err != nil {
+		slog.Warn(fmt.Sprintf("failed to parse createdFrom: %v", err))
+		ctx.Error(timeParameterError("createdFrom"))
+		return
+	}
+
+	filter.CreatedTo, err = parseTimeQuery(ctx, "createdTo")
+	if err != nil {
+		slog.Warn(fmt.Sprintf("failed to parse createdTo: %v", err))
+		ctx.Error(timeParameterError("createdTo"))
 		return
 	}
 
-	shortURL, err := c.service.Shortener(ctx, *longURL)
+	if limit := ctx.Query("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			slog.Warn(fmt.Sprintf("failed to parse limit: %v", err))
+			ctx.Error(ErrInvalidParameter.WithFields(apperror.FieldError{Field: "limit", Reason: "must be an integer"}))
+			return
+		}
+	}
+
+	page, err := c.service.ListLinks(ctx, filter, ctx.Query("cursor"))
 	if err != nil {
 		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, ShortenerResponse{ShortURL: shortURL.String()})
+	response := ListLinksResponse{Links: make([]LinkResponse, 0, len(page.Links)), NextCursor: page.NextCursor}
+	for _, link := range page.Links {
+		response.Links = append(response.Links, LinkResponse{
+			EncodedKey: link.EncodedKey,
+			LongURL:    link.LongURL.String(),
+			CreatedAt:  link.CreatedAt,
+			ExpiresAt:  link.ExpiresAt,
+			DisabledAt: link.DisabledAt,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, response)
 }
 
 func (c *ShortenerController) RetrieveURL(ctx *gin.Context) {
@@ -60,13 +242,206 @@ func (c *ShortenerController) RetrieveURL(ctx *gin.Context) {
 		return
 	}
 
+	c.clicks.Record(model.Click{
+		EncodedKey: encodedKey,
+		ClickedAt:  time.Now(),
+		Referrer:   ctx.Request.Referer(),
+		UserAgent:  ctx.Request.UserAgent(),
+		ClientIP:   ctx.ClientIP(),
+	})
+
 	http.Redirect(ctx.Writer, ctx.Request, longURL.String(), http.StatusFound)
 }
 
+func (c *ShortenerController) DeleteLink(ctx *gin.Context) {
+	err := c.service.DeleteLink(ctx, ctx.Param("key"), ctx.GetHeader(ManagementTokenHeader))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *ShortenerController) DisableLink(ctx *gin.Context) {
+	err := c.service.DisableLink(ctx, ctx.Param("key"), ctx.GetHeader(ManagementTokenHeader))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *ShortenerController) UpdateDestination(ctx *gin.Context) {
+	var body UpdateDestinationRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("failed to parse body: %v", err))
+		ctx.Error(bindError(err, &body))
+		return
+	}
+
+	longURL, err := url.ParseRequestURI(body.LongURL)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("failed to parse url: %v", err))
+		ctx.Error(longURLError("longUrl"))
+		return
+	}
+
+	err = c.service.UpdateDestination(ctx, ctx.Param("key"), ctx.GetHeader(ManagementTokenHeader), *longURL, actor(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *ShortenerController) History(ctx *gin.Context) {
+	encodedKey := ctx.Param("key")
+
+	history, err := c.service.History(ctx, encodedKey, ctx.GetHeader(ManagementTokenHeader))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response := HistoryResponse{EncodedKey: encodedKey, History: make([]HistoryEntryResponse, 0, len(history))}
+	for _, entry := range history {
+		response.History = append(response.History, HistoryEntryResponse{
+			Version:   entry.Version,
+			LongURL:   entry.LongURL.String(),
+			ChangedAt: entry.ChangedAt,
+			Actor:     entry.Actor,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *ShortenerController) Rollback(ctx *gin.Context) {
+	var body RollbackRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("failed to parse body: %v", err))
+		ctx.Error(bindError(err, &body))
+		return
+	}
+
+	err = c.service.Rollback(ctx, ctx.Param("key"), ctx.GetHeader(ManagementTokenHeader), body.Version, actor(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// actor records who edited a link, the signed-in user when there is one and the
+// client address for links managed by token.
+func actor(ctx *gin.Context) string {
+	principal, ok := service.PrincipalFromContext(ctx.Request.Context())
+	if ok && principal.UserID != "" {
+		return principal.UserID
+	}
+
+	return ctx.ClientIP()
+}
+
+func batchItem(request ShortenerRequest) (model.BatchItem, error) {
+	longURL, err := url.ParseRequestURI(request.LongURL)
+	if err != nil {
+		return model.BatchItem{}, longURLError("longUrl")
+	}
+
+	item := model.BatchItem{LongURL: *longURL, Options: model.ShortenOptions{Alias: request.Alias, ExpiresAt: request.ExpiresAt}}
+	if request.TTL != "" {
+		item.Options.TTL, err = time.ParseDuration(request.TTL)
+		if err != nil {
+			return model.BatchItem{}, ttlError("ttl")
+		}
+	}
+
+	return item, nil
+}
+
+func failedBatchResult(index int, err error) BatchResultResponse {
+	result := BatchResultResponse{Index: index, Status: BatchStatusFailed, Error: err.Error()}
+	if appErr, ok := apperror.As(err); ok {
+		result.Code = appErr.Code
+	}
+
+	return result
+}
+
+func batchResultResponse(index int, result model.BatchResult) BatchResultResponse {
+	if result.Err != nil {
+		return failedBatchResult(index, result.Err)
+	}
+
+	status := BatchStatusExisting
+	if result.Created {
+		status = BatchStatusCreated
+	}
+
+	return BatchResultResponse{Index: index, Status: status, ShortURL: result.ShortURL.String(), ManagementToken: result.ManagementToken}
+}
+
 type ShortenerRequest struct {
-	LongURL string `json:"longUrl" binding:"required"`
+	LongURL   string     `json:"longUrl" binding:"required"`
+	Alias     string     `json:"alias"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+	TTL       string     `json:"ttl"`
 }
 
 type ShortenerResponse struct {
-	ShortURL string `json:"shortUrl" binding:"required"`
+	ShortURL        string `json:"shortUrl" binding:"required"`
+	ManagementToken string `json:"managementToken,omitempty"`
+}
+
+type BatchShortenResponse struct {
+	Results []BatchResultResponse `json:"results"`
+}
+
+type BatchResultResponse struct {
+	Index           int    `json:"index"`
+	Status          string `json:"status"`
+	ShortURL        string `json:"shortUrl,omitempty"`
+	ManagementToken string `json:"managementToken,omitempty"`
+	Error           string `json:"error,omitempty"`
+	Code            string `json:"code,omitempty"`
+}
+
+type ListLinksResponse struct {
+	Links      []LinkResponse `json:"links"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+}
+
+type LinkResponse struct {
+	EncodedKey string     `json:"encodedKey"`
+	LongURL    string     `json:"longUrl"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	DisabledAt *time.Time `json:"disabledAt,omitempty"`
+}
+
+type UpdateDestinationRequest struct {
+	LongURL string `json:"longUrl" binding:"required"`
+}
+
+type RollbackRequest struct {
+	Version int `json:"version" binding:"required,min=1"`
+}
+
+type HistoryResponse struct {
+	EncodedKey string                 `json:"encodedKey"`
+	History    []HistoryEntryResponse `json:"history"`
+}
+
+type HistoryEntryResponse struct {
+	Version   int       `json:"version"`
+	LongURL   string    `json:"longUrl"`
+	ChangedAt time.Time `json:"changedAt"`
+	Actor     string    `json:"actor"`
 }
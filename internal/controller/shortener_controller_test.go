@@ -9,7 +9,10 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/model"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -28,14 +31,32 @@ func TestShortenerController_ShortURL(t *testing.T) {
 			name:          "when failed to parse request body",
 			requestBody:   "{",
 			setup:         func(*MockShortenerService) {},
-			expectedError: ErrBadRequest,
+			expectedError: ErrBadRequest.WithDetail("body is not valid JSON"),
+		},
+		{
+			name:          "when longUrl is missing",
+			requestBody:   `{"alias": "go"}`,
+			setup:         func(*MockShortenerService) {},
+			expectedError: ErrBadRequest.WithFields(apperror.FieldError{Field: "longUrl", Reason: "is required"}),
+		},
+		{
+			name:          "when a field has the wrong type",
+			requestBody:   `{"longUrl": 42}`,
+			setup:         func(*MockShortenerService) {},
+			expectedError: ErrBadRequest.WithFields(apperror.FieldError{Field: "longUrl", Reason: "must be a string"}),
+		},
+		{
+			name:          "when failed to parse ttl",
+			requestBody:   `{"longUrl": "https://bytebytego.com", "ttl": "tomorrow"}`,
+			setup:         func(*MockShortenerService) {},
+			expectedError: ttlError("ttl"),
 		},
 		{
 			name:        "when shortener service failed",
 			requestBody: `{"longUrl": "https://bytebytego.com/courses/system-design-interview/design-a-url-shortener"}`,
 			setup: func(m *MockShortenerService) {
 				longURL := url.URL{Scheme: "https", Host: "bytebytego.com", Path: "/courses/system-design-interview/design-a-url-shortener"}
-				m.On("Shortener", mock.AnythingOfType("*gin.Context"), longURL).Return(url.URL{}, errors.New("shortener service failed"))
+				m.On("Shortener", mock.AnythingOfType("*gin.Context"), longURL, model.ShortenOptions{}).Return(model.ShortenResult{}, errors.New("shortener service failed"))
 			},
 			expectedError: errors.New("shortener service failed"),
 		},
@@ -45,7 +66,63 @@ func TestShortenerController_ShortURL(t *testing.T) {
 			setup: func(m *MockShortenerService) {
 				longURL := url.URL{Scheme: "https", Host: "bytebytego.com", Path: "/courses/system-design-interview/design-a-url-shortener"}
 				shortenURL, _ := url.Parse("https://gg.com/shorten")
-				m.On("Shortener", mock.AnythingOfType("*gin.Context"), longURL).Return(*shortenURL, nil)
+				m.On("Shortener", mock.AnythingOfType("*gin.Context"), longURL, model.ShortenOptions{}).Return(model.ShortenResult{ShortURL: *shortenURL, Created: true, ManagementToken: "a-management-token"}, nil)
+			},
+			expectedStatusCode:   http.StatusCreated,
+			expectedResponseBody: `{"shortUrl":"https://gg.com/shorten","managementToken":"a-management-token"}`,
+		},
+		{
+			name:        "when successfuly shortens url with alias",
+			requestBody: `{"longUrl": "https://bytebytego.com/courses/system-design-interview/design-a-url-shortener", "alias": "launch-2026"}`,
+			setup: func(m *MockShortenerService) {
+				longURL := url.URL{Scheme: "https", Host: "bytebytego.com", Path: "/courses/system-design-interview/design-a-url-shortener"}
+				shortenURL, _ := url.Parse("https://gg.com/launch-2026")
+				m.On("Shortener", mock.AnythingOfType("*gin.Context"), longURL, model.ShortenOptions{Alias: "launch-2026"}).Return(model.ShortenResult{ShortURL: *shortenURL, Created: true}, nil)
+			},
+			expectedStatusCode:   http.StatusCreated,
+			expectedResponseBody: `{"shortUrl":"https://gg.com/launch-2026"}`,
+		},
+		{
+			name:        "when the alias already points at the url",
+			requestBody: `{"longUrl": "https://bytebytego.com/courses/system-design-interview/design-a-url-shortener", "alias": "launch-2026"}`,
+			setup: func(m *MockShortenerService) {
+				longURL := url.URL{Scheme: "https", Host: "bytebytego.com", Path: "/courses/system-design-interview/design-a-url-shortener"}
+				shortenURL, _ := url.Parse("https://gg.com/launch-2026")
+				m.On("Shortener", mock.AnythingOfType("*gin.Context"), longURL, model.ShortenOptions{Alias: "launch-2026"}).Return(model.ShortenResult{ShortURL: *shortenURL}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"shortUrl":"https://gg.com/launch-2026"}`,
+		},
+		{
+			name:        "when the url already has a link",
+			requestBody: `{"longUrl": "https://bytebytego.com"}`,
+			setup: func(m *MockShortenerService) {
+				longURL := url.URL{Scheme: "https", Host: "bytebytego.com"}
+				shortenURL, _ := url.Parse("https://gg.com/shorten")
+				m.On("Shortener", mock.AnythingOfType("*gin.Context"), longURL, model.ShortenOptions{}).Return(model.ShortenResult{ShortURL: *shortenURL}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"shortUrl":"https://gg.com/shorten"}`,
+		},
+		{
+			name:        "when successfuly shortens url with ttl",
+			requestBody: `{"longUrl": "https://bytebytego.com", "ttl": "72h"}`,
+			setup: func(m *MockShortenerService) {
+				longURL := url.URL{Scheme: "https", Host: "bytebytego.com"}
+				shortenURL, _ := url.Parse("https://gg.com/shorten")
+				m.On("Shortener", mock.AnythingOfType("*gin.Context"), longURL, model.ShortenOptions{TTL: 72 * time.Hour}).Return(model.ShortenResult{ShortURL: *shortenURL, Created: true}, nil)
+			},
+			expectedStatusCode:   http.StatusCreated,
+			expectedResponseBody: `{"shortUrl":"https://gg.com/shorten"}`,
+		},
+		{
+			name:        "when successfuly shortens url with expiresAt",
+			requestBody: `{"longUrl": "https://bytebytego.com", "expiresAt": "2026-12-31T23:59:59Z"}`,
+			setup: func(m *MockShortenerService) {
+				longURL := url.URL{Scheme: "https", Host: "bytebytego.com"}
+				expiresAt := time.Date(2026, 12, 31, 23, 59, 59, 0, time.UTC)
+				shortenURL, _ := url.Parse("https://gg.com/shorten")
+				m.On("Shortener", mock.AnythingOfType("*gin.Context"), longURL, model.ShortenOptions{ExpiresAt: &expiresAt}).Return(model.ShortenResult{ShortURL: *shortenURL, Created: true}, nil)
 			},
 			expectedStatusCode:   http.StatusCreated,
 			expectedResponseBody: `{"shortUrl":"https://gg.com/shorten"}`,
@@ -56,7 +133,7 @@ func TestShortenerController_ShortURL(t *testing.T) {
 			m := &MockShortenerService{}
 			tt.setup(m)
 
-			c := NewShortenerController(m)
+			c := NewShortenerController(m, &MockClickRecorder{}, 4)
 
 			recorder := httptest.NewRecorder()
 			ctx, _ := gin.CreateTestContext(recorder)
@@ -67,7 +144,186 @@ func TestShortenerController_ShortURL(t *testing.T) {
 			c.ShortenURL(ctx)
 
 			if tt.expectedError != nil {
-				assert.Equal(t, tt.expectedError.Error(), ctx.Errors[len(ctx.Errors)-1].Error())
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
+			} else {
+				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+				assert.Equal(t, tt.expectedResponseBody, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestShortenerController_ShortenBatch(t *testing.T) {
+	aURL := url.URL{Scheme: "https", Host: "go.dev"}
+	bURL := url.URL{Scheme: "https", Host: "pkg.go.dev"}
+
+	tests := []struct {
+		name                 string
+		requestBody          string
+		setup                func(*MockShortenerService)
+		expectedStatusCode   int
+		expectedResponseBody string
+		expectedError        error
+	}{
+		{
+			name:          "when request body is not an array",
+			requestBody:   `{"longUrl": "https://go.dev"}`,
+			setup:         func(*MockShortenerService) {},
+			expectedError: ErrBadRequest.WithDetail("body must be an array"),
+		},
+		{
+			name:          "when request body is an empty array",
+			requestBody:   `[]`,
+			setup:         func(*MockShortenerService) {},
+			expectedError: ErrBadRequest.WithDetail("body must hold at least one url"),
+		},
+		{
+			name:          "when request body holds more urls than a batch may",
+			requestBody:   `[{"longUrl": "https://go.dev"}, {"longUrl": "https://go.dev"}, {"longUrl": "https://go.dev"}, {"longUrl": "https://go.dev"}, {"longUrl": "https://go.dev"}]`,
+			setup:         func(*MockShortenerService) {},
+			expectedError: ErrBadRequest.WithDetail("body must hold at most 4 urls"),
+		},
+		{
+			name:          "when request body is larger than a batch may be",
+			requestBody:   `[{"longUrl": "https://go.dev/` + strings.Repeat("a", 4*maxBatchItemBytes) + `"}]`,
+			setup:         func(*MockShortenerService) {},
+			expectedError: ErrBodyTooLarge.WithDetail("body must be at most 32768 bytes"),
+		},
+		{
+			name:          "when an item misses its url",
+			requestBody:   `[{"alias": "go"}]`,
+			setup:         func(*MockShortenerService) {},
+			expectedError: ErrBadRequest.WithFields(apperror.FieldError{Field: "longUrl", Reason: "is required"}),
+		},
+		{
+			name:        "when shortener service failed",
+			requestBody: `[{"longUrl": "https://go.dev"}]`,
+			setup: func(m *MockShortenerService) {
+				m.On("ShortenBatch", mock.AnythingOfType("*gin.Context"), []model.BatchItem{{LongURL: aURL}}).Return([]model.BatchResult(nil), errors.New("shortener service failed"))
+			},
+			expectedError: errors.New("shortener service failed"),
+		},
+		{
+			name:                 "when no item can be parsed",
+			requestBody:          `[{"longUrl": "not a url"}]`,
+			setup:                func(*MockShortenerService) {},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"results":[{"index":0,"status":"failed","error":"invalid longUrl","code":"invalid_long_url"}]}`,
+		},
+		{
+			name:        "when successfully shortens every item it can",
+			requestBody: `[{"longUrl": "https://go.dev"}, {"longUrl": "https://go.dev", "ttl": "tomorrow"}, {"longUrl": "https://pkg.go.dev", "alias": "pkg"}, {"longUrl": "https://go.dev", "alias": "go"}]`,
+			setup: func(m *MockShortenerService) {
+				items := []model.BatchItem{{LongURL: aURL}, {LongURL: bURL, Options: model.ShortenOptions{Alias: "pkg"}}, {LongURL: aURL, Options: model.ShortenOptions{Alias: "go"}}}
+				created, _ := url.Parse("https://gg.com/aB3dE6g")
+				existing, _ := url.Parse("https://gg.com/pkg")
+				m.On("ShortenBatch", mock.AnythingOfType("*gin.Context"), items).Return([]model.BatchResult{
+					{ShortenResult: model.ShortenResult{ShortURL: *created, Created: true, ManagementToken: "a-management-token"}},
+					{ShortenResult: model.ShortenResult{ShortURL: *existing}},
+					{Err: apperror.New("alias_taken", http.StatusConflict, "alias already taken")},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{"results":[` +
+				`{"index":0,"status":"created","shortUrl":"https://gg.com/aB3dE6g","managementToken":"a-management-token"},` +
+				`{"index":1,"status":"failed","error":"invalid ttl","code":"invalid_ttl"},` +
+				`{"index":2,"status":"existing","shortUrl":"https://gg.com/pkg"},` +
+				`{"index":3,"status":"failed","error":"alias already taken","code":"alias_taken"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockShortenerService{}
+			tt.setup(m)
+
+			c := NewShortenerController(m, &MockClickRecorder{}, 4)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{
+				Body: io.NopCloser(strings.NewReader(tt.requestBody)),
+			}
+
+			c.ShortenBatch(ctx)
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
+			} else {
+				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+				assert.Equal(t, tt.expectedResponseBody, recorder.Body.String())
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestShortenerController_ListLinks(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		rawQuery             string
+		setup                func(*MockShortenerService)
+		expectedStatusCode   int
+		expectedResponseBody string
+		expectedError        error
+	}{
+		{
+			name:          "when createdFrom is not a valid time",
+			rawQuery:      "createdFrom=yesterday",
+			setup:         func(*MockShortenerService) {},
+			expectedError: timeParameterError("createdFrom"),
+		},
+		{
+			name:          "when limit is not a number",
+			rawQuery:      "limit=all",
+			setup:         func(*MockShortenerService) {},
+			expectedError: ErrInvalidParameter.WithFields(apperror.FieldError{Field: "limit", Reason: "must be an integer"}),
+		},
+		{
+			name: "when shortener service failed",
+			setup: func(m *MockShortenerService) {
+				m.On("ListLinks", mock.AnythingOfType("*gin.Context"), model.LinkFilter{}, "").Return(model.LinkPage{}, errors.New("shortener service failed"))
+			},
+			expectedError: errors.New("shortener service failed"),
+		},
+		{
+			name:     "when successfully lists links",
+			rawQuery: "createdFrom=2026-01-01T00:00:00Z&domain=go.dev&prefix=launch&q=blog&limit=1&cursor=a-cursor",
+			setup: func(m *MockShortenerService) {
+				filter := model.LinkFilter{CreatedFrom: createdAt, Domain: "go.dev", KeyPrefix: "launch", Search: "blog", Limit: 1}
+				m.On("ListLinks", mock.AnythingOfType("*gin.Context"), filter, "a-cursor").Return(model.LinkPage{
+					Links:      []model.Link{{EncodedKey: "launch-2026", LongURL: url.URL{Scheme: "https", Host: "go.dev", Path: "/blog"}, CreatedAt: createdAt, DisabledAt: &createdAt}},
+					NextCursor: "b-cursor",
+				}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"links":[{"encodedKey":"launch-2026","longUrl":"https://go.dev/blog","createdAt":"2026-01-01T00:00:00Z","disabledAt":"2026-01-01T00:00:00Z"}],"nextCursor":"b-cursor"}`,
+		},
+		{
+			name: "when there are no links",
+			setup: func(m *MockShortenerService) {
+				m.On("ListLinks", mock.AnythingOfType("*gin.Context"), model.LinkFilter{}, "").Return(model.LinkPage{}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"links":[]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockShortenerService{}
+			tt.setup(m)
+
+			c := NewShortenerController(m, &MockClickRecorder{}, 4)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{URL: &url.URL{RawQuery: tt.rawQuery}}
+
+			c.ListLinks(ctx)
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
 			} else {
 				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
 				assert.Equal(t, tt.expectedResponseBody, recorder.Body.String())
@@ -79,22 +335,35 @@ func TestShortenerController_ShortURL(t *testing.T) {
 func TestShortenerController_RetrieveURL(t *testing.T) {
 	tests := []struct {
 		name                string
-		setup               func(*MockShortenerService)
+		setup               func(*MockShortenerService, *MockClickRecorder)
 		expectedStatusCode  int
 		expectedRedirectURL string
 		expectedError       error
 	}{
 		{
 			name: "when failed to retrieve url",
-			setup: func(m *MockShortenerService) {
+			setup: func(m *MockShortenerService, r *MockClickRecorder) {
 				m.On("Retrieve", mock.AnythingOfType("*gin.Context"), "NGVmMjk").Return(url.URL{}, errors.New("shortener service failed"))
 			},
 			expectedError: errors.New("shortener service failed"),
 		},
+		{
+			name: "when click queue is full it still redirects",
+			setup: func(m *MockShortenerService, r *MockClickRecorder) {
+				m.On("Retrieve", mock.AnythingOfType("*gin.Context"), "NGVmMjk").Return(url.URL{Host: "some-url"}, nil)
+				r.On("Record", mock.Anything).Return(false)
+			},
+			expectedStatusCode:  http.StatusFound,
+			expectedRedirectURL: "//some-url",
+		},
 		{
 			name: "when successfully retrieves url",
-			setup: func(m *MockShortenerService) {
+			setup: func(m *MockShortenerService, r *MockClickRecorder) {
 				m.On("Retrieve", mock.AnythingOfType("*gin.Context"), "NGVmMjk").Return(url.URL{Host: "some-url"}, nil)
+				r.On("Record", mock.MatchedBy(func(click model.Click) bool {
+					return click.EncodedKey == "NGVmMjk" && click.Referrer == "https://ref.com" &&
+						click.UserAgent == "curl/8.0" && click.ClientIP == "10.0.0.1" && !click.ClickedAt.IsZero()
+				})).Return(true)
 			},
 			expectedStatusCode:  http.StatusFound,
 			expectedRedirectURL: "//some-url",
@@ -103,25 +372,291 @@ func TestShortenerController_RetrieveURL(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			m := &MockShortenerService{}
-			tt.setup(m)
+			r := &MockClickRecorder{}
+			tt.setup(m, r)
 
 			recorder := httptest.NewRecorder()
 			ctx, _ := gin.CreateTestContext(recorder)
-			ctx.Request = &http.Request{}
+			ctx.Request = &http.Request{
+				Header:     http.Header{"Referer": {"https://ref.com"}, "User-Agent": {"curl/8.0"}},
+				RemoteAddr: "10.0.0.1:51234",
+			}
 			ctx.Params = gin.Params{{Key: "encodedKey", Value: "NGVmMjk"}}
 
-			c := NewShortenerController(m)
+			c := NewShortenerController(m, r, 4)
 
 			c.RetrieveURL(ctx)
 
 			ctx.Writer.WriteHeaderNow()
 
 			if tt.expectedError != nil {
-				assert.Equal(t, tt.expectedError.Error(), ctx.Errors[len(ctx.Errors)-1].Error())
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
 			} else {
 				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
 				assert.Equal(t, tt.expectedRedirectURL, recorder.Header().Get("Location"))
 			}
+			r.AssertExpectations(t)
+		})
+	}
+}
+
+func TestShortenerController_ManageLink(t *testing.T) {
+	tests := []struct {
+		name               string
+		action             string
+		setup              func(*MockShortenerService)
+		expectedStatusCode int
+		expectedError      error
+	}{
+		{
+			name:   "when delete fails",
+			action: "DeleteLink",
+			setup: func(m *MockShortenerService) {
+				m.On("DeleteLink", mock.AnythingOfType("*gin.Context"), "NGVmMjk", "a-management-token").Return(errors.New("shortener service failed"))
+			},
+			expectedError: errors.New("shortener service failed"),
+		},
+		{
+			name:   "when successfully deletes link",
+			action: "DeleteLink",
+			setup: func(m *MockShortenerService) {
+				m.On("DeleteLink", mock.AnythingOfType("*gin.Context"), "NGVmMjk", "a-management-token").Return(nil)
+			},
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:   "when disable fails",
+			action: "DisableLink",
+			setup: func(m *MockShortenerService) {
+				m.On("DisableLink", mock.AnythingOfType("*gin.Context"), "NGVmMjk", "a-management-token").Return(errors.New("shortener service failed"))
+			},
+			expectedError: errors.New("shortener service failed"),
+		},
+		{
+			name:   "when successfully disables link",
+			action: "DisableLink",
+			setup: func(m *MockShortenerService) {
+				m.On("DisableLink", mock.AnythingOfType("*gin.Context"), "NGVmMjk", "a-management-token").Return(nil)
+			},
+			expectedStatusCode: http.StatusNoContent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockShortenerService{}
+			tt.setup(m)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{Header: http.Header{ManagementTokenHeader: {"a-management-token"}}}
+			ctx.Params = gin.Params{{Key: "key", Value: "NGVmMjk"}}
+
+			c := NewShortenerController(m, &MockClickRecorder{}, 4)
+
+			if tt.action == "DeleteLink" {
+				c.DeleteLink(ctx)
+			} else {
+				c.DisableLink(ctx)
+			}
+
+			ctx.Writer.WriteHeaderNow()
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
+			} else {
+				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestShortenerController_UpdateDestination(t *testing.T) {
+	tests := []struct {
+		name               string
+		requestBody        string
+		setup              func(*MockShortenerService)
+		expectedStatusCode int
+		expectedError      error
+	}{
+		{
+			name:          "when failed to parse request body",
+			requestBody:   "{",
+			setup:         func(*MockShortenerService) {},
+			expectedError: ErrBadRequest.WithDetail("body is not valid JSON"),
+		},
+		{
+			name:          "when failed to parse url",
+			requestBody:   `{"longUrl": "not a url"}`,
+			setup:         func(*MockShortenerService) {},
+			expectedError: longURLError("longUrl"),
+		},
+		{
+			name:        "when shortener service failed",
+			requestBody: `{"longUrl": "https://bytebytego.com/new"}`,
+			setup: func(m *MockShortenerService) {
+				longURL := url.URL{Scheme: "https", Host: "bytebytego.com", Path: "/new"}
+				m.On("UpdateDestination", mock.AnythingOfType("*gin.Context"), "NGVmMjk", "a-management-token", longURL, "10.0.0.1").Return(errors.New("shortener service failed"))
+			},
+			expectedError: errors.New("shortener service failed"),
+		},
+		{
+			name:        "when successfully updates destination",
+			requestBody: `{"longUrl": "https://bytebytego.com/new"}`,
+			setup: func(m *MockShortenerService) {
+				longURL := url.URL{Scheme: "https", Host: "bytebytego.com", Path: "/new"}
+				m.On("UpdateDestination", mock.AnythingOfType("*gin.Context"), "NGVmMjk", "a-management-token", longURL, "10.0.0.1").Return(nil)
+			},
+			expectedStatusCode: http.StatusNoContent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockShortenerService{}
+			tt.setup(m)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{
+				Header:     http.Header{ManagementTokenHeader: {"a-management-token"}},
+				Body:       io.NopCloser(strings.NewReader(tt.requestBody)),
+				RemoteAddr: "10.0.0.1:51234",
+			}
+			ctx.Params = gin.Params{{Key: "key", Value: "NGVmMjk"}}
+
+			c := NewShortenerController(m, &MockClickRecorder{}, 4)
+
+			c.UpdateDestination(ctx)
+
+			ctx.Writer.WriteHeaderNow()
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
+			} else {
+				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestShortenerController_History(t *testing.T) {
+	changedAt := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		setup                func(*MockShortenerService)
+		expectedStatusCode   int
+		expectedResponseBody string
+		expectedError        error
+	}{
+		{
+			name: "when shortener service failed",
+			setup: func(m *MockShortenerService) {
+				m.On("History", mock.AnythingOfType("*gin.Context"), "NGVmMjk", "a-management-token").Return([]model.HistoryEntry(nil), errors.New("shortener service failed"))
+			},
+			expectedError: errors.New("shortener service failed"),
+		},
+		{
+			name: "when link was never edited",
+			setup: func(m *MockShortenerService) {
+				m.On("History", mock.AnythingOfType("*gin.Context"), "NGVmMjk", "a-management-token").Return([]model.HistoryEntry{}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"encodedKey":"NGVmMjk","history":[]}`,
+		},
+		{
+			name: "when successfully lists history",
+			setup: func(m *MockShortenerService) {
+				m.On("History", mock.AnythingOfType("*gin.Context"), "NGVmMjk", "a-management-token").Return([]model.HistoryEntry{
+					{Version: 1, LongURL: url.URL{Scheme: "https", Host: "bytebytego.com"}, ChangedAt: changedAt, Actor: "10.0.0.1"},
+				}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"encodedKey":"NGVmMjk","history":[{"version":1,"longUrl":"https://bytebytego.com","changedAt":"2026-01-01T12:00:00Z","actor":"10.0.0.1"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockShortenerService{}
+			tt.setup(m)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{Header: http.Header{ManagementTokenHeader: {"a-management-token"}}}
+			ctx.Params = gin.Params{{Key: "key", Value: "NGVmMjk"}}
+
+			c := NewShortenerController(m, &MockClickRecorder{}, 4)
+
+			c.History(ctx)
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
+			} else {
+				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+				assert.Equal(t, tt.expectedResponseBody, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestShortenerController_Rollback(t *testing.T) {
+	tests := []struct {
+		name               string
+		requestBody        string
+		setup              func(*MockShortenerService)
+		expectedStatusCode int
+		expectedError      error
+	}{
+		{
+			name:          "when version is missing",
+			requestBody:   `{}`,
+			setup:         func(*MockShortenerService) {},
+			expectedError: ErrBadRequest.WithFields(apperror.FieldError{Field: "version", Reason: "is required"}),
+		},
+		{
+			name:        "when shortener service failed",
+			requestBody: `{"version": 2}`,
+			setup: func(m *MockShortenerService) {
+				m.On("Rollback", mock.AnythingOfType("*gin.Context"), "NGVmMjk", "a-management-token", 2, "10.0.0.1").Return(errors.New("shortener service failed"))
+			},
+			expectedError: errors.New("shortener service failed"),
+		},
+		{
+			name:        "when successfully rolls back",
+			requestBody: `{"version": 2}`,
+			setup: func(m *MockShortenerService) {
+				m.On("Rollback", mock.AnythingOfType("*gin.Context"), "NGVmMjk", "a-management-token", 2, "10.0.0.1").Return(nil)
+			},
+			expectedStatusCode: http.StatusNoContent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockShortenerService{}
+			tt.setup(m)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{
+				Header:     http.Header{ManagementTokenHeader: {"a-management-token"}},
+				Body:       io.NopCloser(strings.NewReader(tt.requestBody)),
+				RemoteAddr: "10.0.0.1:51234",
+			}
+			ctx.Params = gin.Params{{Key: "key", Value: "NGVmMjk"}}
+
+			c := NewShortenerController(m, &MockClickRecorder{}, 4)
+
+			c.Rollback(ctx)
+
+			ctx.Writer.WriteHeaderNow()
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
+			} else {
+				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+			}
+			m.AssertExpectations(t)
 		})
 	}
 }
@@ -135,7 +670,51 @@ func (s *MockShortenerService) Retrieve(ctx context.Context, encodedKey string)
 	return args.Get(0).(url.URL), args.Error(1)
 }
 
-func (s *MockShortenerService) Shortener(ctx context.Context, shortURL url.URL) (url.URL, error) {
-	args := s.Called(ctx, shortURL)
-	return args.Get(0).(url.URL), args.Error(1)
+func (s *MockShortenerService) Shortener(ctx context.Context, shortURL url.URL, options model.ShortenOptions) (model.ShortenResult, error) {
+	args := s.Called(ctx, shortURL, options)
+	return args.Get(0).(model.ShortenResult), args.Error(1)
+}
+
+func (s *MockShortenerService) ShortenBatch(ctx context.Context, items []model.BatchItem) ([]model.BatchResult, error) {
+	args := s.Called(ctx, items)
+	return args.Get(0).([]model.BatchResult), args.Error(1)
+}
+
+func (s *MockShortenerService) ListLinks(ctx context.Context, filter model.LinkFilter, cursor string) (model.LinkPage, error) {
+	args := s.Called(ctx, filter, cursor)
+	return args.Get(0).(model.LinkPage), args.Error(1)
+}
+
+func (s *MockShortenerService) DeleteLink(ctx context.Context, encodedKey string, token string) error {
+	args := s.Called(ctx, encodedKey, token)
+	return args.Error(0)
+}
+
+func (s *MockShortenerService) DisableLink(ctx context.Context, encodedKey string, token string) error {
+	args := s.Called(ctx, encodedKey, token)
+	return args.Error(0)
+}
+
+func (s *MockShortenerService) UpdateDestination(ctx context.Context, encodedKey string, token string, longURL url.URL, actor string) error {
+	args := s.Called(ctx, encodedKey, token, longURL, actor)
+	return args.Error(0)
+}
+
+func (s *MockShortenerService) History(ctx context.Context, encodedKey string, token string) ([]model.HistoryEntry, error) {
+	args := s.Called(ctx, encodedKey, token)
+	return args.Get(0).([]model.HistoryEntry), args.Error(1)
+}
+
+func (s *MockShortenerService) Rollback(ctx context.Context, encodedKey string, token string, version int, actor string) error {
+	args := s.Called(ctx, encodedKey, token, version, actor)
+	return args.Error(0)
+}
+
+type MockClickRecorder struct {
+	mock.Mock
+}
+
+func (r *MockClickRecorder) Record(click model.Click) bool {
+	args := r.Called(click)
+	return args.Bool(0)
 }
@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// previewTemplate escapes the destination, a link to a javascript: URL is rendered inert.
+var previewTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<title>Preview of {{.EncodedKey}}</title>
+</head>
+<body>
+<h1>{{.EncodedKey}}</h1>
+<p>This short link goes to:</p>
+<p><a href="{{.LongURL}}" rel="noopener noreferrer">{{.LongURL}}</a></p>
+<p>Created {{.CreatedAt.Format "2006-01-02 15:04 MST"}}{{with .ExpiresAt}}, expires {{.Format "2006-01-02 15:04 MST"}}{{end}}, followed {{.TotalClicks}} times.</p>
+</body>
+</html>
+`))
+
+// PreviewSuffix appended to a short key shows where the link goes instead of going there.
+const PreviewSuffix = "+"
+
+type StatsService interface {
+	Stats(ctx context.Context, encodedKey string, query model.StatsQuery) (model.LinkStats, error)
+	Inspect(ctx context.Context, encodedKey string) (model.LinkMetadata, error)
+}
+
+type StatsController struct {
+	service StatsService
+}
+
+func NewStatsController(service StatsService) *StatsController {
+	return &StatsController{service: service}
+}
+
+func (c *StatsController) Stats(ctx *gin.Context) {
+	query := model.StatsQuery{Interval: ctx.Query("interval")}
+
+	var err error
+	query.From, err = parseTimeQuery(ctx, "from")
+	if err != nil {
+		slog.Warn(fmt.Sprintf("failed to parse from: %v", err))
+		ctx.Error(timeParameterError("from"))
+		return
+	}
+
+	query.To, err = parseTimeQuery(ctx, "to")
+	if err != nil {
+		slog.Warn(fmt.Sprintf("failed to parse to: %v", err))
+		ctx.Error(timeParameterError("to"))
+		return
+	}
+
+	stats, err := c.service.Stats(ctx, ctx.Param("key"), query)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	series := make([]StatsBucket, 0, len(stats.Series))
+	for _, bucket := range stats.Series {
+		series = append(series, StatsBucket{Start: bucket.Start, Clicks: bucket.Clicks})
+	}
+
+	ctx.JSON(http.StatusOK, StatsResponse{EncodedKey: stats.EncodedKey, TotalClicks: stats.TotalClicks, Interval: stats.Interval, Series: series})
+}
+
+func (c *StatsController) Metadata(ctx *gin.Context) {
+	metadata, err := c.service.Inspect(ctx, ctx.Param("key"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, metadataResponse(metadata))
+}
+
+// Preview serves the same metadata as a page for browsers and as JSON for anyone else.
+func (c *StatsController) Preview(ctx *gin.Context) {
+	encodedKey := strings.TrimSuffix(ctx.Param("encodedKey"), PreviewSuffix)
+
+	metadata, err := c.service.Inspect(ctx, encodedKey)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if ctx.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML {
+		ctx.Render(http.StatusOK, render.HTML{Template: previewTemplate, Data: metadataResponse(metadata)})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, metadataResponse(metadata))
+}
+
+// PreviewOr routes keys ending in PreviewSuffix to preview and every other key to next,
+// both share the same path parameter so gin can't tell them apart by route.
+func PreviewOr(preview gin.HandlerFunc, next gin.HandlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if strings.HasSuffix(ctx.Param("encodedKey"), PreviewSuffix) {
+			preview(ctx)
+			return
+		}
+
+		next(ctx)
+	}
+}
+
+func metadataResponse(metadata model.LinkMetadata) MetadataResponse {
+	return MetadataResponse{
+		EncodedKey:  metadata.EncodedKey,
+		LongURL:     metadata.LongURL.String(),
+		CreatedAt:   metadata.CreatedAt,
+		ExpiresAt:   metadata.ExpiresAt,
+		TotalClicks: metadata.TotalClicks,
+	}
+}
+
+func parseTimeQuery(ctx *gin.Context, name string) (time.Time, error) {
+	value := ctx.Query(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+type StatsResponse struct {
+	EncodedKey  string        `json:"encodedKey"`
+	TotalClicks int64         `json:"totalClicks"`
+	Interval    string        `json:"interval"`
+	Series      []StatsBucket `json:"series"`
+}
+
+type MetadataResponse struct {
+	EncodedKey  string     `json:"encodedKey"`
+	LongURL     string     `json:"longUrl"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	TotalClicks int64      `json:"totalClicks"`
+}
+
+type StatsBucket struct {
+	Start  time.Time `json:"start"`
+	Clicks int64     `json:"clicks"`
+}
@@ -0,0 +1,258 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStatsController_Stats(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		rawQuery             string
+		setup                func(*MockStatsService)
+		expectedStatusCode   int
+		expectedResponseBody string
+		expectedError        error
+	}{
+		{
+			name:          "when from is not a valid time",
+			rawQuery:      "from=yesterday",
+			setup:         func(*MockStatsService) {},
+			expectedError: timeParameterError("from"),
+		},
+		{
+			name:          "when to is not a valid time",
+			rawQuery:      "to=tomorrow",
+			setup:         func(*MockStatsService) {},
+			expectedError: timeParameterError("to"),
+		},
+		{
+			name: "when stats service failed",
+			setup: func(m *MockStatsService) {
+				m.On("Stats", mock.AnythingOfType("*gin.Context"), "NGVmMjk", model.StatsQuery{}).Return(model.LinkStats{}, errors.New("stats service failed"))
+			},
+			expectedError: errors.New("stats service failed"),
+		},
+		{
+			name:     "when successfully returns stats",
+			rawQuery: "interval=day&from=2026-01-01T00:00:00Z&to=2026-01-03T00:00:00Z",
+			setup: func(m *MockStatsService) {
+				m.On("Stats", mock.AnythingOfType("*gin.Context"), "NGVmMjk", model.StatsQuery{Interval: "day", From: from, To: to}).Return(model.LinkStats{
+					EncodedKey:  "NGVmMjk",
+					TotalClicks: 8,
+					Interval:    "day",
+					Series:      []model.ClickBucket{{Start: from, Clicks: 3}, {Start: from.Add(24 * time.Hour), Clicks: 5}},
+				}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"encodedKey":"NGVmMjk","totalClicks":8,"interval":"day","series":[{"start":"2026-01-01T00:00:00Z","clicks":3},{"start":"2026-01-02T00:00:00Z","clicks":5}]}`,
+		},
+		{
+			name: "when link has no clicks",
+			setup: func(m *MockStatsService) {
+				m.On("Stats", mock.AnythingOfType("*gin.Context"), "NGVmMjk", model.StatsQuery{}).Return(model.LinkStats{EncodedKey: "NGVmMjk", Interval: "day"}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"encodedKey":"NGVmMjk","totalClicks":0,"interval":"day","series":[]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockStatsService{}
+			tt.setup(m)
+
+			c := NewStatsController(m)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{URL: &url.URL{RawQuery: tt.rawQuery}}
+			ctx.Params = gin.Params{{Key: "key", Value: "NGVmMjk"}}
+
+			c.Stats(ctx)
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
+			} else {
+				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+				assert.Equal(t, tt.expectedResponseBody, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestStatsController_Metadata(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		setup                func(*MockStatsService)
+		expectedStatusCode   int
+		expectedResponseBody string
+		expectedError        error
+	}{
+		{
+			name: "when stats service failed",
+			setup: func(m *MockStatsService) {
+				m.On("Inspect", mock.AnythingOfType("*gin.Context"), "NGVmMjk").Return(model.LinkMetadata{}, errors.New("stats service failed"))
+			},
+			expectedError: errors.New("stats service failed"),
+		},
+		{
+			name: "when successfully returns metadata",
+			setup: func(m *MockStatsService) {
+				m.On("Inspect", mock.AnythingOfType("*gin.Context"), "NGVmMjk").Return(model.LinkMetadata{
+					EncodedKey:  "NGVmMjk",
+					LongURL:     url.URL{Scheme: "https", Host: "go.dev"},
+					CreatedAt:   createdAt,
+					TotalClicks: 8,
+				}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"encodedKey":"NGVmMjk","longUrl":"https://go.dev","createdAt":"2026-01-01T00:00:00Z","totalClicks":8}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockStatsService{}
+			tt.setup(m)
+
+			c := NewStatsController(m)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{URL: &url.URL{}}
+			ctx.Params = gin.Params{{Key: "key", Value: "NGVmMjk"}}
+
+			c.Metadata(ctx)
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
+			} else {
+				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+				assert.Equal(t, tt.expectedResponseBody, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestStatsController_Preview(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	metadata := model.LinkMetadata{
+		EncodedKey: "NGVmMjk",
+		LongURL:    url.URL{Scheme: "https", Host: "go.dev", RawQuery: "a=1&b=<2>"},
+		CreatedAt:  createdAt,
+	}
+
+	tests := []struct {
+		name                string
+		accept              string
+		setup               func(*MockStatsService)
+		expectedContentType string
+		expectedBody        []string
+		expectedError       error
+	}{
+		{
+			name: "when stats service failed",
+			setup: func(m *MockStatsService) {
+				m.On("Inspect", mock.AnythingOfType("*gin.Context"), "NGVmMjk").Return(model.LinkMetadata{}, errors.New("stats service failed"))
+			},
+			expectedError: errors.New("stats service failed"),
+		},
+		{
+			name:   "when client accepts anything it gets json",
+			accept: "*/*",
+			setup: func(m *MockStatsService) {
+				m.On("Inspect", mock.AnythingOfType("*gin.Context"), "NGVmMjk").Return(metadata, nil)
+			},
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        []string{`{"encodedKey":"NGVmMjk","longUrl":"https://go.dev?a=1\u0026b=\u003c2\u003e","createdAt":"2026-01-01T00:00:00Z","totalClicks":0}`},
+		},
+		{
+			name:   "when browser asks for html it gets an escaped page",
+			accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			setup: func(m *MockStatsService) {
+				m.On("Inspect", mock.AnythingOfType("*gin.Context"), "NGVmMjk").Return(metadata, nil)
+			},
+			expectedContentType: "text/html; charset=utf-8",
+			expectedBody:        []string{"<h1>NGVmMjk</h1>", `href="https://go.dev?a=1&amp;b=%3c2%3e"`, "https://go.dev?a=1&amp;b=&lt;2&gt;</a>", "Created 2026-01-01 00:00 UTC, followed 0 times."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockStatsService{}
+			tt.setup(m)
+
+			c := NewStatsController(m)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/api/v1/NGVmMjk+", nil)
+			ctx.Request.Header.Set("Accept", tt.accept)
+			ctx.Params = gin.Params{{Key: "encodedKey", Value: "NGVmMjk+"}}
+
+			c.Preview(ctx)
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
+				return
+			}
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, tt.expectedContentType, recorder.Header().Get("Content-Type"))
+			for _, part := range tt.expectedBody {
+				assert.Contains(t, recorder.Body.String(), part)
+			}
+		})
+	}
+}
+
+func TestPreviewOr(t *testing.T) {
+	tests := []struct {
+		name       string
+		encodedKey string
+		want       string
+	}{
+		{name: "when key ends with the preview suffix", encodedKey: "NGVmMjk+", want: "preview"},
+		{name: "when key is a plain key", encodedKey: "NGVmMjk", want: "next"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := PreviewOr(func(*gin.Context) { got = "preview" }, func(*gin.Context) { got = "next" })
+
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Params = gin.Params{{Key: "encodedKey", Value: tt.encodedKey}}
+
+			handler(ctx)
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+type MockStatsService struct {
+	mock.Mock
+}
+
+func (s *MockStatsService) Stats(ctx context.Context, encodedKey string, query model.StatsQuery) (model.LinkStats, error) {
+	args := s.Called(ctx, encodedKey, query)
+	return args.Get(0).(model.LinkStats), args.Error(1)
+}
+
+func (s *MockStatsService) Inspect(ctx context.Context, encodedKey string) (model.LinkMetadata, error) {
+	args := s.Called(ctx, encodedKey)
+	return args.Get(0).(model.LinkMetadata), args.Error(1)
+}
@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+type WorkspaceService interface {
+	CreateWorkspace(ctx context.Context, name string) (model.UserWorkspace, error)
+	ListWorkspaces(ctx context.Context) ([]model.UserWorkspace, error)
+	ListMembers(ctx context.Context, workspaceID string) ([]model.Member, error)
+	Invite(ctx context.Context, workspaceID string, role model.Role) (model.MintedInvitation, error)
+	AcceptInvitation(ctx context.Context, token string) (model.Member, error)
+	UpdateMemberRole(ctx context.Context, workspaceID string, userID string, role model.Role) error
+	RemoveMember(ctx context.Context, workspaceID string, userID string) error
+}
+
+type WorkspaceController struct {
+	service WorkspaceService
+}
+
+func NewWorkspaceController(service WorkspaceService) *WorkspaceController {
+	return &WorkspaceController{service: service}
+}
+
+func (c *WorkspaceController) Create(ctx *gin.Context) {
+	var body CreateWorkspaceRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("failed to parse body: %v", err))
+		ctx.Error(bindError(err, &body))
+		return
+	}
+
+	workspace, err := c.service.CreateWorkspace(ctx, body.Name)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, workspaceResponse(workspace))
+}
+
+func (c *WorkspaceController) List(ctx *gin.Context) {
+	workspaces, err := c.service.ListWorkspaces(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response := ListWorkspacesResponse{Workspaces: make([]WorkspaceResponse, 0, len(workspaces))}
+	for _, workspace := range workspaces {
+		response.Workspaces = append(response.Workspaces, workspaceResponse(workspace))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *WorkspaceController) ListMembers(ctx *gin.Context) {
+	members, err := c.service.ListMembers(ctx, ctx.Param("id"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response := ListMembersResponse{Members: make([]MemberResponse, 0, len(members))}
+	for _, member := range members {
+		response.Members = append(response.Members, memberResponse(member))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *WorkspaceController) UpdateMember(ctx *gin.Context) {
+	var body UpdateMemberRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("failed to parse body: %v", err))
+		ctx.Error(bindError(err, &body))
+		return
+	}
+
+	err = c.service.UpdateMemberRole(ctx, ctx.Param("id"), ctx.Param("userId"), model.Role(body.Role))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *WorkspaceController) RemoveMember(ctx *gin.Context) {
+	err := c.service.RemoveMember(ctx, ctx.Param("id"), ctx.Param("userId"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *WorkspaceController) Invite(ctx *gin.Context) {
+	var body InviteRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("failed to parse body: %v", err))
+		ctx.Error(bindError(err, &body))
+		return
+	}
+
+	invitation, err := c.service.Invite(ctx, ctx.Param("id"), model.Role(body.Role))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, InvitationResponse{
+		ID:          invitation.ID,
+		WorkspaceID: invitation.WorkspaceID,
+		Role:        string(invitation.Role),
+		ExpiresAt:   invitation.ExpiresAt,
+		Token:       invitation.Token,
+	})
+}
+
+func (c *WorkspaceController) AcceptInvitation(ctx *gin.Context) {
+	var body AcceptInvitationRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("failed to parse body: %v", err))
+		ctx.Error(bindError(err, &body))
+		return
+	}
+
+	member, err := c.service.AcceptInvitation(ctx, body.Token)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, memberResponse(member))
+}
+
+func workspaceResponse(workspace model.UserWorkspace) WorkspaceResponse {
+	return WorkspaceResponse{ID: workspace.ID, Name: workspace.Name, Role: string(workspace.Role), CreatedAt: workspace.CreatedAt}
+}
+
+func memberResponse(member model.Member) MemberResponse {
+	return MemberResponse{WorkspaceID: member.WorkspaceID, UserID: member.UserID, Role: string(member.Role), JoinedAt: member.JoinedAt}
+}
+
+type CreateWorkspaceRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// WorkspaceResponse is a workspace as seen by a member, Role is theirs.
+type WorkspaceResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type ListWorkspacesResponse struct {
+	Workspaces []WorkspaceResponse `json:"workspaces"`
+}
+
+type MemberResponse struct {
+	WorkspaceID string    `json:"workspaceId"`
+	UserID      string    `json:"userId"`
+	Role        string    `json:"role"`
+	JoinedAt    time.Time `json:"joinedAt"`
+}
+
+type ListMembersResponse struct {
+	Members []MemberResponse `json:"members"`
+}
+
+type UpdateMemberRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type InviteRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// InvitationResponse carries the invitation token, the only time it is ever shown.
+type InvitationResponse struct {
+	ID          string    `json:"id"`
+	WorkspaceID string    `json:"workspaceId"`
+	Role        string    `json:"role"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	Token       string    `json:"token"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
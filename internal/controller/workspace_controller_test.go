@@ -0,0 +1,400 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWorkspaceController_Create(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		requestBody          string
+		setup                func(*MockWorkspaceService)
+		expectedStatusCode   int
+		expectedResponseBody string
+		expectedError        error
+	}{
+		{
+			name:          "when name is missing",
+			requestBody:   `{}`,
+			setup:         func(*MockWorkspaceService) {},
+			expectedError: ErrBadRequest.WithFields(apperror.FieldError{Field: "name", Reason: "is required"}),
+		},
+		{
+			name:        "when workspace service failed",
+			requestBody: `{"name": "Marketing"}`,
+			setup: func(m *MockWorkspaceService) {
+				m.On("CreateWorkspace", mock.AnythingOfType("*gin.Context"), "Marketing").Return(model.UserWorkspace{}, errors.New("workspace service failed"))
+			},
+			expectedError: errors.New("workspace service failed"),
+		},
+		{
+			name:        "when successfully creates the workspace",
+			requestBody: `{"name": "Marketing"}`,
+			setup: func(m *MockWorkspaceService) {
+				m.On("CreateWorkspace", mock.AnythingOfType("*gin.Context"), "Marketing").Return(model.UserWorkspace{
+					Workspace: model.Workspace{ID: "a-workspace-id", Name: "Marketing", CreatedAt: createdAt},
+					Role:      model.RoleOwner,
+				}, nil)
+			},
+			expectedStatusCode:   http.StatusCreated,
+			expectedResponseBody: `{"id":"a-workspace-id","name":"Marketing","role":"owner","createdAt":"2026-01-01T00:00:00Z"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockWorkspaceService{}
+			tt.setup(m)
+
+			c := NewWorkspaceController(m)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{
+				Body: io.NopCloser(strings.NewReader(tt.requestBody)),
+			}
+
+			c.Create(ctx)
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
+			} else {
+				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+				assert.Equal(t, tt.expectedResponseBody, recorder.Body.String())
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWorkspaceController_ListMembers(t *testing.T) {
+	joinedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		setup                func(*MockWorkspaceService)
+		expectedStatusCode   int
+		expectedResponseBody string
+		expectedError        error
+	}{
+		{
+			name: "when workspace service failed",
+			setup: func(m *MockWorkspaceService) {
+				m.On("ListMembers", mock.AnythingOfType("*gin.Context"), "a-workspace-id").Return([]model.Member(nil), errors.New("workspace service failed"))
+			},
+			expectedError: errors.New("workspace service failed"),
+		},
+		{
+			name: "when successfully lists the members",
+			setup: func(m *MockWorkspaceService) {
+				m.On("ListMembers", mock.AnythingOfType("*gin.Context"), "a-workspace-id").Return([]model.Member{
+					{WorkspaceID: "a-workspace-id", UserID: "a-user-id", Role: model.RoleEditor, JoinedAt: joinedAt},
+				}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"members":[{"workspaceId":"a-workspace-id","userId":"a-user-id","role":"editor","joinedAt":"2026-01-01T00:00:00Z"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockWorkspaceService{}
+			tt.setup(m)
+
+			c := NewWorkspaceController(m)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{}
+			ctx.Params = gin.Params{{Key: "id", Value: "a-workspace-id"}}
+
+			c.ListMembers(ctx)
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
+			} else {
+				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+				assert.Equal(t, tt.expectedResponseBody, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestWorkspaceController_UpdateMember(t *testing.T) {
+	tests := []struct {
+		name               string
+		requestBody        string
+		setup              func(*MockWorkspaceService)
+		expectedStatusCode int
+		expectedError      error
+	}{
+		{
+			name:          "when role is missing",
+			requestBody:   `{}`,
+			setup:         func(*MockWorkspaceService) {},
+			expectedError: ErrBadRequest.WithFields(apperror.FieldError{Field: "role", Reason: "is required"}),
+		},
+		{
+			name:        "when workspace service failed",
+			requestBody: `{"role": "admin"}`,
+			setup: func(m *MockWorkspaceService) {
+				m.On("UpdateMemberRole", mock.AnythingOfType("*gin.Context"), "a-workspace-id", "a-user-id", model.RoleAdmin).Return(errors.New("workspace service failed"))
+			},
+			expectedError: errors.New("workspace service failed"),
+		},
+		{
+			name:        "when successfully changes the role",
+			requestBody: `{"role": "admin"}`,
+			setup: func(m *MockWorkspaceService) {
+				m.On("UpdateMemberRole", mock.AnythingOfType("*gin.Context"), "a-workspace-id", "a-user-id", model.RoleAdmin).Return(nil)
+			},
+			expectedStatusCode: http.StatusNoContent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockWorkspaceService{}
+			tt.setup(m)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{Body: io.NopCloser(strings.NewReader(tt.requestBody))}
+			ctx.Params = gin.Params{{Key: "id", Value: "a-workspace-id"}, {Key: "userId", Value: "a-user-id"}}
+
+			c := NewWorkspaceController(m)
+
+			c.UpdateMember(ctx)
+
+			ctx.Writer.WriteHeaderNow()
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
+			} else {
+				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWorkspaceController_RemoveMember(t *testing.T) {
+	tests := []struct {
+		name               string
+		setup              func(*MockWorkspaceService)
+		expectedStatusCode int
+		expectedError      error
+	}{
+		{
+			name: "when workspace service failed",
+			setup: func(m *MockWorkspaceService) {
+				m.On("RemoveMember", mock.AnythingOfType("*gin.Context"), "a-workspace-id", "a-user-id").Return(errors.New("workspace service failed"))
+			},
+			expectedError: errors.New("workspace service failed"),
+		},
+		{
+			name: "when successfully removes the member",
+			setup: func(m *MockWorkspaceService) {
+				m.On("RemoveMember", mock.AnythingOfType("*gin.Context"), "a-workspace-id", "a-user-id").Return(nil)
+			},
+			expectedStatusCode: http.StatusNoContent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockWorkspaceService{}
+			tt.setup(m)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{}
+			ctx.Params = gin.Params{{Key: "id", Value: "a-workspace-id"}, {Key: "userId", Value: "a-user-id"}}
+
+			c := NewWorkspaceController(m)
+
+			c.RemoveMember(ctx)
+
+			ctx.Writer.WriteHeaderNow()
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
+			} else {
+				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWorkspaceController_Invite(t *testing.T) {
+	expiresAt := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		requestBody          string
+		setup                func(*MockWorkspaceService)
+		expectedStatusCode   int
+		expectedResponseBody string
+		expectedError        error
+	}{
+		{
+			name:          "when role is missing",
+			requestBody:   `{}`,
+			setup:         func(*MockWorkspaceService) {},
+			expectedError: ErrBadRequest.WithFields(apperror.FieldError{Field: "role", Reason: "is required"}),
+		},
+		{
+			name:        "when workspace service failed",
+			requestBody: `{"role": "editor"}`,
+			setup: func(m *MockWorkspaceService) {
+				m.On("Invite", mock.AnythingOfType("*gin.Context"), "a-workspace-id", model.RoleEditor).Return(model.MintedInvitation{}, errors.New("workspace service failed"))
+			},
+			expectedError: errors.New("workspace service failed"),
+		},
+		{
+			name:        "when successfully invites without the token hash",
+			requestBody: `{"role": "editor"}`,
+			setup: func(m *MockWorkspaceService) {
+				m.On("Invite", mock.AnythingOfType("*gin.Context"), "a-workspace-id", model.RoleEditor).Return(model.MintedInvitation{
+					Invitation: model.Invitation{ID: "an-invitation-id", WorkspaceID: "a-workspace-id", Role: model.RoleEditor, TokenHash: "a-hash", ExpiresAt: expiresAt},
+					Token:      "usi_a-token",
+				}, nil)
+			},
+			expectedStatusCode:   http.StatusCreated,
+			expectedResponseBody: `{"id":"an-invitation-id","workspaceId":"a-workspace-id","role":"editor","expiresAt":"2026-01-08T00:00:00Z","token":"usi_a-token"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockWorkspaceService{}
+			tt.setup(m)
+
+			c := NewWorkspaceController(m)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{Body: io.NopCloser(strings.NewReader(tt.requestBody))}
+			ctx.Params = gin.Params{{Key: "id", Value: "a-workspace-id"}}
+
+			c.Invite(ctx)
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
+			} else {
+				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+				assert.Equal(t, tt.expectedResponseBody, recorder.Body.String())
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWorkspaceController_AcceptInvitation(t *testing.T) {
+	joinedAt := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		requestBody          string
+		setup                func(*MockWorkspaceService)
+		expectedStatusCode   int
+		expectedResponseBody string
+		expectedError        error
+	}{
+		{
+			name:          "when token is missing",
+			requestBody:   `{}`,
+			setup:         func(*MockWorkspaceService) {},
+			expectedError: ErrBadRequest.WithFields(apperror.FieldError{Field: "token", Reason: "is required"}),
+		},
+		{
+			name:        "when workspace service failed",
+			requestBody: `{"token": "usi_a-token"}`,
+			setup: func(m *MockWorkspaceService) {
+				m.On("AcceptInvitation", mock.AnythingOfType("*gin.Context"), "usi_a-token").Return(model.Member{}, errors.New("workspace service failed"))
+			},
+			expectedError: errors.New("workspace service failed"),
+		},
+		{
+			name:        "when successfully accepts",
+			requestBody: `{"token": "usi_a-token"}`,
+			setup: func(m *MockWorkspaceService) {
+				m.On("AcceptInvitation", mock.AnythingOfType("*gin.Context"), "usi_a-token").Return(model.Member{WorkspaceID: "a-workspace-id", UserID: "a-user-id", Role: model.RoleEditor, JoinedAt: joinedAt}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"workspaceId":"a-workspace-id","userId":"a-user-id","role":"editor","joinedAt":"2026-01-02T00:00:00Z"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockWorkspaceService{}
+			tt.setup(m)
+
+			c := NewWorkspaceController(m)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = &http.Request{Body: io.NopCloser(strings.NewReader(tt.requestBody))}
+
+			c.AcceptInvitation(ctx)
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, ctx.Errors[len(ctx.Errors)-1].Err)
+			} else {
+				assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+				assert.Equal(t, tt.expectedResponseBody, recorder.Body.String())
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+type MockWorkspaceService struct {
+	mock.Mock
+}
+
+func (m *MockWorkspaceService) CreateWorkspace(ctx context.Context, name string) (model.UserWorkspace, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(model.UserWorkspace), args.Error(1)
+}
+
+func (m *MockWorkspaceService) ListWorkspaces(ctx context.Context) ([]model.UserWorkspace, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.UserWorkspace), args.Error(1)
+}
+
+func (m *MockWorkspaceService) ListMembers(ctx context.Context, workspaceID string) ([]model.Member, error) {
+	args := m.Called(ctx, workspaceID)
+	return args.Get(0).([]model.Member), args.Error(1)
+}
+
+func (m *MockWorkspaceService) Invite(ctx context.Context, workspaceID string, role model.Role) (model.MintedInvitation, error) {
+	args := m.Called(ctx, workspaceID, role)
+	return args.Get(0).(model.MintedInvitation), args.Error(1)
+}
+
+func (m *MockWorkspaceService) AcceptInvitation(ctx context.Context, token string) (model.Member, error) {
+	args := m.Called(ctx, token)
+	return args.Get(0).(model.Member), args.Error(1)
+}
+
+func (m *MockWorkspaceService) UpdateMemberRole(ctx context.Context, workspaceID string, userID string, role model.Role) error {
+	args := m.Called(ctx, workspaceID, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceService) RemoveMember(ctx context.Context, workspaceID string, userID string) error {
+	args := m.Called(ctx, workspaceID, userID)
+	return args.Error(0)
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/ggoulart/url-shortener/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+var ErrUnauthenticated = apperror.New("unauthenticated", http.StatusUnauthorized, "authentication required")
+var ErrForbidden = apperror.New("forbidden", http.StatusForbidden, "insufficient scope")
+
+// WorkspaceHeader names the workspace a request acts in, requests without it act
+// outside any workspace.
+const WorkspaceHeader = "X-Workspace-ID"
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, secret string) (model.Principal, error)
+}
+
+// Authenticate resolves an "Authorization: Bearer" API key or session token to
+// its principal and attaches it to the request context. Requests without
+// credentials go on anonymous, RequireScope turns them away where a key is
+// needed; wrong credentials never go on. The workspace the request names is
+// only checked once a service acts in it.
+func Authenticate(authenticator Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			return
+		}
+
+		scheme, secret, _ := strings.Cut(header, " ")
+		secret = strings.TrimSpace(secret)
+		if !strings.EqualFold(scheme, "Bearer") || secret == "" {
+			challenge(c, `Bearer error="invalid_request"`, ErrUnauthenticated.WithDetail("authorization must be a Bearer api key or session token"))
+			return
+		}
+
+		principal, err := authenticator.Authenticate(c.Request.Context(), secret)
+		if err != nil {
+			challenge(c, `Bearer error="invalid_token"`, err)
+			return
+		}
+
+		principal.WorkspaceID = strings.TrimSpace(c.GetHeader(WorkspaceHeader))
+		c.Request = c.Request.WithContext(service.ContextWithPrincipal(c.Request.Context(), principal))
+	}
+}
+
+// RequireScope lets a request through only if its credentials hold scope.
+func RequireScope(scope model.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := service.PrincipalFromContext(c.Request.Context())
+		if !ok {
+			challenge(c, "Bearer", ErrUnauthenticated)
+			return
+		}
+
+		if !principal.HasScope(scope) {
+			challenge(c, fmt.Sprintf(`Bearer error="insufficient_scope", scope="%s"`, scope), ErrForbidden.WithDetail(fmt.Sprintf("credentials need the %s scope", scope)))
+		}
+	}
+}
+
+// challenge turns the request away and tells the client how to authenticate, RFC 6750.
+func challenge(c *gin.Context, header string, err error) {
+	c.Header("WWW-Authenticate", header)
+	c.Error(err)
+	c.Abort()
+}
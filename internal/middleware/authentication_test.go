@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/ggoulart/url-shortener/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuthentication(t *testing.T) {
+	creator := model.Principal{KeyID: "a-key-id", Name: "ci", Scopes: []model.Scope{model.ScopeCreate}}
+	admin := model.Principal{KeyID: "b-key-id", Name: "ops", Scopes: []model.Scope{model.ScopeAdmin}}
+
+	tests := []struct {
+		name                    string
+		target                  string
+		authorization           string
+		setup                   func(*MockAuthenticator)
+		expectedStatus          int
+		expectedCode            string
+		expectedWWWAuthenticate string
+		expectedBody            string
+	}{
+		{
+			name:           "when route is public and there are no credentials",
+			target:         "/public",
+			setup:          func(*MockAuthenticator) {},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "anonymous",
+		},
+		{
+			name:                    "when route needs a scope and there are no credentials",
+			target:                  "/create",
+			setup:                   func(*MockAuthenticator) {},
+			expectedStatus:          http.StatusUnauthorized,
+			expectedCode:            "unauthenticated",
+			expectedWWWAuthenticate: "Bearer",
+		},
+		{
+			name:                    "when credentials are not a bearer key",
+			target:                  "/public",
+			authorization:           "Basic dXNlcjpwYXNz",
+			setup:                   func(*MockAuthenticator) {},
+			expectedStatus:          http.StatusUnauthorized,
+			expectedCode:            "unauthenticated",
+			expectedWWWAuthenticate: `Bearer error="invalid_request"`,
+		},
+		{
+			name:          "when key is invalid even on a public route",
+			target:        "/public",
+			authorization: "Bearer usk_revoked",
+			setup: func(m *MockAuthenticator) {
+				m.On("Authenticate", mock.Anything, "usk_revoked").Return(model.Principal{}, service.ErrInvalidAPIKey)
+			},
+			expectedStatus:          http.StatusUnauthorized,
+			expectedCode:            "invalid_api_key",
+			expectedWWWAuthenticate: `Bearer error="invalid_token"`,
+		},
+		{
+			name:          "when key lacks the scope",
+			target:        "/read",
+			authorization: "Bearer usk_creator",
+			setup: func(m *MockAuthenticator) {
+				m.On("Authenticate", mock.Anything, "usk_creator").Return(creator, nil)
+			},
+			expectedStatus:          http.StatusForbidden,
+			expectedCode:            "forbidden",
+			expectedWWWAuthenticate: `Bearer error="insufficient_scope", scope="read"`,
+		},
+		{
+			name:          "when key holds the scope",
+			target:        "/create",
+			authorization: "bearer usk_creator",
+			setup: func(m *MockAuthenticator) {
+				m.On("Authenticate", mock.Anything, "usk_creator").Return(creator, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "ci",
+		},
+		{
+			name:          "when admin key reaches any scope",
+			target:        "/read",
+			authorization: "Bearer usk_admin",
+			setup: func(m *MockAuthenticator) {
+				m.On("Authenticate", mock.Anything, "usk_admin").Return(admin, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "ops",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator := &MockAuthenticator{}
+			tt.setup(authenticator)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(ErrorHandler(), Authenticate(authenticator))
+			whoami := func(c *gin.Context) {
+				principal, ok := service.PrincipalFromContext(c.Request.Context())
+				if !ok {
+					principal.Name = "anonymous"
+				}
+				c.String(http.StatusOK, principal.Name)
+			}
+			r.GET("/public", whoami)
+			r.GET("/create", RequireScope(model.ScopeCreate), whoami)
+			r.GET("/read", RequireScope(model.ScopeRead), whoami)
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			recorder := httptest.NewRecorder()
+
+			r.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedWWWAuthenticate, recorder.Header().Get("WWW-Authenticate"))
+			if tt.expectedCode != "" {
+				assert.Contains(t, recorder.Body.String(), `"code":"`+tt.expectedCode+`"`)
+			} else {
+				assert.Equal(t, tt.expectedBody, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthentication_Workspace(t *testing.T) {
+	authenticator := &MockAuthenticator{}
+	authenticator.On("Authenticate", mock.Anything, "uss_session").Return(model.Principal{UserID: "a-user-id", Scopes: []model.Scope{model.ScopeCreate}}, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorHandler(), Authenticate(authenticator))
+	r.GET("/workspace", func(c *gin.Context) {
+		principal, _ := service.PrincipalFromContext(c.Request.Context())
+		c.String(http.StatusOK, principal.WorkspaceID)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/workspace", nil)
+	req.Header.Set("Authorization", "Bearer uss_session")
+	req.Header.Set(WorkspaceHeader, " a-workspace-id ")
+	recorder := httptest.NewRecorder()
+
+	r.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "a-workspace-id", recorder.Body.String())
+}
+
+type MockAuthenticator struct {
+	mock.Mock
+}
+
+func (m *MockAuthenticator) Authenticate(ctx context.Context, secret string) (model.Principal, error) {
+	args := m.Called(ctx, secret)
+	return args.Get(0).(model.Principal), args.Error(1)
+}
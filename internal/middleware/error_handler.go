@@ -1,31 +1,75 @@
 package middleware
 
 import (
-	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/ggoulart/url-shortener/internal/controller"
-	"github.com/ggoulart/url-shortener/internal/repository"
+	"github.com/ggoulart/url-shortener/internal/apperror"
 	"github.com/gin-gonic/gin"
 )
 
+// ProblemContentType is the media type of RFC 9457 problem details.
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypePrefix is prepended to the error code to build the problem type URI.
+const ProblemTypePrefix = "urn:url-shortener:problem:"
+
+var ErrInternal = apperror.New("internal_error", http.StatusInternalServerError, "internal error")
+
+// Problem is an RFC 9457 problem details object. Code and Errors are extension
+// members: the stable error code and the fields at fault, if any.
+type Problem struct {
+	Type     string                `json:"type"`
+	Title    string                `json:"title"`
+	Status   int                   `json:"status"`
+	Detail   string                `json:"detail,omitempty"`
+	Instance string                `json:"instance,omitempty"`
+	Code     string                `json:"code"`
+	Errors   []apperror.FieldError `json:"errors,omitempty"`
+}
+
+// ErrorHandler answers the last error a handler attached as problem details.
+// Errors that aren't application errors are logged and answered with a bare 500,
+// their messages stay out of responses. Errors that know when the request may
+// succeed again say so in Retry-After.
 func ErrorHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
 
 		if err := c.Errors.Last(); err != nil {
-			var status int
-
-			switch {
-			case errors.Is(err.Err, controller.ErrBadRequest):
-				status = http.StatusBadRequest
-			case errors.Is(err.Err, repository.ErrNotFound):
-				status = http.StatusNotFound
-			default:
-				status = http.StatusInternalServerError
+			appErr, ok := apperror.As(err.Err)
+			if !ok {
+				slog.Error(fmt.Sprintf("unhandled error on %s %s: %v", c.Request.Method, c.Request.URL.Path, err.Err))
+				appErr = ErrInternal
 			}
 
-			c.JSON(status, gin.H{"error": err.Error()})
+			problem := Problem{
+				Type:     ProblemTypePrefix + appErr.Code,
+				Title:    appErr.Title,
+				Status:   appErr.Status,
+				Instance: c.Request.URL.Path,
+				Code:     appErr.Code,
+				Errors:   appErr.Fields,
+			}
+			// the detail, or what wrapping added to the message, explains this occurrence
+			if ok {
+				problem.Detail = strings.TrimPrefix(strings.TrimPrefix(err.Err.Error(), appErr.Title), ": ")
+			}
+
+			if appErr.RetryAfter > 0 {
+				c.Header("Retry-After", retryAfterSeconds(appErr.RetryAfter))
+			}
+			c.Header("Content-Type", ProblemContentType)
+			c.JSON(appErr.Status, problem)
 		}
 	}
 }
+
+// retryAfterSeconds rounds up, a client coming back on time must not be too early.
+func retryAfterSeconds(after time.Duration) string {
+	return strconv.FormatInt(int64((after+time.Second-1)/time.Second), 10)
+}
@@ -2,12 +2,16 @@ package middleware
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/ggoulart/url-shortener/internal/apperror"
 	"github.com/ggoulart/url-shortener/internal/controller"
 	"github.com/ggoulart/url-shortener/internal/repository"
+	"github.com/ggoulart/url-shortener/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
@@ -16,26 +20,115 @@ func TestErrorHandler_Unit(t *testing.T) {
 	tests := []struct {
 		name           string
 		errToAttach    error
+		expectedError  *apperror.Error
 		expectedStatus int
 		expectedBody   string
+		expectedRetry  string
 	}{
 		{
-			name:           "bad request error",
-			errToAttach:    controller.ErrBadRequest,
+			name:          "bad request error",
+			errToAttach:   controller.ErrBadRequest,
+			expectedError: controller.ErrBadRequest,
+		},
+		{
+			name:          "invalid request error",
+			errToAttach:   ErrInvalidRequest,
+			expectedError: ErrInvalidRequest,
+		},
+		{
+			name:          "invalid alias error",
+			errToAttach:   service.ErrInvalidAlias,
+			expectedError: service.ErrInvalidAlias,
+		},
+		{
+			name:          "alias taken error",
+			errToAttach:   service.ErrAliasTaken,
+			expectedError: service.ErrAliasTaken,
+		},
+		{
+			name:          "invalid expiration error",
+			errToAttach:   service.ErrInvalidExpiration,
+			expectedError: service.ErrInvalidExpiration,
+		},
+		{
+			name:          "invalid stats query error",
+			errToAttach:   service.ErrInvalidStatsQuery,
+			expectedError: service.ErrInvalidStatsQuery,
+		},
+		{
+			name:          "invalid url error",
+			errToAttach:   service.ErrInvalidURL,
+			expectedError: service.ErrInvalidURL,
+		},
+		{
+			name:          "invalid batch size error",
+			errToAttach:   service.ErrInvalidBatchSize,
+			expectedError: service.ErrInvalidBatchSize,
+		},
+		{
+			name:          "invalid list query error",
+			errToAttach:   service.ErrInvalidListQuery,
+			expectedError: service.ErrInvalidListQuery,
+		},
+		{
+			name:          "link expired error",
+			errToAttach:   service.ErrLinkExpired,
+			expectedError: service.ErrLinkExpired,
+		},
+		{
+			name:          "policy violation error",
+			errToAttach:   service.ErrPrivateDestination,
+			expectedError: service.ErrPrivateDestination,
+		},
+		{
+			name:          "invalid management token error",
+			errToAttach:   service.ErrInvalidManagementToken,
+			expectedError: service.ErrInvalidManagementToken,
+		},
+		{
+			name:          "link disabled error",
+			errToAttach:   service.ErrLinkDisabled,
+			expectedError: service.ErrLinkDisabled,
+		},
+		{
+			name:          "history version not found error",
+			errToAttach:   service.ErrVersionNotFound,
+			expectedError: service.ErrVersionNotFound,
+		},
+		{
+			name:          "not found error",
+			errToAttach:   repository.ErrNotFound,
+			expectedError: repository.ErrNotFound,
+		},
+		{
+			name:           "error with detail and fields",
+			errToAttach:    controller.ErrBadRequest.WithDetail("body is not valid JSON").WithFields(apperror.FieldError{Field: "longUrl", Reason: "is required"}),
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"` + controller.ErrBadRequest.Error() + `"}`,
+			expectedBody: `{"type":"urn:url-shortener:problem:invalid_body","title":"invalid body","status":400,` +
+				`"detail":"body is not valid JSON","instance":"/api/v1/shorten","code":"invalid_body",` +
+				`"errors":[{"field":"longUrl","reason":"is required"}]}`,
 		},
 		{
-			name:           "not found error",
-			errToAttach:    repository.ErrNotFound,
+			name:           "wrapped error",
+			errToAttach:    fmt.Errorf("%w: aB3dE6g", repository.ErrNotFound),
 			expectedStatus: http.StatusNotFound,
-			expectedBody:   `{"error":"` + repository.ErrNotFound.Error() + `"}`,
+			expectedBody: `{"type":"urn:url-shortener:problem:not_found","title":"record not found","status":404,` +
+				`"detail":"aB3dE6g","instance":"/api/v1/shorten","code":"not_found"}`,
+		},
+		{
+			name:           "error with retry after",
+			errToAttach:    ErrRateLimited.WithRetryAfter(1500 * time.Millisecond),
+			expectedStatus: http.StatusTooManyRequests,
+			expectedBody: `{"type":"urn:url-shortener:problem:rate_limited","title":"too many requests","status":429,` +
+				`"instance":"/api/v1/shorten","code":"rate_limited"}`,
+			expectedRetry: "2",
 		},
 		{
 			name:           "internal server error",
 			errToAttach:    errors.New("something broke"),
 			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   `{"error":"something broke"}`,
+			expectedBody: `{"type":"urn:url-shortener:problem:internal_error","title":"internal error","status":500,` +
+				`"instance":"/api/v1/shorten","code":"internal_error"}`,
 		},
 	}
 
@@ -44,14 +137,23 @@ func TestErrorHandler_Unit(t *testing.T) {
 			resp := httptest.NewRecorder()
 
 			c, _ := gin.CreateTestContext(resp)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/shorten", nil)
 
 			c.Error(tt.errToAttach)
 
 			middlewareFunc := ErrorHandler()
 			middlewareFunc(c)
 
+			if tt.expectedError != nil {
+				tt.expectedStatus = tt.expectedError.Status
+				tt.expectedBody = fmt.Sprintf(`{"type":"urn:url-shortener:problem:%s","title":%q,"status":%d,"instance":"/api/v1/shorten","code":%q}`,
+					tt.expectedError.Code, tt.expectedError.Title, tt.expectedError.Status, tt.expectedError.Code)
+			}
+
 			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.Equal(t, ProblemContentType, resp.Header().Get("Content-Type"))
 			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+			assert.Equal(t, tt.expectedRetry, resp.Header().Get("Retry-After"))
 		})
 	}
 }
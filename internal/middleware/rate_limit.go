@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/ratelimit"
+	"github.com/ggoulart/url-shortener/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+var ErrRateLimited = apperror.New("rate_limited", http.StatusTooManyRequests, "too many requests")
+
+type Limiter interface {
+	Allow(key string) ratelimit.Decision
+}
+
+// RateLimit throttles requests per API key or user, and per client IP for anonymous
+// ones, so it has to run after Authenticate. Every response tells the client where
+// it stands in RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset.
+func RateLimit(limiter Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		decision := limiter.Allow(clientKey(c))
+
+		c.Header("RateLimit-Limit", strconv.Itoa(decision.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		c.Header("RateLimit-Reset", retryAfterSeconds(decision.Reset))
+
+		if !decision.Allowed {
+			c.Error(ErrRateLimited.WithRetryAfter(decision.RetryAfter))
+			c.Abort()
+		}
+	}
+}
+
+func clientKey(c *gin.Context) string {
+	principal, ok := service.PrincipalFromContext(c.Request.Context())
+	if ok {
+		return principal.Subject()
+	}
+	return "ip:" + c.ClientIP()
+}
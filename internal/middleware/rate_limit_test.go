@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/ggoulart/url-shortener/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRateLimit(t *testing.T) {
+	tests := []struct {
+		name               string
+		authorization      string
+		setup              func(*MockLimiter)
+		expectedStatus     int
+		expectedRemaining  string
+		expectedReset      string
+		expectedRetryAfter string
+		expectedCodeInBody string
+	}{
+		{
+			name: "when an anonymous client has tokens left",
+			setup: func(m *MockLimiter) {
+				m.On("Allow", "ip:192.0.2.1").Return(ratelimit.Decision{Allowed: true, Limit: 10, Remaining: 9, Reset: 6 * time.Second})
+			},
+			expectedStatus:    http.StatusOK,
+			expectedRemaining: "9",
+			expectedReset:     "6",
+		},
+		{
+			name:          "when a key has run out of tokens",
+			authorization: "Bearer usk_creator",
+			setup: func(m *MockLimiter) {
+				m.On("Allow", "key:a-key-id").Return(ratelimit.Decision{Limit: 10, Reset: 60 * time.Second, RetryAfter: 5500 * time.Millisecond})
+			},
+			expectedStatus:     http.StatusTooManyRequests,
+			expectedRemaining:  "0",
+			expectedReset:      "60",
+			expectedRetryAfter: "6",
+			expectedCodeInBody: "rate_limited",
+		},
+		{
+			name:          "when a user has tokens left on any of their sessions",
+			authorization: "Bearer uss_session",
+			setup: func(m *MockLimiter) {
+				m.On("Allow", "user:a-user-id").Return(ratelimit.Decision{Allowed: true, Limit: 10, Remaining: 3, Reset: 42 * time.Second})
+			},
+			expectedStatus:    http.StatusOK,
+			expectedRemaining: "3",
+			expectedReset:     "42",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator := &MockAuthenticator{}
+			authenticator.On("Authenticate", mock.Anything, "usk_creator").Return(model.Principal{KeyID: "a-key-id", Scopes: []model.Scope{model.ScopeCreate}}, nil)
+			authenticator.On("Authenticate", mock.Anything, "uss_session").Return(model.Principal{UserID: "a-user-id", SessionID: "a-session-id", Scopes: []model.Scope{model.ScopeCreate}}, nil)
+			limiter := &MockLimiter{}
+			tt.setup(limiter)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(ErrorHandler(), Authenticate(authenticator))
+			r.POST("/shorten", RateLimit(limiter), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodPost, "/shorten", nil)
+			req.RemoteAddr = "192.0.2.1:4321"
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			recorder := httptest.NewRecorder()
+
+			r.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, "10", recorder.Header().Get("RateLimit-Limit"))
+			assert.Equal(t, tt.expectedRemaining, recorder.Header().Get("RateLimit-Remaining"))
+			assert.Equal(t, tt.expectedReset, recorder.Header().Get("RateLimit-Reset"))
+			assert.Equal(t, tt.expectedRetryAfter, recorder.Header().Get("Retry-After"))
+			if tt.expectedCodeInBody != "" {
+				assert.Contains(t, recorder.Body.String(), `"code":"`+tt.expectedCodeInBody+`"`)
+			}
+			limiter.AssertExpectations(t)
+		})
+	}
+}
+
+type MockLimiter struct {
+	mock.Mock
+}
+
+func (m *MockLimiter) Allow(key string) ratelimit.Decision {
+	args := m.Called(key)
+	return args.Get(0).(ratelimit.Decision)
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/controller"
+	"github.com/gin-gonic/gin"
+)
+
+var ErrInvalidRequest = apperror.New("invalid_request", http.StatusBadRequest, "invalid request")
+
+// ValidateRequests rejects requests that break the OpenAPI document before they reach
+// a controller. Paths the document doesn't describe are left to gin's own routing.
+func ValidateRequests(doc *openapi3.T) (gin.HandlerFunc, error) {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to route openapi document: %v", err)
+	}
+
+	// credentials are checked by Authenticate and RequireScope before this runs
+	options := &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc}
+
+	return func(c *gin.Context) {
+		route, pathParams, err := router.FindRoute(c.Request)
+		if err != nil {
+			return
+		}
+
+		err = openapi3filter.ValidateRequest(c.Request.Context(), &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    options,
+		})
+		if err != nil {
+			slog.Warn(fmt.Sprintf("request does not match openapi document: %v", err))
+			c.Error(requestError(err))
+			c.Abort()
+		}
+	}, nil
+}
+
+// requestError keeps what the client got wrong and drops the schema dumps
+// openapi3filter puts in its errors.
+func requestError(err error) error {
+	var requestErr *openapi3filter.RequestError
+	if !errors.As(err, &requestErr) {
+		return ErrInvalidRequest.WithDetail(err.Error())
+	}
+
+	var parseErr *openapi3filter.ParseError
+	if requestErr.RequestBody != nil && errors.As(requestErr.Err, &parseErr) {
+		return controller.ErrBadRequest.WithDetail("body is not valid JSON")
+	}
+
+	reason := requestErr.Reason
+	var pointer []string
+	var schemaErr *openapi3.SchemaError
+	if errors.As(requestErr.Err, &schemaErr) {
+		// format errors go on to dump the whole pattern
+		reason, _, _ = strings.Cut(schemaErr.Reason, " (string doesn't match pattern")
+		pointer = schemaErr.JSONPointer()
+	} else if reason == "" && requestErr.Err != nil {
+		reason = requestErr.Err.Error()
+	}
+
+	switch {
+	case requestErr.Parameter != nil:
+		return ErrInvalidRequest.
+			WithDetail(fmt.Sprintf("%s parameter %q: %s", requestErr.Parameter.In, requestErr.Parameter.Name, reason)).
+			WithFields(apperror.FieldError{Field: requestErr.Parameter.Name, Reason: reason})
+	case requestErr.RequestBody != nil && len(pointer) > 0:
+		return ErrInvalidRequest.
+			WithDetail(fmt.Sprintf("request body: %s at %q", reason, "/"+strings.Join(pointer, "/"))).
+			WithFields(apperror.FieldError{Field: strings.Join(pointer, "."), Reason: reason})
+	case requestErr.RequestBody != nil:
+		return ErrInvalidRequest.WithDetail(fmt.Sprintf("request body: %s", reason))
+	default:
+		return ErrInvalidRequest.WithDetail(reason)
+	}
+}
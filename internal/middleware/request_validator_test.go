@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+const testDocument = `
+openapi: 3.0.3
+info:
+  title: test
+  version: 1.0.0
+paths:
+  /items:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            minimum: 1
+      responses:
+        "200":
+          description: ok
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+      responses:
+        "201":
+          description: created
+`
+
+func TestValidateRequests(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		contentType    string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "when request matches the document",
+			method:         http.MethodGet,
+			target:         "/items?limit=10",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"ok":true}`,
+		},
+		{
+			name:           "when query parameter is not an integer",
+			method:         http.MethodGet,
+			target:         "/items?limit=all",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: `{"type":"urn:url-shortener:problem:invalid_request","title":"invalid request","status":400,` +
+				`"detail":"query parameter \"limit\": value all: an invalid integer: invalid syntax","instance":"/items","code":"invalid_request",` +
+				`"errors":[{"field":"limit","reason":"value all: an invalid integer: invalid syntax"}]}`,
+		},
+		{
+			name:           "when query parameter is below its minimum",
+			method:         http.MethodGet,
+			target:         "/items?limit=0",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: `{"type":"urn:url-shortener:problem:invalid_request","title":"invalid request","status":400,` +
+				`"detail":"query parameter \"limit\": number must be at least 1","instance":"/items","code":"invalid_request",` +
+				`"errors":[{"field":"limit","reason":"number must be at least 1"}]}`,
+		},
+		{
+			name:           "when body misses a required property",
+			method:         http.MethodPost,
+			target:         "/items",
+			contentType:    "application/json",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: `{"type":"urn:url-shortener:problem:invalid_request","title":"invalid request","status":400,` +
+				`"detail":"request body: property \"name\" is missing at \"/name\"","instance":"/items","code":"invalid_request",` +
+				`"errors":[{"field":"name","reason":"property \"name\" is missing"}]}`,
+		},
+		{
+			name:           "when body is not json",
+			method:         http.MethodPost,
+			target:         "/items",
+			contentType:    "application/json",
+			body:           `{"name":`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: `{"type":"urn:url-shortener:problem:invalid_body","title":"invalid body","status":400,` +
+				`"detail":"body is not valid JSON","instance":"/items","code":"invalid_body"}`,
+		},
+		{
+			name:           "when content type is not json",
+			method:         http.MethodPost,
+			target:         "/items",
+			contentType:    "text/plain",
+			body:           `{"name":"a"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: `{"type":"urn:url-shortener:problem:invalid_request","title":"invalid request","status":400,` +
+				`"detail":"request body: header Content-Type has unexpected value \"text/plain\"","instance":"/items","code":"invalid_request"}`,
+		},
+		{
+			name:           "when path is not in the document it is left to gin",
+			method:         http.MethodGet,
+			target:         "/unknown?limit=all",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"ok":true}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := openapi3.NewLoader().LoadFromData([]byte(testDocument))
+			assert.NoError(t, err)
+
+			validator, err := ValidateRequests(doc)
+			assert.NoError(t, err)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(ErrorHandler(), validator)
+			ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) }
+			r.GET("/items", ok)
+			r.POST("/items", ok)
+			r.GET("/unknown", ok)
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			recorder := httptest.NewRecorder()
+
+			r.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.JSONEq(t, tt.expectedBody, recorder.Body.String())
+		})
+	}
+}
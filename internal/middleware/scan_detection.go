@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+var ErrScanningBlocked = apperror.New("scanning_blocked", http.StatusTooManyRequests, "too many requests for missing links")
+
+type ScanDetector interface {
+	Check(ip string) ratelimit.Verdict
+	Record(ip string, missed bool)
+}
+
+// DetectScanning holds up clients that look like they are walking the key space,
+// most of their requests asking for links that don't exist. Clients are told apart
+// by IP, each request is counted once it was handled.
+func DetectScanning(detector ScanDetector) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+
+		verdict := detector.Check(ip)
+		if verdict.Blocked {
+			c.Error(ErrScanningBlocked.WithRetryAfter(verdict.RetryAfter))
+			c.Abort()
+			return
+		}
+
+		if verdict.Delay > 0 {
+			timer := time.NewTimer(verdict.Delay)
+			select {
+			case <-timer.C:
+			case <-c.Request.Context().Done():
+				timer.Stop()
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+
+		detector.Record(ip, missed(c))
+	}
+}
+
+// missed tells whether the request asked for a link that doesn't exist. Handlers
+// attach their errors and leave rendering to ErrorHandler, so the status isn't
+// written yet.
+func missed(c *gin.Context) bool {
+	if err := c.Errors.Last(); err != nil {
+		appErr, ok := apperror.As(err.Err)
+		return ok && appErr.Status == http.StatusNotFound
+	}
+	return c.Writer.Status() == http.StatusNotFound
+}
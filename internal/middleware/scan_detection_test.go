@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/ratelimit"
+	"github.com/ggoulart/url-shortener/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDetectScanning(t *testing.T) {
+	tests := []struct {
+		name               string
+		target             string
+		setup              func(*MockScanDetector)
+		expectedStatus     int
+		expectedRetryAfter string
+		expectedCode       string
+	}{
+		{
+			name:   "when the link exists",
+			target: "/aB3dE6g",
+			setup: func(m *MockScanDetector) {
+				m.On("Check", "192.0.2.1").Return(ratelimit.Verdict{})
+				m.On("Record", "192.0.2.1", false).Return()
+			},
+			expectedStatus: http.StatusFound,
+		},
+		{
+			name:   "when the link does not exist",
+			target: "/missing",
+			setup: func(m *MockScanDetector) {
+				m.On("Check", "192.0.2.1").Return(ratelimit.Verdict{})
+				m.On("Record", "192.0.2.1", true).Return()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "not_found",
+		},
+		{
+			name:   "when the client is slowed down",
+			target: "/missing",
+			setup: func(m *MockScanDetector) {
+				m.On("Check", "192.0.2.1").Return(ratelimit.Verdict{Delay: time.Millisecond})
+				m.On("Record", "192.0.2.1", true).Return()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "not_found",
+		},
+		{
+			name:   "when the client is blocked",
+			target: "/aB3dE6g",
+			setup: func(m *MockScanDetector) {
+				m.On("Check", "192.0.2.1").Return(ratelimit.Verdict{Blocked: true, RetryAfter: 10 * time.Minute})
+			},
+			expectedStatus:     http.StatusTooManyRequests,
+			expectedRetryAfter: "600",
+			expectedCode:       "scanning_blocked",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := &MockScanDetector{}
+			tt.setup(detector)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(ErrorHandler())
+			r.GET("/:encodedKey", DetectScanning(detector), func(c *gin.Context) {
+				if c.Param("encodedKey") == "missing" {
+					c.Error(repository.ErrNotFound)
+					return
+				}
+				c.Redirect(http.StatusFound, "https://example.com")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.RemoteAddr = "192.0.2.1:4321"
+			recorder := httptest.NewRecorder()
+
+			r.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedRetryAfter, recorder.Header().Get("Retry-After"))
+			if tt.expectedCode != "" {
+				assert.Contains(t, recorder.Body.String(), `"code":"`+tt.expectedCode+`"`)
+			}
+			detector.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDetectScanning_GivesUpWithTheClient(t *testing.T) {
+	detector := &MockScanDetector{}
+	detector.On("Check", "192.0.2.1").Return(ratelimit.Verdict{Delay: time.Hour})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/:encodedKey", DetectScanning(detector), func(c *gin.Context) {
+		t.Error("a client that went away is not served")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "/aB3dE6g", nil).WithContext(ctx)
+	req.RemoteAddr = "192.0.2.1:4321"
+
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	detector.AssertNotCalled(t, "Record", mock.Anything, mock.Anything)
+}
+
+type MockScanDetector struct {
+	mock.Mock
+}
+
+func (m *MockScanDetector) Check(ip string) ratelimit.Verdict {
+	args := m.Called(ip)
+	return args.Get(0).(ratelimit.Verdict)
+}
+
+func (m *MockScanDetector) Record(ip string, missed bool) {
+	m.Called(ip, missed)
+}
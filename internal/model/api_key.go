@@ -0,0 +1,60 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// Scope is a permission granted to an API key. Admin implies every other scope.
+type Scope string
+
+const (
+	ScopeCreate Scope = "create"
+	ScopeRead   Scope = "read"
+	ScopeAdmin  Scope = "admin"
+)
+
+// APIKey is a credential to the API. Only the hash of its secret is stored, Prefix
+// is the start of the secret, kept so a key can be told apart from the others.
+type APIKey struct {
+	ID         string
+	Name       string
+	Prefix     string
+	Hash       string
+	Scopes     []Scope
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// MintedAPIKey is a key that was just created, Secret is never stored or shown again.
+type MintedAPIKey struct {
+	APIKey
+	Secret string
+}
+
+// Principal is who a request authenticated as, either an API key or the session of
+// a signed in user. UserID is only set for the latter.
+type Principal struct {
+	KeyID     string
+	Name      string
+	UserID    string
+	SessionID string
+	Scopes    []Scope
+	// WorkspaceID is the workspace the request acts in, as picked by the client.
+	// Membership is only checked when the workspace is used.
+	WorkspaceID string
+}
+
+func (p Principal) HasScope(scope Scope) bool {
+	return slices.Contains(p.Scopes, scope) || slices.Contains(p.Scopes, ScopeAdmin)
+}
+
+// Subject names who is accountable for a request, a user across all their sessions
+// or a key. Limits and quotas are counted per subject.
+func (p Principal) Subject() string {
+	if p.UserID != "" {
+		return "user:" + p.UserID
+	}
+	return "key:" + p.KeyID
+}
@@ -0,0 +1,9 @@
+package model
+
+// CacheStats counts the lookups of the link cache since the process started, and
+// how many keys it holds now.
+type CacheStats struct {
+	Hits   int64
+	Misses int64
+	Size   int
+}
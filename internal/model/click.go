@@ -0,0 +1,41 @@
+package model
+
+import (
+	"net/url"
+	"time"
+)
+
+type Click struct {
+	EncodedKey string
+	ClickedAt  time.Time
+	Referrer   string
+	UserAgent  string
+	ClientIP   string
+}
+
+type ClickBucket struct {
+	Start  time.Time
+	Clicks int64
+}
+
+type StatsQuery struct {
+	Interval string
+	From     time.Time
+	To       time.Time
+}
+
+type LinkStats struct {
+	EncodedKey  string
+	TotalClicks int64
+	Interval    string
+	Series      []ClickBucket
+}
+
+// LinkMetadata describes a link without following it.
+type LinkMetadata struct {
+	EncodedKey  string
+	LongURL     url.URL
+	CreatedAt   time.Time
+	ExpiresAt   *time.Time
+	TotalClicks int64
+}
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"net/url"
+	"time"
+)
+
+type Link struct {
+	EncodedKey          string
+	LongURL             url.URL
+	ExpiresAt           *time.Time
+	ManagementTokenHash string
+	DisabledAt          *time.Time
+	CreatedAt           time.Time
+	// OwnerID is the user who created the link, links created with an API key have none
+	OwnerID string
+	// WorkspaceID is the workspace the link belongs to, its members manage it by role
+	WorkspaceID string
+}
+
+type ShortenOptions struct {
+	Alias     string
+	ExpiresAt *time.Time
+	TTL       time.Duration
+}
+
+// HistoryEntry is a destination a link pointed to before it was edited. Version
+// counts up from 1 per link, ChangedAt and Actor describe the edit that replaced it.
+type HistoryEntry struct {
+	Version   int
+	LongURL   url.URL
+	ChangedAt time.Time
+	Actor     string
+}
+
+type ShortenResult struct {
+	ShortURL url.URL
+	// Created tells a new link from an existing one that was handed out again
+	Created bool
+	// ManagementToken is only set when a new link without an owner was created, it is
+	// never stored or shown again
+	ManagementToken string
+}
+
+// BatchItem is one url of a batch shorten request.
+type BatchItem struct {
+	LongURL url.URL
+	Options ShortenOptions
+}
+
+// BatchResult is the outcome of the BatchItem at the same index, Err is set when that
+// item failed and the rest of the batch went through.
+type BatchResult struct {
+	ShortenResult
+	Err error
+}
+
+// LinkFilter narrows a listing of links, zero fields don't filter. Links are listed
+// newest first and After resumes the listing right past the link it points at.
+type LinkFilter struct {
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	Domain      string
+	KeyPrefix   string
+	Search      string
+	OwnerID     string
+	WorkspaceID string
+	After       *LinkCursor
+	Limit       int
+}
+
+// LinkCursor is the position of a link in the listing order.
+type LinkCursor struct {
+	CreatedAt  time.Time
+	EncodedKey string
+}
+
+type LinkPage struct {
+	Links      []Link
+	NextCursor string
+}
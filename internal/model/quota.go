@@ -0,0 +1,14 @@
+package model
+
+// CreationQuota caps how many links a subject creates per UTC day and calendar
+// month. Zero leaves a period uncapped.
+type CreationQuota struct {
+	Daily   int
+	Monthly int
+}
+
+// CreationUsage is how many links a subject created so far today and this month.
+type CreationUsage struct {
+	Daily   int
+	Monthly int
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// User is an account signed in to with an email and a password, only the hash of the
+// password is stored.
+type User struct {
+	ID           string
+	Email        string
+	PasswordHash string
+	CreatedAt    time.Time
+}
+
+// Session is one sign in of a user. Only the hashes of its tokens are stored, a
+// refresh replaces both tokens so the previous pair stops working.
+type Session struct {
+	ID               string
+	UserID           string
+	AccessHash       string
+	AccessExpiresAt  time.Time
+	RefreshHash      string
+	RefreshExpiresAt time.Time
+	CreatedAt        time.Time
+	RevokedAt        *time.Time
+}
+
+// SessionTokens are the secrets of a session, they are never stored or shown again.
+type SessionTokens struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
@@ -0,0 +1,75 @@
+package model
+
+import "time"
+
+// Role is what a member may do in a workspace, each role holds the permissions of
+// the roles below it: viewer, editor, admin, owner.
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+	RoleOwner  Role = "owner"
+)
+
+var roleRanks = map[Role]int{RoleViewer: 1, RoleEditor: 2, RoleAdmin: 3, RoleOwner: 4}
+
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// AtLeast tells whether r holds every permission of other.
+func (r Role) AtLeast(other Role) bool {
+	return r.Valid() && roleRanks[r] >= roleRanks[other]
+}
+
+// Action is something done in a workspace that needs a minimum role.
+type Action string
+
+const (
+	ActionViewLinks     Action = "view_links"
+	ActionCreateLinks   Action = "create_links"
+	ActionEditLinks     Action = "edit_links"
+	ActionDeleteLinks   Action = "delete_links"
+	ActionViewMembers   Action = "view_members"
+	ActionManageMembers Action = "manage_members"
+)
+
+type Workspace struct {
+	ID        string
+	Name      string
+	CreatedAt time.Time
+}
+
+// UserWorkspace is a workspace as seen by one of its members.
+type UserWorkspace struct {
+	Workspace
+	Role Role
+}
+
+type Member struct {
+	WorkspaceID string
+	UserID      string
+	Role        Role
+	JoinedAt    time.Time
+}
+
+// Invitation lets whoever holds its token join a workspace once, only the hash of
+// the token is stored.
+type Invitation struct {
+	ID          string
+	WorkspaceID string
+	Role        Role
+	TokenHash   string
+	InvitedBy   string
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// MintedInvitation is an invitation that was just created, Token is never stored or shown again.
+type MintedInvitation struct {
+	Invitation
+	Token string
+}
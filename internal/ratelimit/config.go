@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Config sets the limits of link creation and of redirects apart, following links
+// is far cheaper and far more common than creating them. Auth limits the sign in
+// routes, which are mostly called anonymously and so kept per IP, to slow down
+// password guessing. Clients bounds how many buckets are kept per limit.
+type Config struct {
+	Enabled  bool  `mapstructure:"ENABLED"`
+	Clients  int   `mapstructure:"CLIENTS"`
+	Create   Limit `mapstructure:"CREATE"`
+	Redirect Limit `mapstructure:"REDIRECT"`
+	Auth     Limit `mapstructure:"AUTH"`
+}
+
+func NewConfig() (*Config, error) {
+	config := &Config{
+		Enabled:  true,
+		Clients:  100000,
+		Create:   Limit{PerMinute: 60, Burst: 30},
+		Redirect: Limit{PerMinute: 1200, Burst: 100},
+		Auth:     Limit{PerMinute: 10, Burst: 20},
+	}
+	err := viper.UnmarshalKey("ratelimit", config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rate limit config: %v", err)
+	}
+
+	if config.Enabled && (config.Clients <= 0 || !config.Create.valid() || !config.Redirect.valid() || !config.Auth.valid()) {
+		return nil, fmt.Errorf("invalid rate limit config: clients %d, create %+v, redirect %+v, auth %+v", config.Clients, config.Create, config.Redirect, config.Auth)
+	}
+
+	return config, nil
+}
+
+func (l Limit) valid() bool {
+	return l.PerMinute > 0 && l.Burst > 0
+}
+
+// ScanConfig sets when a client of the redirect route counts as scanning for keys:
+// at least MinRequests requests within Window of which MissRatio or more missed.
+// Allowlist holds addresses and CIDR prefixes of trusted clients, such as uptime
+// monitors, which are never slowed down or blocked.
+type ScanConfig struct {
+	Enabled     bool          `mapstructure:"ENABLED"`
+	Clients     int           `mapstructure:"CLIENTS"`
+	Window      time.Duration `mapstructure:"WINDOW"`
+	MinRequests int           `mapstructure:"MIN_REQUESTS"`
+	MissRatio   float64       `mapstructure:"MISS_RATIO"`
+	Delay       time.Duration `mapstructure:"DELAY"`
+	MaxDelay    time.Duration `mapstructure:"MAX_DELAY"`
+	BlockAfter  int           `mapstructure:"BLOCK_AFTER"`
+	BlockFor    time.Duration `mapstructure:"BLOCK_FOR"`
+	Allowlist   []string      `mapstructure:"ALLOWLIST"`
+}
+
+func NewScanConfig() (*ScanConfig, error) {
+	config := &ScanConfig{
+		Enabled:     true,
+		Clients:     100000,
+		Window:      time.Minute,
+		MinRequests: 20,
+		MissRatio:   0.5,
+		Delay:       100 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+		BlockAfter:  20,
+		BlockFor:    15 * time.Minute,
+	}
+	err := viper.UnmarshalKey("scanning", config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scanning config: %v", err)
+	}
+
+	if !config.Enabled {
+		return config, nil
+	}
+
+	if config.Clients <= 0 || config.Window <= 0 || config.MinRequests <= 0 || config.MissRatio <= 0 || config.MissRatio > 1 {
+		return nil, fmt.Errorf("invalid scanning config: clients %d, window %s, min requests %d, miss ratio %g", config.Clients, config.Window, config.MinRequests, config.MissRatio)
+	}
+
+	if config.Delay < 0 || config.MaxDelay < config.Delay || config.BlockAfter < 0 || config.BlockFor <= 0 {
+		return nil, fmt.Errorf("invalid scanning config: delay %s, max delay %s, block after %d, block for %s", config.Delay, config.MaxDelay, config.BlockAfter, config.BlockFor)
+	}
+
+	_, err = parseAllowlist(config.Allowlist)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scanning config: %v", err)
+	}
+
+	return config, nil
+}
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    *Config
+		wantErr error
+	}{
+		{
+			name: "applies defaults for missing keys",
+			yaml: "db:\n  HOST: localhost\n",
+			want: &Config{Enabled: true, Clients: 100000, Create: Limit{PerMinute: 60, Burst: 30}, Redirect: Limit{PerMinute: 1200, Burst: 100}, Auth: Limit{PerMinute: 10, Burst: 20}},
+		},
+		{
+			name: "reads configured keys",
+			yaml: "ratelimit:\n  CLIENTS: 10\n  CREATE:\n    PER_MINUTE: 6\n    BURST: 2\n  REDIRECT:\n    PER_MINUTE: 60\n    BURST: 5\n  AUTH:\n    PER_MINUTE: 3\n    BURST: 1\n",
+			want: &Config{Enabled: true, Clients: 10, Create: Limit{PerMinute: 6, Burst: 2}, Redirect: Limit{PerMinute: 60, Burst: 5}, Auth: Limit{PerMinute: 3, Burst: 1}},
+		},
+		{
+			name: "when disabled the limits are not checked",
+			yaml: "ratelimit:\n  ENABLED: false\n  CREATE:\n    BURST: 0\n",
+			want: &Config{Enabled: false, Clients: 100000, Create: Limit{PerMinute: 60, Burst: 0}, Redirect: Limit{PerMinute: 1200, Burst: 100}, Auth: Limit{PerMinute: 10, Burst: 20}},
+		},
+		{
+			name:    "when a limit is invalid",
+			yaml:    "ratelimit:\n  REDIRECT:\n    PER_MINUTE: 0\n",
+			wantErr: errors.New("invalid rate limit config: clients 100000, create {PerMinute:60 Burst:30}, redirect {PerMinute:0 Burst:100}, auth {PerMinute:10 Burst:20}"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			viper.SetConfigType("yaml")
+			assert.NoError(t, viper.ReadConfig(strings.NewReader(tt.yaml)))
+
+			got, err := NewConfig()
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestNewScanConfig(t *testing.T) {
+	defaults := ScanConfig{Enabled: true, Clients: 100000, Window: time.Minute, MinRequests: 20, MissRatio: 0.5, Delay: 100 * time.Millisecond, MaxDelay: 5 * time.Second, BlockAfter: 20, BlockFor: 15 * time.Minute}
+
+	tests := []struct {
+		name    string
+		yaml    string
+		want    *ScanConfig
+		wantErr error
+	}{
+		{
+			name: "applies defaults for missing keys",
+			yaml: "db:\n  HOST: localhost\n",
+			want: &defaults,
+		},
+		{
+			name: "reads configured keys",
+			yaml: "scanning:\n  WINDOW: 5m\n  MISS_RATIO: 0.8\n  ALLOWLIST: [\"10.0.0.0/8\", \"192.0.2.7\"]\n",
+			want: &ScanConfig{Enabled: true, Clients: 100000, Window: 5 * time.Minute, MinRequests: 20, MissRatio: 0.8, Delay: 100 * time.Millisecond, MaxDelay: 5 * time.Second, BlockAfter: 20, BlockFor: 15 * time.Minute, Allowlist: []string{"10.0.0.0/8", "192.0.2.7"}},
+		},
+		{
+			name:    "when the miss ratio can never be reached",
+			yaml:    "scanning:\n  MISS_RATIO: 1.5\n",
+			wantErr: errors.New("invalid scanning config: clients 100000, window 1m0s, min requests 20, miss ratio 1.5"),
+		},
+		{
+			name:    "when the max delay is below the delay",
+			yaml:    "scanning:\n  MAX_DELAY: 10ms\n",
+			wantErr: errors.New("invalid scanning config: delay 100ms, max delay 10ms, block after 20, block for 15m0s"),
+		},
+		{
+			name:    "when the allowlist has a host name",
+			yaml:    "scanning:\n  ALLOWLIST: [\"monitoring.example.com\"]\n",
+			wantErr: errors.New(`invalid scanning config: invalid allowlist entry "monitoring.example.com", want an address or a CIDR prefix`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			viper.SetConfigType("yaml")
+			assert.NoError(t, viper.ReadConfig(strings.NewReader(tt.yaml)))
+
+			got, err := NewScanConfig()
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/cache"
+)
+
+// Verdict is how a client's next request is to be handled, after Delay or not at
+// all while it is Blocked.
+type Verdict struct {
+	Delay      time.Duration
+	Blocked    bool
+	RetryAfter time.Duration
+}
+
+// ScanDetector tells clients walking the key space apart from clients following
+// links. It keeps how many requests of each client missed over a sliding window.
+// Once the share of misses crosses the threshold every further request is delayed a
+// little longer than the one before, and a client that keeps going is blocked.
+type ScanDetector struct {
+	mu        sync.Mutex
+	config    ScanConfig
+	allowlist []netip.Prefix
+	clients   *cache.LRU[string, *scanState]
+	now       func() time.Time
+}
+
+// scanState approximates the sliding window with two fixed ones: the counts of the
+// previous window are weighted by how much of it the sliding window still covers.
+type scanState struct {
+	windowStart  time.Time
+	current      scanCounts
+	previous     scanCounts
+	strikes      int
+	blockedUntil time.Time
+}
+
+type scanCounts struct {
+	requests int
+	misses   int
+}
+
+func NewScanDetector(config ScanConfig) (*ScanDetector, error) {
+	allowlist, err := parseAllowlist(config.Allowlist)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScanDetector{config: config, allowlist: allowlist, clients: cache.NewLRU[string, *scanState](config.Clients), now: time.Now}, nil
+}
+
+// Check decides on the next request of the client at ip. Every request made while
+// the client looks like a scanner counts as a strike, strikes set the delay and
+// BlockAfter of them block the client. Clients on the allowlist are never held up.
+func (d *ScanDetector) Check(ip string) Verdict {
+	if d.allowed(ip) {
+		return Verdict{}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	state, ok := d.clients.Get(ip)
+	if !ok {
+		return Verdict{}
+	}
+
+	if now.Before(state.blockedUntil) {
+		return Verdict{Blocked: true, RetryAfter: state.blockedUntil.Sub(now)}
+	}
+
+	requests, misses := state.counts(now, d.config.Window)
+	if requests < float64(d.config.MinRequests) || misses/requests < d.config.MissRatio {
+		state.strikes = 0
+		return Verdict{}
+	}
+
+	state.strikes++
+	if state.strikes > d.config.BlockAfter {
+		state.blockedUntil = now.Add(d.config.BlockFor)
+		state.strikes = 0
+		state.current, state.previous = scanCounts{}, scanCounts{}
+		d.clients.Set(ip, state, d.config.BlockFor+2*d.config.Window)
+		securityEvent("block", ip, requests, misses, d.config.Window, slog.Duration("blocked_for", d.config.BlockFor))
+		return Verdict{Blocked: true, RetryAfter: d.config.BlockFor}
+	}
+
+	if state.strikes == 1 {
+		securityEvent("slowdown", ip, requests, misses, d.config.Window)
+	}
+
+	return Verdict{Delay: d.delay(state.strikes)}
+}
+
+// Record counts a request of the client at ip once it was answered.
+func (d *ScanDetector) Record(ip string, missed bool) {
+	if d.allowed(ip) {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	state, ok := d.clients.Get(ip)
+	if !ok {
+		state = &scanState{windowStart: now}
+	}
+
+	state.advance(now, d.config.Window)
+	state.current.requests++
+	if missed {
+		state.current.misses++
+	}
+
+	ttl := 2 * d.config.Window
+	if now.Before(state.blockedUntil) {
+		ttl += state.blockedUntil.Sub(now)
+	}
+	d.clients.Set(ip, state, ttl)
+}
+
+// delay doubles with every strike, up to MaxDelay.
+func (d *ScanDetector) delay(strikes int) time.Duration {
+	delay := float64(d.config.Delay) * math.Pow(2, float64(strikes-1))
+	if delay > float64(d.config.MaxDelay) {
+		return d.config.MaxDelay
+	}
+	return time.Duration(delay)
+}
+
+func (d *ScanDetector) allowed(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+	for _, prefix := range d.allowlist {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *scanState) advance(now time.Time, window time.Duration) {
+	elapsed := int(now.Sub(s.windowStart) / window)
+	switch {
+	case elapsed <= 0:
+		return
+	case elapsed == 1:
+		s.previous = s.current
+	default:
+		s.previous = scanCounts{}
+	}
+	s.current = scanCounts{}
+	s.windowStart = s.windowStart.Add(time.Duration(elapsed) * window)
+}
+
+// counts estimates the requests and misses of the sliding window ending at now.
+func (s *scanState) counts(now time.Time, window time.Duration) (float64, float64) {
+	s.advance(now, window)
+	weight := 1 - float64(now.Sub(s.windowStart))/float64(window)
+
+	requests := float64(s.current.requests) + float64(s.previous.requests)*weight
+	misses := float64(s.current.misses) + float64(s.previous.misses)*weight
+	return requests, misses
+}
+
+// securityEvent logs a step taken against a suspected scanner as structured fields,
+// so they can be picked out of the log and alerted on.
+func securityEvent(action string, ip string, requests float64, misses float64, window time.Duration, attrs ...slog.Attr) {
+	attrs = append([]slog.Attr{
+		slog.String("category", "security"),
+		slog.String("event", "key_enumeration"),
+		slog.String("action", action),
+		slog.String("client_ip", ip),
+		slog.Int("requests", int(math.Round(requests))),
+		slog.Int("misses", int(math.Round(misses))),
+		slog.Duration("window", window),
+	}, attrs...)
+	slog.LogAttrs(context.Background(), slog.LevelWarn, "suspected key enumeration", attrs...)
+}
+
+// parseAllowlist reads addresses and CIDR prefixes, an address stands for itself.
+func parseAllowlist(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid allowlist entry %q, want an address or a CIDR prefix", entry)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowlist entry %q, want an address or a CIDR prefix", entry)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+
+	return prefixes, nil
+}
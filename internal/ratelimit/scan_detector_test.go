@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testScanConfig = ScanConfig{
+	Clients:     10,
+	Window:      time.Minute,
+	MinRequests: 4,
+	MissRatio:   0.5,
+	Delay:       100 * time.Millisecond,
+	MaxDelay:    250 * time.Millisecond,
+	BlockAfter:  3,
+	BlockFor:    10 * time.Minute,
+}
+
+func newTestScanDetector(t *testing.T, config ScanConfig, now *time.Time) *ScanDetector {
+	d, err := NewScanDetector(config)
+	assert.NoError(t, err)
+	d.now = func() time.Time { return *now }
+	return d
+}
+
+func TestScanDetector_Check(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	d := newTestScanDetector(t, testScanConfig, &now)
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, Verdict{}, d.Check("192.0.2.1"))
+		d.Record("192.0.2.1", true)
+	}
+	assert.Equal(t, Verdict{}, d.Check("192.0.2.1"), "too few requests to tell")
+	d.Record("192.0.2.1", false)
+
+	assert.Equal(t, Verdict{Delay: 100 * time.Millisecond}, d.Check("192.0.2.1"))
+	assert.Equal(t, Verdict{Delay: 200 * time.Millisecond}, d.Check("192.0.2.1"))
+	assert.Equal(t, Verdict{Delay: 250 * time.Millisecond}, d.Check("192.0.2.1"), "the delay is capped")
+	assert.Equal(t, Verdict{Blocked: true, RetryAfter: 10 * time.Minute}, d.Check("192.0.2.1"))
+	assert.Equal(t, Verdict{}, d.Check("192.0.2.2"), "every client is judged on its own requests")
+
+	now = now.Add(4 * time.Minute)
+	assert.Equal(t, Verdict{Blocked: true, RetryAfter: 6 * time.Minute}, d.Check("192.0.2.1"))
+
+	now = now.Add(6 * time.Minute)
+	assert.Equal(t, Verdict{}, d.Check("192.0.2.1"), "the block is lifted with a clean slate")
+}
+
+func TestScanDetector_Check_SlidingWindow(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	d := newTestScanDetector(t, testScanConfig, &now)
+
+	for i := 0; i < 4; i++ {
+		d.Record("192.0.2.1", true)
+	}
+
+	now = now.Add(90 * time.Second)
+	assert.Equal(t, Verdict{}, d.Check("192.0.2.1"), "half of the previous window still counts, 2 requests")
+
+	d.Record("192.0.2.1", true)
+	d.Record("192.0.2.1", false)
+	assert.Equal(t, Verdict{Delay: 100 * time.Millisecond}, d.Check("192.0.2.1"), "3 misses out of 4 requests")
+
+	d.Record("192.0.2.1", false)
+	d.Record("192.0.2.1", false)
+	d.Record("192.0.2.1", false)
+	assert.Equal(t, Verdict{}, d.Check("192.0.2.1"), "3 misses out of 7 requests")
+	d.Record("192.0.2.1", true)
+	d.Record("192.0.2.1", true)
+	assert.Equal(t, Verdict{Delay: 100 * time.Millisecond}, d.Check("192.0.2.1"), "strikes start over once a client looked fine")
+
+	now = now.Add(3 * time.Minute)
+	assert.Equal(t, Verdict{}, d.Check("192.0.2.1"), "old windows are forgotten")
+}
+
+func TestScanDetector_Allowlist(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	config := testScanConfig
+	config.Allowlist = []string{"198.51.100.0/24", "2001:db8::1", " 192.0.2.7 "}
+	d := newTestScanDetector(t, config, &now)
+
+	for _, ip := range []string{"198.51.100.42", "2001:db8::1", "::ffff:192.0.2.7", "192.0.2.8"} {
+		for i := 0; i < 10; i++ {
+			d.Record(ip, true)
+		}
+	}
+
+	assert.Equal(t, Verdict{}, d.Check("198.51.100.42"))
+	assert.Equal(t, Verdict{}, d.Check("2001:db8::1"))
+	assert.Equal(t, Verdict{}, d.Check("::ffff:192.0.2.7"))
+	assert.Equal(t, Verdict{Delay: 100 * time.Millisecond}, d.Check("192.0.2.8"))
+}
+
+func TestNewScanDetector_InvalidAllowlist(t *testing.T) {
+	config := testScanConfig
+	config.Allowlist = []string{"monitoring.example.com"}
+
+	_, err := NewScanDetector(config)
+
+	assert.EqualError(t, err, `invalid allowlist entry "monitoring.example.com", want an address or a CIDR prefix`)
+}
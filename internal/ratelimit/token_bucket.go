@@ -0,0 +1,86 @@
+// Package ratelimit throttles clients with a token bucket each. A bucket holds up
+// to Burst tokens and refills at PerMinute tokens a minute, every request takes one.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/cache"
+)
+
+type Limit struct {
+	PerMinute int `mapstructure:"PER_MINUTE"`
+	Burst     int `mapstructure:"BURST"`
+}
+
+// Decision is the outcome of a request and the state of its bucket afterwards.
+// Reset is how long until the bucket is full again, RetryAfter how long until the
+// next token when the request was refused.
+type Decision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Limiter keeps the buckets of the most recent clients. A bucket that is evicted or
+// idle long enough to refill is dropped, the client starts again with a full one.
+type Limiter struct {
+	mu      sync.Mutex
+	limit   Limit
+	rate    float64
+	buckets *cache.LRU[string, *bucket]
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+func NewLimiter(limit Limit, clients int) *Limiter {
+	return &Limiter{
+		limit:   limit,
+		rate:    float64(limit.PerMinute) / float64(time.Minute),
+		buckets: cache.NewLRU[string, *bucket](clients),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from the bucket of key, if there is one left.
+func (l *Limiter) Allow(key string) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	burst := float64(l.limit.Burst)
+
+	b, ok := l.buckets.Get(key)
+	if !ok {
+		b = &bucket{tokens: burst, updatedAt: now}
+	}
+	b.tokens = math.Min(burst, b.tokens+float64(now.Sub(b.updatedAt))*l.rate)
+	b.updatedAt = now
+
+	decision := Decision{Limit: l.limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = l.refill(1 - b.tokens)
+	}
+	decision.Remaining = int(b.tokens)
+	decision.Reset = l.refill(burst - b.tokens)
+
+	l.buckets.Set(key, b, l.refill(burst))
+
+	return decision
+}
+
+// refill is how long the bucket takes to gain tokens.
+func (l *Limiter) refill(tokens float64) time.Duration {
+	return time.Duration(math.Ceil(tokens / l.rate))
+}
@@ -0,0 +1,37 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	limiter := NewLimiter(Limit{PerMinute: 6, Burst: 2}, 10)
+	limiter.now = func() time.Time { return now }
+
+	assert.Equal(t, Decision{Allowed: true, Limit: 2, Remaining: 1, Reset: 10 * time.Second}, limiter.Allow("a"))
+	assert.Equal(t, Decision{Allowed: true, Limit: 2, Remaining: 0, Reset: 20 * time.Second}, limiter.Allow("a"))
+	assert.Equal(t, Decision{Allowed: false, Limit: 2, Remaining: 0, Reset: 20 * time.Second, RetryAfter: 10 * time.Second}, limiter.Allow("a"))
+	assert.Equal(t, Decision{Allowed: true, Limit: 2, Remaining: 1, Reset: 10 * time.Second}, limiter.Allow("b"), "every key has a bucket of its own")
+
+	now = now.Add(4 * time.Second)
+	assert.Equal(t, Decision{Allowed: false, Limit: 2, Remaining: 0, Reset: 16 * time.Second, RetryAfter: 6 * time.Second}, limiter.Allow("a"))
+
+	now = now.Add(6 * time.Second)
+	assert.Equal(t, Decision{Allowed: true, Limit: 2, Remaining: 0, Reset: 20 * time.Second}, limiter.Allow("a"))
+
+	now = now.Add(time.Hour)
+	assert.Equal(t, Decision{Allowed: true, Limit: 2, Remaining: 1, Reset: 10 * time.Second}, limiter.Allow("a"), "a bucket never holds more than the burst")
+}
+
+func TestLimiter_Evicted(t *testing.T) {
+	limiter := NewLimiter(Limit{PerMinute: 1, Burst: 1}, 1)
+
+	assert.True(t, limiter.Allow("a").Allowed)
+	assert.False(t, limiter.Allow("a").Allowed)
+	assert.True(t, limiter.Allow("b").Allowed)
+	assert.True(t, limiter.Allow("a").Allowed, "an evicted client starts with a full bucket")
+}
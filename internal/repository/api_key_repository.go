@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/lib/pq"
+)
+
+const apiKeyColumns = `id, name, key_prefix, key_hash, scopes, created_at, last_used_at, revoked_at`
+
+type APIKeyRepository struct {
+	db DB
+}
+
+func NewAPIKeyRepository(db DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) SaveAPIKey(ctx context.Context, key model.APIKey) error {
+	query := `INSERT INTO api_keys (id, name, key_prefix, key_hash, scopes, created_at) VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err := r.db.ExecContext(ctx, query, key.ID, key.Name, key.Prefix, key.Hash, pq.Array(scopeStrings(key.Scopes)), key.CreatedAt)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to insert api key: %v", err))
+		return ErrUnexpected
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepository) FindAPIKeyByHash(ctx context.Context, hash string) (model.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, hash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.APIKey{}, ErrNotFound
+		}
+
+		slog.Error(fmt.Sprintf("failed to find api key: %v", err))
+		return model.APIKey{}, ErrUnexpected
+	}
+
+	return key, nil
+}
+
+// ListAPIKeys returns every key, revoked ones included, oldest first.
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY created_at, id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to list api keys: %v", err))
+		return nil, ErrUnexpected
+	}
+	defer rows.Close()
+
+	keys := []model.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			slog.Error(fmt.Sprintf("failed to scan api key: %v", err))
+			return nil, ErrUnexpected
+		}
+		keys = append(keys, key)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to read api keys: %v", err))
+		return nil, ErrUnexpected
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey is idempotent, a key revoked twice keeps its first revocation time.
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, id string, revokedAt time.Time) error {
+	query := `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $2) WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id, revokedAt)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to revoke api key: %v", err))
+		return ErrUnexpected
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to read revoked api keys: %v", err))
+		return ErrUnexpected
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepository) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	query := `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, query, id, usedAt)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to touch api key: %v", err))
+		return ErrUnexpected
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIKey(row scanner) (model.APIKey, error) {
+	var key model.APIKey
+	var scopes []string
+	var lastUsedAt, revokedAt sql.NullTime
+	err := row.Scan(&key.ID, &key.Name, &key.Prefix, &key.Hash, pq.Array(&scopes), &key.CreatedAt, &lastUsedAt, &revokedAt)
+	if err != nil {
+		return model.APIKey{}, err
+	}
+
+	for _, scope := range scopes {
+		key.Scopes = append(key.Scopes, model.Scope(scope))
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+
+	return key, nil
+}
+
+func scopeStrings(scopes []model.Scope) []string {
+	strs := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		strs = append(strs, string(scope))
+	}
+
+	return strs
+}
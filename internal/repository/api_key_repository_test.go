@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var apiKeyRows = []string{"id", "name", "key_prefix", "key_hash", "scopes", "created_at", "last_used_at", "revoked_at"}
+
+func TestAPIKeyRepository_SaveAPIKey(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	key := model.APIKey{ID: "a-key-id", Name: "ci", Prefix: "usk_aB3dE6gH", Hash: "a-hash", Scopes: []model.Scope{model.ScopeCreate, model.ScopeRead}, CreatedAt: createdAt}
+	query := regexp.QuoteMeta(`INSERT INTO api_keys (id, name, key_prefix, key_hash, scopes, created_at) VALUES ($1, $2, $3, $4, $5, $6)`)
+
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "when failed to insert the key",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when successfully inserts the key",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).
+					WithArgs("a-key-id", "ci", "usk_aB3dE6gH", "a-hash", pq.Array([]string{"create", "read"}), createdAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewAPIKeyRepository(db)
+
+			err = r.SaveAPIKey(context.Background(), key)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAPIKeyRepository_FindAPIKeyByHash(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	usedAt := createdAt.Add(time.Hour)
+	query := regexp.QuoteMeta(`SELECT id, name, key_prefix, key_hash, scopes, created_at, last_used_at, revoked_at FROM api_keys WHERE key_hash = $1`)
+
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		want    model.APIKey
+		wantErr error
+	}{
+		{
+			name: "when key is not found",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("a-hash").WillReturnRows(sqlmock.NewRows(apiKeyRows))
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "when db failed",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("a-hash").WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when key is found",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("a-hash").WillReturnRows(sqlmock.NewRows(apiKeyRows).
+					AddRow("a-key-id", "ci", "usk_aB3dE6gH", "a-hash", "{create,read}", createdAt, usedAt, nil))
+			},
+			want: model.APIKey{ID: "a-key-id", Name: "ci", Prefix: "usk_aB3dE6gH", Hash: "a-hash", Scopes: []model.Scope{model.ScopeCreate, model.ScopeRead}, CreatedAt: createdAt, LastUsedAt: &usedAt},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewAPIKeyRepository(db)
+
+			got, err := r.FindAPIKeyByHash(context.Background(), "a-hash")
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAPIKeyRepository_ListAPIKeys(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	revokedAt := createdAt.Add(time.Hour)
+	query := regexp.QuoteMeta(`SELECT id, name, key_prefix, key_hash, scopes, created_at, last_used_at, revoked_at FROM api_keys ORDER BY created_at, id`)
+
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		want    []model.APIKey
+		wantErr error
+	}{
+		{
+			name: "when db failed",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when there are no keys",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WillReturnRows(sqlmock.NewRows(apiKeyRows))
+			},
+			want: []model.APIKey{},
+		},
+		{
+			name: "when keys are listed",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WillReturnRows(sqlmock.NewRows(apiKeyRows).
+					AddRow("a-key-id", "ci", "usk_aB3dE6gH", "a-hash", "{admin}", createdAt, nil, revokedAt).
+					AddRow("b-key-id", "web", "usk_xZya7gGh", "b-hash", "{read}", createdAt, nil, nil))
+			},
+			want: []model.APIKey{
+				{ID: "a-key-id", Name: "ci", Prefix: "usk_aB3dE6gH", Hash: "a-hash", Scopes: []model.Scope{model.ScopeAdmin}, CreatedAt: createdAt, RevokedAt: &revokedAt},
+				{ID: "b-key-id", Name: "web", Prefix: "usk_xZya7gGh", Hash: "b-hash", Scopes: []model.Scope{model.ScopeRead}, CreatedAt: createdAt},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewAPIKeyRepository(db)
+
+			got, err := r.ListAPIKeys(context.Background())
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAPIKeyRepository_RevokeAPIKey(t *testing.T) {
+	revokedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $2) WHERE id = $1`)
+
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "when db failed",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WithArgs("a-key-id", revokedAt).WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when key is not found",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WithArgs("a-key-id", revokedAt).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "when key is revoked",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WithArgs("a-key-id", revokedAt).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewAPIKeyRepository(db)
+
+			err = r.RevokeAPIKey(context.Background(), "a-key-id", revokedAt)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAPIKeyRepository_TouchAPIKey(t *testing.T) {
+	usedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`UPDATE api_keys SET last_used_at = $2 WHERE id = $1`)
+
+	db, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbMock.ExpectExec(query).WithArgs("a-key-id", usedAt).WillReturnError(errors.New("db error"))
+
+	r := NewAPIKeyRepository(db)
+
+	err = r.TouchAPIKey(context.Background(), "a-key-id", usedAt)
+
+	assert.Equal(t, ErrUnexpected, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/cache"
+	"github.com/ggoulart/url-shortener/internal/model"
+)
+
+type LinkStore interface {
+	FindEncodedKey(ctx context.Context, longURL url.URL, workspaceID string, ownerID string) (string, error)
+	FindEncodedKeys(ctx context.Context, longURLs []url.URL, workspaceID string, ownerID string) (map[string]string, error)
+	FindLink(ctx context.Context, encodedKey string) (model.Link, error)
+	SaveURL(ctx context.Context, link model.Link) error
+	SaveURLs(ctx context.Context, links []model.Link) (map[string]bool, error)
+	ListLinks(ctx context.Context, filter model.LinkFilter) ([]model.Link, error)
+	DeleteLink(ctx context.Context, encodedKey string) error
+	DisableLink(ctx context.Context, encodedKey string, disabledAt time.Time) error
+	UpdateLongURL(ctx context.Context, encodedKey string, longURL url.URL, changedAt time.Time, actor string) error
+	ListHistory(ctx context.Context, encodedKey string) ([]model.HistoryEntry, error)
+	DeleteExpired(ctx context.Context, before time.Time, limit int) ([]string, error)
+}
+
+// CachedShortenerRepository is a read-through cache in front of FindLink. Misses are
+// cached too, with a shorter TTL, so scans for unknown keys don't reach the store.
+type CachedShortenerRepository struct {
+	store  LinkStore
+	config CacheConfig
+	links  *cache.LRU[string, cachedLink]
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+type cachedLink struct {
+	link     model.Link
+	notFound bool
+}
+
+func NewCachedShortenerRepository(store LinkStore, config CacheConfig) *CachedShortenerRepository {
+	return &CachedShortenerRepository{store: store, config: config, links: cache.NewLRU[string, cachedLink](config.Size)}
+}
+
+func (r *CachedShortenerRepository) FindEncodedKey(ctx context.Context, longURL url.URL, workspaceID string, ownerID string) (string, error) {
+	return r.store.FindEncodedKey(ctx, longURL, workspaceID, ownerID)
+}
+
+func (r *CachedShortenerRepository) FindEncodedKeys(ctx context.Context, longURLs []url.URL, workspaceID string, ownerID string) (map[string]string, error) {
+	return r.store.FindEncodedKeys(ctx, longURLs, workspaceID, ownerID)
+}
+
+func (r *CachedShortenerRepository) FindLink(ctx context.Context, encodedKey string) (model.Link, error) {
+	if cached, ok := r.links.Get(encodedKey); ok {
+		r.hits.Add(1)
+		if cached.notFound {
+			return model.Link{}, ErrNotFound
+		}
+
+		return cached.link, nil
+	}
+
+	r.misses.Add(1)
+
+	link, err := r.store.FindLink(ctx, encodedKey)
+	if errors.Is(err, ErrNotFound) {
+		if r.config.NegativeTTL > 0 {
+			r.links.Set(encodedKey, cachedLink{notFound: true}, r.config.NegativeTTL)
+		}
+
+		return model.Link{}, err
+	}
+	if err != nil {
+		return model.Link{}, err
+	}
+
+	r.links.Set(encodedKey, cachedLink{link: link}, r.config.TTL)
+
+	return link, nil
+}
+
+func (r *CachedShortenerRepository) SaveURL(ctx context.Context, link model.Link) error {
+	err := r.store.SaveURL(ctx, link)
+
+	// drops a cached miss for the key, and any expired link the save replaced
+	r.Invalidate(link.EncodedKey)
+
+	return err
+}
+
+func (r *CachedShortenerRepository) SaveURLs(ctx context.Context, links []model.Link) (map[string]bool, error) {
+	inserted, err := r.store.SaveURLs(ctx, links)
+
+	for _, link := range links {
+		r.Invalidate(link.EncodedKey)
+	}
+
+	return inserted, err
+}
+
+func (r *CachedShortenerRepository) ListLinks(ctx context.Context, filter model.LinkFilter) ([]model.Link, error) {
+	return r.store.ListLinks(ctx, filter)
+}
+
+func (r *CachedShortenerRepository) DeleteLink(ctx context.Context, encodedKey string) error {
+	err := r.store.DeleteLink(ctx, encodedKey)
+	r.Invalidate(encodedKey)
+
+	return err
+}
+
+func (r *CachedShortenerRepository) DisableLink(ctx context.Context, encodedKey string, disabledAt time.Time) error {
+	err := r.store.DisableLink(ctx, encodedKey, disabledAt)
+	r.Invalidate(encodedKey)
+
+	return err
+}
+
+func (r *CachedShortenerRepository) UpdateLongURL(ctx context.Context, encodedKey string, longURL url.URL, changedAt time.Time, actor string) error {
+	err := r.store.UpdateLongURL(ctx, encodedKey, longURL, changedAt, actor)
+	r.Invalidate(encodedKey)
+
+	return err
+}
+
+func (r *CachedShortenerRepository) ListHistory(ctx context.Context, encodedKey string) ([]model.HistoryEntry, error) {
+	return r.store.ListHistory(ctx, encodedKey)
+}
+
+func (r *CachedShortenerRepository) DeleteExpired(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	deleted, err := r.store.DeleteExpired(ctx, before, limit)
+	for _, encodedKey := range deleted {
+		r.Invalidate(encodedKey)
+	}
+
+	return deleted, err
+}
+
+// Invalidate drops the cached entry for a key, call it whenever a link changes.
+func (r *CachedShortenerRepository) Invalidate(encodedKey string) {
+	r.links.Delete(encodedKey)
+}
+
+func (r *CachedShortenerRepository) Stats() model.CacheStats {
+	return model.CacheStats{Hits: r.hits.Load(), Misses: r.misses.Load(), Size: r.links.Len()}
+}
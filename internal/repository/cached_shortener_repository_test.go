@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var testCacheConfig = CacheConfig{Enabled: true, Size: 10, TTL: time.Minute, NegativeTTL: time.Second}
+
+func TestCachedShortenerRepository_FindLink(t *testing.T) {
+	link := model.Link{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}}
+
+	tests := []struct {
+		name       string
+		setup      func(*MockLinkStore)
+		calls      int
+		want       model.Link
+		wantErr    error
+		wantStats  model.CacheStats
+		storeCalls int
+	}{
+		{
+			name: "when store failed the error is not cached",
+			setup: func(s *MockLinkStore) {
+				s.On("FindLink", mock.Anything, "a-encoded-key").Return(model.Link{}, ErrUnexpected)
+			},
+			calls:      2,
+			wantErr:    ErrUnexpected,
+			wantStats:  model.CacheStats{Misses: 2},
+			storeCalls: 2,
+		},
+		{
+			name: "when link is not found the miss is cached",
+			setup: func(s *MockLinkStore) {
+				s.On("FindLink", mock.Anything, "a-encoded-key").Return(model.Link{}, ErrNotFound)
+			},
+			calls:      2,
+			wantErr:    ErrNotFound,
+			wantStats:  model.CacheStats{Hits: 1, Misses: 1, Size: 1},
+			storeCalls: 1,
+		},
+		{
+			name: "when link is found it is cached",
+			setup: func(s *MockLinkStore) {
+				s.On("FindLink", mock.Anything, "a-encoded-key").Return(link, nil)
+			},
+			calls:      3,
+			want:       link,
+			wantStats:  model.CacheStats{Hits: 2, Misses: 1, Size: 1},
+			storeCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &MockLinkStore{}
+			tt.setup(s)
+
+			r := NewCachedShortenerRepository(s, testCacheConfig)
+
+			var got model.Link
+			var err error
+			for i := 0; i < tt.calls; i++ {
+				got, err = r.FindLink(context.Background(), "a-encoded-key")
+			}
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantStats, r.Stats())
+			s.AssertNumberOfCalls(t, "FindLink", tt.storeCalls)
+		})
+	}
+}
+
+func TestCachedShortenerRepository_SaveURL(t *testing.T) {
+	link := model.Link{EncodedKey: "launch-2026", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}}
+
+	tests := []struct {
+		name    string
+		saveErr error
+	}{
+		{name: "when save failed", saveErr: errors.New("db error")},
+		{name: "when save succeeded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &MockLinkStore{}
+			s.On("FindLink", mock.Anything, "launch-2026").Return(model.Link{}, ErrNotFound).Once()
+			s.On("SaveURL", mock.Anything, link).Return(tt.saveErr)
+			s.On("FindLink", mock.Anything, "launch-2026").Return(link, nil).Once()
+
+			r := NewCachedShortenerRepository(s, testCacheConfig)
+
+			_, err := r.FindLink(context.Background(), "launch-2026")
+			assert.Equal(t, ErrNotFound, err)
+
+			err = r.SaveURL(context.Background(), link)
+			assert.Equal(t, tt.saveErr, err)
+
+			got, err := r.FindLink(context.Background(), "launch-2026")
+			assert.NoError(t, err)
+			assert.Equal(t, link, got)
+		})
+	}
+}
+
+func TestCachedShortenerRepository_SaveURLs(t *testing.T) {
+	link := model.Link{EncodedKey: "launch-2026", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}}
+
+	s := &MockLinkStore{}
+	s.On("FindLink", mock.Anything, "launch-2026").Return(model.Link{}, ErrNotFound).Once()
+	s.On("SaveURLs", mock.Anything, []model.Link{link}).Return(map[string]bool{"launch-2026": true}, nil)
+	s.On("FindLink", mock.Anything, "launch-2026").Return(link, nil).Once()
+
+	r := NewCachedShortenerRepository(s, testCacheConfig)
+
+	_, err := r.FindLink(context.Background(), "launch-2026")
+	assert.Equal(t, ErrNotFound, err)
+
+	inserted, err := r.SaveURLs(context.Background(), []model.Link{link})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"launch-2026": true}, inserted)
+
+	got, err := r.FindLink(context.Background(), "launch-2026")
+	assert.NoError(t, err)
+	assert.Equal(t, link, got)
+}
+
+func TestCachedShortenerRepository_DisableLink(t *testing.T) {
+	disabledAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	link := model.Link{EncodedKey: "launch-2026", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}}
+	disabled := model.Link{EncodedKey: "launch-2026", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}, DisabledAt: &disabledAt}
+
+	s := &MockLinkStore{}
+	s.On("FindLink", mock.Anything, "launch-2026").Return(link, nil).Once()
+	s.On("DisableLink", mock.Anything, "launch-2026", disabledAt).Return(nil)
+	s.On("FindLink", mock.Anything, "launch-2026").Return(disabled, nil).Once()
+
+	r := NewCachedShortenerRepository(s, testCacheConfig)
+
+	_, err := r.FindLink(context.Background(), "launch-2026")
+	assert.NoError(t, err)
+
+	err = r.DisableLink(context.Background(), "launch-2026", disabledAt)
+	assert.NoError(t, err)
+
+	got, err := r.FindLink(context.Background(), "launch-2026")
+	assert.NoError(t, err)
+	assert.Equal(t, disabled, got)
+}
+
+func TestCachedShortenerRepository_DeleteLink(t *testing.T) {
+	link := model.Link{EncodedKey: "launch-2026", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}}
+
+	s := &MockLinkStore{}
+	s.On("FindLink", mock.Anything, "launch-2026").Return(link, nil).Once()
+	s.On("DeleteLink", mock.Anything, "launch-2026").Return(nil)
+	s.On("FindLink", mock.Anything, "launch-2026").Return(model.Link{}, ErrNotFound).Once()
+
+	r := NewCachedShortenerRepository(s, testCacheConfig)
+
+	_, err := r.FindLink(context.Background(), "launch-2026")
+	assert.NoError(t, err)
+
+	err = r.DeleteLink(context.Background(), "launch-2026")
+	assert.NoError(t, err)
+
+	_, err = r.FindLink(context.Background(), "launch-2026")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestCachedShortenerRepository_DeleteExpired(t *testing.T) {
+	before := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	link := model.Link{EncodedKey: "launch-2026", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}, ExpiresAt: &before}
+
+	s := &MockLinkStore{}
+	s.On("FindLink", mock.Anything, "launch-2026").Return(link, nil).Once()
+	s.On("DeleteExpired", mock.Anything, before, 100).Return([]string{"launch-2026"}, nil)
+	s.On("FindLink", mock.Anything, "launch-2026").Return(model.Link{}, ErrNotFound).Once()
+
+	r := NewCachedShortenerRepository(s, testCacheConfig)
+
+	_, err := r.FindLink(context.Background(), "launch-2026")
+	assert.NoError(t, err)
+
+	deleted, err := r.DeleteExpired(context.Background(), before, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"launch-2026"}, deleted)
+
+	_, err = r.FindLink(context.Background(), "launch-2026")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestCachedShortenerRepository_UpdateLongURL(t *testing.T) {
+	changedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	link := model.Link{EncodedKey: "launch-2026", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}}
+	updated := model.Link{EncodedKey: "launch-2026", LongURL: url.URL{Scheme: "http", Host: "new-url"}}
+
+	s := &MockLinkStore{}
+	s.On("FindLink", mock.Anything, "launch-2026").Return(link, nil).Once()
+	s.On("UpdateLongURL", mock.Anything, "launch-2026", updated.LongURL, changedAt, "10.0.0.1").Return(nil)
+	s.On("FindLink", mock.Anything, "launch-2026").Return(updated, nil).Once()
+
+	r := NewCachedShortenerRepository(s, testCacheConfig)
+
+	_, err := r.FindLink(context.Background(), "launch-2026")
+	assert.NoError(t, err)
+
+	err = r.UpdateLongURL(context.Background(), "launch-2026", updated.LongURL, changedAt, "10.0.0.1")
+	assert.NoError(t, err)
+
+	got, err := r.FindLink(context.Background(), "launch-2026")
+	assert.NoError(t, err)
+	assert.Equal(t, updated, got)
+}
+
+func TestCachedShortenerRepository_FindEncodedKey(t *testing.T) {
+	s := &MockLinkStore{}
+	s.On("FindEncodedKey", mock.Anything, url.URL{Scheme: "http", Host: "a-long-url"}, "a-workspace-id", "a-user-id").Return("a-encoded-key", nil)
+
+	r := NewCachedShortenerRepository(s, testCacheConfig)
+
+	got, err := r.FindEncodedKey(context.Background(), url.URL{Scheme: "http", Host: "a-long-url"}, "a-workspace-id", "a-user-id")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "a-encoded-key", got)
+}
+
+type MockLinkStore struct {
+	mock.Mock
+}
+
+func (m *MockLinkStore) FindEncodedKey(ctx context.Context, longURL url.URL, workspaceID string, ownerID string) (string, error) {
+	args := m.Called(ctx, longURL, workspaceID, ownerID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockLinkStore) FindEncodedKeys(ctx context.Context, longURLs []url.URL, workspaceID string, ownerID string) (map[string]string, error) {
+	args := m.Called(ctx, longURLs, workspaceID, ownerID)
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
+func (m *MockLinkStore) FindLink(ctx context.Context, encodedKey string) (model.Link, error) {
+	args := m.Called(ctx, encodedKey)
+	return args.Get(0).(model.Link), args.Error(1)
+}
+
+func (m *MockLinkStore) SaveURL(ctx context.Context, link model.Link) error {
+	args := m.Called(ctx, link)
+	return args.Error(0)
+}
+
+func (m *MockLinkStore) SaveURLs(ctx context.Context, links []model.Link) (map[string]bool, error) {
+	args := m.Called(ctx, links)
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
+func (m *MockLinkStore) ListLinks(ctx context.Context, filter model.LinkFilter) ([]model.Link, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]model.Link), args.Error(1)
+}
+
+func (m *MockLinkStore) DeleteLink(ctx context.Context, encodedKey string) error {
+	args := m.Called(ctx, encodedKey)
+	return args.Error(0)
+}
+
+func (m *MockLinkStore) DisableLink(ctx context.Context, encodedKey string, disabledAt time.Time) error {
+	args := m.Called(ctx, encodedKey, disabledAt)
+	return args.Error(0)
+}
+
+func (m *MockLinkStore) UpdateLongURL(ctx context.Context, encodedKey string, longURL url.URL, changedAt time.Time, actor string) error {
+	args := m.Called(ctx, encodedKey, longURL, changedAt, actor)
+	return args.Error(0)
+}
+
+func (m *MockLinkStore) ListHistory(ctx context.Context, encodedKey string) ([]model.HistoryEntry, error) {
+	args := m.Called(ctx, encodedKey)
+	return args.Get(0).([]model.HistoryEntry), args.Error(1)
+}
+
+func (m *MockLinkStore) DeleteExpired(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Get(0).([]string), args.Error(1)
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+)
+
+type ClickRepository struct {
+	db DB
+}
+
+func NewClickRepository(db DB) *ClickRepository {
+	return &ClickRepository{db: db}
+}
+
+func (r *ClickRepository) SaveClicks(ctx context.Context, clicks []model.Click) error {
+	if len(clicks) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(clicks))
+	args := make([]any, 0, len(clicks)*5)
+	for i, click := range clicks {
+		n := i * 5
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5))
+		args = append(args, click.EncodedKey, click.ClickedAt, click.Referrer, click.UserAgent, click.ClientIP)
+	}
+
+	query := `INSERT INTO clicks (encoded_key, clicked_at, referrer, user_agent, client_ip) VALUES ` + strings.Join(placeholders, ", ")
+
+	_, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to insert clicks: %v", err))
+		return ErrUnexpected
+	}
+
+	return nil
+}
+
+func (r *ClickRepository) CountClicks(ctx context.Context, encodedKey string) (int64, error) {
+	query := `SELECT COUNT(*) FROM clicks WHERE encoded_key = $1`
+
+	var total int64
+	err := r.db.QueryRowContext(ctx, query, encodedKey).Scan(&total)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to count clicks: %v", err))
+		return 0, ErrUnexpected
+	}
+
+	return total, nil
+}
+
+func (r *ClickRepository) ClickSeries(ctx context.Context, encodedKey string, query model.StatsQuery) ([]model.ClickBucket, error) {
+	sqlQuery := `SELECT date_trunc($2, clicked_at) AS bucket, COUNT(*) FROM clicks
+		WHERE encoded_key = $1 AND clicked_at >= $3 AND clicked_at < $4
+		GROUP BY bucket ORDER BY bucket`
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, encodedKey, query.Interval, query.From, query.To)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to query click series: %v", err))
+		return nil, ErrUnexpected
+	}
+	defer rows.Close()
+
+	series := []model.ClickBucket{}
+	for rows.Next() {
+		var bucket model.ClickBucket
+		err = rows.Scan(&bucket.Start, &bucket.Clicks)
+		if err != nil {
+			slog.Error(fmt.Sprintf("failed to scan click series: %v", err))
+			return nil, ErrUnexpected
+		}
+
+		bucket.Start = bucket.Start.In(time.UTC)
+		series = append(series, bucket)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to read click series: %v", err))
+		return nil, ErrUnexpected
+	}
+
+	return series, nil
+}
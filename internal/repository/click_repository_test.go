@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClickRepository_SaveClicks(t *testing.T) {
+	clickedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clicks := []model.Click{
+		{EncodedKey: "a-encoded-key", ClickedAt: clickedAt, Referrer: "https://ref.com", UserAgent: "curl/8.0", ClientIP: "10.0.0.1"},
+		{EncodedKey: "b-encoded-key", ClickedAt: clickedAt, UserAgent: "Mozilla/5.0", ClientIP: "10.0.0.2"},
+	}
+	query := regexp.QuoteMeta(`INSERT INTO clicks (encoded_key, clicked_at, referrer, user_agent, client_ip) VALUES ($1, $2, $3, $4, $5), ($6, $7, $8, $9, $10)`)
+
+	tests := []struct {
+		name    string
+		clicks  []model.Click
+		setup   func(sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name:   "when there are no clicks",
+			clicks: nil,
+			setup:  func(s sqlmock.Sqlmock) {},
+		},
+		{
+			name:   "when failed to insert clicks",
+			clicks: clicks,
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name:   "when successfully insert clicks in a single statement",
+			clicks: clicks,
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).
+					WithArgs("a-encoded-key", clickedAt, "https://ref.com", "curl/8.0", "10.0.0.1", "b-encoded-key", clickedAt, "", "Mozilla/5.0", "10.0.0.2").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewClickRepository(db)
+
+			err = r.SaveClicks(context.Background(), tt.clicks)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestClickRepository_CountClicks(t *testing.T) {
+	query := regexp.QuoteMeta(`SELECT COUNT(*) FROM clicks WHERE encoded_key = $1`)
+
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		want    int64
+		wantErr error
+	}{
+		{
+			name: "when db failed",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("a-encoded-key").WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when successfully count clicks",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("a-encoded-key").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(42))
+			},
+			want: 42,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewClickRepository(db)
+
+			got, err := r.CountClicks(context.Background(), "a-encoded-key")
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestClickRepository_ClickSeries(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)
+	statsQuery := model.StatsQuery{Interval: "day", From: from, To: to}
+	query := regexp.QuoteMeta(`SELECT date_trunc($2, clicked_at) AS bucket, COUNT(*) FROM clicks
+		WHERE encoded_key = $1 AND clicked_at >= $3 AND clicked_at < $4
+		GROUP BY bucket ORDER BY bucket`)
+
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		want    []model.ClickBucket
+		wantErr error
+	}{
+		{
+			name: "when db failed",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("a-encoded-key", "day", from, to).WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when failed to scan a row",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).
+					WithArgs("a-encoded-key", "day", from, to).
+					WillReturnRows(sqlmock.NewRows([]string{"bucket", "count"}).AddRow("not-a-time", 1))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when failed while reading rows",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).
+					WithArgs("a-encoded-key", "day", from, to).
+					WillReturnRows(sqlmock.NewRows([]string{"bucket", "count"}).AddRow(from, 1).RowError(0, errors.New("db error")))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when there are no clicks",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).
+					WithArgs("a-encoded-key", "day", from, to).
+					WillReturnRows(sqlmock.NewRows([]string{"bucket", "count"}))
+			},
+			want: []model.ClickBucket{},
+		},
+		{
+			name: "when successfully query click series",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).
+					WithArgs("a-encoded-key", "day", from, to).
+					WillReturnRows(sqlmock.NewRows([]string{"bucket", "count"}).AddRow(from, 3).AddRow(from.Add(24*time.Hour), 5))
+			},
+			want: []model.ClickBucket{{Start: from, Clicks: 3}, {Start: from.Add(24 * time.Hour), Clicks: 5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewClickRepository(db)
+
+			got, err := r.ClickSeries(context.Background(), "a-encoded-key", statsQuery)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
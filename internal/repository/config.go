@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+type CacheConfig struct {
+	Enabled     bool          `mapstructure:"ENABLED"`
+	Size        int           `mapstructure:"SIZE"`
+	TTL         time.Duration `mapstructure:"TTL"`
+	NegativeTTL time.Duration `mapstructure:"NEGATIVE_TTL"`
+}
+
+func NewCacheConfig() (*CacheConfig, error) {
+	config := &CacheConfig{Enabled: true, Size: 10000, TTL: 10 * time.Minute, NegativeTTL: 30 * time.Second}
+	err := viper.UnmarshalKey("cache", config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cache config: %v", err)
+	}
+
+	if config.Enabled && (config.Size <= 0 || config.TTL <= 0 || config.NegativeTTL < 0) {
+		return nil, fmt.Errorf("invalid cache config: size %d, ttl %s, negative ttl %s", config.Size, config.TTL, config.NegativeTTL)
+	}
+
+	return config, nil
+}
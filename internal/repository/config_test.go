@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCacheConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    *CacheConfig
+		wantErr error
+	}{
+		{
+			name: "applies defaults for missing keys",
+			yaml: "db:\n  HOST: localhost\n",
+			want: &CacheConfig{Enabled: true, Size: 10000, TTL: 10 * time.Minute, NegativeTTL: 30 * time.Second},
+		},
+		{
+			name: "reads configured keys",
+			yaml: "cache:\n  ENABLED: false\n  SIZE: 10\n  TTL: 1m\n  NEGATIVE_TTL: 5s\n",
+			want: &CacheConfig{Enabled: false, Size: 10, TTL: time.Minute, NegativeTTL: 5 * time.Second},
+		},
+		{
+			name:    "when size is invalid",
+			yaml:    "cache:\n  SIZE: 0\n",
+			wantErr: errors.New("invalid cache config: size 0, ttl 10m0s, negative ttl 30s"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			viper.SetConfigType("yaml")
+			assert.NoError(t, viper.ReadConfig(strings.NewReader(tt.yaml)))
+
+			got, err := NewCacheConfig()
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
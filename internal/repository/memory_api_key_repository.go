@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+)
+
+type MemoryAPIKeyRepository struct {
+	mu       sync.RWMutex
+	keys     map[string]model.APIKey
+	idByHash map[string]string
+}
+
+func NewMemoryAPIKeyRepository() *MemoryAPIKeyRepository {
+	return &MemoryAPIKeyRepository{keys: map[string]model.APIKey{}, idByHash: map[string]string{}}
+}
+
+func (r *MemoryAPIKeyRepository) SaveAPIKey(ctx context.Context, key model.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key.LastUsedAt, key.RevokedAt = nil, nil
+	r.keys[key.ID] = key
+	r.idByHash[key.Hash] = key.ID
+
+	return nil
+}
+
+func (r *MemoryAPIKeyRepository) FindAPIKeyByHash(ctx context.Context, hash string) (model.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.idByHash[hash]
+	if !ok {
+		return model.APIKey{}, ErrNotFound
+	}
+
+	return r.keys[id], nil
+}
+
+func (r *MemoryAPIKeyRepository) ListAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]model.APIKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+
+	return keys, nil
+}
+
+func (r *MemoryAPIKeyRepository) RevokeAPIKey(ctx context.Context, id string, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	if key.RevokedAt == nil {
+		key.RevokedAt = &revokedAt
+		r.keys[id] = key
+	}
+
+	return nil
+}
+
+func (r *MemoryAPIKeyRepository) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.keys[id]
+	if ok {
+		key.LastUsedAt = &usedAt
+		r.keys[id] = key
+	}
+
+	return nil
+}
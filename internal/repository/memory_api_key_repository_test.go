@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryAPIKeyRepository(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	revokedAt := createdAt.Add(time.Hour)
+	usedAt := createdAt.Add(2 * time.Hour)
+	a := model.APIKey{ID: "a-key-id", Name: "ci", Hash: "a-hash", Scopes: []model.Scope{model.ScopeCreate}, CreatedAt: createdAt.Add(time.Minute)}
+	b := model.APIKey{ID: "b-key-id", Name: "web", Hash: "b-hash", Scopes: []model.Scope{model.ScopeRead}, CreatedAt: createdAt}
+
+	r := NewMemoryAPIKeyRepository()
+	assert.NoError(t, r.SaveAPIKey(context.Background(), a))
+	assert.NoError(t, r.SaveAPIKey(context.Background(), b))
+
+	_, err := r.FindAPIKeyByHash(context.Background(), "c-hash")
+	assert.Equal(t, ErrNotFound, err)
+	assert.Equal(t, ErrNotFound, r.RevokeAPIKey(context.Background(), "c-key-id", revokedAt))
+
+	assert.NoError(t, r.RevokeAPIKey(context.Background(), "a-key-id", revokedAt))
+	assert.NoError(t, r.RevokeAPIKey(context.Background(), "a-key-id", usedAt))
+	assert.NoError(t, r.TouchAPIKey(context.Background(), "b-key-id", usedAt))
+
+	got, err := r.FindAPIKeyByHash(context.Background(), "a-hash")
+	assert.NoError(t, err)
+	assert.Equal(t, &revokedAt, got.RevokedAt)
+
+	keys, err := r.ListAPIKeys(context.Background())
+	assert.NoError(t, err)
+	b.LastUsedAt = &usedAt
+	a.RevokedAt = &revokedAt
+	assert.Equal(t, []model.APIKey{b, a}, keys)
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+)
+
+type MemoryClickRepository struct {
+	mu     sync.RWMutex
+	clicks map[string][]model.Click
+}
+
+func NewMemoryClickRepository() *MemoryClickRepository {
+	return &MemoryClickRepository{clicks: map[string][]model.Click{}}
+}
+
+func (r *MemoryClickRepository) SaveClicks(ctx context.Context, clicks []model.Click) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, click := range clicks {
+		r.clicks[click.EncodedKey] = append(r.clicks[click.EncodedKey], click)
+	}
+
+	return nil
+}
+
+func (r *MemoryClickRepository) deleteClicks(encodedKey string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.clicks, encodedKey)
+}
+
+func (r *MemoryClickRepository) CountClicks(ctx context.Context, encodedKey string) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return int64(len(r.clicks[encodedKey])), nil
+}
+
+func (r *MemoryClickRepository) ClickSeries(ctx context.Context, encodedKey string, query model.StatsQuery) ([]model.ClickBucket, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := map[time.Time]int64{}
+	for _, click := range r.clicks[encodedKey] {
+		if click.ClickedAt.Before(query.From) || !click.ClickedAt.Before(query.To) {
+			continue
+		}
+
+		counts[truncate(click.ClickedAt, query.Interval)]++
+	}
+
+	series := make([]model.ClickBucket, 0, len(counts))
+	for start, clicks := range counts {
+		series = append(series, model.ClickBucket{Start: start, Clicks: clicks})
+	}
+	sort.Slice(series, func(i, j int) bool { return series[i].Start.Before(series[j].Start) })
+
+	return series, nil
+}
+
+// truncate mirrors postgres date_trunc for the intervals stats support, in UTC.
+func truncate(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	if interval == "hour" {
+		return t.Truncate(time.Hour)
+	}
+
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryClickRepository(t *testing.T) {
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	r := NewMemoryClickRepository()
+	err := r.SaveClicks(context.Background(), []model.Click{
+		{EncodedKey: "a-encoded-key", ClickedAt: day.Add(25 * time.Hour)},
+		{EncodedKey: "a-encoded-key", ClickedAt: day.Add(time.Hour)},
+		{EncodedKey: "a-encoded-key", ClickedAt: day.Add(90 * time.Minute)},
+		{EncodedKey: "a-encoded-key", ClickedAt: day.Add(72 * time.Hour)},
+		{EncodedKey: "b-encoded-key", ClickedAt: day},
+	})
+	assert.NoError(t, err)
+
+	total, err := r.CountClicks(context.Background(), "a-encoded-key")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), total)
+
+	tests := []struct {
+		name  string
+		query model.StatsQuery
+		want  []model.ClickBucket
+	}{
+		{
+			name:  "groups clicks by day inside the range",
+			query: model.StatsQuery{Interval: "day", From: day, To: day.Add(48 * time.Hour)},
+			want:  []model.ClickBucket{{Start: day, Clicks: 2}, {Start: day.Add(24 * time.Hour), Clicks: 1}},
+		},
+		{
+			name:  "groups clicks by hour inside the range",
+			query: model.StatsQuery{Interval: "hour", From: day, To: day.Add(24 * time.Hour)},
+			want:  []model.ClickBucket{{Start: day.Add(time.Hour), Clicks: 2}},
+		},
+		{
+			name:  "returns an empty series outside the range",
+			query: model.StatsQuery{Interval: "day", From: day.Add(-48 * time.Hour), To: day},
+			want:  []model.ClickBucket{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.ClickSeries(context.Background(), "a-encoded-key", tt.query)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+)
+
+type MemoryQuotaRepository struct {
+	mu      sync.Mutex
+	created map[string]map[time.Time]int
+}
+
+func NewMemoryQuotaRepository() *MemoryQuotaRepository {
+	return &MemoryQuotaRepository{created: map[string]map[time.Time]int{}}
+}
+
+func (r *MemoryQuotaRepository) ConsumeCreations(ctx context.Context, subject string, day time.Time, count int, quota model.CreationQuota) (model.CreationUsage, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	month := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	var usage model.CreationUsage
+	for d, created := range r.created[subject] {
+		if d.Before(month) || d.After(day) {
+			continue
+		}
+		usage.Monthly += created
+		if d.Equal(day) {
+			usage.Daily += created
+		}
+	}
+
+	if (quota.Daily > 0 && usage.Daily+count > quota.Daily) || (quota.Monthly > 0 && usage.Monthly+count > quota.Monthly) {
+		return usage, false, nil
+	}
+
+	if r.created[subject] == nil {
+		r.created[subject] = map[time.Time]int{}
+	}
+	r.created[subject][day] += count
+
+	return usage, true, nil
+}
+
+func (r *MemoryQuotaRepository) RefundCreations(ctx context.Context, subject string, day time.Time, count int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	created, ok := r.created[subject][day]
+	if !ok {
+		return nil
+	}
+	r.created[subject][day] = max(created-count, 0)
+
+	return nil
+}
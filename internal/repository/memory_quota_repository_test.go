@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryQuotaRepository(t *testing.T) {
+	quota := model.CreationQuota{Daily: 3, Monthly: 5}
+	first := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	second := first.AddDate(0, 0, 1)
+	nextMonth := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	r := NewMemoryQuotaRepository()
+
+	usage, consumed, err := r.ConsumeCreations(context.Background(), "key:a", first, 3, quota)
+	assert.NoError(t, err)
+	assert.True(t, consumed)
+	assert.Equal(t, model.CreationUsage{}, usage)
+
+	usage, consumed, err = r.ConsumeCreations(context.Background(), "key:a", first, 1, quota)
+	assert.NoError(t, err)
+	assert.False(t, consumed, "the daily quota is used up")
+	assert.Equal(t, model.CreationUsage{Daily: 3, Monthly: 3}, usage)
+
+	_, consumed, err = r.ConsumeCreations(context.Background(), "key:b", first, 1, quota)
+	assert.NoError(t, err)
+	assert.True(t, consumed, "every subject has a quota of its own")
+
+	usage, consumed, err = r.ConsumeCreations(context.Background(), "key:a", second, 3, quota)
+	assert.NoError(t, err)
+	assert.False(t, consumed, "the monthly quota would be exceeded")
+	assert.Equal(t, model.CreationUsage{Daily: 0, Monthly: 3}, usage)
+
+	usage, consumed, err = r.ConsumeCreations(context.Background(), "key:a", nextMonth, 3, quota)
+	assert.NoError(t, err)
+	assert.True(t, consumed)
+	assert.Equal(t, model.CreationUsage{}, usage)
+
+	_, consumed, err = r.ConsumeCreations(context.Background(), "key:a", nextMonth, 100, model.CreationQuota{})
+	assert.NoError(t, err)
+	assert.True(t, consumed, "a quota of zero is uncapped")
+}
+
+func TestMemoryQuotaRepository_RefundCreations(t *testing.T) {
+	quota := model.CreationQuota{Daily: 3}
+	day := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	r := NewMemoryQuotaRepository()
+
+	_, consumed, err := r.ConsumeCreations(context.Background(), "key:a", day, 3, quota)
+	assert.NoError(t, err)
+	assert.True(t, consumed)
+
+	assert.NoError(t, r.RefundCreations(context.Background(), "key:a", day, 2))
+
+	usage, consumed, err := r.ConsumeCreations(context.Background(), "key:a", day, 2, quota)
+	assert.NoError(t, err)
+	assert.True(t, consumed, "refunded links can be created again")
+	assert.Equal(t, model.CreationUsage{Daily: 1, Monthly: 1}, usage)
+
+	assert.NoError(t, r.RefundCreations(context.Background(), "key:a", day, 10))
+	assert.NoError(t, r.RefundCreations(context.Background(), "key:b", day, 1))
+
+	usage, _, err = r.ConsumeCreations(context.Background(), "key:a", day, 1, quota)
+	assert.NoError(t, err)
+	assert.Equal(t, model.CreationUsage{}, usage, "usage doesn't go below zero")
+}
@@ -0,0 +1,303 @@
+package repository
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+)
+
+// MemoryShortenerRepository keeps links in process memory with the same semantics as
+// ShortenerRepository: keys are unique and expired links hold theirs until DeleteExpired.
+// keysByURL only holds the link a dedup lookup may return, expiring links never enter
+// it and disabled and edited links leave it. Deleting a link deletes its clicks too, a key used again starts from none.
+type MemoryShortenerRepository struct {
+	mu        sync.RWMutex
+	links     map[string]model.Link
+	keysByURL map[dedupKey]string
+	history   map[string][]model.HistoryEntry
+	clicks    *MemoryClickRepository
+	now       func() time.Time
+}
+
+func NewMemoryShortenerRepository(clicks *MemoryClickRepository) *MemoryShortenerRepository {
+	return &MemoryShortenerRepository{
+		links:     map[string]model.Link{},
+		keysByURL: map[dedupKey]string{},
+		history:   map[string][]model.HistoryEntry{},
+		clicks:    clicks,
+		now:       time.Now,
+	}
+}
+
+func (r *MemoryShortenerRepository) Ping() error {
+	return nil
+}
+
+func (r *MemoryShortenerRepository) FindEncodedKey(ctx context.Context, longURL url.URL, workspaceID string, ownerID string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	encodedKey, ok := r.keysByURL[newDedupKey(longURL, workspaceID, ownerID)]
+	if !ok {
+		return "", nil
+	}
+
+	return encodedKey, nil
+}
+
+func (r *MemoryShortenerRepository) FindLink(ctx context.Context, encodedKey string) (model.Link, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	link, ok := r.links[encodedKey]
+	if !ok {
+		return model.Link{}, ErrNotFound
+	}
+
+	return link, nil
+}
+
+func (r *MemoryShortenerRepository) FindEncodedKeys(ctx context.Context, longURLs []url.URL, workspaceID string, ownerID string) (map[string]string, error) {
+	keys := map[string]string{}
+	for _, longURL := range longURLs {
+		encodedKey, _ := r.FindEncodedKey(ctx, longURL, workspaceID, ownerID)
+		if encodedKey != "" {
+			keys[longURL.String()] = encodedKey
+		}
+	}
+
+	return keys, nil
+}
+
+func (r *MemoryShortenerRepository) ListLinks(ctx context.Context, filter model.LinkFilter) ([]model.Link, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	links := []model.Link{}
+	for _, link := range r.links {
+		if matchesFilter(link, filter) {
+			links = append(links, link)
+		}
+	}
+
+	sort.Slice(links, func(i, j int) bool {
+		return listedBefore(links[i].CreatedAt, links[i].EncodedKey, links[j].CreatedAt, links[j].EncodedKey)
+	})
+
+	if len(links) > filter.Limit {
+		links = links[:filter.Limit]
+	}
+
+	return links, nil
+}
+
+func (r *MemoryShortenerRepository) SaveURL(ctx context.Context, link model.Link) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.save(link)
+}
+
+func (r *MemoryShortenerRepository) SaveURLs(ctx context.Context, links []model.Link) (map[string]bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	inserted := map[string]bool{}
+	for _, link := range links {
+		if r.save(link) == nil {
+			inserted[link.EncodedKey] = true
+		}
+	}
+
+	return inserted, nil
+}
+
+func (r *MemoryShortenerRepository) save(link model.Link) error {
+	if _, ok := r.links[link.EncodedKey]; ok {
+		return ErrKeyAlreadyExists
+	}
+
+	if link.CreatedAt.IsZero() {
+		link.CreatedAt = r.now()
+	}
+	r.links[link.EncodedKey] = link
+
+	key := linkDedupKey(link)
+	if _, ok := r.keysByURL[key]; !ok && link.ExpiresAt == nil {
+		r.keysByURL[key] = link.EncodedKey
+	}
+
+	return nil
+}
+
+func (r *MemoryShortenerRepository) UpdateLongURL(ctx context.Context, encodedKey string, longURL url.URL, changedAt time.Time, actor string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	link, ok := r.links[encodedKey]
+	if !ok {
+		return ErrNotFound
+	}
+
+	r.releaseURL(link)
+	r.history[encodedKey] = append(r.history[encodedKey], model.HistoryEntry{
+		Version:   len(r.history[encodedKey]) + 1,
+		LongURL:   link.LongURL,
+		ChangedAt: changedAt,
+		Actor:     actor,
+	})
+
+	link.LongURL = longURL
+	r.links[encodedKey] = link
+
+	return nil
+}
+
+func (r *MemoryShortenerRepository) ListHistory(ctx context.Context, encodedKey string) ([]model.HistoryEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := r.history[encodedKey]
+	history := make([]model.HistoryEntry, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		history = append(history, entries[i])
+	}
+
+	return history, nil
+}
+
+func (r *MemoryShortenerRepository) DeleteLink(ctx context.Context, encodedKey string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	link, ok := r.links[encodedKey]
+	if !ok {
+		return ErrNotFound
+	}
+
+	r.delete(link)
+
+	return nil
+}
+
+func (r *MemoryShortenerRepository) DisableLink(ctx context.Context, encodedKey string, disabledAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	link, ok := r.links[encodedKey]
+	if !ok {
+		return ErrNotFound
+	}
+
+	if link.DisabledAt == nil {
+		link.DisabledAt = &disabledAt
+		r.links[encodedKey] = link
+		r.releaseURL(link)
+	}
+
+	return nil
+}
+
+func (r *MemoryShortenerRepository) DeleteExpired(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var expired []model.Link
+	for _, link := range r.links {
+		if link.ExpiresAt != nil && !link.ExpiresAt.After(before) {
+			expired = append(expired, link)
+		}
+	}
+
+	sort.Slice(expired, func(i, j int) bool { return expired[i].ExpiresAt.Before(*expired[j].ExpiresAt) })
+	if len(expired) > limit {
+		expired = expired[:limit]
+	}
+
+	deleted := make([]string, 0, len(expired))
+	for _, link := range expired {
+		r.delete(link)
+		deleted = append(deleted, link.EncodedKey)
+	}
+
+	return deleted, nil
+}
+
+func (r *MemoryShortenerRepository) expired(link model.Link) bool {
+	return link.ExpiresAt != nil && !link.ExpiresAt.After(r.now())
+}
+
+func (r *MemoryShortenerRepository) delete(link model.Link) {
+	delete(r.links, link.EncodedKey)
+	delete(r.history, link.EncodedKey)
+	r.clicks.deleteClicks(link.EncodedKey)
+	r.releaseURL(link)
+}
+
+// releaseURL takes the link out of the dedup lookup if it is the one held there and
+// hands the url to another link that may still be returned for it.
+func (r *MemoryShortenerRepository) releaseURL(link model.Link) {
+	dedup := linkDedupKey(link)
+	if r.keysByURL[dedup] != link.EncodedKey {
+		return
+	}
+
+	delete(r.keysByURL, dedup)
+	for key, candidate := range r.links {
+		if key != link.EncodedKey && linkDedupKey(candidate) == dedup && candidate.DisabledAt == nil &&
+			len(r.history[key]) == 0 && candidate.ExpiresAt == nil {
+			r.keysByURL[dedup] = key
+			return
+		}
+	}
+}
+
+// dedupKey is what links are deduplicated by, links are only shared within a workspace
+// or, outside any workspace, between the links of the same owner.
+type dedupKey struct {
+	workspaceID string
+	ownerID     string
+	longURL     string
+}
+
+func newDedupKey(longURL url.URL, workspaceID string, ownerID string) dedupKey {
+	if workspaceID != "" {
+		ownerID = ""
+	}
+
+	return dedupKey{workspaceID: workspaceID, ownerID: ownerID, longURL: longURL.String()}
+}
+
+func linkDedupKey(link model.Link) dedupKey {
+	return newDedupKey(link.LongURL, link.WorkspaceID, link.OwnerID)
+}
+
+func matchesFilter(link model.Link, filter model.LinkFilter) bool {
+	switch {
+	case !filter.CreatedFrom.IsZero() && link.CreatedAt.Before(filter.CreatedFrom),
+		!filter.CreatedTo.IsZero() && !link.CreatedAt.Before(filter.CreatedTo),
+		filter.Domain != "" && strings.ToLower(link.LongURL.Hostname()) != filter.Domain,
+		!strings.HasPrefix(link.EncodedKey, filter.KeyPrefix),
+		!strings.Contains(strings.ToLower(link.LongURL.String()), strings.ToLower(filter.Search)),
+		filter.OwnerID != "" && link.OwnerID != filter.OwnerID,
+		filter.WorkspaceID != "" && link.WorkspaceID != filter.WorkspaceID,
+		filter.After != nil && !listedBefore(filter.After.CreatedAt, filter.After.EncodedKey, link.CreatedAt, link.EncodedKey):
+		return false
+	}
+
+	return true
+}
+
+// listedBefore orders links the way ShortenerRepository.ListLinks does, newest first.
+func listedBefore(createdAt time.Time, encodedKey string, otherCreatedAt time.Time, otherEncodedKey string) bool {
+	if !createdAt.Equal(otherCreatedAt) {
+		return createdAt.After(otherCreatedAt)
+	}
+
+	return encodedKey > otherEncodedKey
+}
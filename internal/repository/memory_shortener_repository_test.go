@@ -0,0 +1,409 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryShortenerRepository_FindEncodedKey(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+
+	tests := []struct {
+		name  string
+		links []model.Link
+		want  string
+	}{
+		{
+			name: "when long url is unknown",
+		},
+		{
+			name:  "when link has expired",
+			links: []model.Link{{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}, ExpiresAt: &past}},
+		},
+		{
+			name:  "when link has not expired yet it is still not handed out",
+			links: []model.Link{{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}, ExpiresAt: &future}},
+		},
+		{
+			name: "when a permanent link follows an expiring one",
+			links: []model.Link{
+				{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}, ExpiresAt: &future},
+				{EncodedKey: "b-encoded-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}},
+			},
+			want: "b-encoded-key",
+		},
+		{
+			name:  "when link never expires",
+			links: []model.Link{{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}}},
+			want:  "a-encoded-key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewMemoryShortenerRepository(NewMemoryClickRepository())
+			for _, link := range tt.links {
+				assert.NoError(t, r.SaveURL(context.Background(), link))
+			}
+			r.now = func() time.Time { return now }
+
+			got, err := r.FindEncodedKey(context.Background(), url.URL{Scheme: "http", Host: "a-long-url"}, "", "")
+
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMemoryShortenerRepository_FindEncodedKey_Workspace(t *testing.T) {
+	longURL := url.URL{Scheme: "http", Host: "a-long-url"}
+
+	r := NewMemoryShortenerRepository(NewMemoryClickRepository())
+	assert.NoError(t, r.SaveURL(context.Background(), model.Link{EncodedKey: "a-encoded-key", LongURL: longURL, WorkspaceID: "a-workspace-id"}))
+	assert.NoError(t, r.SaveURL(context.Background(), model.Link{EncodedKey: "b-encoded-key", LongURL: longURL, WorkspaceID: "b-workspace-id"}))
+
+	encodedKey, err := r.FindEncodedKey(context.Background(), longURL, "a-workspace-id", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "a-encoded-key", encodedKey)
+
+	encodedKey, err = r.FindEncodedKey(context.Background(), longURL, "b-workspace-id", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "b-encoded-key", encodedKey)
+
+	encodedKey, err = r.FindEncodedKey(context.Background(), longURL, "", "")
+	assert.NoError(t, err)
+	assert.Empty(t, encodedKey, "links outside any workspace are deduplicated among themselves")
+}
+
+func TestMemoryShortenerRepository_FindEncodedKey_Owner(t *testing.T) {
+	longURL := url.URL{Scheme: "http", Host: "a-long-url"}
+
+	r := NewMemoryShortenerRepository(NewMemoryClickRepository())
+	assert.NoError(t, r.SaveURL(context.Background(), model.Link{EncodedKey: "a-encoded-key", LongURL: longURL, OwnerID: "a-user-id"}))
+	assert.NoError(t, r.SaveURL(context.Background(), model.Link{EncodedKey: "b-encoded-key", LongURL: longURL}))
+	assert.NoError(t, r.SaveURL(context.Background(), model.Link{EncodedKey: "c-encoded-key", LongURL: longURL, OwnerID: "a-user-id", WorkspaceID: "a-workspace-id"}))
+
+	encodedKey, err := r.FindEncodedKey(context.Background(), longURL, "", "a-user-id")
+	assert.NoError(t, err)
+	assert.Equal(t, "a-encoded-key", encodedKey)
+
+	encodedKey, err = r.FindEncodedKey(context.Background(), longURL, "", "b-user-id")
+	assert.NoError(t, err)
+	assert.Empty(t, encodedKey, "a user is never handed the link of another user")
+
+	encodedKey, err = r.FindEncodedKey(context.Background(), longURL, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "b-encoded-key", encodedKey, "links without an owner are deduplicated among themselves")
+
+	encodedKey, err = r.FindEncodedKey(context.Background(), longURL, "a-workspace-id", "b-user-id")
+	assert.NoError(t, err)
+	assert.Equal(t, "c-encoded-key", encodedKey, "links of a workspace are shared whoever created them")
+}
+
+func TestMemoryShortenerRepository_FindLink(t *testing.T) {
+	link := model.Link{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}, CreatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name    string
+		links   []model.Link
+		want    model.Link
+		wantErr error
+	}{
+		{
+			name:    "when no encoded key is stored",
+			wantErr: ErrNotFound,
+		},
+		{
+			name:  "when successfully find link",
+			links: []model.Link{link},
+			want:  link,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewMemoryShortenerRepository(NewMemoryClickRepository())
+			for _, link := range tt.links {
+				assert.NoError(t, r.SaveURL(context.Background(), link))
+			}
+
+			got, err := r.FindLink(context.Background(), "a-encoded-key")
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestMemoryShortenerRepository_SaveURL(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		links   []model.Link
+		link    model.Link
+		wantErr error
+	}{
+		{
+			name:    "when encoded key already exists",
+			links:   []model.Link{{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "another-url"}}},
+			link:    model.Link{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}},
+			wantErr: ErrKeyAlreadyExists,
+		},
+		{
+			name:  "when long url already has a link",
+			links: []model.Link{{EncodedKey: "another-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}}},
+			link:  model.Link{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}},
+		},
+		{
+			name:    "when expired link still holds the encoded key",
+			links:   []model.Link{{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "another-url"}, ExpiresAt: &past}},
+			link:    model.Link{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}},
+			wantErr: ErrKeyAlreadyExists,
+		},
+		{
+			name:  "when expired link held the long url",
+			links: []model.Link{{EncodedKey: "another-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}, ExpiresAt: &past}},
+			link:  model.Link{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}},
+		},
+		{
+			name:  "when link with an expiry is saved for an already shortened url",
+			links: []model.Link{{EncodedKey: "another-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}}},
+			link:  model.Link{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}, ExpiresAt: &future},
+		},
+		{
+			name: "when successfully save url",
+			link: model.Link{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewMemoryShortenerRepository(NewMemoryClickRepository())
+			for _, link := range tt.links {
+				assert.NoError(t, r.SaveURL(context.Background(), link))
+			}
+			r.now = func() time.Time { return now }
+
+			err := r.SaveURL(context.Background(), tt.link)
+
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr == nil {
+				want := tt.link
+				want.CreatedAt = now
+
+				got, err := r.FindLink(context.Background(), tt.link.EncodedKey)
+				assert.NoError(t, err)
+				assert.Equal(t, want, got)
+			}
+		})
+	}
+}
+
+func TestMemoryShortenerRepository_SaveURLs(t *testing.T) {
+	r := NewMemoryShortenerRepository(NewMemoryClickRepository())
+	assert.NoError(t, r.SaveURL(context.Background(), model.Link{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "another-url"}}))
+
+	links := []model.Link{
+		{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}},
+		{EncodedKey: "b-encoded-key", LongURL: url.URL{Scheme: "http", Host: "b-long-url"}},
+	}
+
+	inserted, err := r.SaveURLs(context.Background(), links)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"b-encoded-key": true}, inserted)
+
+	keys, err := r.FindEncodedKeys(context.Background(), []url.URL{{Scheme: "http", Host: "b-long-url"}, {Scheme: "http", Host: "a-long-url"}}, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"http://b-long-url": "b-encoded-key"}, keys)
+}
+
+func TestMemoryShortenerRepository_ListLinks(t *testing.T) {
+	jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	links := []model.Link{
+		{EncodedKey: "launch-a", LongURL: url.URL{Scheme: "https", Host: "go.dev", Path: "/Blog"}, CreatedAt: jan},
+		{EncodedKey: "launch-b", LongURL: url.URL{Scheme: "https", Host: "pkg.go.dev"}, CreatedAt: feb, OwnerID: "a-user-id"},
+		{EncodedKey: "other", LongURL: url.URL{Scheme: "https", Host: "go.dev", Path: "/doc"}, CreatedAt: feb},
+	}
+
+	tests := []struct {
+		name   string
+		filter model.LinkFilter
+		want   []string
+	}{
+		{name: "when listing everything newest first", filter: model.LinkFilter{Limit: 10}, want: []string{"other", "launch-b", "launch-a"}},
+		{name: "when listing is limited", filter: model.LinkFilter{Limit: 1}, want: []string{"other"}},
+		{name: "when resuming after a link", filter: model.LinkFilter{Limit: 10, After: &model.LinkCursor{CreatedAt: feb, EncodedKey: "other"}}, want: []string{"launch-b", "launch-a"}},
+		{name: "when filtering by creation time", filter: model.LinkFilter{Limit: 10, CreatedFrom: jan, CreatedTo: feb}, want: []string{"launch-a"}},
+		{name: "when filtering by domain", filter: model.LinkFilter{Limit: 10, Domain: "go.dev"}, want: []string{"other", "launch-a"}},
+		{name: "when filtering by key prefix", filter: model.LinkFilter{Limit: 10, KeyPrefix: "launch-"}, want: []string{"launch-b", "launch-a"}},
+		{name: "when searching the long url", filter: model.LinkFilter{Limit: 10, Search: "blog"}, want: []string{"launch-a"}},
+		{name: "when filtering by owner", filter: model.LinkFilter{Limit: 10, OwnerID: "a-user-id"}, want: []string{"launch-b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewMemoryShortenerRepository(NewMemoryClickRepository())
+			for _, link := range links {
+				assert.NoError(t, r.SaveURL(context.Background(), link))
+			}
+
+			got, err := r.ListLinks(context.Background(), tt.filter)
+
+			assert.NoError(t, err)
+			keys := []string{}
+			for _, link := range got {
+				keys = append(keys, link.EncodedKey)
+			}
+			assert.Equal(t, tt.want, keys)
+		})
+	}
+}
+
+func TestMemoryShortenerRepository_SaveURL_Concurrent(t *testing.T) {
+	r := NewMemoryShortenerRepository(NewMemoryClickRepository())
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- r.SaveURL(context.Background(), model.Link{EncodedKey: "launch-2026", LongURL: url.URL{Scheme: "http", Host: fmt.Sprintf("url-%d", i)}})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	saved := 0
+	for err := range errs {
+		if err == nil {
+			saved++
+		} else {
+			assert.Equal(t, ErrKeyAlreadyExists, err)
+		}
+	}
+	assert.Equal(t, 1, saved)
+}
+
+func TestMemoryShortenerRepository_DeleteExpired(t *testing.T) {
+	before := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	older := before.Add(-2 * time.Hour)
+	old := before.Add(-time.Hour)
+	newer := before.Add(time.Hour)
+
+	r := NewMemoryShortenerRepository(NewMemoryClickRepository())
+	r.now = func() time.Time { return older.Add(-time.Hour) }
+	for key, expiresAt := range map[string]*time.Time{"older": &older, "old": &old, "newer": &newer, "never": nil} {
+		assert.NoError(t, r.SaveURL(context.Background(), model.Link{EncodedKey: key, LongURL: url.URL{Scheme: "http", Host: key}, ExpiresAt: expiresAt}))
+	}
+
+	deleted, err := r.DeleteExpired(context.Background(), before, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"older"}, deleted)
+	_, err = r.FindLink(context.Background(), "older")
+	assert.Equal(t, ErrNotFound, err)
+
+	deleted, err = r.DeleteExpired(context.Background(), before, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"old"}, deleted)
+	_, err = r.FindLink(context.Background(), "old")
+	assert.Equal(t, ErrNotFound, err)
+
+	for _, key := range []string{"newer", "never"} {
+		_, err = r.FindLink(context.Background(), key)
+		assert.NoError(t, err)
+	}
+}
+
+func TestMemoryShortenerRepository_DisableLink(t *testing.T) {
+	disabledAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	longURL := url.URL{Scheme: "http", Host: "a-long-url"}
+
+	r := NewMemoryShortenerRepository(NewMemoryClickRepository())
+	assert.NoError(t, r.SaveURL(context.Background(), model.Link{EncodedKey: "a-encoded-key", LongURL: longURL}))
+
+	assert.Equal(t, ErrNotFound, r.DisableLink(context.Background(), "missing-key", disabledAt))
+	assert.NoError(t, r.DisableLink(context.Background(), "a-encoded-key", disabledAt))
+	assert.NoError(t, r.DisableLink(context.Background(), "a-encoded-key", disabledAt.Add(time.Hour)))
+
+	got, err := r.FindLink(context.Background(), "a-encoded-key")
+	assert.NoError(t, err)
+	assert.Equal(t, &disabledAt, got.DisabledAt)
+
+	encodedKey, err := r.FindEncodedKey(context.Background(), longURL, "", "")
+	assert.NoError(t, err)
+	assert.Empty(t, encodedKey)
+
+	// the disabled link released its long url, deleting it must not release the new link's
+	assert.NoError(t, r.SaveURL(context.Background(), model.Link{EncodedKey: "another-key", LongURL: longURL}))
+	assert.NoError(t, r.DeleteLink(context.Background(), "a-encoded-key"))
+
+	encodedKey, err = r.FindEncodedKey(context.Background(), longURL, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "another-key", encodedKey)
+}
+
+func TestMemoryShortenerRepository_DeleteLink(t *testing.T) {
+	longURL := url.URL{Scheme: "http", Host: "a-long-url"}
+
+	clicks := NewMemoryClickRepository()
+	r := NewMemoryShortenerRepository(clicks)
+	assert.NoError(t, r.SaveURL(context.Background(), model.Link{EncodedKey: "a-encoded-key", LongURL: longURL}))
+	assert.NoError(t, clicks.SaveClicks(context.Background(), []model.Click{{EncodedKey: "a-encoded-key", ClickedAt: time.Now()}}))
+
+	assert.Equal(t, ErrNotFound, r.DeleteLink(context.Background(), "missing-key"))
+	assert.NoError(t, r.DeleteLink(context.Background(), "a-encoded-key"))
+
+	_, err := r.FindLink(context.Background(), "a-encoded-key")
+	assert.Equal(t, ErrNotFound, err)
+	assert.NoError(t, r.SaveURL(context.Background(), model.Link{EncodedKey: "a-encoded-key", LongURL: longURL}))
+
+	total, err := clicks.CountClicks(context.Background(), "a-encoded-key")
+	assert.NoError(t, err)
+	assert.Zero(t, total, "a key used again must not inherit the clicks of the deleted link")
+}
+
+func TestMemoryShortenerRepository_UpdateLongURL(t *testing.T) {
+	changedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := url.URL{Scheme: "http", Host: "first-url"}
+	second := url.URL{Scheme: "http", Host: "second-url"}
+	third := url.URL{Scheme: "http", Host: "third-url"}
+
+	r := NewMemoryShortenerRepository(NewMemoryClickRepository())
+	assert.NoError(t, r.SaveURL(context.Background(), model.Link{EncodedKey: "a-encoded-key", LongURL: first}))
+	assert.NoError(t, r.SaveURL(context.Background(), model.Link{EncodedKey: "another-key", LongURL: first}))
+
+	assert.Equal(t, ErrNotFound, r.UpdateLongURL(context.Background(), "missing-key", second, changedAt, "10.0.0.1"))
+	assert.NoError(t, r.UpdateLongURL(context.Background(), "a-encoded-key", second, changedAt, "10.0.0.1"))
+	assert.NoError(t, r.UpdateLongURL(context.Background(), "a-encoded-key", third, changedAt.Add(time.Hour), "10.0.0.2"))
+
+	got, err := r.FindLink(context.Background(), "a-encoded-key")
+	assert.NoError(t, err)
+	assert.Equal(t, third, got.LongURL)
+
+	history, err := r.ListHistory(context.Background(), "a-encoded-key")
+	assert.NoError(t, err)
+	assert.Equal(t, []model.HistoryEntry{
+		{Version: 2, LongURL: second, ChangedAt: changedAt.Add(time.Hour), Actor: "10.0.0.2"},
+		{Version: 1, LongURL: first, ChangedAt: changedAt, Actor: "10.0.0.1"},
+	}, history)
+
+	// the edited link left the dedup lookup, the other link for the url took its place
+	encodedKey, err := r.FindEncodedKey(context.Background(), first, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "another-key", encodedKey)
+
+	encodedKey, err = r.FindEncodedKey(context.Background(), third, "", "")
+	assert.NoError(t, err)
+	assert.Empty(t, encodedKey)
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+)
+
+type MemoryUserRepository struct {
+	mu       sync.RWMutex
+	users    map[string]model.User
+	sessions map[string]model.Session
+}
+
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{users: map[string]model.User{}, sessions: map[string]model.Session{}}
+}
+
+func (r *MemoryUserRepository) SaveUser(ctx context.Context, user model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.Email]; ok {
+		return ErrEmailAlreadyExists
+	}
+	r.users[user.Email] = user
+
+	return nil
+}
+
+func (r *MemoryUserRepository) FindUserByEmail(ctx context.Context, email string) (model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[email]
+	if !ok {
+		return model.User{}, ErrNotFound
+	}
+
+	return user, nil
+}
+
+func (r *MemoryUserRepository) SaveSession(ctx context.Context, session model.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session.RevokedAt = nil
+	r.sessions[session.ID] = session
+
+	return nil
+}
+
+func (r *MemoryUserRepository) FindSessionByAccessHash(ctx context.Context, hash string) (model.Session, error) {
+	return r.findSession(func(session model.Session) bool { return session.AccessHash == hash })
+}
+
+func (r *MemoryUserRepository) FindSessionByRefreshHash(ctx context.Context, hash string) (model.Session, error) {
+	return r.findSession(func(session model.Session) bool { return session.RefreshHash == hash })
+}
+
+func (r *MemoryUserRepository) RotateSession(ctx context.Context, session model.Session, previousRefreshHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.sessions[session.ID]
+	if !ok || existing.RefreshHash != previousRefreshHash || existing.RevokedAt != nil {
+		return ErrNotFound
+	}
+
+	existing.AccessHash, existing.AccessExpiresAt = session.AccessHash, session.AccessExpiresAt
+	existing.RefreshHash, existing.RefreshExpiresAt = session.RefreshHash, session.RefreshExpiresAt
+	r.sessions[session.ID] = existing
+
+	return nil
+}
+
+func (r *MemoryUserRepository) RevokeSession(ctx context.Context, id string, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	if session.RevokedAt == nil {
+		session.RevokedAt = &revokedAt
+		r.sessions[id] = session
+	}
+
+	return nil
+}
+
+// findSession scans every session, the memory driver is meant for a handful of users.
+func (r *MemoryUserRepository) findSession(matches func(model.Session) bool) (model.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, session := range r.sessions {
+		if matches(session) {
+			return session, nil
+		}
+	}
+
+	return model.Session{}, ErrNotFound
+}
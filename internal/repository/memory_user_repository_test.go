@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryUserRepository(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	revokedAt := createdAt.Add(time.Hour)
+	user := model.User{ID: "a-user-id", Email: "ada@example.com", PasswordHash: "a-password-hash", CreatedAt: createdAt}
+	session := model.Session{ID: "a-session-id", UserID: "a-user-id", AccessHash: "an-access-hash", RefreshHash: "a-refresh-hash", CreatedAt: createdAt}
+	rotated := model.Session{ID: "a-session-id", AccessHash: "another-access-hash", RefreshHash: "another-refresh-hash"}
+
+	r := NewMemoryUserRepository()
+	assert.NoError(t, r.SaveUser(context.Background(), user))
+	assert.Equal(t, ErrEmailAlreadyExists, r.SaveUser(context.Background(), model.User{ID: "b-user-id", Email: "ada@example.com"}))
+
+	got, err := r.FindUserByEmail(context.Background(), "ada@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, user, got)
+	_, err = r.FindUserByEmail(context.Background(), "bob@example.com")
+	assert.Equal(t, ErrNotFound, err)
+
+	assert.NoError(t, r.SaveSession(context.Background(), session))
+	assert.Equal(t, ErrNotFound, r.RotateSession(context.Background(), rotated, "a-stale-refresh-hash"))
+	assert.NoError(t, r.RotateSession(context.Background(), rotated, "a-refresh-hash"))
+
+	_, err = r.FindSessionByAccessHash(context.Background(), "an-access-hash")
+	assert.Equal(t, ErrNotFound, err)
+	found, err := r.FindSessionByRefreshHash(context.Background(), "another-refresh-hash")
+	assert.NoError(t, err)
+	assert.Equal(t, "a-user-id", found.UserID)
+
+	assert.Equal(t, ErrNotFound, r.RevokeSession(context.Background(), "b-session-id", revokedAt))
+	assert.NoError(t, r.RevokeSession(context.Background(), "a-session-id", revokedAt))
+	assert.NoError(t, r.RevokeSession(context.Background(), "a-session-id", revokedAt.Add(time.Hour)))
+	assert.Equal(t, ErrNotFound, r.RotateSession(context.Background(), model.Session{ID: "a-session-id"}, "another-refresh-hash"))
+
+	found, err = r.FindSessionByAccessHash(context.Background(), "another-access-hash")
+	assert.NoError(t, err)
+	assert.Equal(t, &revokedAt, found.RevokedAt)
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+)
+
+type MemoryWorkspaceRepository struct {
+	mu          sync.RWMutex
+	workspaces  map[string]model.Workspace
+	members     map[string]map[string]model.Member
+	invitations map[string]model.Invitation
+	accepted    map[string]bool
+}
+
+func NewMemoryWorkspaceRepository() *MemoryWorkspaceRepository {
+	return &MemoryWorkspaceRepository{
+		workspaces:  map[string]model.Workspace{},
+		members:     map[string]map[string]model.Member{},
+		invitations: map[string]model.Invitation{},
+		accepted:    map[string]bool{},
+	}
+}
+
+func (r *MemoryWorkspaceRepository) CreateWorkspace(ctx context.Context, workspace model.Workspace, ownerID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.workspaces[workspace.ID] = workspace
+	r.members[workspace.ID] = map[string]model.Member{
+		ownerID: {WorkspaceID: workspace.ID, UserID: ownerID, Role: model.RoleOwner, JoinedAt: workspace.CreatedAt},
+	}
+
+	return nil
+}
+
+func (r *MemoryWorkspaceRepository) ListWorkspaces(ctx context.Context, userID string) ([]model.UserWorkspace, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	workspaces := []model.UserWorkspace{}
+	for id, members := range r.members {
+		if member, ok := members[userID]; ok {
+			workspaces = append(workspaces, model.UserWorkspace{Workspace: r.workspaces[id], Role: member.Role})
+		}
+	}
+
+	sort.Slice(workspaces, func(i, j int) bool {
+		if !workspaces[i].CreatedAt.Equal(workspaces[j].CreatedAt) {
+			return workspaces[i].CreatedAt.Before(workspaces[j].CreatedAt)
+		}
+		return workspaces[i].ID < workspaces[j].ID
+	})
+
+	return workspaces, nil
+}
+
+func (r *MemoryWorkspaceRepository) FindMember(ctx context.Context, workspaceID string, userID string) (model.Member, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	member, ok := r.members[workspaceID][userID]
+	if !ok {
+		return model.Member{}, ErrNotFound
+	}
+
+	return member, nil
+}
+
+func (r *MemoryWorkspaceRepository) ListMembers(ctx context.Context, workspaceID string) ([]model.Member, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	members := []model.Member{}
+	for _, member := range r.members[workspaceID] {
+		members = append(members, member)
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		if !members[i].JoinedAt.Equal(members[j].JoinedAt) {
+			return members[i].JoinedAt.Before(members[j].JoinedAt)
+		}
+		return members[i].UserID < members[j].UserID
+	})
+
+	return members, nil
+}
+
+func (r *MemoryWorkspaceRepository) UpdateMemberRole(ctx context.Context, workspaceID string, userID string, role model.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	member, ok := r.members[workspaceID][userID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	member.Role = role
+	r.members[workspaceID][userID] = member
+
+	return nil
+}
+
+func (r *MemoryWorkspaceRepository) DeleteMember(ctx context.Context, workspaceID string, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.members[workspaceID][userID]; !ok {
+		return ErrNotFound
+	}
+
+	delete(r.members[workspaceID], userID)
+
+	return nil
+}
+
+func (r *MemoryWorkspaceRepository) SaveInvitation(ctx context.Context, invitation model.Invitation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.invitations[invitation.TokenHash] = invitation
+
+	return nil
+}
+
+func (r *MemoryWorkspaceRepository) AcceptInvitation(ctx context.Context, tokenHash string, userID string, acceptedAt time.Time) (model.Member, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invitation, ok := r.invitations[tokenHash]
+	if !ok || r.accepted[tokenHash] || !invitation.ExpiresAt.After(acceptedAt) {
+		return model.Member{}, ErrNotFound
+	}
+
+	members, ok := r.members[invitation.WorkspaceID]
+	if !ok {
+		return model.Member{}, ErrNotFound
+	}
+	if _, ok := members[userID]; ok {
+		return model.Member{}, ErrMemberAlreadyExists
+	}
+
+	member := model.Member{WorkspaceID: invitation.WorkspaceID, UserID: userID, Role: invitation.Role, JoinedAt: acceptedAt}
+	members[userID] = member
+	r.accepted[tokenHash] = true
+
+	return member, nil
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryWorkspaceRepository(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	acceptedAt := createdAt.Add(time.Hour)
+	workspace := model.Workspace{ID: "a-workspace-id", Name: "growth", CreatedAt: createdAt}
+	owner := model.Member{WorkspaceID: "a-workspace-id", UserID: "a-user-id", Role: model.RoleOwner, JoinedAt: createdAt}
+	invitation := model.Invitation{ID: "an-invitation-id", WorkspaceID: "a-workspace-id", Role: model.RoleEditor, TokenHash: "a-token-hash", ExpiresAt: createdAt.Add(24 * time.Hour)}
+	expired := model.Invitation{ID: "b-invitation-id", WorkspaceID: "a-workspace-id", Role: model.RoleEditor, TokenHash: "b-token-hash", ExpiresAt: createdAt}
+
+	r := NewMemoryWorkspaceRepository()
+	assert.NoError(t, r.CreateWorkspace(context.Background(), workspace, "a-user-id"))
+
+	got, err := r.FindMember(context.Background(), "a-workspace-id", "a-user-id")
+	assert.NoError(t, err)
+	assert.Equal(t, owner, got)
+	_, err = r.FindMember(context.Background(), "a-workspace-id", "b-user-id")
+	assert.Equal(t, ErrNotFound, err)
+
+	assert.NoError(t, r.SaveInvitation(context.Background(), invitation))
+	assert.NoError(t, r.SaveInvitation(context.Background(), expired))
+	_, err = r.AcceptInvitation(context.Background(), "b-token-hash", "b-user-id", acceptedAt)
+	assert.Equal(t, ErrNotFound, err)
+	_, err = r.AcceptInvitation(context.Background(), "a-token-hash", "a-user-id", acceptedAt)
+	assert.Equal(t, ErrMemberAlreadyExists, err)
+
+	editor, err := r.AcceptInvitation(context.Background(), "a-token-hash", "b-user-id", acceptedAt)
+	assert.NoError(t, err)
+	assert.Equal(t, model.Member{WorkspaceID: "a-workspace-id", UserID: "b-user-id", Role: model.RoleEditor, JoinedAt: acceptedAt}, editor)
+	_, err = r.AcceptInvitation(context.Background(), "a-token-hash", "c-user-id", acceptedAt)
+	assert.Equal(t, ErrNotFound, err, "an invitation is only accepted once")
+
+	assert.NoError(t, r.UpdateMemberRole(context.Background(), "a-workspace-id", "b-user-id", model.RoleViewer))
+	assert.Equal(t, ErrNotFound, r.UpdateMemberRole(context.Background(), "a-workspace-id", "c-user-id", model.RoleViewer))
+
+	members, err := r.ListMembers(context.Background(), "a-workspace-id")
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Member{owner, {WorkspaceID: "a-workspace-id", UserID: "b-user-id", Role: model.RoleViewer, JoinedAt: acceptedAt}}, members)
+
+	workspaces, err := r.ListWorkspaces(context.Background(), "b-user-id")
+	assert.NoError(t, err)
+	assert.Equal(t, []model.UserWorkspace{{Workspace: workspace, Role: model.RoleViewer}}, workspaces)
+
+	assert.NoError(t, r.DeleteMember(context.Background(), "a-workspace-id", "b-user-id"))
+	assert.Equal(t, ErrNotFound, r.DeleteMember(context.Background(), "a-workspace-id", "b-user-id"))
+
+	workspaces, err = r.ListWorkspaces(context.Background(), "b-user-id")
+	assert.NoError(t, err)
+	assert.Empty(t, workspaces)
+}
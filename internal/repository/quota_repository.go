@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+)
+
+type QuotaRepository struct {
+	db DB
+}
+
+func NewQuotaRepository(db DB) *QuotaRepository {
+	return &QuotaRepository{db: db}
+}
+
+// ConsumeCreations counts count new links of subject on day, unless that would go
+// over quota. It tells whether they were counted, along with the usage before them.
+// The conflict clause holds the daily quota against concurrent requests, the
+// monthly one is checked against the usage as the statement found it.
+func (r *QuotaRepository) ConsumeCreations(ctx context.Context, subject string, day time.Time, count int, quota model.CreationQuota) (model.CreationUsage, bool, error) {
+	query := `WITH usage AS (
+		SELECT COALESCE(SUM(created) FILTER (WHERE day = $2::date), 0) AS daily, COALESCE(SUM(created), 0) AS monthly
+		FROM creation_usage WHERE subject = $1 AND day >= date_trunc('month', $2::date)::date AND day <= $2::date
+	), consumed AS (
+		INSERT INTO creation_usage (subject, day, created)
+		SELECT $1, $2::date, $3 FROM usage WHERE ($4 = 0 OR daily + $3 <= $4) AND ($5 = 0 OR monthly + $3 <= $5)
+		ON CONFLICT (subject, day) DO UPDATE SET created = creation_usage.created + EXCLUDED.created
+		WHERE $4 = 0 OR creation_usage.created + EXCLUDED.created <= $4
+		RETURNING created
+	)
+	SELECT daily, monthly, EXISTS (SELECT 1 FROM consumed) FROM usage`
+
+	var usage model.CreationUsage
+	var consumed bool
+	err := r.db.QueryRowContext(ctx, query, subject, day, count, quota.Daily, quota.Monthly).Scan(&usage.Daily, &usage.Monthly, &consumed)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to consume creation quota: %v", err))
+		return model.CreationUsage{}, false, ErrUnexpected
+	}
+
+	return usage, consumed, nil
+}
+
+// RefundCreations takes back count links of subject on day that ConsumeCreations
+// counted but were never created.
+func (r *QuotaRepository) RefundCreations(ctx context.Context, subject string, day time.Time, count int) error {
+	query := `UPDATE creation_usage SET created = GREATEST(created - $3, 0) WHERE subject = $1 AND day = $2::date`
+
+	_, err := r.db.ExecContext(ctx, query, subject, day, count)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to refund creation quota: %v", err))
+		return ErrUnexpected
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotaRepository_ConsumeCreations(t *testing.T) {
+	day := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	quota := model.CreationQuota{Daily: 100, Monthly: 1000}
+	query := `WITH usage AS \(.+FROM creation_usage WHERE subject = \$1.+INSERT INTO creation_usage \(subject, day, created\).+ON CONFLICT \(subject, day\) DO UPDATE.+SELECT daily, monthly, EXISTS \(SELECT 1 FROM consumed\) FROM usage`
+
+	tests := []struct {
+		name         string
+		setup        func(sqlmock.Sqlmock)
+		want         model.CreationUsage
+		wantConsumed bool
+		wantErr      error
+	}{
+		{
+			name: "when failed to consume the quota",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when the quota is used up",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).
+					WithArgs("key:a-key-id", day, 2, 100, 1000).
+					WillReturnRows(sqlmock.NewRows([]string{"daily", "monthly", "exists"}).AddRow(99, 420, false))
+			},
+			want: model.CreationUsage{Daily: 99, Monthly: 420},
+		},
+		{
+			name: "when the links are counted",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).
+					WithArgs("key:a-key-id", day, 2, 100, 1000).
+					WillReturnRows(sqlmock.NewRows([]string{"daily", "monthly", "exists"}).AddRow(12, 420, true))
+			},
+			want:         model.CreationUsage{Daily: 12, Monthly: 420},
+			wantConsumed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewQuotaRepository(db)
+
+			got, consumed, err := r.ConsumeCreations(context.Background(), "key:a-key-id", day, 2, quota)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantConsumed, consumed)
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestQuotaRepository_RefundCreations(t *testing.T) {
+	day := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	query := `UPDATE creation_usage SET created = GREATEST\(created - \$3, 0\) WHERE subject = \$1 AND day = \$2::date`
+
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "when failed to refund the quota",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when the links are refunded",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WithArgs("key:a-key-id", day, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewQuotaRepository(db)
+
+			err = r.RefundCreations(context.Background(), "key:a-key-id", day, 2)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
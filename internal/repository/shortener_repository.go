@@ -6,13 +6,33 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/lib/pq"
 )
 
 var ErrUnexpected = errors.New("unknown database error")
-var ErrNotFound = errors.New("record not found")
+var ErrNotFound = apperror.New("not_found", http.StatusNotFound, "record not found")
+var ErrKeyAlreadyExists = errors.New("encoded key already exists")
+
+const (
+	uniqueViolationCode = "23505"
+	urlsKeyConstraint   = "urls_pkey"
+)
+
+// urlHostExpr extracts the host of long_url, it must stay identical to the expression
+// of idx_urls_long_url_host or the domain filter can't use the index.
+const urlHostExpr = `substring(long_url from '^[^:]+://(?:[^/?#@]*@)?([^/?#:]+)')`
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
 
 type DB interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
 	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
 	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 }
@@ -25,11 +45,20 @@ func NewShortenerRepository(db DB) *ShortenerRepository {
 	return &ShortenerRepository{db: db}
 }
 
-func (r *ShortenerRepository) FindEncodedKey(ctx context.Context, longURL url.URL) (string, error) {
-	query := `SELECT encoded_key FROM urls WHERE long_url = $1`
+// FindEncodedKey only matches permanent links whose destination was never edited, a
+// link that was repointed no longer stands for the url it was created with, and one
+// that expires must not be handed to a caller asking for a permanent link. Links are only
+// shared within workspaceID, an empty one matches the links outside any workspace.
+// Those are only shared between links of ownerID, so nobody is handed a link they
+// can't manage, an empty one matches the links without an owner.
+func (r *ShortenerRepository) FindEncodedKey(ctx context.Context, longURL url.URL, workspaceID string, ownerID string) (string, error) {
+	query := `SELECT encoded_key FROM urls
+		WHERE long_url = $1 AND workspace_id IS NOT DISTINCT FROM $2 AND (workspace_id IS NOT NULL OR owner_id IS NOT DISTINCT FROM $3)
+		AND version = 1 AND disabled_at IS NULL AND expires_at IS NULL
+		ORDER BY created_at LIMIT 1`
 
 	var encodedKey string
-	err := r.db.QueryRowContext(ctx, query, longURL.String()).Scan(&encodedKey)
+	err := r.db.QueryRowContext(ctx, query, longURL.String(), nullString(workspaceID), nullString(ownerID)).Scan(&encodedKey)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return "", nil
@@ -42,37 +71,388 @@ func (r *ShortenerRepository) FindEncodedKey(ctx context.Context, longURL url.UR
 	return encodedKey, nil
 }
 
-func (r *ShortenerRepository) FindLongURL(ctx context.Context, encodedKey string) (url.URL, error) {
-	query := `SELECT long_url FROM urls WHERE encoded_key = $1`
+// FindEncodedKeys is FindEncodedKey for many urls in one query, urls without a
+// link are left out of the result.
+func (r *ShortenerRepository) FindEncodedKeys(ctx context.Context, longURLs []url.URL, workspaceID string, ownerID string) (map[string]string, error) {
+	keys := map[string]string{}
+	if len(longURLs) == 0 {
+		return keys, nil
+	}
+
+	query := `SELECT DISTINCT ON (long_url) long_url, encoded_key FROM urls
+		WHERE long_url = ANY($1) AND workspace_id IS NOT DISTINCT FROM $2 AND (workspace_id IS NOT NULL OR owner_id IS NOT DISTINCT FROM $3)
+		AND version = 1 AND disabled_at IS NULL AND expires_at IS NULL
+		ORDER BY long_url, created_at`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(urlStrings(longURLs)), nullString(workspaceID), nullString(ownerID))
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to find encoded keys: %v", err))
+		return nil, ErrUnexpected
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var longURL, encodedKey string
+		err = rows.Scan(&longURL, &encodedKey)
+		if err != nil {
+			slog.Error(fmt.Sprintf("failed to scan encoded key: %v", err))
+			return nil, ErrUnexpected
+		}
+		keys[longURL] = encodedKey
+	}
+
+	err = rows.Err()
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to read encoded keys: %v", err))
+		return nil, ErrUnexpected
+	}
+
+	return keys, nil
+}
+
+func (r *ShortenerRepository) FindLink(ctx context.Context, encodedKey string) (model.Link, error) {
+	query := `SELECT long_url, expires_at, management_token_hash, disabled_at, created_at, owner_id, workspace_id FROM urls WHERE encoded_key = $1`
 
 	var dbLongURL string
-	err := r.db.QueryRowContext(ctx, query, encodedKey).Scan(&dbLongURL)
+	var expiresAt, disabledAt, createdAt sql.NullTime
+	var tokenHash, ownerID, workspaceID sql.NullString
+	err := r.db.QueryRowContext(ctx, query, encodedKey).Scan(&dbLongURL, &expiresAt, &tokenHash, &disabledAt, &createdAt, &ownerID, &workspaceID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return url.URL{}, ErrNotFound
+			return model.Link{}, ErrNotFound
 		}
 
 		slog.Error(fmt.Sprintf("failed to find longURL: %v", err))
-		return url.URL{}, ErrUnexpected
+		return model.Link{}, ErrUnexpected
 	}
 
 	longURL, err := url.Parse(dbLongURL)
 	if err != nil {
 		slog.Error(fmt.Sprintf("failed to parse longURL: %v", err))
-		return url.URL{}, ErrUnexpected
+		return model.Link{}, ErrUnexpected
 	}
 
-	return *longURL, nil
+	link := model.Link{EncodedKey: encodedKey, LongURL: *longURL, ManagementTokenHash: tokenHash.String, CreatedAt: createdAt.Time, OwnerID: ownerID.String, WorkspaceID: workspaceID.String}
+	if expiresAt.Valid {
+		link.ExpiresAt = &expiresAt.Time
+	}
+	if disabledAt.Valid {
+		link.DisabledAt = &disabledAt.Time
+	}
+
+	return link, nil
 }
 
-func (r *ShortenerRepository) SaveURL(ctx context.Context, encodedKey string, longURL url.URL) error {
-	query := `INSERT INTO urls (encoded_key, long_url) VALUES ($1, $2)`
+// SaveURL inserts link. An expired link keeps its key until DeleteExpired removes it,
+// so its key keeps answering 410 Gone instead of pointing somewhere else.
+func (r *ShortenerRepository) SaveURL(ctx context.Context, link model.Link) error {
+	query := `INSERT INTO urls (encoded_key, long_url, expires_at, management_token_hash, owner_id, workspace_id) VALUES ($1, $2, $3, $4, $5, $6)`
 
-	_, err := r.db.ExecContext(ctx, query, encodedKey, longURL.String())
+	_, err := r.db.ExecContext(ctx, query, link.EncodedKey, link.LongURL.String(), link.ExpiresAt, nullString(link.ManagementTokenHash), nullString(link.OwnerID), nullString(link.WorkspaceID))
 	if err != nil {
+		if uniqueErr := uniqueViolation(err); uniqueErr != nil {
+			return uniqueErr
+		}
+
 		slog.Error(fmt.Sprintf("failed to insert url: %v", err))
 		return ErrUnexpected
 	}
 
 	return nil
 }
+
+// SaveURLs inserts links with a single statement and returns the keys it inserted.
+// A link whose key is already taken is skipped instead of failing the whole batch.
+func (r *ShortenerRepository) SaveURLs(ctx context.Context, links []model.Link) (map[string]bool, error) {
+	inserted := map[string]bool{}
+	if len(links) == 0 {
+		return inserted, nil
+	}
+
+	placeholders := make([]string, 0, len(links))
+	args := make([]any, 0, len(links)*6)
+	for i, link := range links {
+		n := i * 6
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6))
+		args = append(args, link.EncodedKey, link.LongURL.String(), link.ExpiresAt, nullString(link.ManagementTokenHash), nullString(link.OwnerID), nullString(link.WorkspaceID))
+	}
+
+	query := `INSERT INTO urls (encoded_key, long_url, expires_at, management_token_hash, owner_id, workspace_id) VALUES ` + strings.Join(placeholders, ", ") + `
+		ON CONFLICT (encoded_key) DO NOTHING RETURNING encoded_key`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to insert urls: %v", err))
+		return nil, ErrUnexpected
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var encodedKey string
+		err = rows.Scan(&encodedKey)
+		if err != nil {
+			slog.Error(fmt.Sprintf("failed to scan inserted url: %v", err))
+			return nil, ErrUnexpected
+		}
+		inserted[encodedKey] = true
+	}
+
+	err = rows.Err()
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to read inserted urls: %v", err))
+		return nil, ErrUnexpected
+	}
+
+	return inserted, nil
+}
+
+// ListLinks pages through links newest first. It seeks past filter.After instead of
+// skipping rows, so every page costs the same however deep the listing goes.
+func (r *ShortenerRepository) ListLinks(ctx context.Context, filter model.LinkFilter) ([]model.Link, error) {
+	var conditions []string
+	var args []any
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if !filter.CreatedFrom.IsZero() {
+		conditions = append(conditions, "created_at >= "+arg(filter.CreatedFrom))
+	}
+	if !filter.CreatedTo.IsZero() {
+		conditions = append(conditions, "created_at < "+arg(filter.CreatedTo))
+	}
+	if filter.Domain != "" {
+		conditions = append(conditions, urlHostExpr+" = "+arg(filter.Domain))
+	}
+	if filter.KeyPrefix != "" {
+		conditions = append(conditions, "encoded_key LIKE "+arg(likeEscaper.Replace(filter.KeyPrefix)+"%"))
+	}
+	if filter.Search != "" {
+		conditions = append(conditions, "long_url ILIKE "+arg("%"+likeEscaper.Replace(filter.Search)+"%"))
+	}
+	if filter.OwnerID != "" {
+		conditions = append(conditions, "owner_id = "+arg(filter.OwnerID))
+	}
+	if filter.WorkspaceID != "" {
+		conditions = append(conditions, "workspace_id = "+arg(filter.WorkspaceID))
+	}
+	if filter.After != nil {
+		conditions = append(conditions, fmt.Sprintf("(created_at, encoded_key) < (%s, %s)", arg(filter.After.CreatedAt), arg(filter.After.EncodedKey)))
+	}
+
+	query := `SELECT encoded_key, long_url, expires_at, disabled_at, created_at, owner_id, workspace_id FROM urls`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC, encoded_key DESC LIMIT " + arg(filter.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to list links: %v", err))
+		return nil, ErrUnexpected
+	}
+	defer rows.Close()
+
+	links := []model.Link{}
+	for rows.Next() {
+		var encodedKey, dbLongURL string
+		var expiresAt, disabledAt sql.NullTime
+		var ownerID, workspaceID sql.NullString
+		var createdAt time.Time
+		err = rows.Scan(&encodedKey, &dbLongURL, &expiresAt, &disabledAt, &createdAt, &ownerID, &workspaceID)
+		if err != nil {
+			slog.Error(fmt.Sprintf("failed to scan link: %v", err))
+			return nil, ErrUnexpected
+		}
+
+		longURL, err := url.Parse(dbLongURL)
+		if err != nil {
+			slog.Error(fmt.Sprintf("failed to parse longURL: %v", err))
+			return nil, ErrUnexpected
+		}
+
+		link := model.Link{EncodedKey: encodedKey, LongURL: *longURL, CreatedAt: createdAt, OwnerID: ownerID.String, WorkspaceID: workspaceID.String}
+		if expiresAt.Valid {
+			link.ExpiresAt = &expiresAt.Time
+		}
+		if disabledAt.Valid {
+			link.DisabledAt = &disabledAt.Time
+		}
+		links = append(links, link)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to read links: %v", err))
+		return nil, ErrUnexpected
+	}
+
+	return links, nil
+}
+
+// DeleteLink deletes the clicks of the link in the same statement, clicks have no
+// foreign key and a key used again must not inherit them.
+func (r *ShortenerRepository) DeleteLink(ctx context.Context, encodedKey string) error {
+	query := `WITH deleted_clicks AS (
+		DELETE FROM clicks WHERE encoded_key = $1
+	)
+	DELETE FROM urls WHERE encoded_key = $1`
+
+	result, err := r.db.ExecContext(ctx, query, encodedKey)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to delete url: %v", err))
+		return ErrUnexpected
+	}
+
+	return requireAffected(result)
+}
+
+// DisableLink keeps the first disabled_at, disabling twice is not an error.
+func (r *ShortenerRepository) DisableLink(ctx context.Context, encodedKey string, disabledAt time.Time) error {
+	query := `UPDATE urls SET disabled_at = COALESCE(disabled_at, $2) WHERE encoded_key = $1`
+
+	result, err := r.db.ExecContext(ctx, query, encodedKey, disabledAt)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to disable url: %v", err))
+		return ErrUnexpected
+	}
+
+	return requireAffected(result)
+}
+
+// UpdateLongURL repoints a link and records the destination it replaced in
+// url_history, both in one statement so a concurrent edit can't skip a version.
+func (r *ShortenerRepository) UpdateLongURL(ctx context.Context, encodedKey string, longURL url.URL, changedAt time.Time, actor string) error {
+	query := `WITH previous AS (
+		SELECT encoded_key, long_url, version FROM urls WHERE encoded_key = $1 FOR UPDATE
+	), history AS (
+		INSERT INTO url_history (encoded_key, version, long_url, changed_at, actor)
+		SELECT encoded_key, version, long_url, $3, $4 FROM previous
+	)
+	UPDATE urls SET long_url = $2, version = previous.version + 1
+	FROM previous WHERE urls.encoded_key = previous.encoded_key`
+
+	result, err := r.db.ExecContext(ctx, query, encodedKey, longURL.String(), changedAt, actor)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to update url: %v", err))
+		return ErrUnexpected
+	}
+
+	return requireAffected(result)
+}
+
+func (r *ShortenerRepository) ListHistory(ctx context.Context, encodedKey string) ([]model.HistoryEntry, error) {
+	query := `SELECT version, long_url, changed_at, actor FROM url_history WHERE encoded_key = $1 ORDER BY version DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, encodedKey)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to list url history: %v", err))
+		return nil, ErrUnexpected
+	}
+	defer rows.Close()
+
+	history := []model.HistoryEntry{}
+	for rows.Next() {
+		var entry model.HistoryEntry
+		var dbLongURL string
+		err = rows.Scan(&entry.Version, &dbLongURL, &entry.ChangedAt, &entry.Actor)
+		if err != nil {
+			slog.Error(fmt.Sprintf("failed to scan url history: %v", err))
+			return nil, ErrUnexpected
+		}
+
+		longURL, err := url.Parse(dbLongURL)
+		if err != nil {
+			slog.Error(fmt.Sprintf("failed to parse history longURL: %v", err))
+			return nil, ErrUnexpected
+		}
+		entry.LongURL = *longURL
+
+		history = append(history, entry)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to read url history: %v", err))
+		return nil, ErrUnexpected
+	}
+
+	return history, nil
+}
+
+// DeleteExpired deletes the clicks of the links it deletes too, like DeleteLink, and
+// returns the keys of the links it deleted.
+func (r *ShortenerRepository) DeleteExpired(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	query := `WITH deleted AS (
+		DELETE FROM urls WHERE encoded_key IN (
+			SELECT encoded_key FROM urls WHERE expires_at <= $1 ORDER BY expires_at LIMIT $2
+		) RETURNING encoded_key
+	), deleted_clicks AS (
+		DELETE FROM clicks WHERE encoded_key IN (SELECT encoded_key FROM deleted)
+	)
+	SELECT encoded_key FROM deleted`
+
+	rows, err := r.db.QueryContext(ctx, query, before, limit)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to delete expired urls: %v", err))
+		return nil, ErrUnexpected
+	}
+	defer rows.Close()
+
+	var deleted []string
+	for rows.Next() {
+		var encodedKey string
+		err = rows.Scan(&encodedKey)
+		if err != nil {
+			slog.Error(fmt.Sprintf("failed to read deleted url: %v", err))
+			return nil, ErrUnexpected
+		}
+
+		deleted = append(deleted, encodedKey)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to delete expired urls: %v", err))
+		return nil, ErrUnexpected
+	}
+
+	return deleted, nil
+}
+
+func urlStrings(longURLs []url.URL) []string {
+	strs := make([]string, 0, len(longURLs))
+	for _, longURL := range longURLs {
+		strs = append(strs, longURL.String())
+	}
+
+	return strs
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to count affected urls: %v", err))
+		return ErrUnexpected
+	}
+
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func uniqueViolation(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != uniqueViolationCode || pqErr.Constraint != urlsKeyConstraint {
+		return nil
+	}
+
+	return ErrKeyAlreadyExists
+}
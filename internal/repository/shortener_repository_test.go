@@ -7,12 +7,20 @@ import (
 	"net/url"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestShortenerRepository_FindEncodedKey(t *testing.T) {
+	findEncodedKeyQuery := regexp.QuoteMeta(`SELECT encoded_key FROM urls
+		WHERE long_url = $1 AND workspace_id IS NOT DISTINCT FROM $2 AND (workspace_id IS NOT NULL OR owner_id IS NOT DISTINCT FROM $3)
+		AND version = 1 AND disabled_at IS NULL AND expires_at IS NULL
+		ORDER BY created_at LIMIT 1`)
+
 	tests := []struct {
 		name    string
 		setup   func(sqlmock.Sqlmock)
@@ -22,8 +30,8 @@ func TestShortenerRepository_FindEncodedKey(t *testing.T) {
 		{
 			name: "when db failed",
 			setup: func(s sqlmock.Sqlmock) {
-				s.ExpectQuery(regexp.QuoteMeta(`SELECT encoded_key FROM urls WHERE long_url = $1`)).
-					WithArgs("a-long-url").
+				s.ExpectQuery(findEncodedKeyQuery).
+					WithArgs("http://a-long-url", "a-workspace-id", "a-user-id").
 					WillReturnError(errors.New("db error"))
 			},
 			wantErr: ErrUnexpected,
@@ -31,8 +39,8 @@ func TestShortenerRepository_FindEncodedKey(t *testing.T) {
 		{
 			name: "when db has no long url",
 			setup: func(s sqlmock.Sqlmock) {
-				s.ExpectQuery(regexp.QuoteMeta(`SELECT encoded_key FROM urls WHERE long_url = $1`)).
-					WithArgs("http://a-long-url").
+				s.ExpectQuery(findEncodedKeyQuery).
+					WithArgs("http://a-long-url", "a-workspace-id", "a-user-id").
 					WillReturnError(sql.ErrNoRows)
 			},
 		},
@@ -40,8 +48,8 @@ func TestShortenerRepository_FindEncodedKey(t *testing.T) {
 			name: "when successfully find encoded key",
 			setup: func(s sqlmock.Sqlmock) {
 				row := sqlmock.NewRows([]string{"encoded_key"}).AddRow("a-encoded-key")
-				s.ExpectQuery(regexp.QuoteMeta(`SELECT encoded_key FROM urls WHERE long_url = $1`)).
-					WithArgs("http://a-long-url").
+				s.ExpectQuery(findEncodedKeyQuery).
+					WithArgs("http://a-long-url", "a-workspace-id", "a-user-id").
 					WillReturnRows(row)
 			},
 			want: "a-encoded-key",
@@ -57,7 +65,7 @@ func TestShortenerRepository_FindEncodedKey(t *testing.T) {
 
 			r := NewShortenerRepository(db)
 
-			got, err := r.FindEncodedKey(context.Background(), url.URL{Scheme: "http", Host: "a-long-url"})
+			got, err := r.FindEncodedKey(context.Background(), url.URL{Scheme: "http", Host: "a-long-url"}, "a-workspace-id", "a-user-id")
 
 			assert.Equal(t, tt.want, got)
 			assert.Equal(t, tt.wantErr, err)
@@ -65,17 +73,89 @@ func TestShortenerRepository_FindEncodedKey(t *testing.T) {
 	}
 }
 
-func TestShortenerRepository_FindLongURL(t *testing.T) {
+func TestShortenerRepository_FindEncodedKeys(t *testing.T) {
+	longURLs := []url.URL{{Scheme: "http", Host: "a-long-url"}, {Scheme: "http", Host: "b-long-url"}}
+	query := regexp.QuoteMeta(`SELECT DISTINCT ON (long_url) long_url, encoded_key FROM urls
+		WHERE long_url = ANY($1) AND workspace_id IS NOT DISTINCT FROM $2 AND (workspace_id IS NOT NULL OR owner_id IS NOT DISTINCT FROM $3)
+		AND version = 1 AND disabled_at IS NULL AND expires_at IS NULL
+		ORDER BY long_url, created_at`)
+	args := pq.Array([]string{"http://a-long-url", "http://b-long-url"})
+
+	tests := []struct {
+		name     string
+		longURLs []url.URL
+		setup    func(sqlmock.Sqlmock)
+		want     map[string]string
+		wantErr  error
+	}{
+		{
+			name:     "when there are no urls",
+			longURLs: nil,
+			setup:    func(s sqlmock.Sqlmock) {},
+			want:     map[string]string{},
+		},
+		{
+			name:     "when db failed",
+			longURLs: longURLs,
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs(args, nil, nil).WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name:     "when failed while reading rows",
+			longURLs: longURLs,
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).
+					WithArgs(args, nil, nil).
+					WillReturnRows(sqlmock.NewRows([]string{"long_url", "encoded_key"}).AddRow("http://a-long-url", "a-encoded-key").RowError(0, errors.New("db error")))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name:     "when successfully find the urls that have a link",
+			longURLs: longURLs,
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).
+					WithArgs(args, nil, nil).
+					WillReturnRows(sqlmock.NewRows([]string{"long_url", "encoded_key"}).AddRow("http://a-long-url", "a-encoded-key"))
+			},
+			want: map[string]string{"http://a-long-url": "a-encoded-key"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewShortenerRepository(db)
+
+			got, err := r.FindEncodedKeys(context.Background(), tt.longURLs, "", "")
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestShortenerRepository_FindLink(t *testing.T) {
+	expiresAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
 	tests := []struct {
 		name    string
 		setup   func(sqlmock.Sqlmock)
-		want    url.URL
+		want    model.Link
 		wantErr error
 	}{
 		{
 			name: "when no encoded key on db",
 			setup: func(s sqlmock.Sqlmock) {
-				s.ExpectQuery(regexp.QuoteMeta(`SELECT long_url FROM urls WHERE encoded_key = $1`)).
+				s.ExpectQuery(regexp.QuoteMeta(`SELECT long_url, expires_at, management_token_hash, disabled_at, created_at, owner_id, workspace_id FROM urls WHERE encoded_key = $1`)).
 					WithArgs("a-encoded-key").
 					WillReturnError(sql.ErrNoRows)
 			},
@@ -84,7 +164,7 @@ func TestShortenerRepository_FindLongURL(t *testing.T) {
 		{
 			name: "when db failed",
 			setup: func(s sqlmock.Sqlmock) {
-				s.ExpectQuery(regexp.QuoteMeta(`SELECT long_url FROM urls WHERE encoded_key = $1`)).
+				s.ExpectQuery(regexp.QuoteMeta(`SELECT long_url, expires_at, management_token_hash, disabled_at, created_at, owner_id, workspace_id FROM urls WHERE encoded_key = $1`)).
 					WithArgs("a-encoded-key").
 					WillReturnError(errors.New("db error"))
 			},
@@ -93,20 +173,38 @@ func TestShortenerRepository_FindLongURL(t *testing.T) {
 		{
 			name: "when db has invalid URL",
 			setup: func(s sqlmock.Sqlmock) {
-				s.ExpectQuery(regexp.QuoteMeta(`SELECT long_url FROM urls WHERE encoded_key = $1`)).
+				s.ExpectQuery(regexp.QuoteMeta(`SELECT long_url, expires_at, management_token_hash, disabled_at, created_at, owner_id, workspace_id FROM urls WHERE encoded_key = $1`)).
 					WithArgs("a-encoded-key").
-					WillReturnRows(sqlmock.NewRows([]string{"long_url"}).AddRow("://missing-scheme.com"))
+					WillReturnRows(sqlmock.NewRows([]string{"long_url", "expires_at", "management_token_hash", "disabled_at", "created_at", "owner_id", "workspace_id"}).AddRow("://missing-scheme.com", nil, nil, nil, nil, nil, nil))
 			},
 			wantErr: ErrUnexpected,
 		},
 		{
-			name: "when successfully find longURL",
+			name: "when successfully find link without expiration",
 			setup: func(s sqlmock.Sqlmock) {
-				s.ExpectQuery(regexp.QuoteMeta(`SELECT long_url FROM urls WHERE encoded_key = $1`)).
+				s.ExpectQuery(regexp.QuoteMeta(`SELECT long_url, expires_at, management_token_hash, disabled_at, created_at, owner_id, workspace_id FROM urls WHERE encoded_key = $1`)).
 					WithArgs("a-encoded-key").
-					WillReturnRows(sqlmock.NewRows([]string{"long_url"}).AddRow("http://valid-url.com"))
+					WillReturnRows(sqlmock.NewRows([]string{"long_url", "expires_at", "management_token_hash", "disabled_at", "created_at", "owner_id", "workspace_id"}).AddRow("http://valid-url.com", nil, nil, nil, createdAt, nil, nil))
 			},
-			want: url.URL{Scheme: "http", Host: "valid-url.com"},
+			want: model.Link{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "valid-url.com"}, CreatedAt: createdAt},
+		},
+		{
+			name: "when successfully find link with expiration",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(regexp.QuoteMeta(`SELECT long_url, expires_at, management_token_hash, disabled_at, created_at, owner_id, workspace_id FROM urls WHERE encoded_key = $1`)).
+					WithArgs("a-encoded-key").
+					WillReturnRows(sqlmock.NewRows([]string{"long_url", "expires_at", "management_token_hash", "disabled_at", "created_at", "owner_id", "workspace_id"}).AddRow("http://valid-url.com", expiresAt, nil, nil, createdAt, nil, nil))
+			},
+			want: model.Link{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "valid-url.com"}, ExpiresAt: &expiresAt, CreatedAt: createdAt},
+		},
+		{
+			name: "when successfully find disabled workspace link with management token",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(regexp.QuoteMeta(`SELECT long_url, expires_at, management_token_hash, disabled_at, created_at, owner_id, workspace_id FROM urls WHERE encoded_key = $1`)).
+					WithArgs("a-encoded-key").
+					WillReturnRows(sqlmock.NewRows([]string{"long_url", "expires_at", "management_token_hash", "disabled_at", "created_at", "owner_id", "workspace_id"}).AddRow("http://valid-url.com", nil, "a-token-hash", expiresAt, createdAt, "a-user-id", "a-workspace-id"))
+			},
+			want: model.Link{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "valid-url.com"}, ManagementTokenHash: "a-token-hash", DisabledAt: &expiresAt, CreatedAt: createdAt, OwnerID: "a-user-id", WorkspaceID: "a-workspace-id"},
 		},
 	}
 	for _, tt := range tests {
@@ -119,7 +217,7 @@ func TestShortenerRepository_FindLongURL(t *testing.T) {
 
 			r := NewShortenerRepository(db)
 
-			got, err := r.FindLongURL(context.Background(), "a-encoded-key")
+			got, err := r.FindLink(context.Background(), "a-encoded-key")
 
 			assert.Equal(t, tt.want, got)
 			assert.Equal(t, tt.wantErr, err)
@@ -128,25 +226,50 @@ func TestShortenerRepository_FindLongURL(t *testing.T) {
 }
 
 func TestShortenerRepository_SaveURL(t *testing.T) {
+	expiresAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	insertQuery := regexp.QuoteMeta(`INSERT INTO urls (encoded_key, long_url, expires_at, management_token_hash, owner_id, workspace_id) VALUES ($1, $2, $3, $4, $5, $6)`)
+
 	tests := []struct {
 		name    string
+		link    model.Link
 		setup   func(sqlmock.Sqlmock)
 		wantErr error
 	}{
 		{
 			name: "when failed to insert url",
+			link: model.Link{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}},
 			setup: func(s sqlmock.Sqlmock) {
-				s.ExpectExec(regexp.QuoteMeta(`INSERT INTO urls (encoded_key, long_url) VALUES ($1, $2)`)).
-					WithArgs("a-encoded-key", "a-long-url").
+				s.ExpectExec(insertQuery).
+					WithArgs("a-encoded-key", "http://a-long-url", nil, nil, nil, nil).
 					WillReturnError(errors.New("db error"))
 			},
 			wantErr: ErrUnexpected,
 		},
+		{
+			name: "when encoded key already exists",
+			link: model.Link{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}},
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(insertQuery).
+					WithArgs("a-encoded-key", "http://a-long-url", nil, nil, nil, nil).
+					WillReturnError(&pq.Error{Code: "23505", Constraint: "urls_pkey"})
+			},
+			wantErr: ErrKeyAlreadyExists,
+		},
 		{
 			name: "when successfully save url",
+			link: model.Link{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}},
 			setup: func(s sqlmock.Sqlmock) {
-				s.ExpectExec(regexp.QuoteMeta(`INSERT INTO urls (encoded_key, long_url) VALUES ($1, $2)`)).
-					WithArgs("a-encoded-key", "http://a-long-url").
+				s.ExpectExec(insertQuery).
+					WithArgs("a-encoded-key", "http://a-long-url", nil, nil, nil, nil).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name: "when successfully save workspace url with expiration and management token",
+			link: model.Link{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}, ExpiresAt: &expiresAt, ManagementTokenHash: "a-token-hash", OwnerID: "a-user-id", WorkspaceID: "a-workspace-id"},
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(insertQuery).
+					WithArgs("a-encoded-key", "http://a-long-url", expiresAt, "a-token-hash", "a-user-id", "a-workspace-id").
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 		},
@@ -161,9 +284,433 @@ func TestShortenerRepository_SaveURL(t *testing.T) {
 
 			r := NewShortenerRepository(db)
 
-			got := r.SaveURL(context.Background(), "a-encoded-key", url.URL{Scheme: "http", Host: "a-long-url"})
+			got := r.SaveURL(context.Background(), tt.link)
 
 			assert.Equal(t, tt.wantErr, got)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestShortenerRepository_SaveURLs(t *testing.T) {
+	expiresAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	links := []model.Link{
+		{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}, ManagementTokenHash: "a-token-hash"},
+		{EncodedKey: "b-encoded-key", LongURL: url.URL{Scheme: "http", Host: "b-long-url"}, ExpiresAt: &expiresAt},
+	}
+	insertQuery := regexp.QuoteMeta(`INSERT INTO urls (encoded_key, long_url, expires_at, management_token_hash, owner_id, workspace_id) VALUES ($1, $2, $3, $4, $5, $6), ($7, $8, $9, $10, $11, $12)
+		ON CONFLICT (encoded_key) DO NOTHING RETURNING encoded_key`)
+
+	tests := []struct {
+		name    string
+		links   []model.Link
+		setup   func(sqlmock.Sqlmock)
+		want    map[string]bool
+		wantErr error
+	}{
+		{
+			name:  "when there are no links",
+			links: nil,
+			setup: func(s sqlmock.Sqlmock) {},
+			want:  map[string]bool{},
+		},
+		{
+			name:  "when failed to insert urls",
+			links: links,
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(insertQuery).WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name:  "when successfully insert urls in a single statement skipping taken keys",
+			links: links,
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(insertQuery).
+					WithArgs("a-encoded-key", "http://a-long-url", nil, "a-token-hash", nil, nil, "b-encoded-key", "http://b-long-url", expiresAt, nil, nil, nil).
+					WillReturnRows(sqlmock.NewRows([]string{"encoded_key"}).AddRow("b-encoded-key"))
+			},
+			want: map[string]bool{"b-encoded-key": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewShortenerRepository(db)
+
+			got, err := r.SaveURLs(context.Background(), tt.links)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestShortenerRepository_ListLinks(t *testing.T) {
+	createdAt := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"encoded_key", "long_url", "expires_at", "disabled_at", "created_at", "owner_id", "workspace_id"}
+	unfilteredQuery := regexp.QuoteMeta(`SELECT encoded_key, long_url, expires_at, disabled_at, created_at, owner_id, workspace_id FROM urls ORDER BY created_at DESC, encoded_key DESC LIMIT $1`)
+	filteredQuery := regexp.QuoteMeta(`SELECT encoded_key, long_url, expires_at, disabled_at, created_at, owner_id, workspace_id FROM urls` +
+		` WHERE created_at >= $1 AND created_at < $2` +
+		` AND substring(long_url from '^[^:]+://(?:[^/?#@]*@)?([^/?#:]+)') = $3` +
+		` AND encoded_key LIKE $4 AND long_url ILIKE $5 AND owner_id = $6 AND workspace_id = $7 AND (created_at, encoded_key) < ($8, $9)` +
+		` ORDER BY created_at DESC, encoded_key DESC LIMIT $10`)
+
+	tests := []struct {
+		name    string
+		filter  model.LinkFilter
+		setup   func(sqlmock.Sqlmock)
+		want    []model.Link
+		wantErr error
+	}{
+		{
+			name:   "when db failed",
+			filter: model.LinkFilter{Limit: 10},
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(unfilteredQuery).WithArgs(10).WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name:   "when db has invalid URL",
+			filter: model.LinkFilter{Limit: 10},
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(unfilteredQuery).WithArgs(10).WillReturnRows(sqlmock.NewRows(columns).AddRow("a-encoded-key", "://missing-scheme.com", nil, nil, createdAt, nil, nil))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name:   "when there are no links",
+			filter: model.LinkFilter{Limit: 10},
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(unfilteredQuery).WithArgs(10).WillReturnRows(sqlmock.NewRows(columns))
+			},
+			want: []model.Link{},
+		},
+		{
+			name: "when successfully list links with every filter and escaped patterns",
+			filter: model.LinkFilter{
+				CreatedFrom: from,
+				CreatedTo:   to,
+				Domain:      "go.dev",
+				KeyPrefix:   "launch_",
+				Search:      "100%",
+				OwnerID:     "a-user-id",
+				WorkspaceID: "a-workspace-id",
+				After:       &model.LinkCursor{CreatedAt: createdAt, EncodedKey: "b-encoded-key"},
+				Limit:       10,
+			},
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(filteredQuery).
+					WithArgs(from, to, "go.dev", `launch\_%`, `%100\%%`, "a-user-id", "a-workspace-id", createdAt, "b-encoded-key", 10).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("launch_a", "https://go.dev/100%25", nil, nil, createdAt, "a-user-id", "a-workspace-id").
+						AddRow("launch_b", "https://go.dev/100%25/", to, from, from, "a-user-id", "a-workspace-id"))
+			},
+			want: []model.Link{
+				{EncodedKey: "launch_a", LongURL: url.URL{Scheme: "https", Host: "go.dev", Path: "/100%"}, CreatedAt: createdAt, OwnerID: "a-user-id", WorkspaceID: "a-workspace-id"},
+				{EncodedKey: "launch_b", LongURL: url.URL{Scheme: "https", Host: "go.dev", Path: "/100%/"}, CreatedAt: from, ExpiresAt: &to, DisabledAt: &from, OwnerID: "a-user-id", WorkspaceID: "a-workspace-id"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewShortenerRepository(db)
+
+			got, err := r.ListLinks(context.Background(), tt.filter)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestShortenerRepository_DeleteLink(t *testing.T) {
+	query := regexp.QuoteMeta(`WITH deleted_clicks AS (
+		DELETE FROM clicks WHERE encoded_key = $1
+	)
+	DELETE FROM urls WHERE encoded_key = $1`)
+
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "when db failed",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WithArgs("a-encoded-key").WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when link does not exist",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WithArgs("a-encoded-key").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "when successfully deletes link",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WithArgs("a-encoded-key").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewShortenerRepository(db)
+
+			got := r.DeleteLink(context.Background(), "a-encoded-key")
+
+			assert.Equal(t, tt.wantErr, got)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestShortenerRepository_DisableLink(t *testing.T) {
+	disabledAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`UPDATE urls SET disabled_at = COALESCE(disabled_at, $2) WHERE encoded_key = $1`)
+
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "when db failed",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WithArgs("a-encoded-key", disabledAt).WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when link does not exist",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WithArgs("a-encoded-key", disabledAt).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "when successfully disables link",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WithArgs("a-encoded-key", disabledAt).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewShortenerRepository(db)
+
+			got := r.DisableLink(context.Background(), "a-encoded-key", disabledAt)
+
+			assert.Equal(t, tt.wantErr, got)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestShortenerRepository_UpdateLongURL(t *testing.T) {
+	changedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`WITH previous AS (
+		SELECT encoded_key, long_url, version FROM urls WHERE encoded_key = $1 FOR UPDATE
+	), history AS (
+		INSERT INTO url_history (encoded_key, version, long_url, changed_at, actor)
+		SELECT encoded_key, version, long_url, $3, $4 FROM previous
+	)
+	UPDATE urls SET long_url = $2, version = previous.version + 1
+	FROM previous WHERE urls.encoded_key = previous.encoded_key`)
+
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "when db failed",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WithArgs("a-encoded-key", "http://new-url", changedAt, "10.0.0.1").WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when link does not exist",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WithArgs("a-encoded-key", "http://new-url", changedAt, "10.0.0.1").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "when successfully updates long url",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WithArgs("a-encoded-key", "http://new-url", changedAt, "10.0.0.1").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewShortenerRepository(db)
+
+			got := r.UpdateLongURL(context.Background(), "a-encoded-key", url.URL{Scheme: "http", Host: "new-url"}, changedAt, "10.0.0.1")
+
+			assert.Equal(t, tt.wantErr, got)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestShortenerRepository_ListHistory(t *testing.T) {
+	changedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`SELECT version, long_url, changed_at, actor FROM url_history WHERE encoded_key = $1 ORDER BY version DESC`)
+	columns := []string{"version", "long_url", "changed_at", "actor"}
+
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		want    []model.HistoryEntry
+		wantErr error
+	}{
+		{
+			name: "when db failed",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("a-encoded-key").WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when db has invalid URL",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("a-encoded-key").
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "://missing-scheme.com", changedAt, "10.0.0.1"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when link was never edited",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("a-encoded-key").WillReturnRows(sqlmock.NewRows(columns))
+			},
+			want: []model.HistoryEntry{},
+		},
+		{
+			name: "when successfully lists history",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("a-encoded-key").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(2, "http://second-url", changedAt.Add(time.Hour), "10.0.0.2").
+						AddRow(1, "http://first-url", changedAt, "10.0.0.1"))
+			},
+			want: []model.HistoryEntry{
+				{Version: 2, LongURL: url.URL{Scheme: "http", Host: "second-url"}, ChangedAt: changedAt.Add(time.Hour), Actor: "10.0.0.2"},
+				{Version: 1, LongURL: url.URL{Scheme: "http", Host: "first-url"}, ChangedAt: changedAt, Actor: "10.0.0.1"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewShortenerRepository(db)
+
+			got, err := r.ListHistory(context.Background(), "a-encoded-key")
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestShortenerRepository_DeleteExpired(t *testing.T) {
+	before := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`WITH deleted AS (
+		DELETE FROM urls WHERE encoded_key IN (
+			SELECT encoded_key FROM urls WHERE expires_at <= $1 ORDER BY expires_at LIMIT $2
+		) RETURNING encoded_key
+	), deleted_clicks AS (
+		DELETE FROM clicks WHERE encoded_key IN (SELECT encoded_key FROM deleted)
+	)
+	SELECT encoded_key FROM deleted`)
+
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "when db failed",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs(before, 100).WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when successfully delete expired urls with their clicks",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs(before, 100).WillReturnRows(sqlmock.NewRows([]string{"encoded_key"}).AddRow("a-encoded-key").AddRow("b-encoded-key"))
+			},
+			want: []string{"a-encoded-key", "b-encoded-key"},
+		},
+		{
+			name: "when reading a deleted url failed",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs(before, 100).WillReturnRows(sqlmock.NewRows([]string{"encoded_key"}).AddRow("a-encoded-key").RowError(0, errors.New("db error")))
+			},
+			wantErr: ErrUnexpected,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewShortenerRepository(db)
+
+			got, err := r.DeleteExpired(context.Background(), before, 100)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
 		})
 	}
 }
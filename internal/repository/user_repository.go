@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/lib/pq"
+)
+
+var ErrEmailAlreadyExists = errors.New("email already exists")
+
+const (
+	usersEmailConstraint = "users_email_key"
+	sessionColumns       = `id, user_id, access_hash, access_expires_at, refresh_hash, refresh_expires_at, created_at, revoked_at`
+)
+
+type UserRepository struct {
+	db DB
+}
+
+func NewUserRepository(db DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (r *UserRepository) SaveUser(ctx context.Context, user model.User) error {
+	query := `INSERT INTO users (id, email, password_hash, created_at) VALUES ($1, $2, $3, $4)`
+
+	_, err := r.db.ExecContext(ctx, query, user.ID, user.Email, user.PasswordHash, user.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolationCode && pqErr.Constraint == usersEmailConstraint {
+			return ErrEmailAlreadyExists
+		}
+
+		slog.Error(fmt.Sprintf("failed to insert user: %v", err))
+		return ErrUnexpected
+	}
+
+	return nil
+}
+
+func (r *UserRepository) FindUserByEmail(ctx context.Context, email string) (model.User, error) {
+	query := `SELECT id, email, password_hash, created_at FROM users WHERE email = $1`
+
+	var user model.User
+	err := r.db.QueryRowContext(ctx, query, email).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.User{}, ErrNotFound
+		}
+
+		slog.Error(fmt.Sprintf("failed to find user: %v", err))
+		return model.User{}, ErrUnexpected
+	}
+
+	return user, nil
+}
+
+func (r *UserRepository) SaveSession(ctx context.Context, session model.Session) error {
+	query := `INSERT INTO sessions (id, user_id, access_hash, access_expires_at, refresh_hash, refresh_expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := r.db.ExecContext(ctx, query, session.ID, session.UserID, session.AccessHash, session.AccessExpiresAt, session.RefreshHash, session.RefreshExpiresAt, session.CreatedAt)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to insert session: %v", err))
+		return ErrUnexpected
+	}
+
+	return nil
+}
+
+func (r *UserRepository) FindSessionByAccessHash(ctx context.Context, hash string) (model.Session, error) {
+	return r.findSession(ctx, `SELECT `+sessionColumns+` FROM sessions WHERE access_hash = $1`, hash)
+}
+
+func (r *UserRepository) FindSessionByRefreshHash(ctx context.Context, hash string) (model.Session, error) {
+	return r.findSession(ctx, `SELECT `+sessionColumns+` FROM sessions WHERE refresh_hash = $1`, hash)
+}
+
+// RotateSession replaces the tokens of session only while its refresh hash is still
+// previousRefreshHash, so a refresh token can't be spent twice by concurrent requests.
+func (r *UserRepository) RotateSession(ctx context.Context, session model.Session, previousRefreshHash string) error {
+	query := `UPDATE sessions SET access_hash = $2, access_expires_at = $3, refresh_hash = $4, refresh_expires_at = $5
+		WHERE id = $1 AND refresh_hash = $6 AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, session.ID, session.AccessHash, session.AccessExpiresAt, session.RefreshHash, session.RefreshExpiresAt, previousRefreshHash)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to rotate session: %v", err))
+		return ErrUnexpected
+	}
+
+	return requireAffected(result)
+}
+
+// RevokeSession is idempotent, a session revoked twice keeps its first revocation time.
+func (r *UserRepository) RevokeSession(ctx context.Context, id string, revokedAt time.Time) error {
+	query := `UPDATE sessions SET revoked_at = COALESCE(revoked_at, $2) WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id, revokedAt)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to revoke session: %v", err))
+		return ErrUnexpected
+	}
+
+	return requireAffected(result)
+}
+
+func (r *UserRepository) findSession(ctx context.Context, query string, hash string) (model.Session, error) {
+	var session model.Session
+	var revokedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, hash).Scan(&session.ID, &session.UserID, &session.AccessHash, &session.AccessExpiresAt,
+		&session.RefreshHash, &session.RefreshExpiresAt, &session.CreatedAt, &revokedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Session{}, ErrNotFound
+		}
+
+		slog.Error(fmt.Sprintf("failed to find session: %v", err))
+		return model.Session{}, ErrUnexpected
+	}
+
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+
+	return session, nil
+}
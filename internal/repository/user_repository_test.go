@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var sessionRows = []string{"id", "user_id", "access_hash", "access_expires_at", "refresh_hash", "refresh_expires_at", "created_at", "revoked_at"}
+
+func TestUserRepository_SaveUser(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	user := model.User{ID: "a-user-id", Email: "ada@example.com", PasswordHash: "a-password-hash", CreatedAt: createdAt}
+	query := regexp.QuoteMeta(`INSERT INTO users (id, email, password_hash, created_at) VALUES ($1, $2, $3, $4)`)
+
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "when email is already registered",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WillReturnError(&pq.Error{Code: "23505", Constraint: "users_email_key"})
+			},
+			wantErr: ErrEmailAlreadyExists,
+		},
+		{
+			name: "when failed to insert the user",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when successfully inserts the user",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WithArgs("a-user-id", "ada@example.com", "a-password-hash", createdAt).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewUserRepository(db)
+
+			err = r.SaveUser(context.Background(), user)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserRepository_FindUserByEmail(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`SELECT id, email, password_hash, created_at FROM users WHERE email = $1`)
+
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		want    model.User
+		wantErr error
+	}{
+		{
+			name: "when user is not found",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("ada@example.com").WillReturnRows(sqlmock.NewRows([]string{"id", "email", "password_hash", "created_at"}))
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "when db failed",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("ada@example.com").WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when user is found",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("ada@example.com").WillReturnRows(sqlmock.NewRows([]string{"id", "email", "password_hash", "created_at"}).
+					AddRow("a-user-id", "ada@example.com", "a-password-hash", createdAt))
+			},
+			want: model.User{ID: "a-user-id", Email: "ada@example.com", PasswordHash: "a-password-hash", CreatedAt: createdAt},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewUserRepository(db)
+
+			got, err := r.FindUserByEmail(context.Background(), "ada@example.com")
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserRepository_SaveSession(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	session := model.Session{ID: "a-session-id", UserID: "a-user-id", AccessHash: "an-access-hash", AccessExpiresAt: createdAt.Add(time.Minute),
+		RefreshHash: "a-refresh-hash", RefreshExpiresAt: createdAt.Add(time.Hour), CreatedAt: createdAt}
+	query := regexp.QuoteMeta(`INSERT INTO sessions (id, user_id, access_hash, access_expires_at, refresh_hash, refresh_expires_at, created_at)`)
+
+	db, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbMock.ExpectExec(query).
+		WithArgs("a-session-id", "a-user-id", "an-access-hash", createdAt.Add(time.Minute), "a-refresh-hash", createdAt.Add(time.Hour), createdAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec(query).WillReturnError(errors.New("db error"))
+
+	r := NewUserRepository(db)
+
+	assert.NoError(t, r.SaveSession(context.Background(), session))
+	assert.Equal(t, ErrUnexpected, r.SaveSession(context.Background(), session))
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestUserRepository_FindSession(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	revokedAt := createdAt.Add(time.Minute)
+	byAccess := regexp.QuoteMeta(`SELECT id, user_id, access_hash, access_expires_at, refresh_hash, refresh_expires_at, created_at, revoked_at FROM sessions WHERE access_hash = $1`)
+	byRefresh := regexp.QuoteMeta(`SELECT id, user_id, access_hash, access_expires_at, refresh_hash, refresh_expires_at, created_at, revoked_at FROM sessions WHERE refresh_hash = $1`)
+	session := model.Session{ID: "a-session-id", UserID: "a-user-id", AccessHash: "an-access-hash", AccessExpiresAt: createdAt.Add(time.Minute),
+		RefreshHash: "a-refresh-hash", RefreshExpiresAt: createdAt.Add(time.Hour), CreatedAt: createdAt}
+
+	tests := []struct {
+		name    string
+		find    func(*UserRepository) (model.Session, error)
+		setup   func(sqlmock.Sqlmock)
+		want    model.Session
+		wantErr error
+	}{
+		{
+			name: "when session is not found",
+			find: func(r *UserRepository) (model.Session, error) {
+				return r.FindSessionByAccessHash(context.Background(), "an-access-hash")
+			},
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(byAccess).WithArgs("an-access-hash").WillReturnRows(sqlmock.NewRows(sessionRows))
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "when db failed",
+			find: func(r *UserRepository) (model.Session, error) {
+				return r.FindSessionByRefreshHash(context.Background(), "a-refresh-hash")
+			},
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(byRefresh).WithArgs("a-refresh-hash").WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when session is found by its access hash",
+			find: func(r *UserRepository) (model.Session, error) {
+				return r.FindSessionByAccessHash(context.Background(), "an-access-hash")
+			},
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(byAccess).WithArgs("an-access-hash").WillReturnRows(sqlmock.NewRows(sessionRows).
+					AddRow("a-session-id", "a-user-id", "an-access-hash", createdAt.Add(time.Minute), "a-refresh-hash", createdAt.Add(time.Hour), createdAt, nil))
+			},
+			want: session,
+		},
+		{
+			name: "when a revoked session is found by its refresh hash",
+			find: func(r *UserRepository) (model.Session, error) {
+				return r.FindSessionByRefreshHash(context.Background(), "a-refresh-hash")
+			},
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(byRefresh).WithArgs("a-refresh-hash").WillReturnRows(sqlmock.NewRows(sessionRows).
+					AddRow("a-session-id", "a-user-id", "an-access-hash", createdAt.Add(time.Minute), "a-refresh-hash", createdAt.Add(time.Hour), createdAt, revokedAt))
+			},
+			want: func() model.Session {
+				revoked := session
+				revoked.RevokedAt = &revokedAt
+				return revoked
+			}(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			got, err := tt.find(NewUserRepository(db))
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserRepository_RotateSession(t *testing.T) {
+	expiresAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	session := model.Session{ID: "a-session-id", AccessHash: "another-access-hash", AccessExpiresAt: expiresAt, RefreshHash: "another-refresh-hash", RefreshExpiresAt: expiresAt}
+	query := regexp.QuoteMeta(`UPDATE sessions SET access_hash = $2, access_expires_at = $3, refresh_hash = $4, refresh_expires_at = $5
+		WHERE id = $1 AND refresh_hash = $6 AND revoked_at IS NULL`)
+
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "when failed to rotate the session",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when refresh token was already spent",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "when successfully rotates the session",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).
+					WithArgs("a-session-id", "another-access-hash", expiresAt, "another-refresh-hash", expiresAt, "a-refresh-hash").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewUserRepository(db)
+
+			err = r.RotateSession(context.Background(), session, "a-refresh-hash")
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserRepository_RevokeSession(t *testing.T) {
+	revokedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`UPDATE sessions SET revoked_at = COALESCE(revoked_at, $2) WHERE id = $1`)
+
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "when failed to revoke the session",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when session is not found",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "when successfully revokes the session",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectExec(query).WithArgs("a-session-id", revokedAt).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewUserRepository(db)
+
+			err = r.RevokeSession(context.Background(), "a-session-id", revokedAt)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
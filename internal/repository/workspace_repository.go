@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/lib/pq"
+)
+
+var ErrMemberAlreadyExists = errors.New("member already exists")
+
+const workspaceMembersKeyConstraint = "workspace_members_pkey"
+
+type WorkspaceRepository struct {
+	db DB
+}
+
+func NewWorkspaceRepository(db DB) *WorkspaceRepository {
+	return &WorkspaceRepository{db: db}
+}
+
+// CreateWorkspace inserts workspace with ownerID as its owner, both in one statement
+// so a workspace never exists without an owner.
+func (r *WorkspaceRepository) CreateWorkspace(ctx context.Context, workspace model.Workspace, ownerID string) error {
+	query := `WITH workspace AS (INSERT INTO workspaces (id, name, created_at) VALUES ($1, $2, $3) RETURNING id)
+		INSERT INTO workspace_members (workspace_id, user_id, role, joined_at) SELECT id, $4, $5, $3 FROM workspace`
+
+	_, err := r.db.ExecContext(ctx, query, workspace.ID, workspace.Name, workspace.CreatedAt, ownerID, model.RoleOwner)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to insert workspace: %v", err))
+		return ErrUnexpected
+	}
+
+	return nil
+}
+
+// ListWorkspaces returns the workspaces userID is a member of, oldest first.
+func (r *WorkspaceRepository) ListWorkspaces(ctx context.Context, userID string) ([]model.UserWorkspace, error) {
+	query := `SELECT w.id, w.name, w.created_at, m.role FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE m.user_id = $1 ORDER BY w.created_at, w.id`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to list workspaces: %v", err))
+		return nil, ErrUnexpected
+	}
+	defer rows.Close()
+
+	workspaces := []model.UserWorkspace{}
+	for rows.Next() {
+		var workspace model.UserWorkspace
+		err = rows.Scan(&workspace.ID, &workspace.Name, &workspace.CreatedAt, &workspace.Role)
+		if err != nil {
+			slog.Error(fmt.Sprintf("failed to scan workspace: %v", err))
+			return nil, ErrUnexpected
+		}
+		workspaces = append(workspaces, workspace)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to read workspaces: %v", err))
+		return nil, ErrUnexpected
+	}
+
+	return workspaces, nil
+}
+
+func (r *WorkspaceRepository) FindMember(ctx context.Context, workspaceID string, userID string) (model.Member, error) {
+	query := `SELECT workspace_id, user_id, role, joined_at FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`
+
+	var member model.Member
+	err := r.db.QueryRowContext(ctx, query, workspaceID, userID).Scan(&member.WorkspaceID, &member.UserID, &member.Role, &member.JoinedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Member{}, ErrNotFound
+		}
+
+		slog.Error(fmt.Sprintf("failed to find member: %v", err))
+		return model.Member{}, ErrUnexpected
+	}
+
+	return member, nil
+}
+
+// ListMembers returns the members of workspaceID in the order they joined.
+func (r *WorkspaceRepository) ListMembers(ctx context.Context, workspaceID string) ([]model.Member, error) {
+	query := `SELECT workspace_id, user_id, role, joined_at FROM workspace_members WHERE workspace_id = $1 ORDER BY joined_at, user_id`
+
+	rows, err := r.db.QueryContext(ctx, query, workspaceID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to list members: %v", err))
+		return nil, ErrUnexpected
+	}
+	defer rows.Close()
+
+	members := []model.Member{}
+	for rows.Next() {
+		var member model.Member
+		err = rows.Scan(&member.WorkspaceID, &member.UserID, &member.Role, &member.JoinedAt)
+		if err != nil {
+			slog.Error(fmt.Sprintf("failed to scan member: %v", err))
+			return nil, ErrUnexpected
+		}
+		members = append(members, member)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to read members: %v", err))
+		return nil, ErrUnexpected
+	}
+
+	return members, nil
+}
+
+func (r *WorkspaceRepository) UpdateMemberRole(ctx context.Context, workspaceID string, userID string, role model.Role) error {
+	query := `UPDATE workspace_members SET role = $3 WHERE workspace_id = $1 AND user_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, workspaceID, userID, role)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to update member role: %v", err))
+		return ErrUnexpected
+	}
+
+	return requireAffected(result)
+}
+
+func (r *WorkspaceRepository) DeleteMember(ctx context.Context, workspaceID string, userID string) error {
+	query := `DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, workspaceID, userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to delete member: %v", err))
+		return ErrUnexpected
+	}
+
+	return requireAffected(result)
+}
+
+func (r *WorkspaceRepository) SaveInvitation(ctx context.Context, invitation model.Invitation) error {
+	query := `INSERT INTO workspace_invitations (id, workspace_id, role, token_hash, invited_by, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := r.db.ExecContext(ctx, query, invitation.ID, invitation.WorkspaceID, invitation.Role, invitation.TokenHash, invitation.InvitedBy, invitation.CreatedAt, invitation.ExpiresAt)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to insert invitation: %v", err))
+		return ErrUnexpected
+	}
+
+	return nil
+}
+
+// AcceptInvitation uses up the invitation of tokenHash and makes userID a member with
+// its role, both in one statement so an invitation can't be accepted twice. Unknown,
+// used and expired invitations are not found.
+func (r *WorkspaceRepository) AcceptInvitation(ctx context.Context, tokenHash string, userID string, acceptedAt time.Time) (model.Member, error) {
+	query := `WITH invitation AS (
+			UPDATE workspace_invitations SET accepted_by = $2, accepted_at = $3
+			WHERE token_hash = $1 AND accepted_at IS NULL AND expires_at > $3
+			RETURNING workspace_id, role
+		)
+		INSERT INTO workspace_members (workspace_id, user_id, role, joined_at) SELECT workspace_id, $2, role, $3 FROM invitation
+		RETURNING workspace_id, user_id, role, joined_at`
+
+	var member model.Member
+	err := r.db.QueryRowContext(ctx, query, tokenHash, userID, acceptedAt).Scan(&member.WorkspaceID, &member.UserID, &member.Role, &member.JoinedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Member{}, ErrNotFound
+		}
+
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolationCode && pqErr.Constraint == workspaceMembersKeyConstraint {
+			return model.Member{}, ErrMemberAlreadyExists
+		}
+
+		slog.Error(fmt.Sprintf("failed to accept invitation: %v", err))
+		return model.Member{}, ErrUnexpected
+	}
+
+	return member, nil
+}
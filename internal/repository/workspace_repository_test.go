@@ -0,0 +1,305 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var memberRows = []string{"workspace_id", "user_id", "role", "joined_at"}
+
+func TestWorkspaceRepository_CreateWorkspace(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	workspace := model.Workspace{ID: "a-workspace-id", Name: "growth", CreatedAt: createdAt}
+	query := regexp.QuoteMeta(`WITH workspace AS (INSERT INTO workspaces (id, name, created_at) VALUES ($1, $2, $3) RETURNING id)
+		INSERT INTO workspace_members (workspace_id, user_id, role, joined_at) SELECT id, $4, $5, $3 FROM workspace`)
+
+	db, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbMock.ExpectExec(query).
+		WithArgs("a-workspace-id", "growth", createdAt, "a-user-id", "owner").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec(query).WillReturnError(errors.New("db error"))
+
+	r := NewWorkspaceRepository(db)
+
+	assert.NoError(t, r.CreateWorkspace(context.Background(), workspace, "a-user-id"))
+	assert.Equal(t, ErrUnexpected, r.CreateWorkspace(context.Background(), workspace, "a-user-id"))
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestWorkspaceRepository_ListWorkspaces(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`SELECT w.id, w.name, w.created_at, m.role FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE m.user_id = $1 ORDER BY w.created_at, w.id`)
+	columns := []string{"id", "name", "created_at", "role"}
+
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		want    []model.UserWorkspace
+		wantErr error
+	}{
+		{
+			name: "when db failed",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("a-user-id").WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when failed while reading rows",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("a-user-id").
+					WillReturnRows(sqlmock.NewRows(columns).AddRow("a-workspace-id", "growth", createdAt, "owner").RowError(0, errors.New("db error")))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when successfully lists the workspaces of the user",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("a-user-id").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("a-workspace-id", "growth", createdAt, "owner").
+						AddRow("b-workspace-id", "platform", createdAt, "viewer"))
+			},
+			want: []model.UserWorkspace{
+				{Workspace: model.Workspace{ID: "a-workspace-id", Name: "growth", CreatedAt: createdAt}, Role: model.RoleOwner},
+				{Workspace: model.Workspace{ID: "b-workspace-id", Name: "platform", CreatedAt: createdAt}, Role: model.RoleViewer},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewWorkspaceRepository(db)
+
+			got, err := r.ListWorkspaces(context.Background(), "a-user-id")
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestWorkspaceRepository_FindMember(t *testing.T) {
+	joinedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`SELECT workspace_id, user_id, role, joined_at FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`)
+
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		want    model.Member
+		wantErr error
+	}{
+		{
+			name: "when user is not a member",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("a-workspace-id", "a-user-id").WillReturnRows(sqlmock.NewRows(memberRows))
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "when db failed",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("a-workspace-id", "a-user-id").WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when member is found",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("a-workspace-id", "a-user-id").
+					WillReturnRows(sqlmock.NewRows(memberRows).AddRow("a-workspace-id", "a-user-id", "editor", joinedAt))
+			},
+			want: model.Member{WorkspaceID: "a-workspace-id", UserID: "a-user-id", Role: model.RoleEditor, JoinedAt: joinedAt},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewWorkspaceRepository(db)
+
+			got, err := r.FindMember(context.Background(), "a-workspace-id", "a-user-id")
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestWorkspaceRepository_ListMembers(t *testing.T) {
+	joinedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`SELECT workspace_id, user_id, role, joined_at FROM workspace_members WHERE workspace_id = $1 ORDER BY joined_at, user_id`)
+
+	db, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbMock.ExpectQuery(query).WithArgs("a-workspace-id").
+		WillReturnRows(sqlmock.NewRows(memberRows).
+			AddRow("a-workspace-id", "a-user-id", "owner", joinedAt).
+			AddRow("a-workspace-id", "b-user-id", "viewer", joinedAt))
+	dbMock.ExpectQuery(query).WithArgs("a-workspace-id").WillReturnError(errors.New("db error"))
+
+	r := NewWorkspaceRepository(db)
+
+	got, err := r.ListMembers(context.Background(), "a-workspace-id")
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Member{
+		{WorkspaceID: "a-workspace-id", UserID: "a-user-id", Role: model.RoleOwner, JoinedAt: joinedAt},
+		{WorkspaceID: "a-workspace-id", UserID: "b-user-id", Role: model.RoleViewer, JoinedAt: joinedAt},
+	}, got)
+
+	_, err = r.ListMembers(context.Background(), "a-workspace-id")
+	assert.Equal(t, ErrUnexpected, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestWorkspaceRepository_UpdateMemberRole(t *testing.T) {
+	query := regexp.QuoteMeta(`UPDATE workspace_members SET role = $3 WHERE workspace_id = $1 AND user_id = $2`)
+
+	db, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbMock.ExpectExec(query).WithArgs("a-workspace-id", "a-user-id", "admin").WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec(query).WithArgs("a-workspace-id", "b-user-id", "admin").WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectExec(query).WillReturnError(errors.New("db error"))
+
+	r := NewWorkspaceRepository(db)
+
+	assert.NoError(t, r.UpdateMemberRole(context.Background(), "a-workspace-id", "a-user-id", model.RoleAdmin))
+	assert.Equal(t, ErrNotFound, r.UpdateMemberRole(context.Background(), "a-workspace-id", "b-user-id", model.RoleAdmin))
+	assert.Equal(t, ErrUnexpected, r.UpdateMemberRole(context.Background(), "a-workspace-id", "a-user-id", model.RoleAdmin))
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestWorkspaceRepository_DeleteMember(t *testing.T) {
+	query := regexp.QuoteMeta(`DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`)
+
+	db, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbMock.ExpectExec(query).WithArgs("a-workspace-id", "a-user-id").WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec(query).WithArgs("a-workspace-id", "b-user-id").WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectExec(query).WillReturnError(errors.New("db error"))
+
+	r := NewWorkspaceRepository(db)
+
+	assert.NoError(t, r.DeleteMember(context.Background(), "a-workspace-id", "a-user-id"))
+	assert.Equal(t, ErrNotFound, r.DeleteMember(context.Background(), "a-workspace-id", "b-user-id"))
+	assert.Equal(t, ErrUnexpected, r.DeleteMember(context.Background(), "a-workspace-id", "a-user-id"))
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestWorkspaceRepository_SaveInvitation(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	invitation := model.Invitation{ID: "an-invitation-id", WorkspaceID: "a-workspace-id", Role: model.RoleEditor, TokenHash: "a-token-hash",
+		InvitedBy: "a-user-id", CreatedAt: createdAt, ExpiresAt: createdAt.Add(time.Hour)}
+	query := regexp.QuoteMeta(`INSERT INTO workspace_invitations (id, workspace_id, role, token_hash, invited_by, created_at, expires_at)`)
+
+	db, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbMock.ExpectExec(query).
+		WithArgs("an-invitation-id", "a-workspace-id", "editor", "a-token-hash", "a-user-id", createdAt, createdAt.Add(time.Hour)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec(query).WillReturnError(errors.New("db error"))
+
+	r := NewWorkspaceRepository(db)
+
+	assert.NoError(t, r.SaveInvitation(context.Background(), invitation))
+	assert.Equal(t, ErrUnexpected, r.SaveInvitation(context.Background(), invitation))
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestWorkspaceRepository_AcceptInvitation(t *testing.T) {
+	acceptedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`WITH invitation AS (
+			UPDATE workspace_invitations SET accepted_by = $2, accepted_at = $3
+			WHERE token_hash = $1 AND accepted_at IS NULL AND expires_at > $3
+			RETURNING workspace_id, role
+		)
+		INSERT INTO workspace_members (workspace_id, user_id, role, joined_at) SELECT workspace_id, $2, role, $3 FROM invitation
+		RETURNING workspace_id, user_id, role, joined_at`)
+
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		want    model.Member
+		wantErr error
+	}{
+		{
+			name: "when invitation is unknown, used or expired",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("a-token-hash", "a-user-id", acceptedAt).WillReturnRows(sqlmock.NewRows(memberRows))
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "when user is already a member",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("a-token-hash", "a-user-id", acceptedAt).
+					WillReturnError(&pq.Error{Code: "23505", Constraint: "workspace_members_pkey"})
+			},
+			wantErr: ErrMemberAlreadyExists,
+		},
+		{
+			name: "when db failed",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("a-token-hash", "a-user-id", acceptedAt).WillReturnError(errors.New("db error"))
+			},
+			wantErr: ErrUnexpected,
+		},
+		{
+			name: "when successfully joins the workspace",
+			setup: func(s sqlmock.Sqlmock) {
+				s.ExpectQuery(query).WithArgs("a-token-hash", "a-user-id", acceptedAt).
+					WillReturnRows(sqlmock.NewRows(memberRows).AddRow("a-workspace-id", "a-user-id", "editor", acceptedAt))
+			},
+			want: model.Member{WorkspaceID: "a-workspace-id", UserID: "a-user-id", Role: model.RoleEditor, JoinedAt: acceptedAt},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			tt.setup(dbMock)
+
+			r := NewWorkspaceRepository(db)
+
+			got, err := r.AcceptInvitation(context.Background(), "a-token-hash", "a-user-id", acceptedAt)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
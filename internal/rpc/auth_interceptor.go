@@ -0,0 +1,65 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	urlshortenerv1 "github.com/ggoulart/url-shortener/api/proto/urlshortener/v1"
+	"github.com/ggoulart/url-shortener/internal/middleware"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/ggoulart/url-shortener/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// methodScopes is the scope each method needs, the ones left out are public like
+// their HTTP routes: Resolve, health and reflection.
+var methodScopes = map[string]model.Scope{
+	urlshortenerv1.UrlShortener_Shorten_FullMethodName: model.ScopeCreate,
+	urlshortenerv1.UrlShortener_GetLink_FullMethodName: model.ScopeRead,
+}
+
+// workspaceMetadata is the metadata form of middleware.WorkspaceHeader.
+const workspaceMetadata = "x-workspace-id"
+
+// AuthInterceptor is the gRPC counterpart of middleware.Authenticate and
+// middleware.RequireScope, the credentials come as "authorization: Bearer" metadata
+// and the workspace as "x-workspace-id".
+func AuthInterceptor(authenticator middleware.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var principal model.Principal
+		var authenticated bool
+
+		if values := metadata.ValueFromIncomingContext(ctx, "authorization"); len(values) > 0 {
+			scheme, secret, _ := strings.Cut(values[0], " ")
+			secret = strings.TrimSpace(secret)
+			if !strings.EqualFold(scheme, "Bearer") || secret == "" {
+				return nil, middleware.ErrUnauthenticated.WithDetail("authorization must be a Bearer api key or session token")
+			}
+
+			var err error
+			principal, err = authenticator.Authenticate(ctx, secret)
+			if err != nil {
+				return nil, err
+			}
+
+			if values := metadata.ValueFromIncomingContext(ctx, workspaceMetadata); len(values) > 0 {
+				principal.WorkspaceID = strings.TrimSpace(values[0])
+			}
+
+			ctx = service.ContextWithPrincipal(ctx, principal)
+			authenticated = true
+		}
+
+		scope, ok := methodScopes[info.FullMethod]
+		if ok && !authenticated {
+			return nil, middleware.ErrUnauthenticated
+		}
+		if ok && !principal.HasScope(scope) {
+			return nil, middleware.ErrForbidden.WithDetail(fmt.Sprintf("credentials need the %s scope", scope))
+		}
+
+		return handler(ctx, req)
+	}
+}
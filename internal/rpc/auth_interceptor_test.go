@@ -0,0 +1,122 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	urlshortenerv1 "github.com/ggoulart/url-shortener/api/proto/urlshortener/v1"
+	"github.com/ggoulart/url-shortener/internal/middleware"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/ggoulart/url-shortener/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestAuthInterceptor(t *testing.T) {
+	reader := model.Principal{KeyID: "a-key-id", Name: "dashboard", Scopes: []model.Scope{model.ScopeRead}}
+
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		setup         func(*MockAuthenticator)
+		want          any
+		wantErr       error
+	}{
+		{
+			name:   "when method is public and there are no credentials",
+			method: urlshortenerv1.UrlShortener_Resolve_FullMethodName,
+			setup:  func(*MockAuthenticator) {},
+			want:   "anonymous",
+		},
+		{
+			name:    "when method needs a scope and there are no credentials",
+			method:  urlshortenerv1.UrlShortener_Shorten_FullMethodName,
+			setup:   func(*MockAuthenticator) {},
+			wantErr: middleware.ErrUnauthenticated,
+		},
+		{
+			name:          "when credentials are not a bearer key",
+			method:        urlshortenerv1.UrlShortener_Resolve_FullMethodName,
+			authorization: "usk_reader",
+			setup:         func(*MockAuthenticator) {},
+			wantErr:       middleware.ErrUnauthenticated.WithDetail("authorization must be a Bearer api key or session token"),
+		},
+		{
+			name:          "when key is invalid",
+			method:        urlshortenerv1.UrlShortener_Resolve_FullMethodName,
+			authorization: "Bearer usk_revoked",
+			setup: func(m *MockAuthenticator) {
+				m.On("Authenticate", mock.Anything, "usk_revoked").Return(model.Principal{}, service.ErrInvalidAPIKey)
+			},
+			wantErr: service.ErrInvalidAPIKey,
+		},
+		{
+			name:          "when key lacks the scope",
+			method:        urlshortenerv1.UrlShortener_Shorten_FullMethodName,
+			authorization: "Bearer usk_reader",
+			setup: func(m *MockAuthenticator) {
+				m.On("Authenticate", mock.Anything, "usk_reader").Return(reader, nil)
+			},
+			wantErr: middleware.ErrForbidden.WithDetail("credentials need the create scope"),
+		},
+		{
+			name:          "when key holds the scope",
+			method:        urlshortenerv1.UrlShortener_GetLink_FullMethodName,
+			authorization: "Bearer usk_reader",
+			setup: func(m *MockAuthenticator) {
+				m.On("Authenticate", mock.Anything, "usk_reader").Return(reader, nil)
+			},
+			want: "dashboard",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator := &MockAuthenticator{}
+			tt.setup(authenticator)
+
+			ctx := context.Background()
+			if tt.authorization != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tt.authorization))
+			}
+			whoami := func(ctx context.Context, req any) (any, error) {
+				principal, ok := service.PrincipalFromContext(ctx)
+				if !ok {
+					return "anonymous", nil
+				}
+				return principal.Name, nil
+			}
+
+			got, err := AuthInterceptor(authenticator)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, whoami)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestAuthInterceptor_Workspace(t *testing.T) {
+	authenticator := &MockAuthenticator{}
+	authenticator.On("Authenticate", mock.Anything, "uss_session").Return(model.Principal{UserID: "a-user-id", Scopes: []model.Scope{model.ScopeCreate}}, nil)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer uss_session", "x-workspace-id", "a-workspace-id"))
+	workspace := func(ctx context.Context, req any) (any, error) {
+		principal, _ := service.PrincipalFromContext(ctx)
+		return principal.WorkspaceID, nil
+	}
+
+	got, err := AuthInterceptor(authenticator)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: urlshortenerv1.UrlShortener_Shorten_FullMethodName}, workspace)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "a-workspace-id", got)
+}
+
+type MockAuthenticator struct {
+	mock.Mock
+}
+
+func (m *MockAuthenticator) Authenticate(ctx context.Context, secret string) (model.Principal, error) {
+	args := m.Called(ctx, secret)
+	return args.Get(0).(model.Principal), args.Error(1)
+}
@@ -0,0 +1,96 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// ErrorDomain names this service in the ErrorInfo details of application errors.
+const ErrorDomain = "url-shortener"
+
+// ErrorInterceptor is the gRPC counterpart of middleware.ErrorHandler, it turns the
+// errors handlers return into statuses with the matching code.
+func ErrorInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, Status(err).Err()
+		}
+
+		return resp, nil
+	}
+}
+
+// Status carries the stable code of application errors as the ErrorInfo reason,
+// their field errors as BadRequest details and when to retry as RetryInfo. Other
+// errors are logged and answered with a bare Internal status, like the HTTP API
+// does.
+func Status(err error) *status.Status {
+	if s, ok := status.FromError(err); ok {
+		return s
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err)
+	}
+
+	appErr, ok := apperror.As(err)
+	if !ok {
+		slog.Error(fmt.Sprintf("unhandled grpc error: %v", err))
+		return status.New(codes.Internal, "internal error")
+	}
+
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: appErr.Code, Domain: ErrorDomain}}
+	if len(appErr.Fields) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(appErr.Fields))
+		for _, field := range appErr.Fields {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: field.Field, Description: field.Reason})
+		}
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+	if appErr.RetryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(appErr.RetryAfter)})
+	}
+
+	s := status.New(code(appErr.Status), err.Error())
+	detailed, detailsErr := s.WithDetails(details...)
+	if detailsErr != nil {
+		return s
+	}
+
+	return detailed
+}
+
+func code(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusGone, http.StatusUnprocessableEntity:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
@@ -0,0 +1,151 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/controller"
+	"github.com/ggoulart/url-shortener/internal/repository"
+	"github.com/ggoulart/url-shortener/internal/service"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		expectedCode    codes.Code
+		expectedMessage string
+	}{
+		{
+			name:            "bad request error",
+			err:             controller.ErrBadRequest,
+			expectedCode:    codes.InvalidArgument,
+			expectedMessage: controller.ErrBadRequest.Error(),
+		},
+		{
+			name:            "invalid long url error",
+			err:             controller.ErrInvalidLongURL,
+			expectedCode:    codes.InvalidArgument,
+			expectedMessage: controller.ErrInvalidLongURL.Error(),
+		},
+		{
+			name:            "invalid alias error",
+			err:             service.ErrInvalidAlias,
+			expectedCode:    codes.InvalidArgument,
+			expectedMessage: service.ErrInvalidAlias.Error(),
+		},
+		{
+			name:            "invalid management token error",
+			err:             service.ErrInvalidManagementToken,
+			expectedCode:    codes.Unauthenticated,
+			expectedMessage: service.ErrInvalidManagementToken.Error(),
+		},
+		{
+			name:            "wrapped not found error",
+			err:             fmt.Errorf("failed to find link: %w", repository.ErrNotFound),
+			expectedCode:    codes.NotFound,
+			expectedMessage: "failed to find link: " + repository.ErrNotFound.Error(),
+		},
+		{
+			name:            "alias taken error",
+			err:             service.ErrAliasTaken,
+			expectedCode:    codes.AlreadyExists,
+			expectedMessage: service.ErrAliasTaken.Error(),
+		},
+		{
+			name:            "link disabled error",
+			err:             service.ErrLinkDisabled,
+			expectedCode:    codes.FailedPrecondition,
+			expectedMessage: service.ErrLinkDisabled.Error(),
+		},
+		{
+			name:            "deadline exceeded error",
+			err:             context.DeadlineExceeded,
+			expectedCode:    codes.DeadlineExceeded,
+			expectedMessage: context.DeadlineExceeded.Error(),
+		},
+		{
+			name:            "status error is kept",
+			err:             status.Error(codes.Unavailable, "try again"),
+			expectedCode:    codes.Unavailable,
+			expectedMessage: "try again",
+		},
+		{
+			name:            "unknown error",
+			err:             errors.New("unknown error"),
+			expectedCode:    codes.Internal,
+			expectedMessage: "internal error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Status(tt.err)
+
+			assert.Equal(t, tt.expectedCode, got.Code())
+			assert.Equal(t, tt.expectedMessage, got.Message())
+		})
+	}
+}
+
+func TestStatus_PolicyViolation(t *testing.T) {
+	got := Status(service.ErrPrivateDestination)
+
+	assert.Equal(t, codes.FailedPrecondition, got.Code())
+	assert.Equal(t, service.ErrPrivateDestination.Error(), got.Message())
+	if assert.Len(t, got.Details(), 1) {
+		info := got.Details()[0].(*errdetails.ErrorInfo)
+		assert.Equal(t, service.ErrPrivateDestination.Code, info.Reason)
+		assert.Equal(t, ErrorDomain, info.Domain)
+	}
+}
+
+func TestStatus_FieldErrors(t *testing.T) {
+	got := Status(controller.ErrInvalidTTL.WithFields(apperror.FieldError{Field: "ttl", Reason: "must be a valid duration"}))
+
+	assert.Equal(t, codes.InvalidArgument, got.Code())
+	if assert.Len(t, got.Details(), 2) {
+		assert.Equal(t, "invalid_ttl", got.Details()[0].(*errdetails.ErrorInfo).Reason)
+		violations := got.Details()[1].(*errdetails.BadRequest).GetFieldViolations()
+		if assert.Len(t, violations, 1) {
+			assert.Equal(t, "ttl", violations[0].GetField())
+			assert.Equal(t, "must be a valid duration", violations[0].GetDescription())
+		}
+	}
+}
+
+func TestStatus_RetryAfter(t *testing.T) {
+	got := Status(service.ErrQuotaExceeded.WithRetryAfter(90 * time.Minute))
+
+	assert.Equal(t, codes.ResourceExhausted, got.Code())
+	if assert.Len(t, got.Details(), 2) {
+		assert.Equal(t, "quota_exceeded", got.Details()[0].(*errdetails.ErrorInfo).Reason)
+		assert.Equal(t, 90*time.Minute, got.Details()[1].(*errdetails.RetryInfo).GetRetryDelay().AsDuration())
+	}
+}
+
+func TestErrorInterceptor(t *testing.T) {
+	interceptor := ErrorInterceptor()
+
+	resp, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+		return nil, repository.ErrNotFound
+	})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	resp, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+		return "ok", nil
+	})
+
+	assert.Equal(t, "ok", resp)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,37 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/ggoulart/url-shortener/internal/middleware"
+	"github.com/ggoulart/url-shortener/internal/service"
+	"google.golang.org/grpc"
+)
+
+// RateLimitInterceptor is the gRPC counterpart of middleware.RateLimit, limits holds
+// the limiter of each method and the others go unlimited. Clients are kept apart the
+// same way, by key or user and by peer IP for anonymous ones, so a limiter shared
+// with the HTTP API holds one budget for both. It has to run after AuthInterceptor.
+func RateLimitInterceptor(limits map[string]middleware.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		limiter, ok := limits[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		decision := limiter.Allow(clientKey(ctx))
+		if !decision.Allowed {
+			return nil, middleware.ErrRateLimited.WithRetryAfter(decision.RetryAfter)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func clientKey(ctx context.Context) string {
+	principal, ok := service.PrincipalFromContext(ctx)
+	if ok {
+		return principal.Subject()
+	}
+	return "ip:" + clientIP(ctx)
+}
@@ -0,0 +1,83 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	urlshortenerv1 "github.com/ggoulart/url-shortener/api/proto/urlshortener/v1"
+	"github.com/ggoulart/url-shortener/internal/middleware"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/ggoulart/url-shortener/internal/ratelimit"
+	"github.com/ggoulart/url-shortener/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+func TestRateLimitInterceptor(t *testing.T) {
+	anonymous := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 51234}})
+	authenticated := service.ContextWithPrincipal(anonymous, model.Principal{KeyID: "a-key-id"})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		method  string
+		setup   func(*MockLimiter)
+		want    any
+		wantErr error
+	}{
+		{
+			name:   "when method is not limited",
+			ctx:    anonymous,
+			method: urlshortenerv1.UrlShortener_GetLink_FullMethodName,
+			setup:  func(*MockLimiter) {},
+			want:   "handled",
+		},
+		{
+			name:   "when anonymous client is within its limit",
+			ctx:    anonymous,
+			method: urlshortenerv1.UrlShortener_Resolve_FullMethodName,
+			setup: func(m *MockLimiter) {
+				m.On("Allow", "ip:192.0.2.1").Return(ratelimit.Decision{Allowed: true})
+			},
+			want: "handled",
+		},
+		{
+			name:   "when key is over its limit",
+			ctx:    authenticated,
+			method: urlshortenerv1.UrlShortener_Resolve_FullMethodName,
+			setup: func(m *MockLimiter) {
+				m.On("Allow", "key:a-key-id").Return(ratelimit.Decision{RetryAfter: 3 * time.Second})
+			},
+			wantErr: middleware.ErrRateLimited.WithRetryAfter(3 * time.Second),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := &MockLimiter{}
+			tt.setup(limiter)
+			handled := func(context.Context, any) (any, error) {
+				return "handled", nil
+			}
+
+			interceptor := RateLimitInterceptor(map[string]middleware.Limiter{urlshortenerv1.UrlShortener_Resolve_FullMethodName: limiter})
+			got, err := interceptor(tt.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handled)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+			limiter.AssertExpectations(t)
+		})
+	}
+}
+
+type MockLimiter struct {
+	mock.Mock
+}
+
+func (m *MockLimiter) Allow(key string) ratelimit.Decision {
+	args := m.Called(key)
+	return args.Get(0).(ratelimit.Decision)
+}
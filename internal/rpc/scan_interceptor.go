@@ -0,0 +1,55 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	urlshortenerv1 "github.com/ggoulart/url-shortener/api/proto/urlshortener/v1"
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ScanInterceptor is the gRPC counterpart of middleware.DetectScanning, it holds up
+// peers whose Resolve calls mostly ask for links that don't exist. Clients are told
+// apart by peer IP, so a detector shared with the HTTP API sees both.
+func ScanInterceptor(detector middleware.ScanDetector) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if info.FullMethod != urlshortenerv1.UrlShortener_Resolve_FullMethodName {
+			return handler(ctx, req)
+		}
+
+		ip := clientIP(ctx)
+
+		verdict := detector.Check(ip)
+		if verdict.Blocked {
+			return nil, middleware.ErrScanningBlocked.WithRetryAfter(verdict.RetryAfter)
+		}
+
+		if verdict.Delay > 0 {
+			timer := time.NewTimer(verdict.Delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			}
+		}
+
+		resp, err := handler(ctx, req)
+
+		detector.Record(ip, missed(err))
+
+		return resp, err
+	}
+}
+
+func missed(err error) bool {
+	if appErr, ok := apperror.As(err); ok {
+		return appErr.Status == http.StatusNotFound
+	}
+	return status.Code(err) == codes.NotFound
+}
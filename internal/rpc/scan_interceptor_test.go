@@ -0,0 +1,94 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	urlshortenerv1 "github.com/ggoulart/url-shortener/api/proto/urlshortener/v1"
+	"github.com/ggoulart/url-shortener/internal/middleware"
+	"github.com/ggoulart/url-shortener/internal/ratelimit"
+	"github.com/ggoulart/url-shortener/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+func TestScanInterceptor(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		handlerErr error
+		setup      func(*MockScanDetector)
+		want       any
+		wantErr    error
+	}{
+		{
+			name:   "when method is not resolve",
+			method: urlshortenerv1.UrlShortener_GetLink_FullMethodName,
+			setup:  func(*MockScanDetector) {},
+			want:   "handled",
+		},
+		{
+			name:   "when the link exists",
+			method: urlshortenerv1.UrlShortener_Resolve_FullMethodName,
+			setup: func(m *MockScanDetector) {
+				m.On("Check", "192.0.2.1").Return(ratelimit.Verdict{})
+				m.On("Record", "192.0.2.1", false).Return()
+			},
+			want: "handled",
+		},
+		{
+			name:       "when the link does not exist",
+			method:     urlshortenerv1.UrlShortener_Resolve_FullMethodName,
+			handlerErr: repository.ErrNotFound,
+			setup: func(m *MockScanDetector) {
+				m.On("Check", "192.0.2.1").Return(ratelimit.Verdict{Delay: time.Millisecond})
+				m.On("Record", "192.0.2.1", true).Return()
+			},
+			wantErr: repository.ErrNotFound,
+		},
+		{
+			name:   "when the client is blocked",
+			method: urlshortenerv1.UrlShortener_Resolve_FullMethodName,
+			setup: func(m *MockScanDetector) {
+				m.On("Check", "192.0.2.1").Return(ratelimit.Verdict{Blocked: true, RetryAfter: 10 * time.Minute})
+			},
+			wantErr: middleware.ErrScanningBlocked.WithRetryAfter(10 * time.Minute),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := &MockScanDetector{}
+			tt.setup(detector)
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 51234}})
+			handler := func(context.Context, any) (any, error) {
+				if tt.handlerErr != nil {
+					return nil, tt.handlerErr
+				}
+				return "handled", nil
+			}
+
+			got, err := ScanInterceptor(detector)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+			detector.AssertExpectations(t)
+		})
+	}
+}
+
+type MockScanDetector struct {
+	mock.Mock
+}
+
+func (m *MockScanDetector) Check(ip string) ratelimit.Verdict {
+	args := m.Called(ip)
+	return args.Get(0).(ratelimit.Verdict)
+}
+
+func (m *MockScanDetector) Record(ip string, missed bool) {
+	m.Called(ip, missed)
+}
@@ -0,0 +1,115 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	urlshortenerv1 "github.com/ggoulart/url-shortener/api/proto/urlshortener/v1"
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/controller"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// UrlShortenerServer serves the gRPC API on top of the same services the HTTP
+// controllers use, errors are turned into statuses by ErrorInterceptor.
+type UrlShortenerServer struct {
+	urlshortenerv1.UnimplementedUrlShortenerServer
+	shortener controller.ShortenerService
+	stats     controller.StatsService
+	clicks    controller.ClickRecorder
+}
+
+func NewUrlShortenerServer(shortener controller.ShortenerService, stats controller.StatsService, clicks controller.ClickRecorder) *UrlShortenerServer {
+	return &UrlShortenerServer{shortener: shortener, stats: stats, clicks: clicks}
+}
+
+func (s *UrlShortenerServer) Shorten(ctx context.Context, req *urlshortenerv1.ShortenRequest) (*urlshortenerv1.ShortenResponse, error) {
+	longURL, err := url.ParseRequestURI(req.GetLongUrl())
+	if err != nil {
+		slog.Warn(fmt.Sprintf("failed to parse url: %v", err))
+		return nil, controller.ErrInvalidLongURL.WithFields(apperror.FieldError{Field: "long_url", Reason: "must be an absolute url"})
+	}
+
+	options := model.ShortenOptions{Alias: req.GetAlias()}
+	if req.ExpiresAt != nil {
+		err = req.ExpiresAt.CheckValid()
+		if err != nil {
+			slog.Warn(fmt.Sprintf("failed to parse expiresAt: %v", err))
+			return nil, controller.ErrBadRequest.WithFields(apperror.FieldError{Field: "expires_at", Reason: "must be a valid timestamp"})
+		}
+		expiresAt := req.ExpiresAt.AsTime()
+		options.ExpiresAt = &expiresAt
+	}
+	if req.Ttl != nil {
+		err = req.Ttl.CheckValid()
+		if err != nil {
+			slog.Warn(fmt.Sprintf("failed to parse ttl: %v", err))
+			return nil, controller.ErrInvalidTTL.WithFields(apperror.FieldError{Field: "ttl", Reason: "must be a valid duration"})
+		}
+		options.TTL = req.Ttl.AsDuration()
+	}
+
+	result, err := s.shortener.Shortener(ctx, *longURL, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &urlshortenerv1.ShortenResponse{ShortUrl: result.ShortURL.String(), ManagementToken: result.ManagementToken}, nil
+}
+
+func (s *UrlShortenerServer) Resolve(ctx context.Context, req *urlshortenerv1.ResolveRequest) (*urlshortenerv1.ResolveResponse, error) {
+	longURL, err := s.shortener.Retrieve(ctx, req.GetEncodedKey())
+	if err != nil {
+		return nil, err
+	}
+
+	s.clicks.Record(model.Click{
+		EncodedKey: req.GetEncodedKey(),
+		ClickedAt:  time.Now(),
+		UserAgent:  strings.Join(metadata.ValueFromIncomingContext(ctx, "user-agent"), " "),
+		ClientIP:   clientIP(ctx),
+	})
+
+	return &urlshortenerv1.ResolveResponse{LongUrl: longURL.String()}, nil
+}
+
+func (s *UrlShortenerServer) GetLink(ctx context.Context, req *urlshortenerv1.GetLinkRequest) (*urlshortenerv1.GetLinkResponse, error) {
+	linkMetadata, err := s.stats.Inspect(ctx, req.GetEncodedKey())
+	if err != nil {
+		return nil, err
+	}
+
+	link := &urlshortenerv1.Link{
+		EncodedKey:  linkMetadata.EncodedKey,
+		LongUrl:     linkMetadata.LongURL.String(),
+		CreatedAt:   timestamppb.New(linkMetadata.CreatedAt),
+		TotalClicks: linkMetadata.TotalClicks,
+	}
+	if linkMetadata.ExpiresAt != nil {
+		link.ExpiresAt = timestamppb.New(*linkMetadata.ExpiresAt)
+	}
+
+	return &urlshortenerv1.GetLinkResponse{Link: link}, nil
+}
+
+func clientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
@@ -0,0 +1,275 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	urlshortenerv1 "github.com/ggoulart/url-shortener/api/proto/urlshortener/v1"
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/controller"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/ggoulart/url-shortener/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestUrlShortenerServer_Shorten(t *testing.T) {
+	longURL := url.URL{Scheme: "https", Host: "bytebytego.com"}
+	shortURL := url.URL{Scheme: "https", Host: "gg.com", Path: "/shorten"}
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		req     *urlshortenerv1.ShortenRequest
+		setup   func(*MockShortenerService)
+		want    *urlshortenerv1.ShortenResponse
+		wantErr error
+	}{
+		{
+			name:    "when long url is invalid",
+			req:     &urlshortenerv1.ShortenRequest{LongUrl: "bytebytego"},
+			setup:   func(*MockShortenerService) {},
+			wantErr: controller.ErrInvalidLongURL.WithFields(apperror.FieldError{Field: "long_url", Reason: "must be an absolute url"}),
+		},
+		{
+			name:    "when expiration is invalid",
+			req:     &urlshortenerv1.ShortenRequest{LongUrl: "https://bytebytego.com", ExpiresAt: &timestamppb.Timestamp{Nanos: -1}},
+			setup:   func(*MockShortenerService) {},
+			wantErr: controller.ErrBadRequest.WithFields(apperror.FieldError{Field: "expires_at", Reason: "must be a valid timestamp"}),
+		},
+		{
+			name:    "when ttl is invalid",
+			req:     &urlshortenerv1.ShortenRequest{LongUrl: "https://bytebytego.com", Ttl: &durationpb.Duration{Seconds: 1, Nanos: -1}},
+			setup:   func(*MockShortenerService) {},
+			wantErr: controller.ErrInvalidTTL.WithFields(apperror.FieldError{Field: "ttl", Reason: "must be a valid duration"}),
+		},
+		{
+			name: "when shortener service failed",
+			req:  &urlshortenerv1.ShortenRequest{LongUrl: "https://bytebytego.com"},
+			setup: func(m *MockShortenerService) {
+				m.On("Shortener", mock.Anything, longURL, model.ShortenOptions{}).Return(model.ShortenResult{}, errors.New("shortener service failed"))
+			},
+			wantErr: errors.New("shortener service failed"),
+		},
+		{
+			name: "when successfully shortens url with options",
+			req: &urlshortenerv1.ShortenRequest{
+				LongUrl:   "https://bytebytego.com",
+				Alias:     "launch-2026",
+				ExpiresAt: timestamppb.New(expiresAt),
+				Ttl:       durationpb.New(72 * time.Hour),
+			},
+			setup: func(m *MockShortenerService) {
+				options := model.ShortenOptions{Alias: "launch-2026", ExpiresAt: &expiresAt, TTL: 72 * time.Hour}
+				m.On("Shortener", mock.Anything, longURL, options).Return(model.ShortenResult{ShortURL: shortURL, ManagementToken: "a-management-token"}, nil)
+			},
+			want: &urlshortenerv1.ShortenResponse{ShortUrl: "https://gg.com/shorten", ManagementToken: "a-management-token"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockShortenerService{}
+			tt.setup(m)
+			s := NewUrlShortenerServer(m, &MockStatsService{}, &MockClickRecorder{})
+
+			got, err := s.Shorten(context.Background(), tt.req)
+
+			assert.True(t, proto.Equal(tt.want, got), "got %v", got)
+			assert.Equal(t, tt.wantErr, err)
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUrlShortenerServer_Resolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(*MockShortenerService, *MockClickRecorder)
+		want    *urlshortenerv1.ResolveResponse
+		wantErr error
+	}{
+		{
+			name: "when link is not found",
+			setup: func(m *MockShortenerService, _ *MockClickRecorder) {
+				m.On("Retrieve", mock.Anything, "abc1234").Return(url.URL{}, repository.ErrNotFound)
+			},
+			wantErr: repository.ErrNotFound,
+		},
+		{
+			name: "when link is resolved a click is recorded",
+			setup: func(m *MockShortenerService, r *MockClickRecorder) {
+				m.On("Retrieve", mock.Anything, "abc1234").Return(url.URL{Scheme: "https", Host: "bytebytego.com"}, nil)
+				r.On("Record", mock.MatchedBy(func(click model.Click) bool {
+					return click.EncodedKey == "abc1234" && click.UserAgent == "grpc-go/1.72.0" && click.ClientIP == "10.0.0.1" && !click.ClickedAt.IsZero()
+				})).Return(true)
+			},
+			want: &urlshortenerv1.ResolveResponse{LongUrl: "https://bytebytego.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockShortenerService{}
+			r := &MockClickRecorder{}
+			tt.setup(m, r)
+			s := NewUrlShortenerServer(m, &MockStatsService{}, r)
+
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("user-agent", "grpc-go/1.72.0"))
+			ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 51234}})
+
+			got, err := s.Resolve(ctx, &urlshortenerv1.ResolveRequest{EncodedKey: "abc1234"})
+
+			assert.True(t, proto.Equal(tt.want, got), "got %v", got)
+			assert.Equal(t, tt.wantErr, err)
+			m.AssertExpectations(t)
+			r.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUrlShortenerServer_GetLink(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiresAt := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		setup   func(*MockStatsService)
+		want    *urlshortenerv1.GetLinkResponse
+		wantErr error
+	}{
+		{
+			name: "when link is not found",
+			setup: func(m *MockStatsService) {
+				m.On("Inspect", mock.Anything, "abc1234").Return(model.LinkMetadata{}, repository.ErrNotFound)
+			},
+			wantErr: repository.ErrNotFound,
+		},
+		{
+			name: "when link never expires",
+			setup: func(m *MockStatsService) {
+				m.On("Inspect", mock.Anything, "abc1234").Return(model.LinkMetadata{
+					EncodedKey:  "abc1234",
+					LongURL:     url.URL{Scheme: "https", Host: "bytebytego.com"},
+					CreatedAt:   createdAt,
+					TotalClicks: 3,
+				}, nil)
+			},
+			want: &urlshortenerv1.GetLinkResponse{Link: &urlshortenerv1.Link{
+				EncodedKey:  "abc1234",
+				LongUrl:     "https://bytebytego.com",
+				CreatedAt:   timestamppb.New(createdAt),
+				TotalClicks: 3,
+			}},
+		},
+		{
+			name: "when link expires",
+			setup: func(m *MockStatsService) {
+				m.On("Inspect", mock.Anything, "abc1234").Return(model.LinkMetadata{
+					EncodedKey: "abc1234",
+					LongURL:    url.URL{Scheme: "https", Host: "bytebytego.com"},
+					CreatedAt:  createdAt,
+					ExpiresAt:  &expiresAt,
+				}, nil)
+			},
+			want: &urlshortenerv1.GetLinkResponse{Link: &urlshortenerv1.Link{
+				EncodedKey: "abc1234",
+				LongUrl:    "https://bytebytego.com",
+				CreatedAt:  timestamppb.New(createdAt),
+				ExpiresAt:  timestamppb.New(expiresAt),
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockStatsService{}
+			tt.setup(m)
+			s := NewUrlShortenerServer(&MockShortenerService{}, m, &MockClickRecorder{})
+
+			got, err := s.GetLink(context.Background(), &urlshortenerv1.GetLinkRequest{EncodedKey: "abc1234"})
+
+			assert.True(t, proto.Equal(tt.want, got), "got %v", got)
+			assert.Equal(t, tt.wantErr, err)
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+type MockShortenerService struct {
+	mock.Mock
+}
+
+func (s *MockShortenerService) Retrieve(ctx context.Context, encodedKey string) (url.URL, error) {
+	args := s.Called(ctx, encodedKey)
+	return args.Get(0).(url.URL), args.Error(1)
+}
+
+func (s *MockShortenerService) Shortener(ctx context.Context, shortURL url.URL, options model.ShortenOptions) (model.ShortenResult, error) {
+	args := s.Called(ctx, shortURL, options)
+	return args.Get(0).(model.ShortenResult), args.Error(1)
+}
+
+func (s *MockShortenerService) ShortenBatch(ctx context.Context, items []model.BatchItem) ([]model.BatchResult, error) {
+	args := s.Called(ctx, items)
+	return args.Get(0).([]model.BatchResult), args.Error(1)
+}
+
+func (s *MockShortenerService) ListLinks(ctx context.Context, filter model.LinkFilter, cursor string) (model.LinkPage, error) {
+	args := s.Called(ctx, filter, cursor)
+	return args.Get(0).(model.LinkPage), args.Error(1)
+}
+
+func (s *MockShortenerService) DeleteLink(ctx context.Context, encodedKey string, token string) error {
+	args := s.Called(ctx, encodedKey, token)
+	return args.Error(0)
+}
+
+func (s *MockShortenerService) DisableLink(ctx context.Context, encodedKey string, token string) error {
+	args := s.Called(ctx, encodedKey, token)
+	return args.Error(0)
+}
+
+func (s *MockShortenerService) UpdateDestination(ctx context.Context, encodedKey string, token string, longURL url.URL, actor string) error {
+	args := s.Called(ctx, encodedKey, token, longURL, actor)
+	return args.Error(0)
+}
+
+func (s *MockShortenerService) History(ctx context.Context, encodedKey string, token string) ([]model.HistoryEntry, error) {
+	args := s.Called(ctx, encodedKey, token)
+	return args.Get(0).([]model.HistoryEntry), args.Error(1)
+}
+
+func (s *MockShortenerService) Rollback(ctx context.Context, encodedKey string, token string, version int, actor string) error {
+	args := s.Called(ctx, encodedKey, token, version, actor)
+	return args.Error(0)
+}
+
+type MockStatsService struct {
+	mock.Mock
+}
+
+func (s *MockStatsService) Stats(ctx context.Context, encodedKey string, query model.StatsQuery) (model.LinkStats, error) {
+	args := s.Called(ctx, encodedKey, query)
+	return args.Get(0).(model.LinkStats), args.Error(1)
+}
+
+func (s *MockStatsService) Inspect(ctx context.Context, encodedKey string) (model.LinkMetadata, error) {
+	args := s.Called(ctx, encodedKey)
+	return args.Get(0).(model.LinkMetadata), args.Error(1)
+}
+
+type MockClickRecorder struct {
+	mock.Mock
+}
+
+func (r *MockClickRecorder) Record(click model.Click) bool {
+	args := r.Called(click)
+	return args.Bool(0)
+}
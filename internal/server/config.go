@@ -0,0 +1,49 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+type Config struct {
+	Address           string        `mapstructure:"ADDRESS"`
+	GRPCAddress       string        `mapstructure:"GRPC_ADDRESS"`
+	ReadTimeout       time.Duration `mapstructure:"READ_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `mapstructure:"READ_HEADER_TIMEOUT"`
+	WriteTimeout      time.Duration `mapstructure:"WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `mapstructure:"IDLE_TIMEOUT"`
+	MaxHeaderBytes    int           `mapstructure:"MAX_HEADER_BYTES"`
+	ShutdownTimeout   time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	// TrustedProxies lists the proxy IPs or CIDRs whose X-Forwarded-For is believed.
+	// None by default, clients are then told apart by the address they connect from.
+	TrustedProxies []string `mapstructure:"TRUSTED_PROXIES"`
+}
+
+func NewConfig() (*Config, error) {
+	config := &Config{
+		Address:           ":8080",
+		GRPCAddress:       ":9090",
+		ReadTimeout:       5 * time.Second,
+		ReadHeaderTimeout: 2 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		MaxHeaderBytes:    1 << 20,
+		ShutdownTimeout:   15 * time.Second,
+	}
+	err := viper.UnmarshalKey("server", config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server config: %v", err)
+	}
+
+	if config.Address == "" || config.ShutdownTimeout <= 0 {
+		return nil, fmt.Errorf("invalid server config: address %q, shutdown timeout %s", config.Address, config.ShutdownTimeout)
+	}
+
+	if config.GRPCAddress == "" || config.GRPCAddress == config.Address {
+		return nil, fmt.Errorf("invalid grpc address: %q", config.GRPCAddress)
+	}
+
+	return config, nil
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    *Config
+		wantErr error
+	}{
+		{
+			name: "applies defaults for missing keys",
+			yaml: "db:\n  HOST: localhost\n",
+			want: &Config{
+				Address:           ":8080",
+				GRPCAddress:       ":9090",
+				ReadTimeout:       5 * time.Second,
+				ReadHeaderTimeout: 2 * time.Second,
+				WriteTimeout:      10 * time.Second,
+				IdleTimeout:       60 * time.Second,
+				MaxHeaderBytes:    1 << 20,
+				ShutdownTimeout:   15 * time.Second,
+			},
+		},
+		{
+			name: "reads configured keys",
+			yaml: "server:\n  ADDRESS: \":9090\"\n  GRPC_ADDRESS: \":9091\"\n  READ_TIMEOUT: 1s\n  READ_HEADER_TIMEOUT: 1s\n  WRITE_TIMEOUT: 2s\n  IDLE_TIMEOUT: 3s\n  MAX_HEADER_BYTES: 4096\n  SHUTDOWN_TIMEOUT: 4s\n  TRUSTED_PROXIES: [\"10.0.0.0/8\"]\n",
+			want: &Config{
+				Address:           ":9090",
+				GRPCAddress:       ":9091",
+				ReadTimeout:       time.Second,
+				ReadHeaderTimeout: time.Second,
+				WriteTimeout:      2 * time.Second,
+				IdleTimeout:       3 * time.Second,
+				MaxHeaderBytes:    4096,
+				ShutdownTimeout:   4 * time.Second,
+				TrustedProxies:    []string{"10.0.0.0/8"},
+			},
+		},
+		{
+			name:    "when shutdown timeout is invalid",
+			yaml:    "server:\n  SHUTDOWN_TIMEOUT: 0s\n",
+			wantErr: errors.New(`invalid server config: address ":8080", shutdown timeout 0s`),
+		},
+		{
+			name:    "when grpc address is the http address",
+			yaml:    "server:\n  ADDRESS: \":9090\"\n",
+			wantErr: errors.New(`invalid grpc address: ":9090"`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			viper.SetConfigType("yaml")
+			assert.NoError(t, viper.ReadConfig(strings.NewReader(tt.yaml)))
+
+			got, err := NewConfig()
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
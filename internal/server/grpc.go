@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+
+	urlshortenerv1 "github.com/ggoulart/url-shortener/api/proto/urlshortener/v1"
+	"github.com/ggoulart/url-shortener/internal/middleware"
+	"github.com/ggoulart/url-shortener/internal/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// GRPCServer serves the UrlShortener service along with the standard health and
+// reflection services.
+type GRPCServer struct {
+	*grpc.Server
+	health *health.Server
+}
+
+// NewGRPC serves urlShortener to callers authenticator accepts, errors of the
+// authentication are answered like the handlers' own. The interceptors run in
+// order after authentication, where the rate limits of the HTTP API come in.
+func NewGRPC(urlShortener urlshortenerv1.UrlShortenerServer, authenticator middleware.Authenticator, interceptors ...grpc.UnaryServerInterceptor) *GRPCServer {
+	chain := append([]grpc.UnaryServerInterceptor{rpc.ErrorInterceptor(), rpc.AuthInterceptor(authenticator)}, interceptors...)
+	s := grpc.NewServer(grpc.ChainUnaryInterceptor(chain...))
+	urlshortenerv1.RegisterUrlShortenerServer(s, urlShortener)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(urlshortenerv1.UrlShortener_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(s, healthServer)
+
+	reflection.Register(s)
+
+	return &GRPCServer{Server: s, health: healthServer}
+}
+
+// Shutdown reports NOT_SERVING to health checks and drains in-flight calls,
+// the ones still running when ctx is done are cut off.
+func (s *GRPCServer) Shutdown(ctx context.Context) error {
+	s.health.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	urlshortenerv1 "github.com/ggoulart/url-shortener/api/proto/urlshortener/v1"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/ggoulart/url-shortener/internal/repository"
+	"github.com/ggoulart/url-shortener/internal/service"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestNewGRPC(t *testing.T) {
+	listener := bufconn.Listen(1 << 20)
+	srv := NewGRPC(&notFoundUrlShortener{}, rejectingAuthenticator{})
+	go srv.Serve(listener)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	ctx := context.Background()
+
+	health, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: urlshortenerv1.UrlShortener_ServiceDesc.ServiceName})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, health.GetStatus())
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{}}))
+	reflected, err := stream.Recv()
+	assert.NoError(t, err)
+	var services []string
+	for _, service := range reflected.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	assert.Contains(t, services, urlshortenerv1.UrlShortener_ServiceDesc.ServiceName)
+	assert.NoError(t, stream.CloseSend())
+
+	_, err = urlshortenerv1.NewUrlShortenerClient(conn).Resolve(ctx, &urlshortenerv1.ResolveRequest{EncodedKey: "abc1234"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = urlshortenerv1.NewUrlShortenerClient(conn).GetLink(ctx, &urlshortenerv1.GetLinkRequest{EncodedKey: "abc1234"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	authCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer usk_unknown")
+	_, err = urlshortenerv1.NewUrlShortenerClient(conn).GetLink(authCtx, &urlshortenerv1.GetLinkRequest{EncodedKey: "abc1234"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	assert.NoError(t, srv.Shutdown(ctx))
+}
+
+type notFoundUrlShortener struct {
+	urlshortenerv1.UnimplementedUrlShortenerServer
+}
+
+func (s *notFoundUrlShortener) Resolve(context.Context, *urlshortenerv1.ResolveRequest) (*urlshortenerv1.ResolveResponse, error) {
+	return nil, repository.ErrNotFound
+}
+
+type rejectingAuthenticator struct{}
+
+func (rejectingAuthenticator) Authenticate(context.Context, string) (model.Principal, error) {
+	return model.Principal{}, service.ErrInvalidAPIKey
+}
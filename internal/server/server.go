@@ -0,0 +1,15 @@
+package server
+
+import "net/http"
+
+func New(config Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              config.Address,
+		Handler:           handler,
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+		MaxHeaderBytes:    config.MaxHeaderBytes,
+	}
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	handler := http.NewServeMux()
+	config := Config{
+		Address:           ":9090",
+		ReadTimeout:       time.Second,
+		ReadHeaderTimeout: 2 * time.Second,
+		WriteTimeout:      3 * time.Second,
+		IdleTimeout:       4 * time.Second,
+		MaxHeaderBytes:    4096,
+	}
+
+	got := New(config, handler)
+
+	assert.Equal(t, ":9090", got.Addr)
+	assert.Equal(t, handler, got.Handler)
+	assert.Equal(t, time.Second, got.ReadTimeout)
+	assert.Equal(t, 2*time.Second, got.ReadHeaderTimeout)
+	assert.Equal(t, 3*time.Second, got.WriteTimeout)
+	assert.Equal(t, 4*time.Second, got.IdleTimeout)
+	assert.Equal(t, 4096, got.MaxHeaderBytes)
+}
+
+func TestNew_ShutdownDrainsActiveRequests(t *testing.T) {
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusFound)
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	srv := New(Config{ReadTimeout: time.Second, WriteTimeout: time.Second}, handler)
+	go srv.Serve(listener)
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	status := make(chan int, 1)
+	go func() {
+		resp, err := client.Get("http://" + listener.Addr().String())
+		if err != nil {
+			status <- 0
+			return
+		}
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+
+	<-started
+	assert.NoError(t, srv.Shutdown(context.Background()))
+	assert.Equal(t, http.StatusFound, <-status)
+}
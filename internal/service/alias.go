@@ -0,0 +1,46 @@
+package service
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+)
+
+var ErrInvalidAlias = apperror.New("invalid_alias", http.StatusBadRequest, "invalid alias")
+var ErrAliasTaken = apperror.New("alias_taken", http.StatusConflict, "alias already taken")
+
+const (
+	aliasMinLength = 3
+	aliasMaxLength = 64
+)
+
+var aliasCharset = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// reservedAliases are path segments served by the API itself under /api/v1/.
+var reservedAliases = map[string]bool{
+	"admin":   true,
+	"api":     true,
+	"docs":    true,
+	"health":  true,
+	"links":   true,
+	"shorten": true,
+	"static":  true,
+}
+
+func validateAlias(alias string) error {
+	if len(alias) < aliasMinLength || len(alias) > aliasMaxLength {
+		return ErrInvalidAlias
+	}
+
+	if !aliasCharset.MatchString(alias) {
+		return ErrInvalidAlias
+	}
+
+	if reservedAliases[strings.ToLower(alias)] {
+		return ErrInvalidAlias
+	}
+
+	return nil
+}
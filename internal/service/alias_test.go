@@ -0,0 +1,32 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAlias(t *testing.T) {
+	tests := []struct {
+		name    string
+		alias   string
+		wantErr error
+	}{
+		{name: "when alias is valid", alias: "launch-2026"},
+		{name: "when alias uses underscores", alias: "spring_sale"},
+		{name: "when alias is too short", alias: "ab", wantErr: ErrInvalidAlias},
+		{name: "when alias is too long", alias: strings.Repeat("a", 65), wantErr: ErrInvalidAlias},
+		{name: "when alias has invalid characters", alias: "launch/2026", wantErr: ErrInvalidAlias},
+		{name: "when alias has unicode characters", alias: "lançamento", wantErr: ErrInvalidAlias},
+		{name: "when alias is reserved", alias: "health", wantErr: ErrInvalidAlias},
+		{name: "when alias is reserved in another case", alias: "Shorten", wantErr: ErrInvalidAlias},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAlias(tt.alias)
+
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
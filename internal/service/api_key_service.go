@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/ggoulart/url-shortener/internal/repository"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidAPIKey = apperror.New("invalid_api_key", http.StatusUnauthorized, "invalid api key")
+var ErrInvalidAPIKeyName = apperror.New("invalid_api_key_name", http.StatusBadRequest, "invalid api key name")
+var ErrInvalidScopes = apperror.New("invalid_scopes", http.StatusBadRequest, "invalid scopes")
+
+// APIKeyPrefix starts every API key so a leaked one is easy to recognize.
+const APIKeyPrefix = "usk_"
+
+// apiKeyVisiblePrefix is how much of a key is stored in the clear to tell it apart.
+const apiKeyVisiblePrefix = len(APIKeyPrefix) + 8
+
+const maxAPIKeyNameLength = 100
+
+// lastUsedResolution spares a busy key a write per request, its last use is only
+// recorded once the stored one is older than this.
+const lastUsedResolution = time.Minute
+
+var knownScopes = []model.Scope{model.ScopeCreate, model.ScopeRead, model.ScopeAdmin}
+
+type APIKeyRepository interface {
+	SaveAPIKey(ctx context.Context, key model.APIKey) error
+	FindAPIKeyByHash(ctx context.Context, hash string) (model.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]model.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string, revokedAt time.Time) error
+	TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error
+}
+
+type APIKeyService struct {
+	repository    APIKeyRepository
+	bootstrapHash string
+	now           func() time.Time
+	newSecret     func() (string, error)
+	newID         func() string
+}
+
+func NewAPIKeyService(repository APIKeyRepository, config AuthConfig) *APIKeyService {
+	s := &APIKeyService{repository: repository, now: time.Now, newSecret: newAPIKeySecret, newID: uuid.NewString}
+	if config.BootstrapKey != "" {
+		s.bootstrapHash = hashManagementToken(config.BootstrapKey)
+	}
+
+	return s
+}
+
+// Mint creates a key, its secret is only ever returned here.
+func (s *APIKeyService) Mint(ctx context.Context, name string, scopes []model.Scope) (model.MintedAPIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxAPIKeyNameLength {
+		return model.MintedAPIKey{}, ErrInvalidAPIKeyName
+	}
+
+	scopes, err := normalizeScopes(scopes)
+	if err != nil {
+		return model.MintedAPIKey{}, err
+	}
+
+	secret, err := s.newSecret()
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to generate api key: %v", err))
+		return model.MintedAPIKey{}, ErrKeyGenerationFailed
+	}
+
+	key := model.APIKey{
+		ID:        s.newID(),
+		Name:      name,
+		Prefix:    secret[:apiKeyVisiblePrefix],
+		Hash:      hashManagementToken(secret),
+		Scopes:    scopes,
+		CreatedAt: s.now().UTC(),
+	}
+
+	err = s.repository.SaveAPIKey(ctx, key)
+	if err != nil {
+		return model.MintedAPIKey{}, err
+	}
+
+	return model.MintedAPIKey{APIKey: key, Secret: secret}, nil
+}
+
+func (s *APIKeyService) ListAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	return s.repository.ListAPIKeys(ctx)
+}
+
+// Revoke stops a key from authenticating, it stays listed with its revocation time.
+func (s *APIKeyService) Revoke(ctx context.Context, id string) error {
+	return s.repository.RevokeAPIKey(ctx, id, s.now().UTC())
+}
+
+// Authenticate tells who secret belongs to. Unknown and revoked keys get the same
+// error so a caller can't tell which keys once existed.
+func (s *APIKeyService) Authenticate(ctx context.Context, secret string) (model.Principal, error) {
+	hash := hashManagementToken(secret)
+
+	if s.bootstrapHash != "" && subtle.ConstantTimeCompare([]byte(hash), []byte(s.bootstrapHash)) == 1 {
+		return model.Principal{KeyID: "bootstrap", Name: "bootstrap", Scopes: []model.Scope{model.ScopeAdmin}}, nil
+	}
+
+	key, err := s.repository.FindAPIKeyByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Principal{}, ErrInvalidAPIKey
+		}
+		return model.Principal{}, err
+	}
+
+	if key.RevokedAt != nil {
+		return model.Principal{}, ErrInvalidAPIKey
+	}
+
+	now := s.now().UTC()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		// a missed last-used time is no reason to turn the request away
+		err = s.repository.TouchAPIKey(ctx, key.ID, now)
+		if err != nil {
+			slog.Warn(fmt.Sprintf("failed to record use of api key %s: %v", key.ID, err))
+		}
+	}
+
+	return model.Principal{KeyID: key.ID, Name: key.Name, Scopes: key.Scopes}, nil
+}
+
+func normalizeScopes(scopes []model.Scope) ([]model.Scope, error) {
+	if len(scopes) == 0 {
+		return nil, ErrInvalidScopes.WithDetail("at least one scope is required")
+	}
+
+	for _, scope := range scopes {
+		if !slices.Contains(knownScopes, scope) {
+			return nil, ErrInvalidScopes.WithDetail(fmt.Sprintf("unknown scope %q", scope))
+		}
+	}
+
+	scopes = slices.Clone(scopes)
+	slices.Sort(scopes)
+
+	return slices.Compact(scopes), nil
+}
+
+// newAPIKeySecret has the entropy of a management token, so it is hashed the same way.
+func newAPIKeySecret() (string, error) {
+	token, err := newManagementToken()
+	if err != nil {
+		return "", err
+	}
+
+	return APIKeyPrefix + token, nil
+}
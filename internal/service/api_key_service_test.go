@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/ggoulart/url-shortener/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testAPIKeySecret = "usk_aB3dE6gHiJkLmNoPqRsTuVwXyZ0123456789-_abcde"
+
+var testAPIKeyHash = hashManagementToken(testAPIKeySecret)
+
+func newTestAPIKeyService(r *MockAPIKeyRepository, config AuthConfig, now time.Time) *APIKeyService {
+	s := NewAPIKeyService(r, config)
+	s.now = func() time.Time { return now }
+	s.newSecret = func() (string, error) { return testAPIKeySecret, nil }
+	s.newID = func() string { return "a-key-id" }
+
+	return s
+}
+
+func TestAPIKeyService_Mint(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	key := model.APIKey{
+		ID:        "a-key-id",
+		Name:      "ci",
+		Prefix:    "usk_aB3dE6gH",
+		Hash:      testAPIKeyHash,
+		Scopes:    []model.Scope{model.ScopeCreate, model.ScopeRead},
+		CreatedAt: now,
+	}
+
+	tests := []struct {
+		name    string
+		keyName string
+		scopes  []model.Scope
+		setup   func(*MockAPIKeyRepository)
+		want    model.MintedAPIKey
+		wantErr error
+	}{
+		{
+			name:    "when name is blank",
+			keyName: "  ",
+			scopes:  []model.Scope{model.ScopeRead},
+			setup:   func(*MockAPIKeyRepository) {},
+			wantErr: ErrInvalidAPIKeyName,
+		},
+		{
+			name:    "when no scope is given",
+			keyName: "ci",
+			setup:   func(*MockAPIKeyRepository) {},
+			wantErr: ErrInvalidScopes.WithDetail("at least one scope is required"),
+		},
+		{
+			name:    "when a scope is unknown",
+			keyName: "ci",
+			scopes:  []model.Scope{model.ScopeRead, "delete"},
+			setup:   func(*MockAPIKeyRepository) {},
+			wantErr: ErrInvalidScopes.WithDetail(`unknown scope "delete"`),
+		},
+		{
+			name:    "when failed to save the key",
+			keyName: "ci",
+			scopes:  []model.Scope{model.ScopeRead, model.ScopeCreate},
+			setup: func(r *MockAPIKeyRepository) {
+				r.On("SaveAPIKey", context.Background(), key).Return(repository.ErrUnexpected)
+			},
+			wantErr: repository.ErrUnexpected,
+		},
+		{
+			name:    "when successfully mints a key",
+			keyName: " ci ",
+			scopes:  []model.Scope{model.ScopeRead, model.ScopeCreate, model.ScopeRead},
+			setup: func(r *MockAPIKeyRepository) {
+				r.On("SaveAPIKey", context.Background(), key).Return(nil)
+			},
+			want: model.MintedAPIKey{APIKey: key, Secret: testAPIKeySecret},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &MockAPIKeyRepository{}
+			s := newTestAPIKeyService(r, AuthConfig{}, now)
+			tt.setup(r)
+
+			got, err := s.Mint(context.Background(), tt.keyName, tt.scopes)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+
+	t.Run("when failed to generate the secret", func(t *testing.T) {
+		r := &MockAPIKeyRepository{}
+		s := newTestAPIKeyService(r, AuthConfig{}, now)
+		s.newSecret = func() (string, error) { return "", errors.New("no entropy") }
+
+		_, err := s.Mint(context.Background(), "ci", []model.Scope{model.ScopeRead})
+
+		assert.Equal(t, ErrKeyGenerationFailed, err)
+		r.AssertNotCalled(t, "SaveAPIKey", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	recently := now.Add(-time.Second)
+	longAgo := now.Add(-time.Hour)
+	key := model.APIKey{ID: "a-key-id", Name: "ci", Hash: testAPIKeyHash, Scopes: []model.Scope{model.ScopeCreate}}
+	principal := model.Principal{KeyID: "a-key-id", Name: "ci", Scopes: []model.Scope{model.ScopeCreate}}
+	bootstrapKey := "a-bootstrap-key-long-enough-to-use"
+
+	tests := []struct {
+		name    string
+		secret  string
+		config  AuthConfig
+		setup   func(*MockAPIKeyRepository)
+		want    model.Principal
+		wantErr error
+	}{
+		{
+			name:   "when key is the bootstrap key",
+			secret: bootstrapKey,
+			config: AuthConfig{BootstrapKey: bootstrapKey},
+			setup:  func(*MockAPIKeyRepository) {},
+			want:   model.Principal{KeyID: "bootstrap", Name: "bootstrap", Scopes: []model.Scope{model.ScopeAdmin}},
+		},
+		{
+			name:   "when key is unknown",
+			secret: "usk_unknown",
+			setup: func(r *MockAPIKeyRepository) {
+				r.On("FindAPIKeyByHash", context.Background(), hashManagementToken("usk_unknown")).Return(model.APIKey{}, repository.ErrNotFound)
+			},
+			wantErr: ErrInvalidAPIKey,
+		},
+		{
+			name:   "when failed to find the key",
+			secret: testAPIKeySecret,
+			setup: func(r *MockAPIKeyRepository) {
+				r.On("FindAPIKeyByHash", context.Background(), testAPIKeyHash).Return(model.APIKey{}, repository.ErrUnexpected)
+			},
+			wantErr: repository.ErrUnexpected,
+		},
+		{
+			name:   "when key is revoked",
+			secret: testAPIKeySecret,
+			setup: func(r *MockAPIKeyRepository) {
+				revoked := key
+				revoked.RevokedAt = &longAgo
+				r.On("FindAPIKeyByHash", context.Background(), testAPIKeyHash).Return(revoked, nil)
+			},
+			wantErr: ErrInvalidAPIKey,
+		},
+		{
+			name:   "when key was never used it records the use",
+			secret: testAPIKeySecret,
+			setup: func(r *MockAPIKeyRepository) {
+				r.On("FindAPIKeyByHash", context.Background(), testAPIKeyHash).Return(key, nil)
+				r.On("TouchAPIKey", context.Background(), "a-key-id", now).Return(nil)
+			},
+			want: principal,
+		},
+		{
+			name:   "when failed to record the use it still authenticates",
+			secret: testAPIKeySecret,
+			setup: func(r *MockAPIKeyRepository) {
+				used := key
+				used.LastUsedAt = &longAgo
+				r.On("FindAPIKeyByHash", context.Background(), testAPIKeyHash).Return(used, nil)
+				r.On("TouchAPIKey", context.Background(), "a-key-id", now).Return(repository.ErrUnexpected)
+			},
+			want: principal,
+		},
+		{
+			name:   "when key was used recently it skips the write",
+			secret: testAPIKeySecret,
+			setup: func(r *MockAPIKeyRepository) {
+				used := key
+				used.LastUsedAt = &recently
+				r.On("FindAPIKeyByHash", context.Background(), testAPIKeyHash).Return(used, nil)
+			},
+			want: principal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &MockAPIKeyRepository{}
+			s := newTestAPIKeyService(r, tt.config, now)
+			tt.setup(r)
+
+			got, err := s.Authenticate(context.Background(), tt.secret)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+			r.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAPIKeyService_Revoke(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := &MockAPIKeyRepository{}
+	s := newTestAPIKeyService(r, AuthConfig{}, now)
+	r.On("RevokeAPIKey", context.Background(), "a-key-id", now).Return(repository.ErrNotFound)
+
+	err := s.Revoke(context.Background(), "a-key-id")
+
+	assert.Equal(t, repository.ErrNotFound, err)
+}
+
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) SaveAPIKey(ctx context.Context, key model.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) FindAPIKeyByHash(ctx context.Context, hash string) (model.APIKey, error) {
+	args := m.Called(ctx, hash)
+	return args.Get(0).(model.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) ListAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) RevokeAPIKey(ctx context.Context, id string, revokedAt time.Time) error {
+	args := m.Called(ctx, id, revokedAt)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	args := m.Called(ctx, id, usedAt)
+	return args.Error(0)
+}
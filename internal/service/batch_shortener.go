@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/model"
+)
+
+var ErrInvalidBatchSize = apperror.New("invalid_batch_size", http.StatusBadRequest, "invalid batch size")
+
+// pendingLink is a batch item whose link still has to be inserted.
+type pendingLink struct {
+	index int
+	link  model.Link
+	token string
+	alias bool
+}
+
+// ShortenBatch shortens every item the way Shortener does, but existing links are
+// looked up and new ones inserted with one query per round instead of one round trip
+// per item. A failed item only fails its own result, a storage error fails the batch.
+// So does a quota too small for every link the batch would create, the links that
+// end up not created are given back.
+func (s *ShortenerService) ShortenBatch(ctx context.Context, items []model.BatchItem) ([]model.BatchResult, error) {
+	if len(items) == 0 || len(items) > s.config.BatchMaxSize {
+		return nil, ErrInvalidBatchSize
+	}
+
+	scope, err := s.resolveScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]model.BatchResult, len(items))
+	// followers repeat an earlier item of the batch and share its link
+	followers := map[int][]int{}
+	aliases := map[string]pendingLink{}
+	deduplicated := map[string]pendingLink{}
+	var aliased, generated, lookups []pendingLink
+	var lookupURLs []url.URL
+
+	for i, item := range items {
+		link, token, err := s.prepareLink(ctx, scope, item.LongURL, item.Options)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		pending := pendingLink{index: i, link: link, token: token}
+
+		switch {
+		case link.EncodedKey != "":
+			err = validateAlias(link.EncodedKey)
+			if err != nil {
+				results[i].Err = err
+				continue
+			}
+
+			first, ok := aliases[link.EncodedKey]
+			if !ok {
+				pending.alias = true
+				aliases[link.EncodedKey] = pending
+				aliased = append(aliased, pending)
+				continue
+			}
+			if first.link.LongURL.String() != link.LongURL.String() {
+				results[i].Err = ErrAliasTaken
+				continue
+			}
+			followers[first.index] = append(followers[first.index], i)
+		// links with an expiry are always new, they must not hand out a permanent link
+		case link.ExpiresAt != nil:
+			generated = append(generated, pending)
+		default:
+			first, ok := deduplicated[link.LongURL.String()]
+			if ok {
+				followers[first.index] = append(followers[first.index], i)
+				continue
+			}
+			deduplicated[link.LongURL.String()] = pending
+			lookups = append(lookups, pending)
+			lookupURLs = append(lookupURLs, link.LongURL)
+		}
+	}
+
+	encodedKeys, err := s.repository.FindEncodedKeys(ctx, lookupURLs, scope.workspaceID, scope.ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pending := range lookups {
+		encodedKey, ok := encodedKeys[pending.link.LongURL.String()]
+		if !ok {
+			generated = append(generated, pending)
+			continue
+		}
+
+		// an existing link is shared, only its creator holds the management token
+		results[pending.index].ShortenResult, results[pending.index].Err = s.buildResult(encodedKey, false, "")
+	}
+
+	consumed := len(aliased) + len(generated)
+	if consumed > 0 {
+		err = s.quota.ConsumeCreations(ctx, consumed)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.saveBatch(ctx, results, aliased, generated)
+	if err != nil {
+		s.refundCreations(ctx, consumed)
+		return nil, err
+	}
+
+	// aliases that already existed and keys that kept colliding created nothing
+	created := 0
+	for _, result := range results {
+		if result.Created {
+			created++
+		}
+	}
+	if created < consumed {
+		s.refundCreations(ctx, consumed-created)
+	}
+
+	for first, indexes := range followers {
+		for _, i := range indexes {
+			results[i].Err = results[first].Err
+			if results[first].Err == nil {
+				results[i].ShortenResult = model.ShortenResult{ShortURL: results[first].ShortURL}
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// saveBatch inserts the aliased links and the generated ones in a single statement,
+// then retries the generated keys that collided. Results are filled in place.
+func (s *ShortenerService) saveBatch(ctx context.Context, results []model.BatchResult, aliased []pendingLink, generated []pendingLink) error {
+	pending := aliased
+	collisions := 0
+
+	for attempt := 0; attempt <= s.config.KeyMaxRetries && len(pending)+len(generated) > 0; attempt++ {
+		length := int(s.keyLength.Load())
+
+		// keys must also be unique within the statement, or two items would share a row
+		taken := map[string]bool{}
+		for _, p := range pending {
+			taken[p.link.EncodedKey] = true
+		}
+
+		var retry []pendingLink
+		for _, p := range generated {
+			encodedKey, err := s.keyGenerator.Generate(length)
+			if err != nil {
+				slog.Error(fmt.Sprintf("failed to generate key: %v", err))
+				return ErrKeyGenerationFailed
+			}
+
+			if taken[encodedKey] {
+				retry = append(retry, p)
+				continue
+			}
+
+			taken[encodedKey] = true
+			p.link.EncodedKey = encodedKey
+			pending = append(pending, p)
+		}
+
+		links := make([]model.Link, 0, len(pending))
+		for _, p := range pending {
+			links = append(links, p.link)
+		}
+
+		inserted, err := s.repository.SaveURLs(ctx, links)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range pending {
+			switch {
+			case inserted[p.link.EncodedKey]:
+				results[p.index].ShortenResult, results[p.index].Err = s.buildResult(p.link.EncodedKey, true, p.token)
+			case p.alias:
+				results[p.index].ShortenResult, results[p.index].Err = s.existingAlias(ctx, p.link)
+			default:
+				retry = append(retry, p)
+			}
+		}
+
+		if len(retry) > 0 {
+			slog.Warn(fmt.Sprintf("%d encoded key collisions at length %d", len(retry), length))
+			collisions += len(retry)
+			if collisions >= collisionsBeforeGrow {
+				s.growKeyLength(length)
+				collisions = 0
+			}
+		}
+
+		pending = nil
+		generated = retry
+	}
+
+	for _, p := range generated {
+		results[p.index].Err = ErrKeyGenerationFailed
+	}
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestShortenerService_ShortenBatch(t *testing.T) {
+	config := testConfig
+	config.BatchMaxSize = 6
+
+	aURL := url.URL{Scheme: "http", Host: "a-long-url"}
+	bURL := url.URL{Scheme: "http", Host: "b-long-url"}
+	cURL := url.URL{Scheme: "http", Host: "c-long-url"}
+	shortURL := func(encodedKey string) url.URL {
+		return url.URL{Scheme: "http", Host: "host-url.com", Path: "/api/v1/" + encodedKey}
+	}
+	aLink := model.Link{EncodedKey: "aB3dE6g", LongURL: aURL, ManagementTokenHash: testTokenHash}
+	aliasLink := model.Link{EncodedKey: "launch-2026", LongURL: cURL, ManagementTokenHash: testTokenHash}
+
+	tests := []struct {
+		name    string
+		items   []model.BatchItem
+		setup   func(*MockShortenerRepository, *MockKeyGenerator)
+		want    []model.BatchResult
+		wantErr error
+	}{
+		{
+			name:    "when batch is empty",
+			items:   nil,
+			setup:   func(r *MockShortenerRepository, g *MockKeyGenerator) {},
+			wantErr: ErrInvalidBatchSize,
+		},
+		{
+			name:    "when batch is larger than allowed",
+			items:   make([]model.BatchItem, 7),
+			setup:   func(r *MockShortenerRepository, g *MockKeyGenerator) {},
+			wantErr: ErrInvalidBatchSize,
+		},
+		{
+			name:  "when failed to find existing links",
+			items: []model.BatchItem{{LongURL: aURL}},
+			setup: func(r *MockShortenerRepository, g *MockKeyGenerator) {
+				r.On("FindEncodedKeys", context.Background(), []url.URL{aURL}, "", "").Return(map[string]string(nil), errors.New("db error"))
+			},
+			wantErr: errors.New("db error"),
+		},
+		{
+			name:  "when failed to save links",
+			items: []model.BatchItem{{LongURL: aURL}},
+			setup: func(r *MockShortenerRepository, g *MockKeyGenerator) {
+				r.On("FindEncodedKeys", context.Background(), []url.URL{aURL}, "", "").Return(map[string]string{}, nil)
+				g.On("Generate", 7).Return("aB3dE6g", nil)
+				r.On("SaveURLs", context.Background(), []model.Link{aLink}).Return(map[string]bool(nil), errors.New("db error"))
+			},
+			wantErr: errors.New("db error"),
+		},
+		{
+			name: "when items are new, existing, repeated or invalid",
+			items: []model.BatchItem{
+				{LongURL: aURL},
+				{LongURL: bURL},
+				{LongURL: aURL},
+				{LongURL: cURL, Options: model.ShortenOptions{Alias: "no"}},
+				{LongURL: cURL, Options: model.ShortenOptions{Alias: "launch-2026"}},
+				{LongURL: aURL, Options: model.ShortenOptions{Alias: "launch-2026"}},
+			},
+			setup: func(r *MockShortenerRepository, g *MockKeyGenerator) {
+				r.On("FindEncodedKeys", context.Background(), []url.URL{aURL, bURL}, "", "").Return(map[string]string{"http://b-long-url": "xZya7gG"}, nil)
+				g.On("Generate", 7).Return("aB3dE6g", nil)
+				r.On("SaveURLs", context.Background(), []model.Link{aliasLink, aLink}).Return(map[string]bool{"launch-2026": true, "aB3dE6g": true}, nil)
+			},
+			want: []model.BatchResult{
+				{ShortenResult: model.ShortenResult{ShortURL: shortURL("aB3dE6g"), Created: true, ManagementToken: testToken}},
+				{ShortenResult: model.ShortenResult{ShortURL: shortURL("xZya7gG")}},
+				{ShortenResult: model.ShortenResult{ShortURL: shortURL("aB3dE6g")}},
+				{Err: ErrInvalidAlias},
+				{ShortenResult: model.ShortenResult{ShortURL: shortURL("launch-2026"), Created: true, ManagementToken: testToken}},
+				{Err: ErrAliasTaken},
+			},
+		},
+		{
+			name:  "when alias already points at the same url",
+			items: []model.BatchItem{{LongURL: cURL, Options: model.ShortenOptions{Alias: "launch-2026"}}},
+			setup: func(r *MockShortenerRepository, g *MockKeyGenerator) {
+				r.On("FindEncodedKeys", context.Background(), []url.URL(nil), "", "").Return(map[string]string{}, nil)
+				r.On("SaveURLs", context.Background(), []model.Link{aliasLink}).Return(map[string]bool{}, nil)
+				r.On("FindLink", context.Background(), "launch-2026").Return(model.Link{EncodedKey: "launch-2026", LongURL: cURL}, nil)
+			},
+			want: []model.BatchResult{{ShortenResult: model.ShortenResult{ShortURL: shortURL("launch-2026")}}},
+		},
+		{
+			name:  "when generated key collides it is retried in another insert",
+			items: []model.BatchItem{{LongURL: aURL}},
+			setup: func(r *MockShortenerRepository, g *MockKeyGenerator) {
+				r.On("FindEncodedKeys", context.Background(), []url.URL{aURL}, "", "").Return(map[string]string{}, nil)
+				g.On("Generate", 7).Return("zY9xW8v", nil).Once()
+				g.On("Generate", 7).Return("aB3dE6g", nil).Once()
+				r.On("SaveURLs", context.Background(), []model.Link{{EncodedKey: "zY9xW8v", LongURL: aURL, ManagementTokenHash: testTokenHash}}).Return(map[string]bool{}, nil)
+				r.On("SaveURLs", context.Background(), []model.Link{aLink}).Return(map[string]bool{"aB3dE6g": true}, nil)
+			},
+			want: []model.BatchResult{{ShortenResult: model.ShortenResult{ShortURL: shortURL("aB3dE6g"), Created: true, ManagementToken: testToken}}},
+		},
+		{
+			name:  "when generated keys keep colliding retries are exhausted",
+			items: []model.BatchItem{{LongURL: aURL}},
+			setup: func(r *MockShortenerRepository, g *MockKeyGenerator) {
+				r.On("FindEncodedKeys", context.Background(), []url.URL{aURL}, "", "").Return(map[string]string{}, nil)
+				g.On("Generate", 7).Return("aB3dE6g", nil).Twice()
+				g.On("Generate", 8).Return("aB3dE6gH", nil).Once()
+				r.On("SaveURLs", context.Background(), []model.Link{aLink}).Return(map[string]bool{}, nil)
+				r.On("SaveURLs", context.Background(), []model.Link{{EncodedKey: "aB3dE6gH", LongURL: aURL, ManagementTokenHash: testTokenHash}}).Return(map[string]bool{}, nil)
+			},
+			want: []model.BatchResult{{Err: ErrKeyGenerationFailed}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &MockShortenerRepository{}
+			g := &MockKeyGenerator{}
+			s := newTestShortenerService(r, g, allowAllPolicy(), config)
+			tt.setup(r, g)
+
+			got, err := s.ShortenBatch(context.Background(), tt.items)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+			r.AssertExpectations(t)
+		})
+	}
+}
+
+func TestShortenerService_ShortenBatch_Quota(t *testing.T) {
+	config := testConfig
+	config.BatchMaxSize = 4
+
+	aURL := url.URL{Scheme: "http", Host: "a-long-url"}
+	bURL := url.URL{Scheme: "http", Host: "b-long-url"}
+	ctx := ContextWithPrincipal(context.Background(), model.Principal{KeyID: "a-key-id"})
+	items := []model.BatchItem{{LongURL: aURL}, {LongURL: bURL}, {LongURL: bURL}, {LongURL: aURL, Options: model.ShortenOptions{Alias: "my-alias"}}}
+
+	r := &MockShortenerRepository{}
+	q := &MockCreationQuota{}
+	s := newTestShortenerService(r, &MockKeyGenerator{}, allowAllPolicy(), config)
+	s.quota = q
+	r.On("FindEncodedKeys", ctx, []url.URL{aURL, bURL}, "", "").Return(map[string]string{aURL.String(): "xZya7gG"}, nil)
+	q.On("ConsumeCreations", ctx, 2).Return(ErrQuotaExceeded)
+
+	got, err := s.ShortenBatch(ctx, items)
+
+	assert.Nil(t, got)
+	assert.ErrorIs(t, err, ErrQuotaExceeded, "only the alias and the new url count, repeats and existing links don't")
+	r.AssertNotCalled(t, "SaveURLs", mock.Anything, mock.Anything)
+	q.AssertExpectations(t)
+}
+
+func TestShortenerService_ShortenBatch_Refund(t *testing.T) {
+	config := testConfig
+	config.BatchMaxSize = 4
+
+	cURL := url.URL{Scheme: "http", Host: "c-long-url"}
+	ctx := ContextWithPrincipal(context.Background(), model.Principal{KeyID: "a-key-id"})
+	items := []model.BatchItem{{LongURL: cURL, Options: model.ShortenOptions{Alias: "launch-2026"}}}
+	aliasLink := model.Link{EncodedKey: "launch-2026", LongURL: cURL, ManagementTokenHash: testTokenHash}
+
+	r := &MockShortenerRepository{}
+	q := &MockCreationQuota{}
+	s := newTestShortenerService(r, &MockKeyGenerator{}, allowAllPolicy(), config)
+	s.quota = q
+	r.On("FindEncodedKeys", ctx, []url.URL(nil), "", "").Return(map[string]string{}, nil)
+	q.On("ConsumeCreations", ctx, 1).Return(nil)
+	r.On("SaveURLs", ctx, []model.Link{aliasLink}).Return(map[string]bool{}, nil)
+	r.On("FindLink", ctx, "launch-2026").Return(aliasLink, nil)
+	q.On("RefundCreations", ctx, 1).Return(nil)
+
+	got, err := s.ShortenBatch(ctx, items)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.BatchResult{{ShortenResult: model.ShortenResult{ShortURL: url.URL{Scheme: "http", Host: "host-url.com", Path: "/api/v1/launch-2026"}}}}, got)
+	q.AssertExpectations(t)
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"golang.org/x/net/idna"
+)
+
+var ErrInvalidURL = apperror.New("invalid_url", http.StatusBadRequest, "invalid url")
+
+var defaultPorts = map[string]string{"http": "80", "https": "443"}
+
+// canonicalizeURL rewrites longURL so that equivalent spellings of the same
+// destination compare equal when they are looked up and stored.
+func canonicalizeURL(longURL url.URL, sortQuery bool) (url.URL, error) {
+	canonical := longURL
+	canonical.Scheme = strings.ToLower(canonical.Scheme)
+
+	host, err := canonicalHost(canonical.Scheme, canonical.Host)
+	if err != nil {
+		return url.URL{}, err
+	}
+	canonical.Host = host
+
+	path := normalizePercentEncoding(canonical.EscapedPath())
+	if path == "" && canonical.Host != "" {
+		path = "/"
+	}
+	canonical.Path, err = url.PathUnescape(path)
+	if err != nil {
+		return url.URL{}, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	canonical.RawPath = path
+
+	canonical.RawQuery = normalizePercentEncoding(canonical.RawQuery)
+	if sortQuery {
+		canonical.RawQuery = sortQueryParams(canonical.RawQuery)
+	}
+	canonical.ForceQuery = canonical.ForceQuery && canonical.RawQuery != ""
+
+	return canonical, nil
+}
+
+func canonicalHost(scheme, host string) (string, error) {
+	if host == "" {
+		return "", nil
+	}
+
+	hostname, port, err := net.SplitHostPort(host)
+	if err != nil {
+		// no port in the host
+		hostname, port = strings.Trim(host, "[]"), ""
+	}
+
+	if port == defaultPorts[scheme] {
+		port = ""
+	}
+
+	hostname = strings.ToLower(hostname)
+	if net.ParseIP(hostname) == nil {
+		hostname, err = idna.Lookup.ToASCII(hostname)
+		if err != nil {
+			return "", fmt.Errorf("%w: host: %v", ErrInvalidURL, err)
+		}
+	}
+
+	if strings.Contains(hostname, ":") {
+		hostname = "[" + hostname + "]"
+	}
+	if port != "" {
+		hostname += ":" + port
+	}
+
+	return hostname, nil
+}
+
+// normalizePercentEncoding decodes escaped unreserved characters and uppercases
+// the hex digits of every escape that has to stay, as RFC 3986 section 6.2.2 describes.
+func normalizePercentEncoding(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		c := unhex(s[i+1])<<4 | unhex(s[i+2])
+		if isUnreserved(c) {
+			b.WriteByte(c)
+		} else {
+			b.WriteString(strings.ToUpper(s[i : i+3]))
+		}
+		i += 2
+	}
+
+	return b.String()
+}
+
+// sortQueryParams orders the parameters by key, keeping their encoding and the
+// relative order of repeated keys.
+func sortQueryParams(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	params := strings.Split(rawQuery, "&")
+	sort.SliceStable(params, func(i, j int) bool {
+		return queryKey(params[i]) < queryKey(params[j])
+	})
+
+	return strings.Join(params, "&")
+}
+
+func queryKey(param string) string {
+	key, _, _ := strings.Cut(param, "=")
+	return key
+}
+
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
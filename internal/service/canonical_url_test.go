@@ -0,0 +1,103 @@
+package service
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalizeURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		rawURL    string
+		sortQuery bool
+		want      string
+		wantErr   error
+	}{
+		{
+			name:   "lowercases scheme and host",
+			rawURL: "HTTPS://Example.COM/Some/Path",
+			want:   "https://example.com/Some/Path",
+		},
+		{
+			name:   "drops default http port",
+			rawURL: "http://example.com:80/a",
+			want:   "http://example.com/a",
+		},
+		{
+			name:   "drops default https port",
+			rawURL: "https://example.com:443/a",
+			want:   "https://example.com/a",
+		},
+		{
+			name:   "keeps non default port",
+			rawURL: "https://example.com:8443/a",
+			want:   "https://example.com:8443/a",
+		},
+		{
+			name:   "converts idn host to punycode",
+			rawURL: "https://Bücher.example/a",
+			want:   "https://xn--bcher-kva.example/a",
+		},
+		{
+			name:   "keeps ipv6 host",
+			rawURL: "http://[::1]:80/a",
+			want:   "http://[::1]/a",
+		},
+		{
+			name:   "adds root path when empty",
+			rawURL: "https://example.com",
+			want:   "https://example.com/",
+		},
+		{
+			name:   "decodes unreserved characters and uppercases escapes",
+			rawURL: "https://example.com/%7euser/a%2fb?q=%41%3d%e2%82%ac",
+			want:   "https://example.com/~user/a%2Fb?q=A%3D%E2%82%AC",
+		},
+		{
+			name:   "keeps query order by default",
+			rawURL: "https://example.com/a?b=1&a=2",
+			want:   "https://example.com/a?b=1&a=2",
+		},
+		{
+			name:      "sorts query parameters when enabled",
+			rawURL:    "https://example.com/a?b=1&a=2&b=0",
+			sortQuery: true,
+			want:      "https://example.com/a?a=2&b=1&b=0",
+		},
+		{
+			name:    "when host is not a valid idn",
+			rawURL:  "https://exa_mple..com/a",
+			wantErr: ErrInvalidURL,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			longURL, err := url.Parse(tt.rawURL)
+			assert.NoError(t, err)
+
+			got, err := canonicalizeURL(*longURL, tt.sortQuery)
+
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestCanonicalizeURL_EquivalentURLsMatch(t *testing.T) {
+	first, _ := url.Parse("HTTPS://Example.com:443/a?b=1&a=2")
+	second, _ := url.Parse("https://example.com/a?a=2&b=1")
+
+	got1, err := canonicalizeURL(*first, true)
+	assert.NoError(t, err)
+	got2, err := canonicalizeURL(*second, true)
+	assert.NoError(t, err)
+
+	assert.Equal(t, got1, got2)
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+)
+
+type ClickRepository interface {
+	SaveClicks(ctx context.Context, clicks []model.Click) error
+}
+
+// ClickRecorder keeps click events off the redirect path. Record only enqueues into a
+// bounded in-memory queue and a single writer flushes it to the repository in batches.
+//
+// Drop policy: when the queue is full the new click is dropped instead of blocking the
+// redirect. Dropped clicks are counted and reported on the next flush. Clicks still
+// queued on Stop are flushed before it returns.
+type ClickRecorder struct {
+	repository ClickRepository
+	config     ClickConfig
+	queue      chan model.Click
+	dropped    atomic.Int64
+	cancel     context.CancelFunc
+	done       chan struct{}
+}
+
+func NewClickRecorder(repository ClickRepository, config ClickConfig) *ClickRecorder {
+	return &ClickRecorder{repository: repository, config: config, queue: make(chan model.Click, config.QueueSize)}
+}
+
+// Record enqueues a click without blocking and reports whether it was accepted.
+func (r *ClickRecorder) Record(click model.Click) bool {
+	select {
+	case r.queue <- click:
+		return true
+	default:
+		r.dropped.Add(1)
+		return false
+	}
+}
+
+func (r *ClickRecorder) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.done = make(chan struct{})
+
+	go r.run(ctx)
+}
+
+// Stop cancels the writer and waits for the final flush.
+func (r *ClickRecorder) Stop() {
+	if r.cancel == nil {
+		return
+	}
+
+	r.cancel()
+	<-r.done
+}
+
+func (r *ClickRecorder) run(ctx context.Context) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]model.Click, 0, r.config.BatchSize)
+	for {
+		select {
+		case click := <-r.queue:
+			batch = append(batch, click)
+			if len(batch) >= r.config.BatchSize {
+				batch = r.flush(batch)
+			}
+		case <-ticker.C:
+			batch = r.flush(batch)
+		case <-ctx.Done():
+			r.drain(batch)
+			return
+		}
+	}
+}
+
+func (r *ClickRecorder) drain(batch []model.Click) {
+	for {
+		select {
+		case click := <-r.queue:
+			batch = append(batch, click)
+			if len(batch) >= r.config.BatchSize {
+				batch = r.flush(batch)
+			}
+		default:
+			r.flush(batch)
+			return
+		}
+	}
+}
+
+func (r *ClickRecorder) flush(batch []model.Click) []model.Click {
+	if dropped := r.dropped.Swap(0); dropped > 0 {
+		slog.Warn(fmt.Sprintf("dropped %d clicks, queue is full", dropped))
+	}
+
+	if len(batch) == 0 {
+		return batch
+	}
+
+	// the writer outlives request contexts, so each flush gets its own deadline
+	ctx, cancel := context.WithTimeout(context.Background(), r.config.WriteTimeout)
+	defer cancel()
+
+	err := r.repository.SaveClicks(ctx, batch)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to save %d clicks: %v", len(batch), err))
+	}
+
+	return batch[:0]
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClickRecorder_Record(t *testing.T) {
+	r := NewClickRecorder(&FakeClickRepository{}, ClickConfig{QueueSize: 1, BatchSize: 10, FlushInterval: time.Hour, WriteTimeout: time.Second})
+
+	assert.True(t, r.Record(model.Click{EncodedKey: "a"}))
+	assert.False(t, r.Record(model.Click{EncodedKey: "b"}))
+	assert.Equal(t, int64(1), r.dropped.Load())
+}
+
+func TestClickRecorder_FlushesFullBatches(t *testing.T) {
+	repo := &FakeClickRepository{}
+	r := NewClickRecorder(repo, ClickConfig{QueueSize: 10, BatchSize: 2, FlushInterval: time.Hour, WriteTimeout: time.Second})
+	r.Start(context.Background())
+	defer r.Stop()
+
+	r.Record(model.Click{EncodedKey: "a"})
+	r.Record(model.Click{EncodedKey: "b"})
+
+	assert.Eventually(t, func() bool { return len(repo.Batches()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, [][]model.Click{{{EncodedKey: "a"}, {EncodedKey: "b"}}}, repo.Batches())
+}
+
+func TestClickRecorder_FlushesOnInterval(t *testing.T) {
+	repo := &FakeClickRepository{}
+	r := NewClickRecorder(repo, ClickConfig{QueueSize: 10, BatchSize: 100, FlushInterval: time.Millisecond, WriteTimeout: time.Second})
+	r.Start(context.Background())
+	defer r.Stop()
+
+	r.Record(model.Click{EncodedKey: "a"})
+
+	assert.Eventually(t, func() bool { return len(repo.Batches()) == 1 }, time.Second, time.Millisecond)
+}
+
+func TestClickRecorder_StopFlushesQueuedClicks(t *testing.T) {
+	repo := &FakeClickRepository{}
+	r := NewClickRecorder(repo, ClickConfig{QueueSize: 10, BatchSize: 2, FlushInterval: time.Hour, WriteTimeout: time.Second})
+
+	for _, key := range []string{"a", "b", "c"} {
+		r.Record(model.Click{EncodedKey: key})
+	}
+
+	r.Start(context.Background())
+	r.Stop()
+
+	var saved []model.Click
+	for _, batch := range repo.Batches() {
+		saved = append(saved, batch...)
+	}
+	assert.Equal(t, []model.Click{{EncodedKey: "a"}, {EncodedKey: "b"}, {EncodedKey: "c"}}, saved)
+}
+
+func TestClickRecorder_KeepsRunningWhenSaveFails(t *testing.T) {
+	repo := &FakeClickRepository{err: errors.New("db error")}
+	r := NewClickRecorder(repo, ClickConfig{QueueSize: 10, BatchSize: 1, FlushInterval: time.Hour, WriteTimeout: time.Second})
+	r.Start(context.Background())
+
+	r.Record(model.Click{EncodedKey: "a"})
+	r.Record(model.Click{EncodedKey: "b"})
+	r.Stop()
+
+	assert.Len(t, repo.Batches(), 2)
+}
+
+type FakeClickRepository struct {
+	mu      sync.Mutex
+	batches [][]model.Click
+	err     error
+}
+
+func (f *FakeClickRepository) SaveClicks(ctx context.Context, clicks []model.Click) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.batches = append(f.batches, append([]model.Click(nil), clicks...))
+	return f.err
+}
+
+func (f *FakeClickRepository) Batches() [][]model.Click {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([][]model.Click(nil), f.batches...)
+}
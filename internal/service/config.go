@@ -0,0 +1,197 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+type Config struct {
+	ShortenerHost string `mapstructure:"SHORTENER_HOST"`
+	KeyMinLength  int    `mapstructure:"KEY_MIN_LENGTH"`
+	KeyMaxLength  int    `mapstructure:"KEY_MAX_LENGTH"`
+	KeyMaxRetries int    `mapstructure:"KEY_MAX_RETRIES"`
+	BatchMaxSize  int    `mapstructure:"BATCH_MAX_SIZE"`
+
+	// CanonicalizeURLs rewrites long URLs to one canonical spelling before they are
+	// deduplicated and stored. SortQueryParams also orders the query, which some
+	// servers treat as significant, so it is off by default.
+	CanonicalizeURLs bool `mapstructure:"CANONICALIZE_URLS"`
+	SortQueryParams  bool `mapstructure:"SORT_QUERY_PARAMS"`
+}
+
+func NewConfig() (*Config, error) {
+	config := &Config{KeyMinLength: 7, KeyMaxLength: 12, KeyMaxRetries: 5, BatchMaxSize: 100, CanonicalizeURLs: true}
+	err := viper.UnmarshalKey("service", config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load service config: %v", err)
+	}
+
+	if config.KeyMinLength <= 0 || config.KeyMaxLength < config.KeyMinLength {
+		return nil, fmt.Errorf("invalid key length range: %d..%d", config.KeyMinLength, config.KeyMaxLength)
+	}
+
+	// every link takes 6 bind parameters and postgres allows 65535 per statement
+	if config.BatchMaxSize <= 0 || config.BatchMaxSize > 10000 {
+		return nil, fmt.Errorf("invalid batch max size: %d", config.BatchMaxSize)
+	}
+
+	return config, nil
+}
+
+type ReaperConfig struct {
+	Interval    time.Duration `mapstructure:"INTERVAL"`
+	BatchSize   int           `mapstructure:"BATCH_SIZE"`
+	GracePeriod time.Duration `mapstructure:"GRACE_PERIOD"`
+}
+
+func NewReaperConfig() (*ReaperConfig, error) {
+	config := &ReaperConfig{Interval: time.Minute, BatchSize: 500, GracePeriod: 30 * 24 * time.Hour}
+	err := viper.UnmarshalKey("reaper", config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reaper config: %v", err)
+	}
+
+	if config.Interval <= 0 || config.BatchSize <= 0 {
+		return nil, fmt.Errorf("invalid reaper config: interval %s, batch size %d", config.Interval, config.BatchSize)
+	}
+
+	return config, nil
+}
+
+type ClickConfig struct {
+	QueueSize     int           `mapstructure:"QUEUE_SIZE"`
+	BatchSize     int           `mapstructure:"BATCH_SIZE"`
+	FlushInterval time.Duration `mapstructure:"FLUSH_INTERVAL"`
+	WriteTimeout  time.Duration `mapstructure:"WRITE_TIMEOUT"`
+}
+
+func NewClickConfig() (*ClickConfig, error) {
+	config := &ClickConfig{QueueSize: 10000, BatchSize: 500, FlushInterval: time.Second, WriteTimeout: 5 * time.Second}
+	err := viper.UnmarshalKey("clicks", config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load clicks config: %v", err)
+	}
+
+	// every click takes 5 bind parameters and postgres allows 65535 per statement
+	if config.QueueSize <= 0 || config.BatchSize <= 0 || config.BatchSize > 10000 || config.FlushInterval <= 0 || config.WriteTimeout <= 0 {
+		return nil, fmt.Errorf("invalid clicks config: queue size %d, batch size %d, flush interval %s, write timeout %s", config.QueueSize, config.BatchSize, config.FlushInterval, config.WriteTimeout)
+	}
+
+	return config, nil
+}
+
+type PolicyConfig struct {
+	AllowedSchemes       []string `mapstructure:"ALLOWED_SCHEMES"`
+	BlockPrivateNetworks bool     `mapstructure:"BLOCK_PRIVATE_NETWORKS"`
+	ResolveHosts         bool     `mapstructure:"RESOLVE_HOSTS"`
+	DenylistFile         string   `mapstructure:"DENYLIST_FILE"`
+}
+
+func NewPolicyConfig() (*PolicyConfig, error) {
+	config := &PolicyConfig{AllowedSchemes: []string{"http", "https"}, BlockPrivateNetworks: true}
+	err := viper.UnmarshalKey("policy", config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load policy config: %v", err)
+	}
+
+	if len(config.AllowedSchemes) == 0 {
+		return nil, fmt.Errorf("invalid policy config: no allowed schemes")
+	}
+
+	return config, nil
+}
+
+// minBootstrapKeyLength keeps the bootstrap key about as hard to guess as a minted one.
+const minBootstrapKeyLength = 32
+
+type AuthConfig struct {
+	// BootstrapKey is accepted as an admin API key without being stored, it lets the
+	// first real keys be minted. Empty disables it.
+	BootstrapKey string `mapstructure:"BOOTSTRAP_KEY"`
+}
+
+func NewAuthConfig() (*AuthConfig, error) {
+	config := &AuthConfig{}
+	err := viper.UnmarshalKey("auth", config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load auth config: %v", err)
+	}
+
+	// the key is a secret, it is expected from AUTH_BOOTSTRAP_KEY rather than the
+	// config file and UnmarshalKey doesn't see environment overrides
+	if key := viper.GetString("auth.BOOTSTRAP_KEY"); key != "" {
+		config.BootstrapKey = key
+	}
+
+	if config.BootstrapKey != "" && len(config.BootstrapKey) < minBootstrapKeyLength {
+		return nil, fmt.Errorf("invalid bootstrap key: shorter than %d characters", minBootstrapKeyLength)
+	}
+
+	return config, nil
+}
+
+// SessionConfig leaves registering users to admin keys unless OpenRegistration lets
+// anyone sign up. Sessions can create and read links, so opening registration hands
+// that to anyone, as if they had been minted a create and read key.
+type SessionConfig struct {
+	AccessTTL        time.Duration `mapstructure:"ACCESS_TTL"`
+	RefreshTTL       time.Duration `mapstructure:"REFRESH_TTL"`
+	OpenRegistration bool          `mapstructure:"OPEN_REGISTRATION"`
+}
+
+func NewSessionConfig() (*SessionConfig, error) {
+	config := &SessionConfig{AccessTTL: 15 * time.Minute, RefreshTTL: 30 * 24 * time.Hour}
+	err := viper.UnmarshalKey("sessions", config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sessions config: %v", err)
+	}
+
+	// a refresh token that expires first would leave the session unable to renew
+	if config.AccessTTL <= 0 || config.RefreshTTL < config.AccessTTL {
+		return nil, fmt.Errorf("invalid sessions config: access ttl %s, refresh ttl %s", config.AccessTTL, config.RefreshTTL)
+	}
+
+	return config, nil
+}
+
+type WorkspaceConfig struct {
+	InvitationTTL time.Duration `mapstructure:"INVITATION_TTL"`
+}
+
+func NewWorkspaceConfig() (*WorkspaceConfig, error) {
+	config := &WorkspaceConfig{InvitationTTL: 7 * 24 * time.Hour}
+	err := viper.UnmarshalKey("workspaces", config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workspaces config: %v", err)
+	}
+
+	if config.InvitationTTL <= 0 {
+		return nil, fmt.Errorf("invalid workspaces config: invitation ttl %s", config.InvitationTTL)
+	}
+
+	return config, nil
+}
+
+// QuotaConfig caps how many links each API key or user creates, zero leaves a
+// period uncapped.
+type QuotaConfig struct {
+	Daily   int `mapstructure:"DAILY"`
+	Monthly int `mapstructure:"MONTHLY"`
+}
+
+func NewQuotaConfig() (*QuotaConfig, error) {
+	config := &QuotaConfig{Daily: 1000, Monthly: 10000}
+	err := viper.UnmarshalKey("quotas", config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load quotas config: %v", err)
+	}
+
+	// a daily quota above the monthly one could never be used up
+	if config.Daily < 0 || config.Monthly < 0 || (config.Monthly > 0 && config.Daily > config.Monthly) {
+		return nil, fmt.Errorf("invalid quotas config: daily %d, monthly %d", config.Daily, config.Monthly)
+	}
+
+	return config, nil
+}
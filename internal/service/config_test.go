@@ -0,0 +1,322 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    *Config
+		wantErr error
+	}{
+		{
+			name: "applies defaults for missing keys",
+			yaml: "service:\n  SHORTENER_HOST: http://localhost:8080\n",
+			want: &Config{ShortenerHost: "http://localhost:8080", KeyMinLength: 7, KeyMaxLength: 12, KeyMaxRetries: 5, BatchMaxSize: 100, CanonicalizeURLs: true},
+		},
+		{
+			name: "reads configured keys",
+			yaml: "service:\n  SHORTENER_HOST: http://localhost:8080\n  KEY_MIN_LENGTH: 6\n  KEY_MAX_LENGTH: 6\n  KEY_MAX_RETRIES: 1\n  BATCH_MAX_SIZE: 50\n  CANONICALIZE_URLS: false\n  SORT_QUERY_PARAMS: true\n",
+			want: &Config{ShortenerHost: "http://localhost:8080", KeyMinLength: 6, KeyMaxLength: 6, KeyMaxRetries: 1, BatchMaxSize: 50, SortQueryParams: true},
+		},
+		{
+			name:    "when key length range is invalid",
+			yaml:    "service:\n  KEY_MIN_LENGTH: 8\n  KEY_MAX_LENGTH: 7\n",
+			wantErr: errors.New("invalid key length range: 8..7"),
+		},
+		{
+			name:    "when batch max size is too large for a single insert",
+			yaml:    "service:\n  BATCH_MAX_SIZE: 20000\n",
+			wantErr: errors.New("invalid batch max size: 20000"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			viper.SetConfigType("yaml")
+			assert.NoError(t, viper.ReadConfig(strings.NewReader(tt.yaml)))
+
+			got, err := NewConfig()
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestNewReaperConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    *ReaperConfig
+		wantErr error
+	}{
+		{
+			name: "applies defaults for missing keys",
+			yaml: "service:\n  SHORTENER_HOST: http://localhost:8080\n",
+			want: &ReaperConfig{Interval: time.Minute, BatchSize: 500, GracePeriod: 720 * time.Hour},
+		},
+		{
+			name: "reads configured keys",
+			yaml: "reaper:\n  INTERVAL: 30s\n  BATCH_SIZE: 100\n  GRACE_PERIOD: 24h\n",
+			want: &ReaperConfig{Interval: 30 * time.Second, BatchSize: 100, GracePeriod: 24 * time.Hour},
+		},
+		{
+			name:    "when batch size is invalid",
+			yaml:    "reaper:\n  BATCH_SIZE: 0\n",
+			wantErr: errors.New("invalid reaper config: interval 1m0s, batch size 0"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			viper.SetConfigType("yaml")
+			assert.NoError(t, viper.ReadConfig(strings.NewReader(tt.yaml)))
+
+			got, err := NewReaperConfig()
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestNewClickConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    *ClickConfig
+		wantErr error
+	}{
+		{
+			name: "applies defaults for missing keys",
+			yaml: "service:\n  SHORTENER_HOST: http://localhost:8080\n",
+			want: &ClickConfig{QueueSize: 10000, BatchSize: 500, FlushInterval: time.Second, WriteTimeout: 5 * time.Second},
+		},
+		{
+			name: "reads configured keys",
+			yaml: "clicks:\n  QUEUE_SIZE: 100\n  BATCH_SIZE: 10\n  FLUSH_INTERVAL: 500ms\n  WRITE_TIMEOUT: 1s\n",
+			want: &ClickConfig{QueueSize: 100, BatchSize: 10, FlushInterval: 500 * time.Millisecond, WriteTimeout: time.Second},
+		},
+		{
+			name:    "when batch size is too large",
+			yaml:    "clicks:\n  BATCH_SIZE: 20000\n",
+			wantErr: errors.New("invalid clicks config: queue size 10000, batch size 20000, flush interval 1s, write timeout 5s"),
+		},
+		{
+			name:    "when write timeout is not positive",
+			yaml:    "clicks:\n  WRITE_TIMEOUT: 0s\n",
+			wantErr: errors.New("invalid clicks config: queue size 10000, batch size 500, flush interval 1s, write timeout 0s"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			viper.SetConfigType("yaml")
+			assert.NoError(t, viper.ReadConfig(strings.NewReader(tt.yaml)))
+
+			got, err := NewClickConfig()
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestNewPolicyConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    *PolicyConfig
+		wantErr error
+	}{
+		{
+			name: "applies defaults for missing keys",
+			yaml: "service:\n  SHORTENER_HOST: http://localhost:8080\n",
+			want: &PolicyConfig{AllowedSchemes: []string{"http", "https"}, BlockPrivateNetworks: true},
+		},
+		{
+			name: "reads configured keys",
+			yaml: "policy:\n  ALLOWED_SCHEMES: [https]\n  BLOCK_PRIVATE_NETWORKS: false\n  RESOLVE_HOSTS: true\n  DENYLIST_FILE: ./configs/denylist.txt\n",
+			want: &PolicyConfig{AllowedSchemes: []string{"https"}, ResolveHosts: true, DenylistFile: "./configs/denylist.txt"},
+		},
+		{
+			name:    "when no scheme is allowed",
+			yaml:    "policy:\n  ALLOWED_SCHEMES: []\n",
+			wantErr: errors.New("invalid policy config: no allowed schemes"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			viper.SetConfigType("yaml")
+			assert.NoError(t, viper.ReadConfig(strings.NewReader(tt.yaml)))
+
+			got, err := NewPolicyConfig()
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestNewAuthConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		env     string
+		want    *AuthConfig
+		wantErr error
+	}{
+		{
+			name: "leaves the bootstrap key off by default",
+			yaml: "auth:\n  BOOTSTRAP_KEY: \"\"\n",
+			want: &AuthConfig{},
+		},
+		{
+			name: "reads the bootstrap key from the environment",
+			yaml: "auth:\n  BOOTSTRAP_KEY: \"\"\n",
+			env:  "a-bootstrap-key-long-enough-to-use",
+			want: &AuthConfig{BootstrapKey: "a-bootstrap-key-long-enough-to-use"},
+		},
+		{
+			name:    "when bootstrap key is too short",
+			yaml:    "auth:\n  BOOTSTRAP_KEY: secret\n",
+			wantErr: errors.New("invalid bootstrap key: shorter than 32 characters"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			viper.SetConfigType("yaml")
+			viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+			viper.AutomaticEnv()
+			t.Setenv("AUTH_BOOTSTRAP_KEY", tt.env)
+			assert.NoError(t, viper.ReadConfig(strings.NewReader(tt.yaml)))
+
+			got, err := NewAuthConfig()
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestNewSessionConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    *SessionConfig
+		wantErr error
+	}{
+		{
+			name: "applies defaults for missing keys",
+			yaml: "sessions:\n",
+			want: &SessionConfig{AccessTTL: 15 * time.Minute, RefreshTTL: 720 * time.Hour},
+		},
+		{
+			name: "reads configured keys",
+			yaml: "sessions:\n  ACCESS_TTL: 5m\n  REFRESH_TTL: 24h\n  OPEN_REGISTRATION: true\n",
+			want: &SessionConfig{AccessTTL: 5 * time.Minute, RefreshTTL: 24 * time.Hour, OpenRegistration: true},
+		},
+		{
+			name:    "when refresh token would expire before the access token",
+			yaml:    "sessions:\n  ACCESS_TTL: 1h\n  REFRESH_TTL: 5m\n",
+			wantErr: errors.New("invalid sessions config: access ttl 1h0m0s, refresh ttl 5m0s"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			viper.SetConfigType("yaml")
+			assert.NoError(t, viper.ReadConfig(strings.NewReader(tt.yaml)))
+
+			got, err := NewSessionConfig()
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestNewWorkspaceConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    *WorkspaceConfig
+		wantErr error
+	}{
+		{
+			name: "applies defaults for missing keys",
+			yaml: "workspaces:\n",
+			want: &WorkspaceConfig{InvitationTTL: 168 * time.Hour},
+		},
+		{
+			name: "reads configured keys",
+			yaml: "workspaces:\n  INVITATION_TTL: 48h\n",
+			want: &WorkspaceConfig{InvitationTTL: 48 * time.Hour},
+		},
+		{
+			name:    "when invitations would never be valid",
+			yaml:    "workspaces:\n  INVITATION_TTL: 0s\n",
+			wantErr: errors.New("invalid workspaces config: invitation ttl 0s"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			viper.SetConfigType("yaml")
+			assert.NoError(t, viper.ReadConfig(strings.NewReader(tt.yaml)))
+
+			got, err := NewWorkspaceConfig()
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestNewQuotaConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    *QuotaConfig
+		wantErr error
+	}{
+		{
+			name: "applies defaults for missing keys",
+			yaml: "quotas:\n",
+			want: &QuotaConfig{Daily: 1000, Monthly: 10000},
+		},
+		{
+			name: "reads configured keys",
+			yaml: "quotas:\n  DAILY: 0\n  MONTHLY: 50\n",
+			want: &QuotaConfig{Daily: 0, Monthly: 50},
+		},
+		{
+			name:    "when the daily quota is above the monthly one",
+			yaml:    "quotas:\n  DAILY: 100\n  MONTHLY: 50\n",
+			wantErr: errors.New("invalid quotas config: daily 100, monthly 50"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			viper.SetConfigType("yaml")
+			assert.NoError(t, viper.ReadConfig(strings.NewReader(tt.yaml)))
+
+			got, err := NewQuotaConfig()
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
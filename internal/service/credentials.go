@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+)
+
+type CredentialChecker interface {
+	Authenticate(ctx context.Context, secret string) (model.Principal, error)
+}
+
+// Credentials checks a bearer secret with the service that issued it. Session tokens
+// are told apart by their prefix, anything else is taken for an API key.
+type Credentials struct {
+	apiKeys  CredentialChecker
+	sessions CredentialChecker
+}
+
+func NewCredentials(apiKeys CredentialChecker, sessions CredentialChecker) *Credentials {
+	return &Credentials{apiKeys: apiKeys, sessions: sessions}
+}
+
+func (c *Credentials) Authenticate(ctx context.Context, secret string) (model.Principal, error) {
+	if strings.HasPrefix(secret, SessionTokenPrefix) {
+		return c.sessions.Authenticate(ctx, secret)
+	}
+
+	return c.apiKeys.Authenticate(ctx, secret)
+}
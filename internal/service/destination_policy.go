@@ -0,0 +1,178 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"golang.org/x/net/idna"
+)
+
+// Destination policy errors are returned when a long URL is well formed but not
+// allowed as a destination.
+var (
+	ErrSchemeNotAllowed    = apperror.New("scheme_not_allowed", http.StatusUnprocessableEntity, "url scheme is not allowed")
+	ErrMissingHost         = apperror.New("missing_host", http.StatusUnprocessableEntity, "url has no host")
+	ErrPrivateDestination  = apperror.New("private_destination", http.StatusUnprocessableEntity, "url points to a private network address")
+	ErrDeniedDomain        = apperror.New("denied_domain", http.StatusUnprocessableEntity, "url domain is denied")
+	ErrSelfReferencingLink = apperror.New("self_referencing_link", http.StatusUnprocessableEntity, "url points back to the shortener")
+)
+
+// privateHostSuffixes are names that only ever resolve inside a local network.
+var privateHostSuffixes = []string{"localhost", "local", "internal", "localdomain"}
+
+type DestinationPolicy struct {
+	config        PolicyConfig
+	schemes       map[string]bool
+	deniedDomains map[string]bool
+	shortenerHost string
+	lookupIP      func(ctx context.Context, network, host string) ([]net.IP, error)
+}
+
+func NewDestinationPolicy(config PolicyConfig, shortenerHost string) (*DestinationPolicy, error) {
+	p := &DestinationPolicy{
+		config:        config,
+		schemes:       map[string]bool{},
+		deniedDomains: map[string]bool{},
+		lookupIP:      net.DefaultResolver.LookupIP,
+	}
+
+	for _, scheme := range config.AllowedSchemes {
+		p.schemes[strings.ToLower(scheme)] = true
+	}
+
+	if shortenerHost != "" {
+		u, err := url.Parse(shortenerHost)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse shortener host: %v", err)
+		}
+		p.shortenerHost = strings.ToLower(u.Hostname())
+	}
+
+	if config.DenylistFile != "" {
+		err := p.loadDenylist(config.DenylistFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+func (p *DestinationPolicy) Check(ctx context.Context, longURL url.URL) error {
+	if !p.schemes[strings.ToLower(longURL.Scheme)] {
+		return ErrSchemeNotAllowed
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(longURL.Hostname()), ".")
+	if host == "" {
+		return ErrMissingHost
+	}
+
+	// any port on the shortener's host counts, a link there only ever redirects again
+	if p.shortenerHost != "" && host == p.shortenerHost {
+		return ErrSelfReferencingLink
+	}
+
+	if p.isDenied(host) {
+		return ErrDeniedDomain
+	}
+
+	if p.config.BlockPrivateNetworks {
+		return p.checkPrivate(ctx, host)
+	}
+
+	return nil
+}
+
+func (p *DestinationPolicy) checkPrivate(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if isPrivateIP(ip) {
+			return ErrPrivateDestination
+		}
+		return nil
+	}
+
+	for _, suffix := range privateHostSuffixes {
+		if host == suffix || strings.HasSuffix(host, "."+suffix) {
+			return ErrPrivateDestination
+		}
+	}
+
+	if !p.config.ResolveHosts {
+		return nil
+	}
+
+	ips, err := p.lookupIP(ctx, "ip", host)
+	if err != nil {
+		// an unresolvable host can't reach the private network either
+		slog.Warn(fmt.Sprintf("failed to resolve destination host %s: %v", host, err))
+		return nil
+	}
+
+	for _, ip := range ips {
+		if isPrivateIP(ip) {
+			return ErrPrivateDestination
+		}
+	}
+
+	return nil
+}
+
+// isDenied matches the host and every parent domain against the denylist, so
+// denying example.com also denies www.example.com.
+func (p *DestinationPolicy) isDenied(host string) bool {
+	for {
+		if p.deniedDomains[host] {
+			return true
+		}
+
+		_, parent, found := strings.Cut(host, ".")
+		if !found {
+			return false
+		}
+		host = parent
+	}
+}
+
+// loadDenylist reads one domain per line, blank lines and lines starting with # are skipped.
+func (p *DestinationPolicy) loadDenylist(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open denylist: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		domain, err := idna.Lookup.ToASCII(strings.TrimSuffix(strings.ToLower(line), "."))
+		if err != nil {
+			return fmt.Errorf("invalid denylist domain %q: %v", line, err)
+		}
+		p.deniedDomains[domain] = true
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return fmt.Errorf("failed to read denylist: %v", err)
+	}
+
+	return nil
+}
+
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified()
+}
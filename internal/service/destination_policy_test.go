@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDestinationPolicy_Check(t *testing.T) {
+	denylist := filepath.Join(t.TempDir(), "denylist.txt")
+	assert.NoError(t, os.WriteFile(denylist, []byte("# known bad\nEvil.example\n\nbücher.example\n"), 0o600))
+
+	config := PolicyConfig{AllowedSchemes: []string{"http", "https"}, BlockPrivateNetworks: true, DenylistFile: denylist}
+
+	tests := []struct {
+		name    string
+		rawURL  string
+		config  PolicyConfig
+		wantErr error
+	}{
+		{name: "allows public https url", rawURL: "https://example.com/a", config: config},
+		{name: "allows public ip literal", rawURL: "http://93.184.216.34/a", config: config},
+		{name: "rejects javascript scheme", rawURL: "javascript:alert(1)", config: config, wantErr: ErrSchemeNotAllowed},
+		{name: "rejects file scheme", rawURL: "file:///etc/passwd", config: config, wantErr: ErrSchemeNotAllowed},
+		{name: "rejects data scheme", rawURL: "data:text/html,hi", config: config, wantErr: ErrSchemeNotAllowed},
+		{name: "rejects url without host", rawURL: "http:///a", config: config, wantErr: ErrMissingHost},
+		{name: "rejects loopback ip", rawURL: "http://127.0.0.1:9000/", config: config, wantErr: ErrPrivateDestination},
+		{name: "rejects rfc1918 ip", rawURL: "http://10.1.2.3/", config: config, wantErr: ErrPrivateDestination},
+		{name: "rejects link local ip", rawURL: "http://169.254.169.254/latest/meta-data", config: config, wantErr: ErrPrivateDestination},
+		{name: "rejects ipv6 loopback", rawURL: "http://[::1]/", config: config, wantErr: ErrPrivateDestination},
+		{name: "rejects localhost", rawURL: "http://LOCALHOST:3000/", config: config, wantErr: ErrPrivateDestination},
+		{name: "rejects local subdomain", rawURL: "http://printer.local/", config: config, wantErr: ErrPrivateDestination},
+		{
+			name:   "allows private ip when blocking is off",
+			rawURL: "http://10.1.2.3/",
+			config: PolicyConfig{AllowedSchemes: []string{"http"}},
+		},
+		{name: "rejects denied domain", rawURL: "https://evil.example/", config: config, wantErr: ErrDeniedDomain},
+		{name: "rejects denied subdomain", rawURL: "https://www.evil.example/", config: config, wantErr: ErrDeniedDomain},
+		{name: "rejects denied idn domain", rawURL: "https://xn--bcher-kva.example/", config: config, wantErr: ErrDeniedDomain},
+		{name: "allows domain that only shares a suffix", rawURL: "https://notevil.example/", config: config},
+		{name: "rejects self referencing link", rawURL: "https://Sho.rt/api/v1/abc", config: config, wantErr: ErrSelfReferencingLink},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewDestinationPolicy(tt.config, "https://sho.rt")
+			assert.NoError(t, err)
+			longURL, err := url.Parse(tt.rawURL)
+			assert.NoError(t, err)
+
+			err = p.Check(context.Background(), *longURL)
+
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestDestinationPolicy_Check_ResolveHosts(t *testing.T) {
+	tests := []struct {
+		name     string
+		lookupIP func(ctx context.Context, network, host string) ([]net.IP, error)
+		wantErr  error
+	}{
+		{
+			name: "rejects host resolving to a private ip",
+			lookupIP: func(ctx context.Context, network, host string) ([]net.IP, error) {
+				return []net.IP{net.ParseIP("93.184.216.34"), net.ParseIP("192.168.0.10")}, nil
+			},
+			wantErr: ErrPrivateDestination,
+		},
+		{
+			name: "allows host resolving to public ips",
+			lookupIP: func(ctx context.Context, network, host string) ([]net.IP, error) {
+				return []net.IP{net.ParseIP("93.184.216.34")}, nil
+			},
+		},
+		{
+			name: "allows host that does not resolve",
+			lookupIP: func(ctx context.Context, network, host string) ([]net.IP, error) {
+				return nil, errors.New("no such host")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewDestinationPolicy(PolicyConfig{AllowedSchemes: []string{"https"}, BlockPrivateNetworks: true, ResolveHosts: true}, "")
+			assert.NoError(t, err)
+			p.lookupIP = tt.lookupIP
+
+			err = p.Check(context.Background(), url.URL{Scheme: "https", Host: "example.com"})
+
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestNewDestinationPolicy(t *testing.T) {
+	t.Run("when denylist file is missing", func(t *testing.T) {
+		_, err := NewDestinationPolicy(PolicyConfig{DenylistFile: filepath.Join(t.TempDir(), "missing.txt")}, "")
+
+		assert.ErrorContains(t, err, "failed to open denylist")
+	})
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+type ExpiredLinkRepository interface {
+	DeleteExpired(ctx context.Context, before time.Time, limit int) ([]string, error)
+}
+
+// ExpirationReaper periodically deletes links that expired more than a grace period
+// ago. Until then RetrieveURL keeps answering 410 Gone for them.
+type ExpirationReaper struct {
+	repository ExpiredLinkRepository
+	config     ReaperConfig
+	now        func() time.Time
+	cancel     context.CancelFunc
+	done       chan struct{}
+}
+
+func NewExpirationReaper(repository ExpiredLinkRepository, config ReaperConfig) *ExpirationReaper {
+	return &ExpirationReaper{repository: repository, config: config, now: time.Now}
+}
+
+func (r *ExpirationReaper) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.done = make(chan struct{})
+
+	go r.run(ctx)
+}
+
+// Stop cancels the reaper and waits for an in-progress batch to finish.
+func (r *ExpirationReaper) Stop() {
+	if r.cancel == nil {
+		return
+	}
+
+	r.cancel()
+	<-r.done
+}
+
+func (r *ExpirationReaper) run(ctx context.Context) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Reap(ctx)
+		}
+	}
+}
+
+// Reap deletes expired links batch by batch until a batch comes back short.
+func (r *ExpirationReaper) Reap(ctx context.Context) int64 {
+	before := r.now().Add(-r.config.GracePeriod)
+
+	var total int64
+	for ctx.Err() == nil {
+		deleted, err := r.repository.DeleteExpired(ctx, before, r.config.BatchSize)
+		if err != nil {
+			slog.Error(fmt.Sprintf("failed to reap expired links: %v", err))
+			break
+		}
+
+		total += int64(len(deleted))
+		if len(deleted) < r.config.BatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		slog.Info(fmt.Sprintf("reaped %d expired links", total))
+	}
+
+	return total
+}
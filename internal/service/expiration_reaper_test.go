@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExpirationReaper_Reap(t *testing.T) {
+	now := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2026, 1, 30, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		setup func(*MockExpiredLinkRepository)
+		want  int64
+	}{
+		{
+			name: "when repository failed",
+			setup: func(r *MockExpiredLinkRepository) {
+				r.On("DeleteExpired", mock.Anything, before, 2).Return([]string(nil), errors.New("db error"))
+			},
+		},
+		{
+			name: "when nothing expired",
+			setup: func(r *MockExpiredLinkRepository) {
+				r.On("DeleteExpired", mock.Anything, before, 2).Return([]string{}, nil)
+			},
+		},
+		{
+			name: "when expired links span several batches",
+			setup: func(r *MockExpiredLinkRepository) {
+				r.On("DeleteExpired", mock.Anything, before, 2).Return([]string{"a-encoded-key", "b-encoded-key"}, nil).Twice()
+				r.On("DeleteExpired", mock.Anything, before, 2).Return([]string{"c-encoded-key"}, nil).Once()
+			},
+			want: 5,
+		},
+		{
+			name: "when a later batch failed",
+			setup: func(r *MockExpiredLinkRepository) {
+				r.On("DeleteExpired", mock.Anything, before, 2).Return([]string{"a-encoded-key", "b-encoded-key"}, nil).Once()
+				r.On("DeleteExpired", mock.Anything, before, 2).Return([]string(nil), errors.New("db error")).Once()
+			},
+			want: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockExpiredLinkRepository{}
+			tt.setup(m)
+
+			r := NewExpirationReaper(m, ReaperConfig{Interval: time.Minute, BatchSize: 2, GracePeriod: 24 * time.Hour})
+			r.now = func() time.Time { return now }
+
+			got := r.Reap(context.Background())
+
+			assert.Equal(t, tt.want, got)
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestExpirationReaper_StartStop(t *testing.T) {
+	m := &MockExpiredLinkRepository{}
+	reaped := make(chan struct{}, 1)
+	m.On("DeleteExpired", mock.Anything, mock.Anything, 10).Return([]string{}, nil).Run(func(mock.Arguments) {
+		select {
+		case reaped <- struct{}{}:
+		default:
+		}
+	})
+
+	r := NewExpirationReaper(m, ReaperConfig{Interval: time.Millisecond, BatchSize: 10})
+	r.Start(context.Background())
+
+	select {
+	case <-reaped:
+	case <-time.After(time.Second):
+		t.Fatal("reaper did not run")
+	}
+
+	r.Stop()
+}
+
+type MockExpiredLinkRepository struct {
+	mock.Mock
+}
+
+func (m *MockExpiredLinkRepository) DeleteExpired(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Get(0).([]string), args.Error(1)
+}
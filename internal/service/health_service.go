@@ -1,24 +1,45 @@
 package service
 
-import "context"
+import (
+	"context"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+)
 
 type DBClient interface {
 	Ping() error
 }
 
+type LinkCache interface {
+	Stats() model.CacheStats
+}
+
 type HealthService struct {
-	dbClient DBClient
+	dbClients map[string]DBClient
+	cache     LinkCache
 }
 
-func NewHealthService(dbClient DBClient) *HealthService {
-	return &HealthService{dbClient: dbClient}
+// NewHealthService reports on dbClients and on cache, nil when links aren't cached.
+func NewHealthService(dbClients map[string]DBClient, cache LinkCache) *HealthService {
+	return &HealthService{dbClients: dbClients, cache: cache}
 }
 
 func (s *HealthService) Health(ctx context.Context) map[string]bool {
 	m := map[string]bool{}
 
-	err := s.dbClient.Ping()
-	m["postgres"] = err == nil
+	for name, dbClient := range s.dbClients {
+		err := dbClient.Ping()
+		m[name] = err == nil
+	}
 
 	return m
 }
+
+// CacheStats tells how well the link cache does, false when it is disabled.
+func (s *HealthService) CacheStats(ctx context.Context) (model.CacheStats, bool) {
+	if s.cache == nil {
+		return model.CacheStats{}, false
+	}
+
+	return s.cache.Stats(), true
+}
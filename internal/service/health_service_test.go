@@ -5,6 +5,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/ggoulart/url-shortener/internal/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -33,7 +34,7 @@ func TestHealthService_Health(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			m := &MockDBClient{}
-			s := NewHealthService(m)
+			s := NewHealthService(map[string]DBClient{"postgres": m}, nil)
 			tt.setup(m)
 
 			got := s.Health(context.Background())
@@ -43,6 +44,22 @@ func TestHealthService_Health(t *testing.T) {
 	}
 }
 
+func TestHealthService_CacheStats(t *testing.T) {
+	stats, enabled := NewHealthService(nil, nil).CacheStats(context.Background())
+	assert.False(t, enabled)
+	assert.Equal(t, model.CacheStats{}, stats)
+
+	stats, enabled = NewHealthService(nil, fixedCache{Hits: 3, Misses: 1, Size: 1}).CacheStats(context.Background())
+	assert.True(t, enabled)
+	assert.Equal(t, model.CacheStats{Hits: 3, Misses: 1, Size: 1}, stats)
+}
+
+type fixedCache model.CacheStats
+
+func (c fixedCache) Stats() model.CacheStats {
+	return model.CacheStats(c)
+}
+
 type MockDBClient struct {
 	mock.Mock
 }
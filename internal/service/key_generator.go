@@ -0,0 +1,50 @@
+package service
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+)
+
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// maxUnbiasedByte is the largest multiple of len(base62Alphabet) that fits in a byte.
+// Bytes at or above it are discarded so every character is equally likely.
+const maxUnbiasedByte = 256 - 256%len(base62Alphabet)
+
+type KeyGenerator interface {
+	Generate(length int) (string, error)
+}
+
+type Base62KeyGenerator struct {
+	random io.Reader
+}
+
+func NewBase62KeyGenerator() *Base62KeyGenerator {
+	return &Base62KeyGenerator{random: rand.Reader}
+}
+
+func (g *Base62KeyGenerator) Generate(length int) (string, error) {
+	key := make([]byte, 0, length)
+	buf := make([]byte, length)
+
+	for len(key) < length {
+		_, err := io.ReadFull(g.random, buf)
+		if err != nil {
+			return "", fmt.Errorf("failed to read random bytes: %v", err)
+		}
+
+		for _, b := range buf {
+			if int(b) >= maxUnbiasedByte {
+				continue
+			}
+
+			key = append(key, base62Alphabet[int(b)%len(base62Alphabet)])
+			if len(key) == length {
+				break
+			}
+		}
+	}
+
+	return string(key), nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBase62KeyGenerator_Generate(t *testing.T) {
+	tests := []struct {
+		name    string
+		random  func() *bytes.Reader
+		length  int
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "maps random bytes to base62 characters",
+			random: func() *bytes.Reader { return bytes.NewReader([]byte{0, 10, 36, 61, 62, 123}) },
+			length: 6,
+			want:   "0Aaz0z",
+		},
+		{
+			name:   "discards bytes that would bias the alphabet",
+			random: func() *bytes.Reader { return bytes.NewReader([]byte{248, 255, 1, 2, 3, 4}) },
+			length: 3,
+			want:   "123",
+		},
+		{
+			name:    "when random source is exhausted",
+			random:  func() *bytes.Reader { return bytes.NewReader([]byte{1}) },
+			length:  3,
+			wantErr: errors.New("failed to read random bytes: unexpected EOF"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Base62KeyGenerator{random: tt.random()}
+
+			got, err := g.Generate(tt.length)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestNewBase62KeyGenerator(t *testing.T) {
+	g := NewBase62KeyGenerator()
+
+	got, err := g.Generate(12)
+
+	assert.NoError(t, err)
+	assert.Len(t, got, 12)
+	for _, c := range got {
+		assert.True(t, strings.ContainsRune(base62Alphabet, c))
+	}
+}
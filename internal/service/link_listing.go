@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"golang.org/x/net/idna"
+)
+
+var ErrInvalidListQuery = apperror.New("invalid_list_query", http.StatusBadRequest, "invalid list query")
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+// linkCursor is what an opaque cursor holds, clients must not rely on its layout.
+type linkCursor struct {
+	CreatedAt  time.Time `json:"t"`
+	EncodedKey string    `json:"k"`
+}
+
+// ListLinks returns a page of links matching filter, newest first. cursor comes from
+// the previous page and the next page's cursor is empty once the listing is done.
+// A request acting in a workspace sees the workspace's links, a signed in user
+// otherwise only sees their own links and API keys see every link.
+func (s *ShortenerService) ListLinks(ctx context.Context, filter model.LinkFilter, cursor string) (model.LinkPage, error) {
+	principal, _ := PrincipalFromContext(ctx)
+	switch {
+	case principal.WorkspaceID != "":
+		_, err := s.access.Authorize(ctx, principal.WorkspaceID, model.ActionViewLinks)
+		if err != nil {
+			return model.LinkPage{}, err
+		}
+		filter.WorkspaceID = principal.WorkspaceID
+	case principal.UserID != "":
+		filter.OwnerID = principal.UserID
+	}
+
+	filter, err := normalizeLinkFilter(filter, cursor)
+	if err != nil {
+		return model.LinkPage{}, err
+	}
+
+	// one link past the page tells whether there is a next page
+	limit := filter.Limit
+	filter.Limit++
+
+	links, err := s.repository.ListLinks(ctx, filter)
+	if err != nil {
+		return model.LinkPage{}, err
+	}
+
+	if len(links) <= limit {
+		return model.LinkPage{Links: links}, nil
+	}
+
+	links = links[:limit]
+	last := links[limit-1]
+
+	return model.LinkPage{Links: links, NextCursor: encodeLinkCursor(model.LinkCursor{CreatedAt: last.CreatedAt, EncodedKey: last.EncodedKey})}, nil
+}
+
+func normalizeLinkFilter(filter model.LinkFilter, cursor string) (model.LinkFilter, error) {
+	if filter.Limit == 0 {
+		filter.Limit = defaultListLimit
+	}
+	if filter.Limit < 0 || filter.Limit > maxListLimit {
+		return model.LinkFilter{}, ErrInvalidListQuery
+	}
+
+	if !filter.CreatedFrom.IsZero() && !filter.CreatedTo.IsZero() && !filter.CreatedFrom.Before(filter.CreatedTo) {
+		return model.LinkFilter{}, ErrInvalidListQuery
+	}
+
+	if filter.Domain != "" {
+		domain, err := idna.Lookup.ToASCII(strings.ToLower(filter.Domain))
+		if err != nil {
+			return model.LinkFilter{}, ErrInvalidListQuery
+		}
+		filter.Domain = domain
+	}
+
+	if cursor != "" {
+		after, err := decodeLinkCursor(cursor)
+		if err != nil {
+			return model.LinkFilter{}, ErrInvalidListQuery
+		}
+		filter.After = &after
+	}
+
+	return filter, nil
+}
+
+func encodeLinkCursor(cursor model.LinkCursor) string {
+	// marshalling a struct of a time and a string can't fail
+	payload, _ := json.Marshal(linkCursor{CreatedAt: cursor.CreatedAt, EncodedKey: cursor.EncodedKey})
+
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+func decodeLinkCursor(cursor string) (model.LinkCursor, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return model.LinkCursor{}, err
+	}
+
+	var decoded linkCursor
+	err = json.Unmarshal(payload, &decoded)
+	if err != nil {
+		return model.LinkCursor{}, err
+	}
+
+	if decoded.CreatedAt.IsZero() || decoded.EncodedKey == "" {
+		return model.LinkCursor{}, errors.New("incomplete cursor")
+	}
+
+	return model.LinkCursor{CreatedAt: decoded.CreatedAt, EncodedKey: decoded.EncodedKey}, nil
+}
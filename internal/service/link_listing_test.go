@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestShortenerService_ListLinks(t *testing.T) {
+	jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	aLink := model.Link{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "https", Host: "go.dev"}, CreatedAt: feb}
+	bLink := model.Link{EncodedKey: "b-encoded-key", LongURL: url.URL{Scheme: "https", Host: "go.dev"}, CreatedAt: jan}
+	aCursor := encodeLinkCursor(model.LinkCursor{CreatedAt: feb, EncodedKey: "a-encoded-key"})
+
+	tests := []struct {
+		name    string
+		filter  model.LinkFilter
+		cursor  string
+		setup   func(*MockShortenerRepository)
+		want    model.LinkPage
+		wantErr error
+	}{
+		{
+			name:    "when limit is too large",
+			filter:  model.LinkFilter{Limit: 501},
+			setup:   func(*MockShortenerRepository) {},
+			wantErr: ErrInvalidListQuery,
+		},
+		{
+			name:    "when creation range is reversed",
+			filter:  model.LinkFilter{CreatedFrom: feb, CreatedTo: jan},
+			setup:   func(*MockShortenerRepository) {},
+			wantErr: ErrInvalidListQuery,
+		},
+		{
+			name:    "when cursor is not one we handed out",
+			cursor:  "bm90LWEtY3Vyc29y",
+			setup:   func(*MockShortenerRepository) {},
+			wantErr: ErrInvalidListQuery,
+		},
+		{
+			name: "when repository failed",
+			setup: func(r *MockShortenerRepository) {
+				r.On("ListLinks", context.Background(), model.LinkFilter{Limit: 51}).Return([]model.Link(nil), errors.New("db error"))
+			},
+			wantErr: errors.New("db error"),
+		},
+		{
+			name:   "when there are more links than the page holds",
+			filter: model.LinkFilter{Limit: 1, Domain: "GO.dev"},
+			setup: func(r *MockShortenerRepository) {
+				r.On("ListLinks", context.Background(), model.LinkFilter{Limit: 2, Domain: "go.dev"}).Return([]model.Link{aLink, bLink}, nil)
+			},
+			want: model.LinkPage{Links: []model.Link{aLink}, NextCursor: aCursor},
+		},
+		{
+			name:   "when resuming from a cursor reaches the last page",
+			filter: model.LinkFilter{Limit: 1},
+			cursor: aCursor,
+			setup: func(r *MockShortenerRepository) {
+				r.On("ListLinks", context.Background(), model.LinkFilter{Limit: 2, After: &model.LinkCursor{CreatedAt: feb, EncodedKey: "a-encoded-key"}}).Return([]model.Link{bLink}, nil)
+			},
+			want: model.LinkPage{Links: []model.Link{bLink}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &MockShortenerRepository{}
+			tt.setup(r)
+			s := newTestShortenerService(r, &MockKeyGenerator{}, allowAllPolicy(), testConfig)
+
+			got, err := s.ListLinks(context.Background(), tt.filter, tt.cursor)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestShortenerService_ListLinks_SignedIn(t *testing.T) {
+	r := &MockShortenerRepository{}
+	s := newTestShortenerService(r, &MockKeyGenerator{}, allowAllPolicy(), testConfig)
+	ctx := ContextWithPrincipal(context.Background(), model.Principal{UserID: "a-user-id"})
+	r.On("ListLinks", ctx, model.LinkFilter{OwnerID: "a-user-id", Limit: 51}).Return([]model.Link{}, nil)
+
+	got, err := s.ListLinks(ctx, model.LinkFilter{OwnerID: "another-user-id"}, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.LinkPage{Links: []model.Link{}}, got)
+	r.AssertExpectations(t)
+}
+
+func TestShortenerService_ListLinks_Workspace(t *testing.T) {
+	ctx := ContextWithPrincipal(context.Background(), model.Principal{UserID: "a-user-id", WorkspaceID: "a-workspace-id"})
+
+	t.Run("when a member of the workspace", func(t *testing.T) {
+		r := &MockShortenerRepository{}
+		a := &MockAccessPolicy{}
+		s := newTestShortenerService(r, &MockKeyGenerator{}, allowAllPolicy(), testConfig)
+		s.access = a
+		a.On("Authorize", ctx, "a-workspace-id", model.ActionViewLinks).Return(model.Member{Role: model.RoleViewer}, nil)
+		r.On("ListLinks", ctx, model.LinkFilter{WorkspaceID: "a-workspace-id", Limit: 51}).Return([]model.Link{}, nil)
+
+		got, err := s.ListLinks(ctx, model.LinkFilter{}, "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.LinkPage{Links: []model.Link{}}, got)
+		r.AssertExpectations(t)
+	})
+
+	t.Run("when not a member of the workspace", func(t *testing.T) {
+		r := &MockShortenerRepository{}
+		a := &MockAccessPolicy{}
+		s := newTestShortenerService(r, &MockKeyGenerator{}, allowAllPolicy(), testConfig)
+		s.access = a
+		a.On("Authorize", ctx, "a-workspace-id", model.ActionViewLinks).Return(model.Member{}, ErrNotWorkspaceMember)
+
+		_, err := s.ListLinks(ctx, model.LinkFilter{}, "")
+
+		assert.Equal(t, ErrNotWorkspaceMember, err)
+		r.AssertNotCalled(t, "ListLinks", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+const managementTokenBytes = 32
+
+// newManagementToken returns a random secret, only its hash is ever stored. The token
+// has enough entropy that a fast unsalted hash is not a brute force risk.
+func newManagementToken() (string, error) {
+	b := make([]byte, managementTokenBytes)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %v", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashManagementToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
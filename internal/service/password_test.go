@@ -0,0 +1,64 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testArgon2Params = argon2Params{memory: 64, iterations: 1, parallelism: 1, saltLength: 16, keyLength: 32}
+
+func TestHashPassword(t *testing.T) {
+	hash, err := hashPassword("correct horse battery staple", testArgon2Params)
+	assert.NoError(t, err)
+	assert.Regexp(t, `^\$argon2id\$v=19\$m=64,t=1,p=1\$[A-Za-z0-9+/]{22}\$[A-Za-z0-9+/]{43}$`, hash)
+
+	other, err := hashPassword("correct horse battery staple", testArgon2Params)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, other, "every hash has its own salt")
+}
+
+func TestVerifyPassword(t *testing.T) {
+	hash, err := hashPassword("correct horse battery staple", testArgon2Params)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		password string
+		hash     string
+		want     bool
+		wantErr  error
+	}{
+		{
+			name:     "when password matches",
+			password: "correct horse battery staple",
+			hash:     hash,
+			want:     true,
+		},
+		{
+			name:     "when password does not match",
+			password: "Correct horse battery staple",
+			hash:     hash,
+		},
+		{
+			name:     "when hash is of another algorithm",
+			password: "correct horse battery staple",
+			hash:     "$2a$10$abcdefghijklmnopqrstuv",
+			wantErr:  errMalformedPasswordHash,
+		},
+		{
+			name:     "when hash has a broken salt",
+			password: "correct horse battery staple",
+			hash:     "$argon2id$v=19$m=64,t=1,p=1$not-base64!$AAAA",
+			wantErr:  errMalformedPasswordHash,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := verifyPassword(tt.password, tt.hash)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
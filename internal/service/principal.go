@@ -0,0 +1,20 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+)
+
+type principalKey struct{}
+
+// ContextWithPrincipal attaches who the request authenticated as to ctx.
+func ContextWithPrincipal(ctx context.Context, principal model.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal attached to ctx, if the request authenticated.
+func PrincipalFromContext(ctx context.Context) (model.Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(model.Principal)
+	return principal, ok
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/model"
+)
+
+var ErrQuotaExceeded = apperror.New("quota_exceeded", http.StatusTooManyRequests, "creation quota exceeded")
+
+type QuotaRepository interface {
+	ConsumeCreations(ctx context.Context, subject string, day time.Time, count int, quota model.CreationQuota) (model.CreationUsage, bool, error)
+	RefundCreations(ctx context.Context, subject string, day time.Time, count int) error
+}
+
+// QuotaService counts the links each API key or user creates against their daily
+// and monthly quotas. Periods are UTC days and calendar months.
+type QuotaService struct {
+	repository QuotaRepository
+	quota      model.CreationQuota
+	now        func() time.Time
+}
+
+func NewQuotaService(repository QuotaRepository, config QuotaConfig) *QuotaService {
+	return &QuotaService{repository: repository, quota: model.CreationQuota{Daily: config.Daily, Monthly: config.Monthly}, now: time.Now}
+}
+
+// ConsumeCreations counts count new links for the principal of ctx, or refuses all
+// of them with the time left until the quota that ran out starts over. Requests
+// without a principal have no quota to count against.
+func (s *QuotaService) ConsumeCreations(ctx context.Context, count int) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok || (s.quota.Daily == 0 && s.quota.Monthly == 0) {
+		return nil
+	}
+
+	now := s.now().UTC()
+	day := today(now)
+
+	usage, consumed, err := s.repository.ConsumeCreations(ctx, principal.Subject(), day, count, s.quota)
+	if err != nil {
+		return err
+	}
+	if consumed {
+		return nil
+	}
+
+	// the next day doesn't help once the month is used up
+	if s.quota.Monthly > 0 && usage.Monthly+count > s.quota.Monthly {
+		nextMonth := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		return ErrQuotaExceeded.WithDetail(fmt.Sprintf("%d of %d links created this month", usage.Monthly, s.quota.Monthly)).WithRetryAfter(nextMonth.Sub(now))
+	}
+
+	return ErrQuotaExceeded.WithDetail(fmt.Sprintf("%d of %d links created today", usage.Daily, s.quota.Daily)).WithRetryAfter(day.AddDate(0, 0, 1).Sub(now))
+}
+
+// RefundCreations gives back count links ConsumeCreations counted for the principal
+// of ctx that weren't created after all, the quota is taken before the insert so
+// concurrent requests can't overrun it.
+func (s *QuotaService) RefundCreations(ctx context.Context, count int) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok || count <= 0 || (s.quota.Daily == 0 && s.quota.Monthly == 0) {
+		return nil
+	}
+
+	return s.repository.RefundCreations(ctx, principal.Subject(), today(s.now().UTC()), count)
+}
+
+func today(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
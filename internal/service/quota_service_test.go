@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/ggoulart/url-shortener/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestQuotaService_ConsumeCreations(t *testing.T) {
+	now := time.Date(2026, 10, 18, 18, 30, 0, 0, time.UTC)
+	day := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	quota := model.CreationQuota{Daily: 100, Monthly: 1000}
+	withKey := ContextWithPrincipal(context.Background(), model.Principal{KeyID: "a-key-id"})
+	withUser := ContextWithPrincipal(context.Background(), model.Principal{UserID: "a-user-id", SessionID: "a-session-id"})
+
+	tests := []struct {
+		name           string
+		ctx            context.Context
+		config         QuotaConfig
+		setup          func(*MockQuotaRepository)
+		wantErr        error
+		wantDetail     string
+		wantRetryAfter time.Duration
+	}{
+		{
+			name:   "when the request has no principal",
+			ctx:    context.Background(),
+			config: QuotaConfig{Daily: 100, Monthly: 1000},
+			setup:  func(*MockQuotaRepository) {},
+		},
+		{
+			name:   "when every quota is uncapped",
+			ctx:    withKey,
+			config: QuotaConfig{},
+			setup:  func(*MockQuotaRepository) {},
+		},
+		{
+			name:   "when failed to consume the quota",
+			ctx:    withKey,
+			config: QuotaConfig{Daily: 100, Monthly: 1000},
+			setup: func(m *MockQuotaRepository) {
+				m.On("ConsumeCreations", mock.Anything, "key:a-key-id", day, 3, quota).Return(model.CreationUsage{}, false, repository.ErrUnexpected)
+			},
+			wantErr: repository.ErrUnexpected,
+		},
+		{
+			name:   "when the links are counted against the user",
+			ctx:    withUser,
+			config: QuotaConfig{Daily: 100, Monthly: 1000},
+			setup: func(m *MockQuotaRepository) {
+				m.On("ConsumeCreations", mock.Anything, "user:a-user-id", day, 3, quota).Return(model.CreationUsage{Daily: 10, Monthly: 20}, true, nil)
+			},
+		},
+		{
+			name:   "when the daily quota is used up",
+			ctx:    withKey,
+			config: QuotaConfig{Daily: 100, Monthly: 1000},
+			setup: func(m *MockQuotaRepository) {
+				m.On("ConsumeCreations", mock.Anything, "key:a-key-id", day, 3, quota).Return(model.CreationUsage{Daily: 99, Monthly: 420}, false, nil)
+			},
+			wantErr:        ErrQuotaExceeded,
+			wantDetail:     "99 of 100 links created today",
+			wantRetryAfter: 5*time.Hour + 30*time.Minute,
+		},
+		{
+			name:   "when the monthly quota is used up",
+			ctx:    withKey,
+			config: QuotaConfig{Daily: 100, Monthly: 1000},
+			setup: func(m *MockQuotaRepository) {
+				m.On("ConsumeCreations", mock.Anything, "key:a-key-id", day, 3, quota).Return(model.CreationUsage{Daily: 99, Monthly: 999}, false, nil)
+			},
+			wantErr:        ErrQuotaExceeded,
+			wantDetail:     "999 of 1000 links created this month",
+			wantRetryAfter: 13*24*time.Hour + 5*time.Hour + 30*time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockQuotaRepository{}
+			tt.setup(repo)
+
+			s := NewQuotaService(repo, tt.config)
+			s.now = func() time.Time { return now }
+
+			err := s.ConsumeCreations(tt.ctx, 3)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantDetail != "" {
+				appErr, _ := apperror.As(err)
+				assert.Equal(t, tt.wantDetail, appErr.Detail)
+				assert.Equal(t, tt.wantRetryAfter, appErr.RetryAfter)
+			}
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestQuotaService_RefundCreations(t *testing.T) {
+	now := time.Date(2026, 10, 18, 18, 30, 0, 0, time.UTC)
+	day := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	withKey := ContextWithPrincipal(context.Background(), model.Principal{KeyID: "a-key-id"})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		config  QuotaConfig
+		setup   func(*MockQuotaRepository)
+		wantErr error
+	}{
+		{
+			name:   "when the request has no principal",
+			ctx:    context.Background(),
+			config: QuotaConfig{Daily: 100},
+			setup:  func(*MockQuotaRepository) {},
+		},
+		{
+			name:   "when every quota is uncapped",
+			ctx:    withKey,
+			config: QuotaConfig{},
+			setup:  func(*MockQuotaRepository) {},
+		},
+		{
+			name:   "when failed to refund the quota",
+			ctx:    withKey,
+			config: QuotaConfig{Daily: 100},
+			setup: func(m *MockQuotaRepository) {
+				m.On("RefundCreations", mock.Anything, "key:a-key-id", day, 3).Return(repository.ErrUnexpected)
+			},
+			wantErr: repository.ErrUnexpected,
+		},
+		{
+			name:   "when the links are given back",
+			ctx:    withKey,
+			config: QuotaConfig{Monthly: 1000},
+			setup: func(m *MockQuotaRepository) {
+				m.On("RefundCreations", mock.Anything, "key:a-key-id", day, 3).Return(nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockQuotaRepository{}
+			tt.setup(repo)
+
+			s := NewQuotaService(repo, tt.config)
+			s.now = func() time.Time { return now }
+
+			err := s.RefundCreations(tt.ctx, 3)
+
+			assert.Equal(t, tt.wantErr, err)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+type MockQuotaRepository struct {
+	mock.Mock
+}
+
+func (m *MockQuotaRepository) ConsumeCreations(ctx context.Context, subject string, day time.Time, count int, quota model.CreationQuota) (model.CreationUsage, bool, error) {
+	args := m.Called(ctx, subject, day, count, quota)
+	return args.Get(0).(model.CreationUsage), args.Bool(1), args.Error(2)
+}
+
+func (m *MockQuotaRepository) RefundCreations(ctx context.Context, subject string, day time.Time, count int) error {
+	args := m.Called(ctx, subject, day, count)
+	return args.Error(0)
+}
@@ -2,55 +2,319 @@ package service
 
 import (
 	"context"
-	"encoding/base64"
+	"crypto/subtle"
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/ggoulart/url-shortener/internal/repository"
 )
 
+var ErrKeyGenerationFailed = apperror.New("key_generation_failed", http.StatusInternalServerError, "failed to generate a unique key")
+var ErrInvalidExpiration = apperror.New("invalid_expiration", http.StatusBadRequest, "invalid expiration")
+var ErrLinkExpired = apperror.New("link_expired", http.StatusGone, "link expired")
+var ErrLinkDisabled = apperror.New("link_disabled", http.StatusGone, "link disabled")
+var ErrInvalidManagementToken = apperror.New("invalid_management_token", http.StatusUnauthorized, "invalid management token")
+var ErrNotLinkOwner = apperror.New("not_link_owner", http.StatusForbidden, "link belongs to another user")
+var ErrVersionNotFound = apperror.New("version_not_found", http.StatusNotFound, "history version not found")
+
+// collisionsBeforeGrow is how many key collisions a single request tolerates at the
+// current length before every later key is generated one character longer. Repeated
+// collisions on random keys mean the key space at that length is filling up.
+const collisionsBeforeGrow = 2
+
 type ShortenerRepository interface {
-	FindEncodedKey(ctx context.Context, longURL url.URL) (string, error)
-	FindLongURL(ctx context.Context, encodedKey string) (url.URL, error)
-	SaveURL(ctx context.Context, shortURL string, longURL url.URL) error
+	FindEncodedKey(ctx context.Context, longURL url.URL, workspaceID string, ownerID string) (string, error)
+	FindEncodedKeys(ctx context.Context, longURLs []url.URL, workspaceID string, ownerID string) (map[string]string, error)
+	FindLink(ctx context.Context, encodedKey string) (model.Link, error)
+	SaveURL(ctx context.Context, link model.Link) error
+	SaveURLs(ctx context.Context, links []model.Link) (map[string]bool, error)
+	ListLinks(ctx context.Context, filter model.LinkFilter) ([]model.Link, error)
+	DeleteLink(ctx context.Context, encodedKey string) error
+	DisableLink(ctx context.Context, encodedKey string, disabledAt time.Time) error
+	UpdateLongURL(ctx context.Context, encodedKey string, longURL url.URL, changedAt time.Time, actor string) error
+	ListHistory(ctx context.Context, encodedKey string) ([]model.HistoryEntry, error)
+}
+
+type DestinationChecker interface {
+	Check(ctx context.Context, longURL url.URL) error
+}
+
+// AccessPolicy authorizes actions in a workspace for the signed in user of a request.
+type AccessPolicy interface {
+	Authorize(ctx context.Context, workspaceID string, action model.Action) (model.Member, error)
+}
+
+// CreationQuota counts new links against the quotas of whoever creates them.
+type CreationQuota interface {
+	ConsumeCreations(ctx context.Context, count int) error
+	RefundCreations(ctx context.Context, count int) error
 }
 
 type ShortenerService struct {
-	repository    ShortenerRepository
-	shortenerHost string
-	uuidGenerator func() string
+	repository   ShortenerRepository
+	keyGenerator KeyGenerator
+	policy       DestinationChecker
+	access       AccessPolicy
+	quota        CreationQuota
+	config       Config
+	keyLength    atomic.Int64
+	now          func() time.Time
+	newToken     func() (string, error)
 }
 
-func NewShortenerService(repository ShortenerRepository, shortenerHost string, uuidGenerator func() string) *ShortenerService {
-	return &ShortenerService{repository: repository, shortenerHost: shortenerHost, uuidGenerator: uuidGenerator}
+func NewShortenerService(repository ShortenerRepository, keyGenerator KeyGenerator, policy DestinationChecker, access AccessPolicy, quota CreationQuota, config Config) *ShortenerService {
+	s := &ShortenerService{repository: repository, keyGenerator: keyGenerator, policy: policy, access: access, quota: quota, config: config, now: time.Now, newToken: newManagementToken}
+	s.keyLength.Store(int64(config.KeyMinLength))
+
+	return s
 }
 
-func (s *ShortenerService) Shortener(ctx context.Context, longURL url.URL) (url.URL, error) {
-	encodedKey, err := s.repository.FindEncodedKey(ctx, longURL)
+func (s *ShortenerService) Shortener(ctx context.Context, longURL url.URL, options model.ShortenOptions) (model.ShortenResult, error) {
+	scope, err := s.resolveScope(ctx)
 	if err != nil {
-		return url.URL{}, err
+		return model.ShortenResult{}, err
+	}
+
+	link, token, err := s.prepareLink(ctx, scope, longURL, options)
+	if err != nil {
+		return model.ShortenResult{}, err
+	}
+
+	if link.EncodedKey != "" {
+		return s.shortenWithAlias(ctx, link, token)
+	}
+
+	// links with an expiry are always new, they must not hand out a permanent link
+	if link.ExpiresAt != nil {
+		return s.shortenWithGeneratedKey(ctx, link, token)
+	}
+
+	encodedKey, err := s.repository.FindEncodedKey(ctx, link.LongURL, link.WorkspaceID, link.OwnerID)
+	if err != nil {
+		return model.ShortenResult{}, err
 	}
 
+	// an existing link is shared, only its creator holds the management token
 	if encodedKey != "" {
-		return s.buildShortURL(encodedKey)
+		return s.buildResult(encodedKey, false, "")
 	}
 
-	id := s.uuidGenerator()
-	encodedKey = base64.RawURLEncoding.EncodeToString([]byte(id))
-	if len(encodedKey) > 7 {
-		encodedKey = encodedKey[:7]
+	return s.shortenWithGeneratedKey(ctx, link, token)
+}
+
+func (s *ShortenerService) Retrieve(ctx context.Context, encodedKey string) (url.URL, error) {
+	link, err := s.repository.FindLink(ctx, encodedKey)
+	if err != nil {
+		return url.URL{}, err
 	}
 
-	err = s.repository.SaveURL(ctx, encodedKey, longURL)
+	err = checkAvailable(link, s.now())
 	if err != nil {
 		return url.URL{}, err
 	}
 
-	return s.buildShortURL(encodedKey)
+	return link.LongURL, nil
 }
 
-func (s *ShortenerService) Retrieve(ctx context.Context, encodedKey string) (url.URL, error) {
-	longURL, err := s.repository.FindLongURL(ctx, encodedKey)
+// checkAvailable tells whether link may still be followed or shown to anyone at now.
+func checkAvailable(link model.Link, now time.Time) error {
+	if link.DisabledAt != nil {
+		return ErrLinkDisabled
+	}
+
+	if link.ExpiresAt != nil && !now.Before(*link.ExpiresAt) {
+		return ErrLinkExpired
+	}
+
+	return nil
+}
+
+func (s *ShortenerService) DeleteLink(ctx context.Context, encodedKey string, token string) error {
+	_, err := s.authorize(ctx, encodedKey, token, model.ActionDeleteLinks)
+	if err != nil {
+		return err
+	}
+
+	return s.repository.DeleteLink(ctx, encodedKey)
+}
+
+func (s *ShortenerService) DisableLink(ctx context.Context, encodedKey string, token string) error {
+	_, err := s.authorize(ctx, encodedKey, token, model.ActionEditLinks)
+	if err != nil {
+		return err
+	}
+
+	return s.repository.DisableLink(ctx, encodedKey, s.now())
+}
+
+// UpdateDestination repoints a link, the destination it replaces goes to its history.
+// The new destination goes through the same canonicalization and policy as a new link.
+func (s *ShortenerService) UpdateDestination(ctx context.Context, encodedKey string, token string, longURL url.URL, actor string) error {
+	link, err := s.authorize(ctx, encodedKey, token, model.ActionEditLinks)
+	if err != nil {
+		return err
+	}
+
+	longURL, err = s.checkDestination(ctx, longURL)
+	if err != nil {
+		return err
+	}
+
+	if longURL.String() == link.LongURL.String() {
+		return nil
+	}
+
+	return s.repository.UpdateLongURL(ctx, encodedKey, longURL, s.now(), actor)
+}
+
+// History lists the previous destinations of a link, newest first.
+func (s *ShortenerService) History(ctx context.Context, encodedKey string, token string) ([]model.HistoryEntry, error) {
+	_, err := s.authorize(ctx, encodedKey, token, model.ActionViewLinks)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repository.ListHistory(ctx, encodedKey)
+}
+
+// Rollback points a link back to the destination it had at version. The rollback is
+// an edit itself, so the destination it replaces is kept in the history too.
+func (s *ShortenerService) Rollback(ctx context.Context, encodedKey string, token string, version int, actor string) error {
+	_, err := s.authorize(ctx, encodedKey, token, model.ActionEditLinks)
+	if err != nil {
+		return err
+	}
+
+	history, err := s.repository.ListHistory(ctx, encodedKey)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range history {
+		if entry.Version != version {
+			continue
+		}
+
+		// the policy may have changed since the destination was replaced
+		err = s.policy.Check(ctx, entry.LongURL)
+		if err != nil {
+			return err
+		}
+
+		return s.repository.UpdateLongURL(ctx, encodedKey, entry.LongURL, s.now(), actor)
+	}
+
+	return ErrVersionNotFound
+}
+
+// authorize lets the members of a link's workspace whose role allows action manage
+// it, otherwise only its owner. Links without either check token against the hash
+// stored when the link was created, links created before management tokens existed
+// have no hash and can't be managed. The token is checked on top of the credentials
+// the route requires, it doesn't stand in for them.
+func (s *ShortenerService) authorize(ctx context.Context, encodedKey string, token string, action model.Action) (model.Link, error) {
+	link, err := s.repository.FindLink(ctx, encodedKey)
+	if err != nil {
+		return model.Link{}, err
+	}
+
+	if link.WorkspaceID != "" {
+		_, err = s.access.Authorize(ctx, link.WorkspaceID, action)
+		if err != nil {
+			return model.Link{}, err
+		}
+
+		return link, nil
+	}
+
+	if link.OwnerID != "" {
+		principal, _ := PrincipalFromContext(ctx)
+		if principal.UserID != link.OwnerID {
+			return model.Link{}, ErrNotLinkOwner
+		}
+
+		return link, nil
+	}
+
+	if token == "" || link.ManagementTokenHash == "" {
+		return model.Link{}, ErrInvalidManagementToken
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashManagementToken(token)), []byte(link.ManagementTokenHash)) != 1 {
+		return model.Link{}, ErrInvalidManagementToken
+	}
+
+	return link, nil
+}
+
+// linkScope is who a new link belongs to, it is the same for every link of a request.
+type linkScope struct {
+	ownerID     string
+	workspaceID string
+}
+
+// resolveScope works out who new links of the request belong to. A request acting in
+// a workspace needs a role there that may create links.
+func (s *ShortenerService) resolveScope(ctx context.Context) (linkScope, error) {
+	principal, _ := PrincipalFromContext(ctx)
+	if principal.WorkspaceID == "" {
+		return linkScope{ownerID: principal.UserID}, nil
+	}
+
+	_, err := s.access.Authorize(ctx, principal.WorkspaceID, model.ActionCreateLinks)
+	if err != nil {
+		return linkScope{}, err
+	}
+
+	return linkScope{ownerID: principal.UserID, workspaceID: principal.WorkspaceID}, nil
+}
+
+// prepareLink builds the link a new short URL would get. A link in a workspace or of
+// a signed in user is managed by them, anyone else gets a management token, only
+// handed out if the link ends up being created.
+func (s *ShortenerService) prepareLink(ctx context.Context, scope linkScope, longURL url.URL, options model.ShortenOptions) (model.Link, string, error) {
+	expiresAt, err := s.expiresAt(options)
+	if err != nil {
+		return model.Link{}, "", err
+	}
+
+	longURL, err = s.checkDestination(ctx, longURL)
+	if err != nil {
+		return model.Link{}, "", err
+	}
+
+	link := model.Link{EncodedKey: options.Alias, LongURL: longURL, ExpiresAt: expiresAt, OwnerID: scope.ownerID, WorkspaceID: scope.workspaceID}
+	if link.OwnerID != "" || link.WorkspaceID != "" {
+		return link, "", nil
+	}
+
+	token, err := s.newToken()
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to generate management token: %v", err))
+		return model.Link{}, "", ErrKeyGenerationFailed
+	}
+	link.ManagementTokenHash = hashManagementToken(token)
+
+	return link, token, nil
+}
+
+func (s *ShortenerService) checkDestination(ctx context.Context, longURL url.URL) (url.URL, error) {
+	if s.config.CanonicalizeURLs {
+		var err error
+		longURL, err = canonicalizeURL(longURL, s.config.SortQueryParams)
+		if err != nil {
+			return url.URL{}, err
+		}
+	}
+
+	err := s.policy.Check(ctx, longURL)
 	if err != nil {
 		return url.URL{}, err
 	}
@@ -58,8 +322,147 @@ func (s *ShortenerService) Retrieve(ctx context.Context, encodedKey string) (url
 	return longURL, nil
 }
 
+func (s *ShortenerService) expiresAt(options model.ShortenOptions) (*time.Time, error) {
+	if options.ExpiresAt != nil && options.TTL != 0 {
+		return nil, ErrInvalidExpiration
+	}
+
+	expiresAt := options.ExpiresAt
+	if options.TTL != 0 {
+		ttlExpiresAt := s.now().Add(options.TTL)
+		expiresAt = &ttlExpiresAt
+	}
+
+	if expiresAt != nil && !expiresAt.After(s.now()) {
+		return nil, ErrInvalidExpiration
+	}
+
+	return expiresAt, nil
+}
+
+func (s *ShortenerService) shortenWithGeneratedKey(ctx context.Context, link model.Link, token string) (model.ShortenResult, error) {
+	err := s.quota.ConsumeCreations(ctx, 1)
+	if err != nil {
+		return model.ShortenResult{}, err
+	}
+
+	encodedKey, err := s.saveWithGeneratedKey(ctx, link)
+	if err != nil {
+		s.refundCreations(ctx, 1)
+		return model.ShortenResult{}, err
+	}
+
+	return s.buildResult(encodedKey, true, token)
+}
+
+func (s *ShortenerService) shortenWithAlias(ctx context.Context, link model.Link, token string) (model.ShortenResult, error) {
+	err := validateAlias(link.EncodedKey)
+	if err != nil {
+		return model.ShortenResult{}, err
+	}
+
+	err = s.quota.ConsumeCreations(ctx, 1)
+	if err != nil {
+		return model.ShortenResult{}, err
+	}
+
+	// the insert itself claims the alias, so two concurrent requests can't both get it
+	err = s.repository.SaveURL(ctx, link)
+	if errors.Is(err, repository.ErrKeyAlreadyExists) {
+		s.refundCreations(ctx, 1)
+		return s.existingAlias(ctx, link)
+	}
+	if err != nil {
+		s.refundCreations(ctx, 1)
+		return model.ShortenResult{}, err
+	}
+
+	return s.buildResult(link.EncodedKey, true, token)
+}
+
+// refundCreations gives back quota taken for links that weren't inserted. The
+// request has its answer by then, a failed refund is only logged.
+func (s *ShortenerService) refundCreations(ctx context.Context, count int) {
+	err := s.quota.RefundCreations(ctx, count)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to refund %d creations: %v", count, err))
+	}
+}
+
+// existingAlias makes retried requests idempotent: an alias already pointing at the
+// same long URL in the same workspace, or outside any workspace of the same owner,
+// is returned as is, anything else means the alias is taken. So does an expired or
+// disabled link, its key answers 410 Gone until the cleanup job removes it.
+func (s *ShortenerService) existingAlias(ctx context.Context, link model.Link) (model.ShortenResult, error) {
+	existing, err := s.repository.FindLink(ctx, link.EncodedKey)
+	if err != nil {
+		return model.ShortenResult{}, err
+	}
+
+	if existing.LongURL.String() != link.LongURL.String() || existing.WorkspaceID != link.WorkspaceID || checkAvailable(existing, s.now()) != nil {
+		return model.ShortenResult{}, ErrAliasTaken
+	}
+
+	if existing.WorkspaceID == "" && existing.OwnerID != link.OwnerID {
+		return model.ShortenResult{}, ErrAliasTaken
+	}
+
+	return s.buildResult(link.EncodedKey, false, "")
+}
+
+func (s *ShortenerService) saveWithGeneratedKey(ctx context.Context, link model.Link) (string, error) {
+	collisions := 0
+
+	for attempt := 0; attempt <= s.config.KeyMaxRetries; attempt++ {
+		length := int(s.keyLength.Load())
+
+		encodedKey, err := s.keyGenerator.Generate(length)
+		if err != nil {
+			slog.Error(fmt.Sprintf("failed to generate key: %v", err))
+			return "", ErrKeyGenerationFailed
+		}
+
+		link.EncodedKey = encodedKey
+		err = s.repository.SaveURL(ctx, link)
+		if err == nil {
+			return encodedKey, nil
+		}
+		if !errors.Is(err, repository.ErrKeyAlreadyExists) {
+			return "", err
+		}
+
+		slog.Warn(fmt.Sprintf("encoded key collision at length %d", length))
+		collisions++
+		if collisions >= collisionsBeforeGrow {
+			s.growKeyLength(length)
+			collisions = 0
+		}
+	}
+
+	return "", ErrKeyGenerationFailed
+}
+
+func (s *ShortenerService) growKeyLength(from int) {
+	if from >= s.config.KeyMaxLength {
+		return
+	}
+
+	if s.keyLength.CompareAndSwap(int64(from), int64(from+1)) {
+		slog.Info(fmt.Sprintf("increased encoded key length to %d", from+1))
+	}
+}
+
+func (s *ShortenerService) buildResult(encodedKey string, created bool, token string) (model.ShortenResult, error) {
+	shortURL, err := s.buildShortURL(encodedKey)
+	if err != nil {
+		return model.ShortenResult{}, err
+	}
+
+	return model.ShortenResult{ShortURL: shortURL, Created: created, ManagementToken: token}, nil
+}
+
 func (s *ShortenerService) buildShortURL(encodedKey string) (url.URL, error) {
-	shortURL, err := url.Parse(s.shortenerHost + "/api/v1/" + encodedKey)
+	shortURL, err := url.Parse(s.config.ShortenerHost + "/api/v1/" + encodedKey)
 	if err != nil {
 		slog.Error(fmt.Sprintf("failed to build short URL: %v", err))
 		return url.URL{}, errors.New("failed to build short URL")
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/model"
+)
+
+var ErrInvalidStatsQuery = apperror.New("invalid_stats_query", http.StatusBadRequest, "invalid stats query")
+
+// maxStatsBuckets bounds how many points a single time series may return.
+const maxStatsBuckets = 1000
+
+var statsIntervals = map[string]time.Duration{
+	"hour": time.Hour,
+	"day":  24 * time.Hour,
+}
+
+var defaultStatsRanges = map[string]time.Duration{
+	"hour": 48 * time.Hour,
+	"day":  30 * 24 * time.Hour,
+}
+
+type LinkFinder interface {
+	FindLink(ctx context.Context, encodedKey string) (model.Link, error)
+}
+
+type StatsRepository interface {
+	CountClicks(ctx context.Context, encodedKey string) (int64, error)
+	ClickSeries(ctx context.Context, encodedKey string, query model.StatsQuery) ([]model.ClickBucket, error)
+}
+
+type StatsService struct {
+	links  LinkFinder
+	clicks StatsRepository
+	access AccessPolicy
+	now    func() time.Time
+}
+
+func NewStatsService(links LinkFinder, clicks StatsRepository, access AccessPolicy) *StatsService {
+	return &StatsService{links: links, clicks: clicks, access: access, now: time.Now}
+}
+
+func (s *StatsService) Stats(ctx context.Context, encodedKey string, query model.StatsQuery) (model.LinkStats, error) {
+	query, err := s.normalizeQuery(query)
+	if err != nil {
+		return model.LinkStats{}, err
+	}
+
+	link, err := s.links.FindLink(ctx, encodedKey)
+	if err != nil {
+		return model.LinkStats{}, err
+	}
+
+	err = s.authorize(ctx, link)
+	if err != nil {
+		return model.LinkStats{}, err
+	}
+
+	total, err := s.clicks.CountClicks(ctx, encodedKey)
+	if err != nil {
+		return model.LinkStats{}, err
+	}
+
+	series, err := s.clicks.ClickSeries(ctx, encodedKey, query)
+	if err != nil {
+		return model.LinkStats{}, err
+	}
+
+	return model.LinkStats{EncodedKey: encodedKey, TotalClicks: total, Interval: query.Interval, Series: series}, nil
+}
+
+// Inspect describes a link to anyone who has its key, so it hides the same links a
+// redirect would refuse to follow. Links of a workspace are only described to its
+// members.
+func (s *StatsService) Inspect(ctx context.Context, encodedKey string) (model.LinkMetadata, error) {
+	link, err := s.links.FindLink(ctx, encodedKey)
+	if err != nil {
+		return model.LinkMetadata{}, err
+	}
+
+	err = s.authorize(ctx, link)
+	if err != nil {
+		return model.LinkMetadata{}, err
+	}
+
+	err = checkAvailable(link, s.now())
+	if err != nil {
+		return model.LinkMetadata{}, err
+	}
+
+	total, err := s.clicks.CountClicks(ctx, encodedKey)
+	if err != nil {
+		return model.LinkMetadata{}, err
+	}
+
+	return model.LinkMetadata{
+		EncodedKey:  encodedKey,
+		LongURL:     link.LongURL,
+		CreatedAt:   link.CreatedAt,
+		ExpiresAt:   link.ExpiresAt,
+		TotalClicks: total,
+	}, nil
+}
+
+// authorize lets the members of a link's workspace whose role may view links see it,
+// otherwise only its owner, like ShortenerService does for the link's history.
+func (s *StatsService) authorize(ctx context.Context, link model.Link) error {
+	if link.WorkspaceID != "" {
+		_, err := s.access.Authorize(ctx, link.WorkspaceID, model.ActionViewLinks)
+
+		return err
+	}
+
+	if link.OwnerID != "" {
+		principal, _ := PrincipalFromContext(ctx)
+		if principal.UserID != link.OwnerID {
+			return ErrNotLinkOwner
+		}
+	}
+
+	return nil
+}
+
+func (s *StatsService) normalizeQuery(query model.StatsQuery) (model.StatsQuery, error) {
+	if query.Interval == "" {
+		query.Interval = "day"
+	}
+
+	interval, ok := statsIntervals[query.Interval]
+	if !ok {
+		return model.StatsQuery{}, ErrInvalidStatsQuery
+	}
+
+	if query.To.IsZero() {
+		query.To = s.now()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.Add(-defaultStatsRanges[query.Interval])
+	}
+
+	if !query.From.Before(query.To) || query.To.Sub(query.From)/interval > maxStatsBuckets {
+		return model.StatsQuery{}, ErrInvalidStatsQuery
+	}
+
+	return query, nil
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/ggoulart/url-shortener/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStatsService_Stats(t *testing.T) {
+	now := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	from := time.Date(2026, 1, 30, 0, 0, 0, 0, time.UTC)
+	defaultQuery := model.StatsQuery{Interval: "day", From: now.Add(-30 * 24 * time.Hour), To: now}
+	series := []model.ClickBucket{{Start: from, Clicks: 3}}
+
+	tests := []struct {
+		name    string
+		query   model.StatsQuery
+		setup   func(*MockShortenerRepository, *MockStatsRepository)
+		want    model.LinkStats
+		wantErr error
+	}{
+		{
+			name:    "when interval is unknown",
+			query:   model.StatsQuery{Interval: "minute"},
+			setup:   func(*MockShortenerRepository, *MockStatsRepository) {},
+			wantErr: ErrInvalidStatsQuery,
+		},
+		{
+			name:    "when range is reversed",
+			query:   model.StatsQuery{From: now, To: from},
+			setup:   func(*MockShortenerRepository, *MockStatsRepository) {},
+			wantErr: ErrInvalidStatsQuery,
+		},
+		{
+			name:    "when range has too many buckets",
+			query:   model.StatsQuery{Interval: "hour", From: now.Add(-1001 * time.Hour), To: now},
+			setup:   func(*MockShortenerRepository, *MockStatsRepository) {},
+			wantErr: ErrInvalidStatsQuery,
+		},
+		{
+			name: "when link does not exist",
+			setup: func(l *MockShortenerRepository, c *MockStatsRepository) {
+				l.On("FindLink", context.Background(), "a-encoded-key").Return(model.Link{}, repository.ErrNotFound)
+			},
+			wantErr: repository.ErrNotFound,
+		},
+		{
+			name: "when failed to count clicks",
+			setup: func(l *MockShortenerRepository, c *MockStatsRepository) {
+				l.On("FindLink", context.Background(), "a-encoded-key").Return(model.Link{}, nil)
+				c.On("CountClicks", context.Background(), "a-encoded-key").Return(int64(0), errors.New("db error"))
+			},
+			wantErr: errors.New("db error"),
+		},
+		{
+			name: "when failed to query series",
+			setup: func(l *MockShortenerRepository, c *MockStatsRepository) {
+				l.On("FindLink", context.Background(), "a-encoded-key").Return(model.Link{}, nil)
+				c.On("CountClicks", context.Background(), "a-encoded-key").Return(int64(10), nil)
+				c.On("ClickSeries", context.Background(), "a-encoded-key", defaultQuery).Return([]model.ClickBucket(nil), errors.New("db error"))
+			},
+			wantErr: errors.New("db error"),
+		},
+		{
+			name: "when successfully returns stats with default range",
+			setup: func(l *MockShortenerRepository, c *MockStatsRepository) {
+				l.On("FindLink", context.Background(), "a-encoded-key").Return(model.Link{}, nil)
+				c.On("CountClicks", context.Background(), "a-encoded-key").Return(int64(10), nil)
+				c.On("ClickSeries", context.Background(), "a-encoded-key", defaultQuery).Return(series, nil)
+			},
+			want: model.LinkStats{EncodedKey: "a-encoded-key", TotalClicks: 10, Interval: "day", Series: series},
+		},
+		{
+			name:  "when successfully returns stats for a given range",
+			query: model.StatsQuery{Interval: "hour", From: from, To: now},
+			setup: func(l *MockShortenerRepository, c *MockStatsRepository) {
+				l.On("FindLink", context.Background(), "a-encoded-key").Return(model.Link{}, nil)
+				c.On("CountClicks", context.Background(), "a-encoded-key").Return(int64(10), nil)
+				c.On("ClickSeries", context.Background(), "a-encoded-key", model.StatsQuery{Interval: "hour", From: from, To: now}).Return(series, nil)
+			},
+			want: model.LinkStats{EncodedKey: "a-encoded-key", TotalClicks: 10, Interval: "hour", Series: series},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &MockShortenerRepository{}
+			c := &MockStatsRepository{}
+			tt.setup(l, c)
+
+			s := NewStatsService(l, c, &MockAccessPolicy{})
+			s.now = func() time.Time { return now }
+
+			got, err := s.Stats(context.Background(), "a-encoded-key", tt.query)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestStatsService_Inspect(t *testing.T) {
+	now := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	link := model.Link{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}, CreatedAt: createdAt, ExpiresAt: &future}
+
+	tests := []struct {
+		name    string
+		setup   func(*MockShortenerRepository, *MockStatsRepository)
+		want    model.LinkMetadata
+		wantErr error
+	}{
+		{
+			name: "when link does not exist",
+			setup: func(l *MockShortenerRepository, c *MockStatsRepository) {
+				l.On("FindLink", context.Background(), "a-encoded-key").Return(model.Link{}, repository.ErrNotFound)
+			},
+			wantErr: repository.ErrNotFound,
+		},
+		{
+			name: "when link is disabled",
+			setup: func(l *MockShortenerRepository, c *MockStatsRepository) {
+				l.On("FindLink", context.Background(), "a-encoded-key").Return(model.Link{DisabledAt: &past}, nil)
+			},
+			wantErr: ErrLinkDisabled,
+		},
+		{
+			name: "when link is expired",
+			setup: func(l *MockShortenerRepository, c *MockStatsRepository) {
+				l.On("FindLink", context.Background(), "a-encoded-key").Return(model.Link{ExpiresAt: &past}, nil)
+			},
+			wantErr: ErrLinkExpired,
+		},
+		{
+			name: "when failed to count clicks",
+			setup: func(l *MockShortenerRepository, c *MockStatsRepository) {
+				l.On("FindLink", context.Background(), "a-encoded-key").Return(link, nil)
+				c.On("CountClicks", context.Background(), "a-encoded-key").Return(int64(0), errors.New("db error"))
+			},
+			wantErr: errors.New("db error"),
+		},
+		{
+			name: "when successfully inspects link",
+			setup: func(l *MockShortenerRepository, c *MockStatsRepository) {
+				l.On("FindLink", context.Background(), "a-encoded-key").Return(link, nil)
+				c.On("CountClicks", context.Background(), "a-encoded-key").Return(int64(10), nil)
+			},
+			want: model.LinkMetadata{EncodedKey: "a-encoded-key", LongURL: link.LongURL, CreatedAt: createdAt, ExpiresAt: &future, TotalClicks: 10},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &MockShortenerRepository{}
+			c := &MockStatsRepository{}
+			tt.setup(l, c)
+
+			s := NewStatsService(l, c, &MockAccessPolicy{})
+			s.now = func() time.Time { return now }
+
+			got, err := s.Inspect(context.Background(), "a-encoded-key")
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestStatsService_Workspace(t *testing.T) {
+	link := model.Link{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}, WorkspaceID: "a-workspace-id"}
+	viewer := model.Member{WorkspaceID: "a-workspace-id", UserID: "a-user-id", Role: model.RoleViewer}
+
+	tests := []struct {
+		name    string
+		setup   func(*MockStatsRepository, *MockAccessPolicy)
+		wantErr error
+	}{
+		{
+			name: "when the caller is not a member of the workspace",
+			setup: func(c *MockStatsRepository, a *MockAccessPolicy) {
+				a.On("Authorize", context.Background(), "a-workspace-id", model.ActionViewLinks).Return(model.Member{}, ErrNotWorkspaceMember)
+			},
+			wantErr: ErrNotWorkspaceMember,
+		},
+		{
+			name: "when the caller is a member of the workspace",
+			setup: func(c *MockStatsRepository, a *MockAccessPolicy) {
+				a.On("Authorize", context.Background(), "a-workspace-id", model.ActionViewLinks).Return(viewer, nil)
+				c.On("CountClicks", context.Background(), "a-encoded-key").Return(int64(10), nil)
+				c.On("ClickSeries", context.Background(), "a-encoded-key", mock.Anything).Return([]model.ClickBucket{}, nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &MockShortenerRepository{}
+			c := &MockStatsRepository{}
+			a := &MockAccessPolicy{}
+			l.On("FindLink", context.Background(), "a-encoded-key").Return(link, nil)
+			tt.setup(c, a)
+
+			s := NewStatsService(l, c, a)
+
+			_, err := s.Stats(context.Background(), "a-encoded-key", model.StatsQuery{})
+			assert.Equal(t, tt.wantErr, err)
+
+			_, err = s.Inspect(context.Background(), "a-encoded-key")
+			assert.Equal(t, tt.wantErr, err)
+
+			c.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStatsService_Owner(t *testing.T) {
+	link := model.Link{EncodedKey: "a-encoded-key", LongURL: url.URL{Scheme: "http", Host: "a-long-url"}, OwnerID: "a-user-id"}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		setup   func(*MockStatsRepository)
+		wantErr error
+	}{
+		{
+			name:    "when another user reads the link",
+			ctx:     ContextWithPrincipal(context.Background(), model.Principal{UserID: "b-user-id"}),
+			setup:   func(c *MockStatsRepository) {},
+			wantErr: ErrNotLinkOwner,
+		},
+		{
+			name:    "when an api key without a user reads the link",
+			ctx:     ContextWithPrincipal(context.Background(), model.Principal{KeyID: "a-key-id"}),
+			setup:   func(c *MockStatsRepository) {},
+			wantErr: ErrNotLinkOwner,
+		},
+		{
+			name: "when the owner reads the link",
+			ctx:  ContextWithPrincipal(context.Background(), model.Principal{UserID: "a-user-id"}),
+			setup: func(c *MockStatsRepository) {
+				c.On("CountClicks", mock.Anything, "a-encoded-key").Return(int64(10), nil)
+				c.On("ClickSeries", mock.Anything, "a-encoded-key", mock.Anything).Return([]model.ClickBucket{}, nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &MockShortenerRepository{}
+			c := &MockStatsRepository{}
+			a := &MockAccessPolicy{}
+			l.On("FindLink", tt.ctx, "a-encoded-key").Return(link, nil)
+			tt.setup(c)
+
+			s := NewStatsService(l, c, a)
+
+			_, err := s.Stats(tt.ctx, "a-encoded-key", model.StatsQuery{})
+			assert.Equal(t, tt.wantErr, err)
+
+			_, err = s.Inspect(tt.ctx, "a-encoded-key")
+			assert.Equal(t, tt.wantErr, err)
+
+			c.AssertExpectations(t)
+			a.AssertExpectations(t)
+		})
+	}
+}
+
+type MockStatsRepository struct {
+	mock.Mock
+}
+
+func (m *MockStatsRepository) CountClicks(ctx context.Context, encodedKey string) (int64, error) {
+	args := m.Called(ctx, encodedKey)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockStatsRepository) ClickSeries(ctx context.Context, encodedKey string, query model.StatsQuery) ([]model.ClickBucket, error) {
+	args := m.Called(ctx, encodedKey, query)
+	return args.Get(0).([]model.ClickBucket), args.Error(1)
+}
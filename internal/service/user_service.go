@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/ggoulart/url-shortener/internal/repository"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidEmail = apperror.New("invalid_email", http.StatusBadRequest, "invalid email")
+var ErrInvalidPassword = apperror.New("invalid_password", http.StatusBadRequest, "invalid password")
+var ErrEmailTaken = apperror.New("email_taken", http.StatusConflict, "email already registered")
+var ErrInvalidCredentials = apperror.New("invalid_credentials", http.StatusUnauthorized, "invalid email or password")
+var ErrInvalidSessionToken = apperror.New("invalid_session_token", http.StatusUnauthorized, "invalid session token")
+var ErrInvalidRefreshToken = apperror.New("invalid_refresh_token", http.StatusUnauthorized, "invalid refresh token")
+var ErrRegistrationClosed = apperror.New("registration_closed", http.StatusForbidden, "registration is closed")
+
+// SessionTokenPrefix starts every access token, it tells them apart from API keys.
+const SessionTokenPrefix = "uss_"
+
+const refreshTokenPrefix = "usr_"
+
+const (
+	minPasswordLength = 12
+	// maxPasswordLength bounds the work a single sign in can ask the hash for
+	maxPasswordLength = 128
+	maxEmailLength    = 254
+)
+
+// sessionScopes is what a signed in user may do, minting API keys stays with admin keys.
+// Users are registered by admin keys unless registration is open, so a session gets
+// no more than an operator agreed to hand out.
+var sessionScopes = []model.Scope{model.ScopeCreate, model.ScopeRead}
+
+type UserRepository interface {
+	SaveUser(ctx context.Context, user model.User) error
+	FindUserByEmail(ctx context.Context, email string) (model.User, error)
+	SaveSession(ctx context.Context, session model.Session) error
+	FindSessionByAccessHash(ctx context.Context, hash string) (model.Session, error)
+	FindSessionByRefreshHash(ctx context.Context, hash string) (model.Session, error)
+	RotateSession(ctx context.Context, session model.Session, previousRefreshHash string) error
+	RevokeSession(ctx context.Context, id string, revokedAt time.Time) error
+}
+
+type UserService struct {
+	repository     UserRepository
+	config         SessionConfig
+	passwordParams argon2Params
+	now            func() time.Time
+	newToken       func() (string, error)
+	newID          func() string
+}
+
+func NewUserService(repository UserRepository, config SessionConfig) *UserService {
+	return &UserService{
+		repository:     repository,
+		config:         config,
+		passwordParams: defaultArgon2Params,
+		now:            time.Now,
+		newToken:       newManagementToken,
+		newID:          uuid.NewString,
+	}
+}
+
+// Register signs up a user. Unless registration is open only admin keys may do it.
+func (s *UserService) Register(ctx context.Context, email string, password string) (model.User, error) {
+	principal, _ := PrincipalFromContext(ctx)
+	if !s.config.OpenRegistration && !principal.HasScope(model.ScopeAdmin) {
+		return model.User{}, ErrRegistrationClosed.WithDetail("users are registered with an admin key")
+	}
+
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	if utf8.RuneCountInString(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return model.User{}, ErrInvalidPassword.WithDetail(fmt.Sprintf("password must be %d to %d characters long", minPasswordLength, maxPasswordLength))
+	}
+
+	hash, err := hashPassword(password, s.passwordParams)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to hash password: %v", err))
+		return model.User{}, errors.New("failed to hash password")
+	}
+
+	user := model.User{ID: s.newID(), Email: email, PasswordHash: hash, CreatedAt: s.now().UTC()}
+
+	err = s.repository.SaveUser(ctx, user)
+	if errors.Is(err, repository.ErrEmailAlreadyExists) {
+		return model.User{}, ErrEmailTaken
+	}
+	if err != nil {
+		return model.User{}, err
+	}
+
+	return user, nil
+}
+
+// Login starts a session. A wrong password and an unknown email get the same error
+// and take about as long, so a caller can't tell which emails are registered.
+func (s *UserService) Login(ctx context.Context, email string, password string) (model.SessionTokens, error) {
+	email, err := normalizeEmail(email)
+	if err != nil || len(password) > maxPasswordLength {
+		return model.SessionTokens{}, ErrInvalidCredentials
+	}
+
+	user, err := s.repository.FindUserByEmail(ctx, email)
+	if errors.Is(err, repository.ErrNotFound) {
+		_, _ = hashPassword(password, s.passwordParams)
+		return model.SessionTokens{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return model.SessionTokens{}, err
+	}
+
+	ok, err := verifyPassword(password, user.PasswordHash)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to verify password of user %s: %v", user.ID, err))
+		return model.SessionTokens{}, ErrInvalidCredentials
+	}
+	if !ok {
+		return model.SessionTokens{}, ErrInvalidCredentials
+	}
+
+	now := s.now().UTC()
+	tokens, err := s.issueTokens(now)
+	if err != nil {
+		return model.SessionTokens{}, err
+	}
+
+	session := model.Session{
+		ID:               s.newID(),
+		UserID:           user.ID,
+		AccessHash:       hashManagementToken(tokens.AccessToken),
+		AccessExpiresAt:  tokens.AccessExpiresAt,
+		RefreshHash:      hashManagementToken(tokens.RefreshToken),
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+		CreatedAt:        now,
+	}
+
+	err = s.repository.SaveSession(ctx, session)
+	if err != nil {
+		return model.SessionTokens{}, err
+	}
+
+	return tokens, nil
+}
+
+// Refresh trades a refresh token for a new pair of tokens. The pair it replaces stops
+// working, so a refresh token can only be spent once.
+func (s *UserService) Refresh(ctx context.Context, refreshToken string) (model.SessionTokens, error) {
+	previousHash := hashManagementToken(refreshToken)
+
+	session, err := s.findRefreshable(ctx, previousHash)
+	if err != nil {
+		return model.SessionTokens{}, err
+	}
+
+	tokens, err := s.issueTokens(s.now().UTC())
+	if err != nil {
+		return model.SessionTokens{}, err
+	}
+
+	session.AccessHash, session.AccessExpiresAt = hashManagementToken(tokens.AccessToken), tokens.AccessExpiresAt
+	session.RefreshHash, session.RefreshExpiresAt = hashManagementToken(tokens.RefreshToken), tokens.RefreshExpiresAt
+
+	err = s.repository.RotateSession(ctx, session, previousHash)
+	if errors.Is(err, repository.ErrNotFound) {
+		// a concurrent refresh spent the token first
+		return model.SessionTokens{}, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return model.SessionTokens{}, err
+	}
+
+	return tokens, nil
+}
+
+// Logout ends the session of refreshToken, its access token stops working right away.
+func (s *UserService) Logout(ctx context.Context, refreshToken string) error {
+	session, err := s.findRefreshable(ctx, hashManagementToken(refreshToken))
+	if err != nil {
+		return err
+	}
+
+	return s.repository.RevokeSession(ctx, session.ID, s.now().UTC())
+}
+
+// Authenticate tells which user an access token was issued to.
+func (s *UserService) Authenticate(ctx context.Context, accessToken string) (model.Principal, error) {
+	session, err := s.repository.FindSessionByAccessHash(ctx, hashManagementToken(accessToken))
+	if errors.Is(err, repository.ErrNotFound) {
+		return model.Principal{}, ErrInvalidSessionToken
+	}
+	if err != nil {
+		return model.Principal{}, err
+	}
+
+	if session.RevokedAt != nil || !s.now().Before(session.AccessExpiresAt) {
+		return model.Principal{}, ErrInvalidSessionToken
+	}
+
+	return model.Principal{UserID: session.UserID, SessionID: session.ID, Scopes: sessionScopes}, nil
+}
+
+func (s *UserService) findRefreshable(ctx context.Context, refreshHash string) (model.Session, error) {
+	session, err := s.repository.FindSessionByRefreshHash(ctx, refreshHash)
+	if errors.Is(err, repository.ErrNotFound) {
+		return model.Session{}, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return model.Session{}, err
+	}
+
+	if session.RevokedAt != nil || !s.now().Before(session.RefreshExpiresAt) {
+		return model.Session{}, ErrInvalidRefreshToken
+	}
+
+	return session, nil
+}
+
+func (s *UserService) issueTokens(now time.Time) (model.SessionTokens, error) {
+	access, err := s.newToken()
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to generate access token: %v", err))
+		return model.SessionTokens{}, ErrKeyGenerationFailed
+	}
+
+	refresh, err := s.newToken()
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to generate refresh token: %v", err))
+		return model.SessionTokens{}, ErrKeyGenerationFailed
+	}
+
+	return model.SessionTokens{
+		AccessToken:      SessionTokenPrefix + access,
+		AccessExpiresAt:  now.Add(s.config.AccessTTL),
+		RefreshToken:     refreshTokenPrefix + refresh,
+		RefreshExpiresAt: now.Add(s.config.RefreshTTL),
+	}, nil
+}
+
+// normalizeEmail lowercases a bare address, display names like "Ada <ada@example.com>"
+// are rejected rather than silently stripped.
+func normalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if len(email) > maxEmailLength {
+		return "", ErrInvalidEmail
+	}
+
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return "", ErrInvalidEmail
+	}
+
+	return email, nil
+}
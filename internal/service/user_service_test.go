@@ -0,0 +1,479 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/ggoulart/url-shortener/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testPassword = "correct horse battery staple"
+
+func newTestUserService(r *MockUserRepository, now time.Time) *UserService {
+	s := NewUserService(r, SessionConfig{AccessTTL: 15 * time.Minute, RefreshTTL: 24 * time.Hour, OpenRegistration: true})
+	s.passwordParams = testArgon2Params
+	s.now = func() time.Time { return now }
+	tokens := []string{"access-secret", "refresh-secret"}
+	s.newToken = func() (string, error) {
+		token := tokens[0]
+		tokens = tokens[1:]
+		return token, nil
+	}
+	s.newID = func() string { return "an-id" }
+
+	return s
+}
+
+func TestUserService_Register(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	isUser := mock.MatchedBy(func(user model.User) bool {
+		ok, err := verifyPassword(testPassword, user.PasswordHash)
+		return user.ID == "an-id" && user.Email == "ada@example.com" && user.CreatedAt.Equal(now) && ok && err == nil
+	})
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		setup    func(*MockUserRepository)
+		wantErr  error
+	}{
+		{
+			name:     "when email is not a bare address",
+			email:    "Ada <ada@example.com>",
+			password: testPassword,
+			setup:    func(*MockUserRepository) {},
+			wantErr:  ErrInvalidEmail,
+		},
+		{
+			name:     "when password is too short",
+			email:    "ada@example.com",
+			password: "hunter2",
+			setup:    func(*MockUserRepository) {},
+			wantErr:  ErrInvalidPassword.WithDetail("password must be 12 to 128 characters long"),
+		},
+		{
+			name:     "when email is already registered",
+			email:    "ada@example.com",
+			password: testPassword,
+			setup: func(r *MockUserRepository) {
+				r.On("SaveUser", context.Background(), isUser).Return(repository.ErrEmailAlreadyExists)
+			},
+			wantErr: ErrEmailTaken,
+		},
+		{
+			name:     "when failed to save the user",
+			email:    "ada@example.com",
+			password: testPassword,
+			setup: func(r *MockUserRepository) {
+				r.On("SaveUser", context.Background(), isUser).Return(repository.ErrUnexpected)
+			},
+			wantErr: repository.ErrUnexpected,
+		},
+		{
+			name:     "when successfully registers with a normalized email",
+			email:    " Ada@Example.com ",
+			password: testPassword,
+			setup: func(r *MockUserRepository) {
+				r.On("SaveUser", context.Background(), isUser).Return(nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &MockUserRepository{}
+			s := newTestUserService(r, now)
+			tt.setup(r)
+
+			got, err := s.Register(context.Background(), tt.email, tt.password)
+
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr == nil {
+				assert.Equal(t, "ada@example.com", got.Email)
+			}
+			r.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserService_Register_Closed(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *model.Principal
+		wantErr   error
+	}{
+		{
+			name:    "when the caller is anonymous",
+			wantErr: ErrRegistrationClosed.WithDetail("users are registered with an admin key"),
+		},
+		{
+			name:      "when the caller is signed in",
+			principal: &model.Principal{UserID: "a-user-id", Scopes: sessionScopes},
+			wantErr:   ErrRegistrationClosed.WithDetail("users are registered with an admin key"),
+		},
+		{
+			name:      "when the caller holds an admin key",
+			principal: &model.Principal{KeyID: "a-key-id", Scopes: []model.Scope{model.ScopeAdmin}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = ContextWithPrincipal(ctx, *tt.principal)
+			}
+			r := &MockUserRepository{}
+			r.On("SaveUser", ctx, mock.Anything).Return(nil)
+			s := newTestUserService(r, time.Now())
+			s.config.OpenRegistration = false
+
+			_, err := s.Register(ctx, "ada@example.com", testPassword)
+
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr != nil {
+				r.AssertNotCalled(t, "SaveUser", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestUserService_Login(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	hash, err := hashPassword(testPassword, testArgon2Params)
+	assert.NoError(t, err)
+	user := model.User{ID: "a-user-id", Email: "ada@example.com", PasswordHash: hash}
+	session := model.Session{
+		ID:               "an-id",
+		UserID:           "a-user-id",
+		AccessHash:       hashManagementToken("uss_access-secret"),
+		AccessExpiresAt:  now.Add(15 * time.Minute),
+		RefreshHash:      hashManagementToken("usr_refresh-secret"),
+		RefreshExpiresAt: now.Add(24 * time.Hour),
+		CreatedAt:        now,
+	}
+	tokens := model.SessionTokens{
+		AccessToken:      "uss_access-secret",
+		AccessExpiresAt:  now.Add(15 * time.Minute),
+		RefreshToken:     "usr_refresh-secret",
+		RefreshExpiresAt: now.Add(24 * time.Hour),
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		setup    func(*MockUserRepository)
+		want     model.SessionTokens
+		wantErr  error
+	}{
+		{
+			name:     "when email is unknown",
+			password: testPassword,
+			setup: func(r *MockUserRepository) {
+				r.On("FindUserByEmail", context.Background(), "ada@example.com").Return(model.User{}, repository.ErrNotFound)
+			},
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:     "when failed to find the user",
+			password: testPassword,
+			setup: func(r *MockUserRepository) {
+				r.On("FindUserByEmail", context.Background(), "ada@example.com").Return(model.User{}, repository.ErrUnexpected)
+			},
+			wantErr: repository.ErrUnexpected,
+		},
+		{
+			name:     "when password is wrong",
+			password: "not the right password",
+			setup: func(r *MockUserRepository) {
+				r.On("FindUserByEmail", context.Background(), "ada@example.com").Return(user, nil)
+			},
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:     "when failed to save the session",
+			password: testPassword,
+			setup: func(r *MockUserRepository) {
+				r.On("FindUserByEmail", context.Background(), "ada@example.com").Return(user, nil)
+				r.On("SaveSession", context.Background(), session).Return(repository.ErrUnexpected)
+			},
+			wantErr: repository.ErrUnexpected,
+		},
+		{
+			name:     "when successfully signs in",
+			password: testPassword,
+			setup: func(r *MockUserRepository) {
+				r.On("FindUserByEmail", context.Background(), "ada@example.com").Return(user, nil)
+				r.On("SaveSession", context.Background(), session).Return(nil)
+			},
+			want: tokens,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &MockUserRepository{}
+			s := newTestUserService(r, now)
+			tt.setup(r)
+
+			got, err := s.Login(context.Background(), "ada@example.com", tt.password)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+			r.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserService_Refresh(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	earlier := now.Add(-time.Hour)
+	previousHash := hashManagementToken("usr_old-refresh-secret")
+	session := model.Session{ID: "a-session-id", UserID: "a-user-id", RefreshHash: previousHash, RefreshExpiresAt: now.Add(time.Hour)}
+	rotated := model.Session{
+		ID:               "a-session-id",
+		UserID:           "a-user-id",
+		AccessHash:       hashManagementToken("uss_access-secret"),
+		AccessExpiresAt:  now.Add(15 * time.Minute),
+		RefreshHash:      hashManagementToken("usr_refresh-secret"),
+		RefreshExpiresAt: now.Add(24 * time.Hour),
+	}
+
+	tests := []struct {
+		name    string
+		setup   func(*MockUserRepository)
+		want    model.SessionTokens
+		wantErr error
+	}{
+		{
+			name: "when refresh token is unknown",
+			setup: func(r *MockUserRepository) {
+				r.On("FindSessionByRefreshHash", context.Background(), previousHash).Return(model.Session{}, repository.ErrNotFound)
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "when session is revoked",
+			setup: func(r *MockUserRepository) {
+				revoked := session
+				revoked.RevokedAt = &earlier
+				r.On("FindSessionByRefreshHash", context.Background(), previousHash).Return(revoked, nil)
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "when refresh token expired",
+			setup: func(r *MockUserRepository) {
+				expired := session
+				expired.RefreshExpiresAt = now
+				r.On("FindSessionByRefreshHash", context.Background(), previousHash).Return(expired, nil)
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "when a concurrent refresh spent the token first",
+			setup: func(r *MockUserRepository) {
+				r.On("FindSessionByRefreshHash", context.Background(), previousHash).Return(session, nil)
+				r.On("RotateSession", context.Background(), rotated, previousHash).Return(repository.ErrNotFound)
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "when successfully rotates the tokens",
+			setup: func(r *MockUserRepository) {
+				r.On("FindSessionByRefreshHash", context.Background(), previousHash).Return(session, nil)
+				r.On("RotateSession", context.Background(), rotated, previousHash).Return(nil)
+			},
+			want: model.SessionTokens{
+				AccessToken:      "uss_access-secret",
+				AccessExpiresAt:  now.Add(15 * time.Minute),
+				RefreshToken:     "usr_refresh-secret",
+				RefreshExpiresAt: now.Add(24 * time.Hour),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &MockUserRepository{}
+			s := newTestUserService(r, now)
+			tt.setup(r)
+
+			got, err := s.Refresh(context.Background(), "usr_old-refresh-secret")
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+			r.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserService_Logout(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	hash := hashManagementToken("usr_refresh-secret")
+
+	tests := []struct {
+		name    string
+		setup   func(*MockUserRepository)
+		wantErr error
+	}{
+		{
+			name: "when refresh token is unknown",
+			setup: func(r *MockUserRepository) {
+				r.On("FindSessionByRefreshHash", context.Background(), hash).Return(model.Session{}, repository.ErrNotFound)
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "when successfully revokes the session",
+			setup: func(r *MockUserRepository) {
+				r.On("FindSessionByRefreshHash", context.Background(), hash).Return(model.Session{ID: "a-session-id", RefreshExpiresAt: now.Add(time.Hour)}, nil)
+				r.On("RevokeSession", context.Background(), "a-session-id", now).Return(nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &MockUserRepository{}
+			s := newTestUserService(r, now)
+			tt.setup(r)
+
+			err := s.Logout(context.Background(), "usr_refresh-secret")
+
+			assert.Equal(t, tt.wantErr, err)
+			r.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserService_Authenticate(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	hash := hashManagementToken("uss_access-secret")
+	session := model.Session{ID: "a-session-id", UserID: "a-user-id", AccessExpiresAt: now.Add(time.Minute)}
+
+	tests := []struct {
+		name    string
+		setup   func(*MockUserRepository)
+		want    model.Principal
+		wantErr error
+	}{
+		{
+			name: "when access token is unknown",
+			setup: func(r *MockUserRepository) {
+				r.On("FindSessionByAccessHash", context.Background(), hash).Return(model.Session{}, repository.ErrNotFound)
+			},
+			wantErr: ErrInvalidSessionToken,
+		},
+		{
+			name: "when failed to find the session",
+			setup: func(r *MockUserRepository) {
+				r.On("FindSessionByAccessHash", context.Background(), hash).Return(model.Session{}, repository.ErrUnexpected)
+			},
+			wantErr: repository.ErrUnexpected,
+		},
+		{
+			name: "when access token expired",
+			setup: func(r *MockUserRepository) {
+				expired := session
+				expired.AccessExpiresAt = now
+				r.On("FindSessionByAccessHash", context.Background(), hash).Return(expired, nil)
+			},
+			wantErr: ErrInvalidSessionToken,
+		},
+		{
+			name: "when session is revoked",
+			setup: func(r *MockUserRepository) {
+				revoked := session
+				revoked.RevokedAt = &now
+				r.On("FindSessionByAccessHash", context.Background(), hash).Return(revoked, nil)
+			},
+			wantErr: ErrInvalidSessionToken,
+		},
+		{
+			name: "when access token is valid",
+			setup: func(r *MockUserRepository) {
+				r.On("FindSessionByAccessHash", context.Background(), hash).Return(session, nil)
+			},
+			want: model.Principal{UserID: "a-user-id", SessionID: "a-session-id", Scopes: []model.Scope{model.ScopeCreate, model.ScopeRead}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &MockUserRepository{}
+			s := newTestUserService(r, now)
+			tt.setup(r)
+
+			got, err := s.Authenticate(context.Background(), "uss_access-secret")
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+			r.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCredentials_Authenticate(t *testing.T) {
+	apiKeys := &MockCredentialChecker{}
+	sessions := &MockCredentialChecker{}
+	apiKeys.On("Authenticate", context.Background(), "usk_a-key").Return(model.Principal{KeyID: "a-key-id"}, nil)
+	sessions.On("Authenticate", context.Background(), "uss_a-token").Return(model.Principal{}, errors.New("expired"))
+
+	c := NewCredentials(apiKeys, sessions)
+
+	got, err := c.Authenticate(context.Background(), "usk_a-key")
+	assert.NoError(t, err)
+	assert.Equal(t, model.Principal{KeyID: "a-key-id"}, got)
+
+	_, err = c.Authenticate(context.Background(), "uss_a-token")
+	assert.Equal(t, errors.New("expired"), err)
+}
+
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) SaveUser(ctx context.Context, user model.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) FindUserByEmail(ctx context.Context, email string) (model.User, error) {
+	args := m.Called(ctx, email)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+func (m *MockUserRepository) SaveSession(ctx context.Context, session model.Session) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) FindSessionByAccessHash(ctx context.Context, hash string) (model.Session, error) {
+	args := m.Called(ctx, hash)
+	return args.Get(0).(model.Session), args.Error(1)
+}
+
+func (m *MockUserRepository) FindSessionByRefreshHash(ctx context.Context, hash string) (model.Session, error) {
+	args := m.Called(ctx, hash)
+	return args.Get(0).(model.Session), args.Error(1)
+}
+
+func (m *MockUserRepository) RotateSession(ctx context.Context, session model.Session, previousRefreshHash string) error {
+	args := m.Called(ctx, session, previousRefreshHash)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) RevokeSession(ctx context.Context, id string, revokedAt time.Time) error {
+	args := m.Called(ctx, id, revokedAt)
+	return args.Error(0)
+}
+
+type MockCredentialChecker struct {
+	mock.Mock
+}
+
+func (m *MockCredentialChecker) Authenticate(ctx context.Context, secret string) (model.Principal, error) {
+	args := m.Called(ctx, secret)
+	return args.Get(0).(model.Principal), args.Error(1)
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/ggoulart/url-shortener/internal/repository"
+)
+
+var ErrWorkspaceNeedsUser = apperror.New("workspace_needs_user", http.StatusForbidden, "workspaces are only available to signed in users")
+var ErrNotWorkspaceMember = apperror.New("not_workspace_member", http.StatusForbidden, "not a member of the workspace")
+var ErrInsufficientRole = apperror.New("insufficient_role", http.StatusForbidden, "role does not allow this action")
+
+// rolePolicy is the least role each action needs, actions missing here are denied.
+var rolePolicy = map[model.Action]model.Role{
+	model.ActionViewLinks:     model.RoleViewer,
+	model.ActionViewMembers:   model.RoleViewer,
+	model.ActionCreateLinks:   model.RoleEditor,
+	model.ActionEditLinks:     model.RoleEditor,
+	model.ActionDeleteLinks:   model.RoleAdmin,
+	model.ActionManageMembers: model.RoleAdmin,
+}
+
+type MemberFinder interface {
+	FindMember(ctx context.Context, workspaceID string, userID string) (model.Member, error)
+}
+
+// WorkspacePolicy decides what the signed in user of a request may do in a workspace.
+type WorkspacePolicy struct {
+	members MemberFinder
+}
+
+func NewWorkspacePolicy(members MemberFinder) *WorkspacePolicy {
+	return &WorkspacePolicy{members: members}
+}
+
+// Authorize returns the signed in user's membership of workspaceID if their role
+// allows action.
+func (p *WorkspacePolicy) Authorize(ctx context.Context, workspaceID string, action model.Action) (model.Member, error) {
+	principal, _ := PrincipalFromContext(ctx)
+	if principal.UserID == "" {
+		return model.Member{}, ErrWorkspaceNeedsUser
+	}
+
+	member, err := p.members.FindMember(ctx, workspaceID, principal.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return model.Member{}, ErrNotWorkspaceMember
+	}
+	if err != nil {
+		return model.Member{}, err
+	}
+
+	required, ok := rolePolicy[action]
+	if !ok {
+		return model.Member{}, ErrInsufficientRole
+	}
+	if !member.Role.AtLeast(required) {
+		return model.Member{}, ErrInsufficientRole.WithDetail(fmt.Sprintf("%s needs the %s role", action, required))
+	}
+
+	return member, nil
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/ggoulart/url-shortener/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkspacePolicy_Authorize(t *testing.T) {
+	ctx := ContextWithPrincipal(context.Background(), model.Principal{UserID: "a-user-id"})
+	member := func(role model.Role) model.Member {
+		return model.Member{WorkspaceID: "a-workspace-id", UserID: "a-user-id", Role: role}
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		action  model.Action
+		setup   func(*MockWorkspaceRepository)
+		want    model.Member
+		wantErr error
+	}{
+		{
+			name:    "when the request has no signed in user",
+			ctx:     ContextWithPrincipal(context.Background(), model.Principal{KeyID: "a-key-id"}),
+			action:  model.ActionViewLinks,
+			setup:   func(*MockWorkspaceRepository) {},
+			wantErr: ErrWorkspaceNeedsUser,
+		},
+		{
+			name:   "when not a member",
+			ctx:    ctx,
+			action: model.ActionViewLinks,
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("FindMember", ctx, "a-workspace-id", "a-user-id").Return(model.Member{}, repository.ErrNotFound)
+			},
+			wantErr: ErrNotWorkspaceMember,
+		},
+		{
+			name:   "when failed to find the member",
+			ctx:    ctx,
+			action: model.ActionViewLinks,
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("FindMember", ctx, "a-workspace-id", "a-user-id").Return(model.Member{}, repository.ErrUnexpected)
+			},
+			wantErr: repository.ErrUnexpected,
+		},
+		{
+			name:   "when a viewer views links",
+			ctx:    ctx,
+			action: model.ActionViewLinks,
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("FindMember", ctx, "a-workspace-id", "a-user-id").Return(member(model.RoleViewer), nil)
+			},
+			want: member(model.RoleViewer),
+		},
+		{
+			name:   "when a viewer creates links",
+			ctx:    ctx,
+			action: model.ActionCreateLinks,
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("FindMember", ctx, "a-workspace-id", "a-user-id").Return(member(model.RoleViewer), nil)
+			},
+			wantErr: ErrInsufficientRole.WithDetail("create_links needs the editor role"),
+		},
+		{
+			name:   "when an editor deletes links",
+			ctx:    ctx,
+			action: model.ActionDeleteLinks,
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("FindMember", ctx, "a-workspace-id", "a-user-id").Return(member(model.RoleEditor), nil)
+			},
+			wantErr: ErrInsufficientRole.WithDetail("delete_links needs the admin role"),
+		},
+		{
+			name:   "when an owner manages members",
+			ctx:    ctx,
+			action: model.ActionManageMembers,
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("FindMember", ctx, "a-workspace-id", "a-user-id").Return(member(model.RoleOwner), nil)
+			},
+			want: member(model.RoleOwner),
+		},
+		{
+			name:   "when the action is unknown",
+			ctx:    ctx,
+			action: model.Action("rename_workspace"),
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("FindMember", ctx, "a-workspace-id", "a-user-id").Return(member(model.RoleOwner), nil)
+			},
+			wantErr: ErrInsufficientRole,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &MockWorkspaceRepository{}
+			tt.setup(r)
+			p := NewWorkspacePolicy(r)
+
+			got, err := p.Authorize(tt.ctx, "a-workspace-id", tt.action)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ggoulart/url-shortener/internal/apperror"
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/ggoulart/url-shortener/internal/repository"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidWorkspaceName = apperror.New("invalid_workspace_name", http.StatusBadRequest, "invalid workspace name")
+var ErrInvalidRole = apperror.New("invalid_role", http.StatusBadRequest, "invalid role")
+var ErrInvalidInvitation = apperror.New("invalid_invitation", http.StatusNotFound, "invitation not found, expired or already used")
+var ErrAlreadyMember = apperror.New("already_member", http.StatusConflict, "already a member of the workspace")
+var ErrLastOwner = apperror.New("last_owner", http.StatusConflict, "a workspace needs at least one owner")
+
+const invitationTokenPrefix = "usi_"
+
+const maxWorkspaceNameLength = 100
+
+type WorkspaceRepository interface {
+	CreateWorkspace(ctx context.Context, workspace model.Workspace, ownerID string) error
+	ListWorkspaces(ctx context.Context, userID string) ([]model.UserWorkspace, error)
+	FindMember(ctx context.Context, workspaceID string, userID string) (model.Member, error)
+	ListMembers(ctx context.Context, workspaceID string) ([]model.Member, error)
+	UpdateMemberRole(ctx context.Context, workspaceID string, userID string, role model.Role) error
+	DeleteMember(ctx context.Context, workspaceID string, userID string) error
+	SaveInvitation(ctx context.Context, invitation model.Invitation) error
+	AcceptInvitation(ctx context.Context, tokenHash string, userID string, acceptedAt time.Time) (model.Member, error)
+}
+
+// WorkspaceService manages workspaces and their members. Members can only hand out
+// or change roles up to their own, so an admin can't make anyone an owner.
+type WorkspaceService struct {
+	repository WorkspaceRepository
+	access     AccessPolicy
+	config     WorkspaceConfig
+	now        func() time.Time
+	newToken   func() (string, error)
+	newID      func() string
+}
+
+func NewWorkspaceService(repository WorkspaceRepository, access AccessPolicy, config WorkspaceConfig) *WorkspaceService {
+	return &WorkspaceService{
+		repository: repository,
+		access:     access,
+		config:     config,
+		now:        time.Now,
+		newToken:   newManagementToken,
+		newID:      uuid.NewString,
+	}
+}
+
+// CreateWorkspace creates a workspace owned by the signed in user.
+func (s *WorkspaceService) CreateWorkspace(ctx context.Context, name string) (model.UserWorkspace, error) {
+	principal, _ := PrincipalFromContext(ctx)
+	if principal.UserID == "" {
+		return model.UserWorkspace{}, ErrWorkspaceNeedsUser
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxWorkspaceNameLength {
+		return model.UserWorkspace{}, ErrInvalidWorkspaceName.WithDetail(fmt.Sprintf("name must be 1 to %d characters long", maxWorkspaceNameLength))
+	}
+
+	workspace := model.Workspace{ID: s.newID(), Name: name, CreatedAt: s.now().UTC()}
+
+	err := s.repository.CreateWorkspace(ctx, workspace, principal.UserID)
+	if err != nil {
+		return model.UserWorkspace{}, err
+	}
+
+	return model.UserWorkspace{Workspace: workspace, Role: model.RoleOwner}, nil
+}
+
+// ListWorkspaces lists the workspaces the signed in user is a member of.
+func (s *WorkspaceService) ListWorkspaces(ctx context.Context) ([]model.UserWorkspace, error) {
+	principal, _ := PrincipalFromContext(ctx)
+	if principal.UserID == "" {
+		return nil, ErrWorkspaceNeedsUser
+	}
+
+	return s.repository.ListWorkspaces(ctx, principal.UserID)
+}
+
+func (s *WorkspaceService) ListMembers(ctx context.Context, workspaceID string) ([]model.Member, error) {
+	_, err := s.access.Authorize(ctx, workspaceID, model.ActionViewMembers)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repository.ListMembers(ctx, workspaceID)
+}
+
+// Invite mints an invitation to join workspaceID with role, whoever accepts it first
+// before it expires becomes a member.
+func (s *WorkspaceService) Invite(ctx context.Context, workspaceID string, role model.Role) (model.MintedInvitation, error) {
+	if !role.Valid() {
+		return model.MintedInvitation{}, ErrInvalidRole
+	}
+
+	inviter, err := s.access.Authorize(ctx, workspaceID, model.ActionManageMembers)
+	if err != nil {
+		return model.MintedInvitation{}, err
+	}
+
+	if !inviter.Role.AtLeast(role) {
+		return model.MintedInvitation{}, ErrInsufficientRole.WithDetail(fmt.Sprintf("%ss can't invite %ss", inviter.Role, role))
+	}
+
+	secret, err := s.newToken()
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to generate invitation token: %v", err))
+		return model.MintedInvitation{}, ErrKeyGenerationFailed
+	}
+	token := invitationTokenPrefix + secret
+
+	now := s.now().UTC()
+	invitation := model.Invitation{
+		ID:          s.newID(),
+		WorkspaceID: workspaceID,
+		Role:        role,
+		TokenHash:   hashManagementToken(token),
+		InvitedBy:   inviter.UserID,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.config.InvitationTTL),
+	}
+
+	err = s.repository.SaveInvitation(ctx, invitation)
+	if err != nil {
+		return model.MintedInvitation{}, err
+	}
+
+	return model.MintedInvitation{Invitation: invitation, Token: token}, nil
+}
+
+// AcceptInvitation makes the signed in user a member of the invitation's workspace.
+func (s *WorkspaceService) AcceptInvitation(ctx context.Context, token string) (model.Member, error) {
+	principal, _ := PrincipalFromContext(ctx)
+	if principal.UserID == "" {
+		return model.Member{}, ErrWorkspaceNeedsUser
+	}
+
+	member, err := s.repository.AcceptInvitation(ctx, hashManagementToken(token), principal.UserID, s.now().UTC())
+	if errors.Is(err, repository.ErrNotFound) {
+		return model.Member{}, ErrInvalidInvitation
+	}
+	if errors.Is(err, repository.ErrMemberAlreadyExists) {
+		return model.Member{}, ErrAlreadyMember
+	}
+	if err != nil {
+		return model.Member{}, err
+	}
+
+	return member, nil
+}
+
+// UpdateMemberRole changes the role of userID in workspaceID. Neither the member's
+// current role nor the new one may be above the role of whoever changes it.
+func (s *WorkspaceService) UpdateMemberRole(ctx context.Context, workspaceID string, userID string, role model.Role) error {
+	if !role.Valid() {
+		return ErrInvalidRole
+	}
+
+	actor, target, err := s.manageableMember(ctx, workspaceID, userID)
+	if err != nil {
+		return err
+	}
+
+	if !actor.Role.AtLeast(role) {
+		return ErrInsufficientRole.WithDetail(fmt.Sprintf("%ss can't grant the %s role", actor.Role, role))
+	}
+
+	if target.Role == role {
+		return nil
+	}
+
+	if target.Role == model.RoleOwner {
+		err = s.ensureOtherOwner(ctx, workspaceID, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.repository.UpdateMemberRole(ctx, workspaceID, userID, role)
+}
+
+// RemoveMember takes userID out of workspaceID. Members may always leave, removing
+// anyone else needs a role at least as high as theirs.
+func (s *WorkspaceService) RemoveMember(ctx context.Context, workspaceID string, userID string) error {
+	principal, _ := PrincipalFromContext(ctx)
+
+	var target model.Member
+	var err error
+	if principal.UserID != "" && principal.UserID == userID {
+		target, err = s.access.Authorize(ctx, workspaceID, model.ActionViewMembers)
+	} else {
+		_, target, err = s.manageableMember(ctx, workspaceID, userID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if target.Role == model.RoleOwner {
+		err = s.ensureOtherOwner(ctx, workspaceID, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.repository.DeleteMember(ctx, workspaceID, userID)
+}
+
+// manageableMember returns the membership of the signed in user and of userID if the
+// signed in user may manage members and ranks at least as high as userID.
+func (s *WorkspaceService) manageableMember(ctx context.Context, workspaceID string, userID string) (model.Member, model.Member, error) {
+	actor, err := s.access.Authorize(ctx, workspaceID, model.ActionManageMembers)
+	if err != nil {
+		return model.Member{}, model.Member{}, err
+	}
+
+	target, err := s.repository.FindMember(ctx, workspaceID, userID)
+	if err != nil {
+		return model.Member{}, model.Member{}, err
+	}
+
+	if !actor.Role.AtLeast(target.Role) {
+		return model.Member{}, model.Member{}, ErrInsufficientRole.WithDetail(fmt.Sprintf("%ss can't manage %ss", actor.Role, target.Role))
+	}
+
+	return actor, target, nil
+}
+
+func (s *WorkspaceService) ensureOtherOwner(ctx context.Context, workspaceID string, userID string) error {
+	members, err := s.repository.ListMembers(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range members {
+		if member.Role == model.RoleOwner && member.UserID != userID {
+			return nil
+		}
+	}
+
+	return ErrLastOwner
+}
@@ -0,0 +1,395 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ggoulart/url-shortener/internal/model"
+	"github.com/ggoulart/url-shortener/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestWorkspaceService(r *MockWorkspaceRepository, now time.Time) *WorkspaceService {
+	s := NewWorkspaceService(r, NewWorkspacePolicy(r), WorkspaceConfig{InvitationTTL: 24 * time.Hour})
+	s.now = func() time.Time { return now }
+	s.newToken = func() (string, error) { return "invitation-secret", nil }
+	s.newID = func() string { return "an-id" }
+
+	return s
+}
+
+func TestWorkspaceService_CreateWorkspace(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	ctx := ContextWithPrincipal(context.Background(), model.Principal{UserID: "a-user-id"})
+	workspace := model.Workspace{ID: "an-id", Name: "Marketing", CreatedAt: now}
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		workspace string
+		setup     func(*MockWorkspaceRepository)
+		want      model.UserWorkspace
+		wantErr   error
+	}{
+		{
+			name:      "when the request has no signed in user",
+			ctx:       ContextWithPrincipal(context.Background(), model.Principal{KeyID: "a-key-id"}),
+			workspace: "Marketing",
+			setup:     func(*MockWorkspaceRepository) {},
+			wantErr:   ErrWorkspaceNeedsUser,
+		},
+		{
+			name:      "when the name is blank",
+			ctx:       ctx,
+			workspace: "  ",
+			setup:     func(*MockWorkspaceRepository) {},
+			wantErr:   ErrInvalidWorkspaceName.WithDetail("name must be 1 to 100 characters long"),
+		},
+		{
+			name:      "when failed to save the workspace",
+			ctx:       ctx,
+			workspace: "Marketing",
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("CreateWorkspace", ctx, workspace, "a-user-id").Return(repository.ErrUnexpected)
+			},
+			wantErr: repository.ErrUnexpected,
+		},
+		{
+			name:      "when successfully creates the workspace owned by its creator",
+			ctx:       ctx,
+			workspace: " Marketing ",
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("CreateWorkspace", ctx, workspace, "a-user-id").Return(nil)
+			},
+			want: model.UserWorkspace{Workspace: workspace, Role: model.RoleOwner},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &MockWorkspaceRepository{}
+			tt.setup(r)
+			s := newTestWorkspaceService(r, now)
+
+			got, err := s.CreateWorkspace(tt.ctx, tt.workspace)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestWorkspaceService_Invite(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	ctx := ContextWithPrincipal(context.Background(), model.Principal{UserID: "a-user-id"})
+	invitation := model.Invitation{
+		ID:          "an-id",
+		WorkspaceID: "a-workspace-id",
+		Role:        model.RoleEditor,
+		TokenHash:   hashManagementToken("usi_invitation-secret"),
+		InvitedBy:   "a-user-id",
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(24 * time.Hour),
+	}
+
+	tests := []struct {
+		name    string
+		role    model.Role
+		setup   func(*MockWorkspaceRepository)
+		want    model.MintedInvitation
+		wantErr error
+	}{
+		{
+			name:    "when the role is unknown",
+			role:    model.Role("superuser"),
+			setup:   func(*MockWorkspaceRepository) {},
+			wantErr: ErrInvalidRole,
+		},
+		{
+			name: "when an editor invites",
+			role: model.RoleViewer,
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("FindMember", ctx, "a-workspace-id", "a-user-id").Return(model.Member{UserID: "a-user-id", Role: model.RoleEditor}, nil)
+			},
+			wantErr: ErrInsufficientRole.WithDetail("manage_members needs the admin role"),
+		},
+		{
+			name: "when an admin invites an owner",
+			role: model.RoleOwner,
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("FindMember", ctx, "a-workspace-id", "a-user-id").Return(model.Member{UserID: "a-user-id", Role: model.RoleAdmin}, nil)
+			},
+			wantErr: ErrInsufficientRole.WithDetail("admins can't invite owners"),
+		},
+		{
+			name: "when failed to save the invitation",
+			role: model.RoleEditor,
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("FindMember", ctx, "a-workspace-id", "a-user-id").Return(model.Member{UserID: "a-user-id", Role: model.RoleAdmin}, nil)
+				r.On("SaveInvitation", ctx, invitation).Return(repository.ErrUnexpected)
+			},
+			wantErr: repository.ErrUnexpected,
+		},
+		{
+			name: "when successfully invites",
+			role: model.RoleEditor,
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("FindMember", ctx, "a-workspace-id", "a-user-id").Return(model.Member{UserID: "a-user-id", Role: model.RoleAdmin}, nil)
+				r.On("SaveInvitation", ctx, invitation).Return(nil)
+			},
+			want: model.MintedInvitation{Invitation: invitation, Token: "usi_invitation-secret"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &MockWorkspaceRepository{}
+			tt.setup(r)
+			s := newTestWorkspaceService(r, now)
+
+			got, err := s.Invite(ctx, "a-workspace-id", tt.role)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestWorkspaceService_AcceptInvitation(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	ctx := ContextWithPrincipal(context.Background(), model.Principal{UserID: "a-user-id"})
+	tokenHash := hashManagementToken("usi_invitation-secret")
+	member := model.Member{WorkspaceID: "a-workspace-id", UserID: "a-user-id", Role: model.RoleEditor, JoinedAt: now}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		setup   func(*MockWorkspaceRepository)
+		want    model.Member
+		wantErr error
+	}{
+		{
+			name:    "when the request has no signed in user",
+			ctx:     context.Background(),
+			setup:   func(*MockWorkspaceRepository) {},
+			wantErr: ErrWorkspaceNeedsUser,
+		},
+		{
+			name: "when the invitation is unknown, used or expired",
+			ctx:  ctx,
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("AcceptInvitation", ctx, tokenHash, "a-user-id", now).Return(model.Member{}, repository.ErrNotFound)
+			},
+			wantErr: ErrInvalidInvitation,
+		},
+		{
+			name: "when already a member",
+			ctx:  ctx,
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("AcceptInvitation", ctx, tokenHash, "a-user-id", now).Return(model.Member{}, repository.ErrMemberAlreadyExists)
+			},
+			wantErr: ErrAlreadyMember,
+		},
+		{
+			name: "when successfully accepts",
+			ctx:  ctx,
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("AcceptInvitation", ctx, tokenHash, "a-user-id", now).Return(member, nil)
+			},
+			want: member,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &MockWorkspaceRepository{}
+			tt.setup(r)
+			s := newTestWorkspaceService(r, now)
+
+			got, err := s.AcceptInvitation(tt.ctx, "usi_invitation-secret")
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestWorkspaceService_UpdateMemberRole(t *testing.T) {
+	ctx := ContextWithPrincipal(context.Background(), model.Principal{UserID: "a-user-id"})
+	admin := model.Member{UserID: "a-user-id", Role: model.RoleAdmin}
+	owner := model.Member{UserID: "a-user-id", Role: model.RoleOwner}
+
+	tests := []struct {
+		name    string
+		role    model.Role
+		setup   func(*MockWorkspaceRepository)
+		wantErr error
+	}{
+		{
+			name:    "when the role is unknown",
+			role:    model.Role("superuser"),
+			setup:   func(*MockWorkspaceRepository) {},
+			wantErr: ErrInvalidRole,
+		},
+		{
+			name: "when the member is not found",
+			role: model.RoleViewer,
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("FindMember", ctx, "a-workspace-id", "a-user-id").Return(admin, nil)
+				r.On("FindMember", ctx, "a-workspace-id", "another-user-id").Return(model.Member{}, repository.ErrNotFound)
+			},
+			wantErr: repository.ErrNotFound,
+		},
+		{
+			name: "when an admin demotes an owner",
+			role: model.RoleViewer,
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("FindMember", ctx, "a-workspace-id", "a-user-id").Return(admin, nil)
+				r.On("FindMember", ctx, "a-workspace-id", "another-user-id").Return(model.Member{UserID: "another-user-id", Role: model.RoleOwner}, nil)
+			},
+			wantErr: ErrInsufficientRole.WithDetail("admins can't manage owners"),
+		},
+		{
+			name: "when an admin promotes to owner",
+			role: model.RoleOwner,
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("FindMember", ctx, "a-workspace-id", "a-user-id").Return(admin, nil)
+				r.On("FindMember", ctx, "a-workspace-id", "another-user-id").Return(model.Member{UserID: "another-user-id", Role: model.RoleEditor}, nil)
+			},
+			wantErr: ErrInsufficientRole.WithDetail("admins can't grant the owner role"),
+		},
+		{
+			name: "when demoting the last owner",
+			role: model.RoleAdmin,
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("FindMember", ctx, "a-workspace-id", "a-user-id").Return(owner, nil)
+				r.On("FindMember", ctx, "a-workspace-id", "another-user-id").Return(model.Member{UserID: "another-user-id", Role: model.RoleOwner}, nil)
+				r.On("ListMembers", ctx, "a-workspace-id").Return([]model.Member{{UserID: "another-user-id", Role: model.RoleOwner}, {UserID: "a-third-user-id", Role: model.RoleAdmin}}, nil)
+			},
+			wantErr: ErrLastOwner,
+		},
+		{
+			name: "when successfully changes the role",
+			role: model.RoleEditor,
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("FindMember", ctx, "a-workspace-id", "a-user-id").Return(admin, nil)
+				r.On("FindMember", ctx, "a-workspace-id", "another-user-id").Return(model.Member{UserID: "another-user-id", Role: model.RoleViewer}, nil)
+				r.On("UpdateMemberRole", ctx, "a-workspace-id", "another-user-id", model.RoleEditor).Return(nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &MockWorkspaceRepository{}
+			tt.setup(r)
+			s := newTestWorkspaceService(r, time.Now())
+
+			err := s.UpdateMemberRole(ctx, "a-workspace-id", "another-user-id", tt.role)
+
+			assert.Equal(t, tt.wantErr, err)
+			r.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWorkspaceService_RemoveMember(t *testing.T) {
+	ctx := ContextWithPrincipal(context.Background(), model.Principal{UserID: "a-user-id"})
+
+	tests := []struct {
+		name    string
+		userID  string
+		setup   func(*MockWorkspaceRepository)
+		wantErr error
+	}{
+		{
+			name:   "when a viewer leaves",
+			userID: "a-user-id",
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("FindMember", ctx, "a-workspace-id", "a-user-id").Return(model.Member{UserID: "a-user-id", Role: model.RoleViewer}, nil)
+				r.On("DeleteMember", ctx, "a-workspace-id", "a-user-id").Return(nil)
+			},
+		},
+		{
+			name:   "when the last owner leaves",
+			userID: "a-user-id",
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("FindMember", ctx, "a-workspace-id", "a-user-id").Return(model.Member{UserID: "a-user-id", Role: model.RoleOwner}, nil)
+				r.On("ListMembers", ctx, "a-workspace-id").Return([]model.Member{{UserID: "a-user-id", Role: model.RoleOwner}}, nil)
+			},
+			wantErr: ErrLastOwner,
+		},
+		{
+			name:   "when an editor removes another member",
+			userID: "another-user-id",
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("FindMember", ctx, "a-workspace-id", "a-user-id").Return(model.Member{UserID: "a-user-id", Role: model.RoleEditor}, nil)
+			},
+			wantErr: ErrInsufficientRole.WithDetail("manage_members needs the admin role"),
+		},
+		{
+			name:   "when an owner removes another owner",
+			userID: "another-user-id",
+			setup: func(r *MockWorkspaceRepository) {
+				r.On("FindMember", ctx, "a-workspace-id", "a-user-id").Return(model.Member{UserID: "a-user-id", Role: model.RoleOwner}, nil)
+				r.On("FindMember", ctx, "a-workspace-id", "another-user-id").Return(model.Member{UserID: "another-user-id", Role: model.RoleOwner}, nil)
+				r.On("ListMembers", ctx, "a-workspace-id").Return([]model.Member{{UserID: "a-user-id", Role: model.RoleOwner}, {UserID: "another-user-id", Role: model.RoleOwner}}, nil)
+				r.On("DeleteMember", ctx, "a-workspace-id", "another-user-id").Return(nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &MockWorkspaceRepository{}
+			tt.setup(r)
+			s := newTestWorkspaceService(r, time.Now())
+
+			err := s.RemoveMember(ctx, "a-workspace-id", tt.userID)
+
+			assert.Equal(t, tt.wantErr, err)
+			r.AssertExpectations(t)
+		})
+	}
+}
+
+type MockWorkspaceRepository struct {
+	mock.Mock
+}
+
+func (m *MockWorkspaceRepository) CreateWorkspace(ctx context.Context, workspace model.Workspace, ownerID string) error {
+	args := m.Called(ctx, workspace, ownerID)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) ListWorkspaces(ctx context.Context, userID string) ([]model.UserWorkspace, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]model.UserWorkspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) FindMember(ctx context.Context, workspaceID string, userID string) (model.Member, error) {
+	args := m.Called(ctx, workspaceID, userID)
+	return args.Get(0).(model.Member), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) ListMembers(ctx context.Context, workspaceID string) ([]model.Member, error) {
+	args := m.Called(ctx, workspaceID)
+	return args.Get(0).([]model.Member), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) UpdateMemberRole(ctx context.Context, workspaceID string, userID string, role model.Role) error {
+	args := m.Called(ctx, workspaceID, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) DeleteMember(ctx context.Context, workspaceID string, userID string) error {
+	args := m.Called(ctx, workspaceID, userID)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) SaveInvitation(ctx context.Context, invitation model.Invitation) error {
+	args := m.Called(ctx, invitation)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) AcceptInvitation(ctx context.Context, tokenHash string, userID string, acceptedAt time.Time) (model.Member, error) {
+	args := m.Called(ctx, tokenHash, userID, acceptedAt)
+	return args.Get(0).(model.Member), args.Error(1)
+}
@@ -0,0 +1,2 @@
+DROP INDEX idx_urls_expires_at;
+ALTER TABLE urls DROP COLUMN expires_at;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE urls
+    ADD COLUMN expires_at TIMESTAMPTZ;
+
+-- Index for the expiration reaper, only links with an expiry are scanned
+CREATE INDEX idx_urls_expires_at ON urls (expires_at) WHERE expires_at IS NOT NULL;
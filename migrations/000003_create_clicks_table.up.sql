@@ -0,0 +1,12 @@
+CREATE TABLE clicks
+(
+    id          BIGSERIAL PRIMARY KEY,
+    encoded_key VARCHAR(255) NOT NULL,
+    clicked_at  TIMESTAMPTZ  NOT NULL,
+    referrer    TEXT,
+    user_agent  TEXT,
+    client_ip   TEXT
+);
+
+-- Index for stats queries, always filtered by key and time range
+CREATE INDEX idx_clicks_encoded_key_clicked_at ON clicks (encoded_key, clicked_at);
@@ -0,0 +1,9 @@
+DROP INDEX urls_long_url_key;
+
+-- Fails while a disabled link shares its long url with another link, those have to
+-- be resolved by hand rather than deleted here
+ALTER TABLE urls ADD CONSTRAINT urls_long_url_key UNIQUE (long_url);
+
+ALTER TABLE urls
+    DROP COLUMN disabled_at,
+    DROP COLUMN management_token_hash;
\ No newline at end of file
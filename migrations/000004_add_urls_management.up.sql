@@ -0,0 +1,8 @@
+ALTER TABLE urls
+    ADD COLUMN management_token_hash TEXT,
+    ADD COLUMN disabled_at           TIMESTAMPTZ;
+
+-- Disabled links release their long url so it can be shortened again,
+-- the index keeps the constraint name the application maps to its error
+ALTER TABLE urls DROP CONSTRAINT urls_long_url_key;
+CREATE UNIQUE INDEX urls_long_url_key ON urls (long_url) WHERE disabled_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,7 @@
+DROP TABLE url_history;
+
+-- Fails while several active links share a long url, those have to be resolved by hand
+CREATE UNIQUE INDEX urls_long_url_key ON urls (long_url) WHERE disabled_at IS NULL;
+
+ALTER TABLE urls
+    DROP COLUMN version;
\ No newline at end of file
@@ -0,0 +1,16 @@
+ALTER TABLE urls
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+-- Edited links can share a destination, long_url is only looked up from now on
+-- through idx_urls_long_url
+DROP INDEX urls_long_url_key;
+
+CREATE TABLE url_history
+(
+    encoded_key VARCHAR(255) NOT NULL REFERENCES urls (encoded_key) ON DELETE CASCADE,
+    version     INTEGER      NOT NULL,
+    long_url    TEXT         NOT NULL,
+    changed_at  TIMESTAMPTZ  NOT NULL,
+    actor       TEXT         NOT NULL,
+    PRIMARY KEY (encoded_key, version)
+);
\ No newline at end of file
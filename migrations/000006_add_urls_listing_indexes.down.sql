@@ -0,0 +1,11 @@
+-- pg_trgm is left installed, other database objects may depend on it
+DROP INDEX idx_urls_long_url_trgm;
+
+DROP INDEX idx_urls_long_url_host;
+
+DROP INDEX idx_urls_encoded_key_pattern;
+
+DROP INDEX idx_urls_created_at_encoded_key;
+
+ALTER TABLE urls
+    ALTER COLUMN created_at DROP NOT NULL;
\ No newline at end of file
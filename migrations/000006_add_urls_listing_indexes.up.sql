@@ -0,0 +1,18 @@
+-- Listing pages through links by (created_at, encoded_key), every link needs a creation time
+UPDATE urls SET created_at = NOW() WHERE created_at IS NULL;
+
+ALTER TABLE urls
+    ALTER COLUMN created_at SET NOT NULL;
+
+CREATE INDEX idx_urls_created_at_encoded_key ON urls (created_at DESC, encoded_key DESC);
+
+-- Key prefix filter, the primary key can't serve LIKE outside the C collation
+CREATE INDEX idx_urls_encoded_key_pattern ON urls (encoded_key text_pattern_ops);
+
+-- Destination domain filter, the expression must stay identical to urlHostExpr
+CREATE INDEX idx_urls_long_url_host ON urls ((substring(long_url from '^[^:]+://(?:[^/?#@]*@)?([^/?#:]+)')));
+
+-- Substring search on the destination
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_urls_long_url_trgm ON urls USING gin (long_url gin_trgm_ops);
\ No newline at end of file
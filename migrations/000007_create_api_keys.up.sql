@@ -0,0 +1,11 @@
+CREATE TABLE api_keys
+(
+    id           TEXT PRIMARY KEY,
+    name         TEXT        NOT NULL,
+    key_prefix   TEXT        NOT NULL,
+    key_hash     TEXT        NOT NULL UNIQUE,
+    scopes       TEXT[]      NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL,
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ
+);
\ No newline at end of file
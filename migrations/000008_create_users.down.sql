@@ -0,0 +1,8 @@
+DROP INDEX idx_urls_owner_id_created_at;
+
+ALTER TABLE urls
+    DROP COLUMN owner_id;
+
+DROP TABLE sessions;
+
+DROP TABLE users;
\ No newline at end of file
@@ -0,0 +1,27 @@
+CREATE TABLE users
+(
+    id            TEXT PRIMARY KEY,
+    email         TEXT        NOT NULL UNIQUE,
+    password_hash TEXT        NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL
+);
+
+-- Only token hashes are stored, a refresh rotates both of them in place
+CREATE TABLE sessions
+(
+    id                 TEXT PRIMARY KEY,
+    user_id            TEXT        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    access_hash        TEXT        NOT NULL UNIQUE,
+    access_expires_at  TIMESTAMPTZ NOT NULL,
+    refresh_hash       TEXT        NOT NULL UNIQUE,
+    refresh_expires_at TIMESTAMPTZ NOT NULL,
+    created_at         TIMESTAMPTZ NOT NULL,
+    revoked_at         TIMESTAMPTZ
+);
+
+-- Links created with an api key have no owner
+ALTER TABLE urls
+    ADD COLUMN owner_id TEXT REFERENCES users (id);
+
+-- Listing the links of one owner, in the order of idx_urls_created_at_encoded_key
+CREATE INDEX idx_urls_owner_id_created_at ON urls (owner_id, created_at DESC, encoded_key DESC) WHERE owner_id IS NOT NULL;
\ No newline at end of file
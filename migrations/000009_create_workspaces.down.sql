@@ -0,0 +1,12 @@
+DROP INDEX idx_urls_workspace_id_created_at;
+
+ALTER TABLE urls
+    DROP COLUMN workspace_id;
+
+DROP TABLE workspace_invitations;
+
+DROP INDEX idx_workspace_members_user_id;
+
+DROP TABLE workspace_members;
+
+DROP TABLE workspaces;
\ No newline at end of file
@@ -0,0 +1,39 @@
+CREATE TABLE workspaces
+(
+    id         TEXT PRIMARY KEY,
+    name       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE workspace_members
+(
+    workspace_id TEXT        NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    user_id      TEXT        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role         TEXT        NOT NULL CHECK (role IN ('owner', 'admin', 'editor', 'viewer')),
+    joined_at    TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+-- Listing the workspaces of one user
+CREATE INDEX idx_workspace_members_user_id ON workspace_members (user_id);
+
+-- Only token hashes are stored, accepting an invitation uses it up
+CREATE TABLE workspace_invitations
+(
+    id           TEXT PRIMARY KEY,
+    workspace_id TEXT        NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    role         TEXT        NOT NULL CHECK (role IN ('owner', 'admin', 'editor', 'viewer')),
+    token_hash   TEXT        NOT NULL UNIQUE,
+    invited_by   TEXT        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at   TIMESTAMPTZ NOT NULL,
+    expires_at   TIMESTAMPTZ NOT NULL,
+    accepted_by  TEXT REFERENCES users (id) ON DELETE SET NULL,
+    accepted_at  TIMESTAMPTZ
+);
+
+-- Links created outside a workspace have none, they are deduplicated among themselves
+ALTER TABLE urls
+    ADD COLUMN workspace_id TEXT REFERENCES workspaces (id);
+
+-- Listing the links of one workspace, in the order of idx_urls_created_at_encoded_key
+CREATE INDEX idx_urls_workspace_id_created_at ON urls (workspace_id, created_at DESC, encoded_key DESC) WHERE workspace_id IS NOT NULL;
\ No newline at end of file
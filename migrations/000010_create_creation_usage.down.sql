@@ -0,0 +1 @@
+DROP TABLE creation_usage;
\ No newline at end of file
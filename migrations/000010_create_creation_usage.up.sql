@@ -0,0 +1,7 @@
+CREATE TABLE creation_usage
+(
+    subject TEXT    NOT NULL,
+    day     DATE    NOT NULL,
+    created INTEGER NOT NULL,
+    PRIMARY KEY (subject, day)
+);
\ No newline at end of file
@@ -0,0 +1,807 @@
+// Package client calls the url shortener HTTP API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultRetries    = 2
+	DefaultMinBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff = 2 * time.Second
+)
+
+type ShortenRequest struct {
+	LongURL string
+	// Alias is a custom key, 3 to 64 letters, digits, "-" or "_"
+	Alias string
+	// ExpiresAt and TTL are mutually exclusive, links without either never expire
+	ExpiresAt *time.Time
+	TTL       time.Duration
+}
+
+type ShortenResponse struct {
+	ShortURL   string
+	EncodedKey string
+	// Created is false when an existing link was handed back
+	Created bool
+	// ManagementToken is only set when a new link was created, it is never shown again
+	ManagementToken string
+}
+
+// StatsQuery narrows link stats, zero fields leave the server defaults: daily
+// buckets over the last 30 days.
+type StatsQuery struct {
+	// Interval is "hour" or "day"
+	Interval string
+	From     time.Time
+	To       time.Time
+}
+
+type Stats struct {
+	EncodedKey  string        `json:"encodedKey"`
+	TotalClicks int64         `json:"totalClicks"`
+	Interval    string        `json:"interval"`
+	Series      []StatsBucket `json:"series"`
+}
+
+type StatsBucket struct {
+	Start  time.Time `json:"start"`
+	Clicks int64     `json:"clicks"`
+}
+
+// APIKey describes a key to the API. Key is the secret, it is only set on the
+// response to MintAPIKey.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	Key        string     `json:"key,omitempty"`
+}
+
+type MintAPIKeyRequest struct {
+	Name string `json:"name"`
+	// Scopes are "create", "read" or "admin", admin holds the other two
+	Scopes []string `json:"scopes"`
+}
+
+// User is an account on the server, as returned by Register.
+type User struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Session holds the tokens of a signed-in user. Pass AccessToken to WithAPIKey until
+// ExpiresAt, then swap RefreshToken for new ones with RefreshSession.
+type Session struct {
+	AccessToken      string    `json:"accessToken"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	RefreshToken     string    `json:"refreshToken"`
+	RefreshExpiresAt time.Time `json:"refreshExpiresAt"`
+}
+
+// Workspace is a workspace as seen by one of its members, Role is theirs: "viewer",
+// "editor", "admin" or "owner".
+type Workspace struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type Member struct {
+	WorkspaceID string    `json:"workspaceId"`
+	UserID      string    `json:"userId"`
+	Role        string    `json:"role"`
+	JoinedAt    time.Time `json:"joinedAt"`
+}
+
+// Invitation lets whoever holds Token join a workspace with Role, Token is never
+// shown again.
+type Invitation struct {
+	ID          string    `json:"id"`
+	WorkspaceID string    `json:"workspaceId"`
+	Role        string    `json:"role"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	Token       string    `json:"token"`
+}
+
+type Client struct {
+	baseURL     *url.URL
+	httpClient  *http.Client
+	apiKey      string
+	workspaceID string
+	retries     int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+}
+
+type Option func(*Client)
+
+// WithHTTPClient sends requests through httpClient instead of http.DefaultClient.
+// Resolve uses a copy of it that doesn't follow redirects.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAPIKey authenticates every request with key, an API key or the access token of
+// a session. Resolve, Health and the session calls work without one.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithWorkspace makes every request act in the workspace of id: new links belong to
+// it and listings show its links. It needs the access token of a member's session.
+func WithWorkspace(id string) Option {
+	return func(c *Client) {
+		c.workspaceID = id
+	}
+}
+
+// WithRetries sets how many times idempotent calls are retried after a network
+// error, a 429 or a 502, 503 or 504. Zero disables retries.
+func WithRetries(retries int) Option {
+	return func(c *Client) {
+		c.retries = retries
+	}
+}
+
+// WithBackoff sets the bounds of the exponential backoff between retries, each
+// wait is picked at random up to the current bound.
+func WithBackoff(minBackoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.minBackoff = minBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// New returns a client for the server at baseURL, such as "http://localhost:8080".
+func New(baseURL string, options ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base url: %q", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: http.DefaultClient,
+		retries:    DefaultRetries,
+		minBackoff: DefaultMinBackoff,
+		maxBackoff: DefaultMaxBackoff,
+	}
+	for _, option := range options {
+		option(c)
+	}
+
+	if c.httpClient == nil || c.retries < 0 || c.minBackoff < 0 || c.maxBackoff < c.minBackoff {
+		return nil, errors.New("invalid client options")
+	}
+
+	return c, nil
+}
+
+// Shorten is never retried, a retry after a lost response could create a second link.
+func (c *Client) Shorten(ctx context.Context, req ShortenRequest) (*ShortenResponse, error) {
+	body := shortenBody{LongURL: req.LongURL, Alias: req.Alias, ExpiresAt: req.ExpiresAt}
+	if req.TTL != 0 {
+		body.TTL = req.TTL.String()
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	resp, err := c.do(ctx, c.httpClient, http.MethodPost, "/api/v1/shorten", nil, payload, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var shortened shortenResult
+	err = json.NewDecoder(resp.Body).Decode(&shortened)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedResponse, err)
+	}
+
+	return &ShortenResponse{
+		ShortURL:        shortened.ShortURL,
+		EncodedKey:      path.Base(shortened.ShortURL),
+		Created:         resp.StatusCode == http.StatusCreated,
+		ManagementToken: shortened.ManagementToken,
+	}, nil
+}
+
+// Resolve returns the destination of a short link without following the redirect.
+// It counts as a click, like any visit to the short link.
+func (c *Client) Resolve(ctx context.Context, encodedKey string) (*url.URL, error) {
+	noRedirects := *c.httpClient
+	noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	resp, err := c.do(ctx, &noRedirects, http.MethodGet, "/api/v1/"+url.PathEscape(encodedKey), nil, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusFound {
+		return nil, apiError(resp)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedResponse, err)
+	}
+
+	return location, nil
+}
+
+// Stats counts clicks on a link over time.
+func (c *Client) Stats(ctx context.Context, encodedKey string, query StatsQuery) (*Stats, error) {
+	params := url.Values{}
+	if query.Interval != "" {
+		params.Set("interval", query.Interval)
+	}
+	if !query.From.IsZero() {
+		params.Set("from", query.From.Format(time.RFC3339))
+	}
+	if !query.To.IsZero() {
+		params.Set("to", query.To.Format(time.RFC3339))
+	}
+
+	resp, err := c.do(ctx, c.httpClient, http.MethodGet, "/api/v1/links/"+url.PathEscape(encodedKey)+"/stats", params, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var stats Stats
+	err = json.NewDecoder(resp.Body).Decode(&stats)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedResponse, err)
+	}
+
+	return &stats, nil
+}
+
+// MintAPIKey needs an admin key. It is never retried, a retry after a lost response
+// could mint a second key.
+func (c *Client) MintAPIKey(ctx context.Context, req MintAPIKeyRequest) (*APIKey, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	resp, err := c.do(ctx, c.httpClient, http.MethodPost, "/api/v1/admin/keys", nil, payload, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, apiError(resp)
+	}
+
+	var key APIKey
+	err = json.NewDecoder(resp.Body).Decode(&key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedResponse, err)
+	}
+
+	return &key, nil
+}
+
+// ListAPIKeys needs an admin key, revoked keys are listed too.
+func (c *Client) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	resp, err := c.do(ctx, c.httpClient, http.MethodGet, "/api/v1/admin/keys", nil, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var keys listAPIKeysResult
+	err = json.NewDecoder(resp.Body).Decode(&keys)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedResponse, err)
+	}
+
+	return keys.Keys, nil
+}
+
+// RevokeAPIKey needs an admin key. Revoking a key twice is not an error.
+func (c *Client) RevokeAPIKey(ctx context.Context, id string) error {
+	resp, err := c.do(ctx, c.httpClient, http.MethodDelete, "/api/v1/admin/keys/"+url.PathEscape(id), nil, nil, true)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return apiError(resp)
+	}
+
+	return nil
+}
+
+// Register creates an account. Unless the server lets anyone sign up it needs an admin
+// key, anyone else gets ErrForbidden. It is never retried, a retry after a lost
+// response would be answered with ErrEmailTaken.
+func (c *Client) Register(ctx context.Context, email string, password string) (*User, error) {
+	payload, err := json.Marshal(credentialsBody{Email: email, Password: password})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	resp, err := c.do(ctx, c.httpClient, http.MethodPost, "/api/v1/auth/register", nil, payload, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, apiError(resp)
+	}
+
+	var user User
+	err = json.NewDecoder(resp.Body).Decode(&user)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedResponse, err)
+	}
+
+	return &user, nil
+}
+
+// Login starts a session. It is never retried, a retry after a lost response would
+// start a second one.
+func (c *Client) Login(ctx context.Context, email string, password string) (*Session, error) {
+	payload, err := json.Marshal(credentialsBody{Email: email, Password: password})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	return c.session(ctx, "/api/v1/auth/login", payload)
+}
+
+// RefreshSession swaps refreshToken for new session tokens. It is never retried, the
+// server only honours a refresh token once.
+func (c *Client) RefreshSession(ctx context.Context, refreshToken string) (*Session, error) {
+	payload, err := json.Marshal(refreshBody{RefreshToken: refreshToken})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	return c.session(ctx, "/api/v1/auth/refresh", payload)
+}
+
+// Logout ends the session of refreshToken. Ending a session twice is not an error.
+func (c *Client) Logout(ctx context.Context, refreshToken string) error {
+	payload, err := json.Marshal(refreshBody{RefreshToken: refreshToken})
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	resp, err := c.do(ctx, c.httpClient, http.MethodPost, "/api/v1/auth/logout", nil, payload, true)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return apiError(resp)
+	}
+
+	return nil
+}
+
+// CreateWorkspace creates a workspace owned by the signed-in user. It is never
+// retried, a retry after a lost response would create a second one.
+func (c *Client) CreateWorkspace(ctx context.Context, name string) (*Workspace, error) {
+	payload, err := json.Marshal(workspaceBody{Name: name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	resp, err := c.do(ctx, c.httpClient, http.MethodPost, "/api/v1/workspaces", nil, payload, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, apiError(resp)
+	}
+
+	var workspace Workspace
+	err = json.NewDecoder(resp.Body).Decode(&workspace)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedResponse, err)
+	}
+
+	return &workspace, nil
+}
+
+// ListWorkspaces lists the workspaces the signed-in user is a member of.
+func (c *Client) ListWorkspaces(ctx context.Context) ([]Workspace, error) {
+	resp, err := c.do(ctx, c.httpClient, http.MethodGet, "/api/v1/workspaces", nil, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var workspaces listWorkspacesResult
+	err = json.NewDecoder(resp.Body).Decode(&workspaces)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedResponse, err)
+	}
+
+	return workspaces.Workspaces, nil
+}
+
+func (c *Client) ListMembers(ctx context.Context, workspaceID string) ([]Member, error) {
+	resp, err := c.do(ctx, c.httpClient, http.MethodGet, "/api/v1/workspaces/"+url.PathEscape(workspaceID)+"/members", nil, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var members listMembersResult
+	err = json.NewDecoder(resp.Body).Decode(&members)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedResponse, err)
+	}
+
+	return members.Members, nil
+}
+
+// UpdateMemberRole changes the role of a member, up to the caller's own role.
+func (c *Client) UpdateMemberRole(ctx context.Context, workspaceID string, userID string, role string) error {
+	payload, err := json.Marshal(roleBody{Role: role})
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	resp, err := c.do(ctx, c.httpClient, http.MethodPatch, "/api/v1/workspaces/"+url.PathEscape(workspaceID)+"/members/"+url.PathEscape(userID), nil, payload, true)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return apiError(resp)
+	}
+
+	return nil
+}
+
+// RemoveMember takes a member out of a workspace, members can remove themselves. It is
+// never retried, a retry after a lost response would be answered with ErrNotFound.
+func (c *Client) RemoveMember(ctx context.Context, workspaceID string, userID string) error {
+	resp, err := c.do(ctx, c.httpClient, http.MethodDelete, "/api/v1/workspaces/"+url.PathEscape(workspaceID)+"/members/"+url.PathEscape(userID), nil, nil, false)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return apiError(resp)
+	}
+
+	return nil
+}
+
+// Invite mints an invitation to join a workspace with role. It is never retried, a
+// retry after a lost response would mint a second one.
+func (c *Client) Invite(ctx context.Context, workspaceID string, role string) (*Invitation, error) {
+	payload, err := json.Marshal(roleBody{Role: role})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	resp, err := c.do(ctx, c.httpClient, http.MethodPost, "/api/v1/workspaces/"+url.PathEscape(workspaceID)+"/invitations", nil, payload, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, apiError(resp)
+	}
+
+	var invitation Invitation
+	err = json.NewDecoder(resp.Body).Decode(&invitation)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedResponse, err)
+	}
+
+	return &invitation, nil
+}
+
+// AcceptInvitation makes the signed-in user a member of the invitation's workspace. It
+// is never retried, a retry after a lost response would be answered with
+// ErrAlreadyMember.
+func (c *Client) AcceptInvitation(ctx context.Context, token string) (*Member, error) {
+	payload, err := json.Marshal(acceptInvitationBody{Token: token})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	resp, err := c.do(ctx, c.httpClient, http.MethodPost, "/api/v1/invitations/accept", nil, payload, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var member Member
+	err = json.NewDecoder(resp.Body).Decode(&member)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedResponse, err)
+	}
+
+	return &member, nil
+}
+
+// Health reports whether each storage backend of the server answers, by name.
+func (c *Client) Health(ctx context.Context) (map[string]bool, error) {
+	resp, err := c.do(ctx, c.httpClient, http.MethodGet, "/api/v1/health", nil, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var health map[string]bool
+	err = json.NewDecoder(resp.Body).Decode(&health)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedResponse, err)
+	}
+
+	return health, nil
+}
+
+func (c *Client) session(ctx context.Context, endpoint string, payload []byte) (*Session, error) {
+	resp, err := c.do(ctx, c.httpClient, http.MethodPost, endpoint, nil, payload, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var session Session
+	err = json.NewDecoder(resp.Body).Decode(&session)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedResponse, err)
+	}
+
+	return &session, nil
+}
+
+func (c *Client) do(ctx context.Context, httpClient *http.Client, method string, endpoint string, query url.Values, payload []byte, idempotent bool) (*http.Response, error) {
+	target := c.baseURL.JoinPath(endpoint)
+	target.RawQuery = query.Encode()
+
+	attempts := 1
+	if idempotent {
+		attempts += c.retries
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			err := sleep(ctx, c.backoff(attempt, lastErr))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("failed to build request: %w", err)
+		}
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Accept", "application/json")
+		if c.apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		}
+		if c.workspaceID != "" {
+			req.Header.Set("X-Workspace-ID", c.workspaceID)
+		}
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			lastErr = err
+			continue
+		}
+
+		if !retryable(resp.StatusCode) || attempt == attempts-1 {
+			return resp, nil
+		}
+
+		lastErr = &retryAfterError{after: retryAfter(resp)}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	return nil, fmt.Errorf("failed to %s %s after %d attempts: %w", method, endpoint, attempts, lastErr)
+}
+
+// backoff waits at random up to an exponentially growing bound, unless the server
+// said when to come back.
+func (c *Client) backoff(attempt int, lastErr error) time.Duration {
+	var retryAfterErr *retryAfterError
+	if errors.As(lastErr, &retryAfterErr) && retryAfterErr.after > 0 {
+		return min(retryAfterErr.after, c.maxBackoff)
+	}
+
+	bound := c.minBackoff << (attempt - 1)
+	if bound <= 0 || bound > c.maxBackoff {
+		bound = c.maxBackoff
+	}
+	if bound <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int64N(int64(bound) + 1))
+}
+
+type retryAfterError struct {
+	after time.Duration
+}
+
+func (e *retryAfterError) Error() string {
+	return "retryable response"
+}
+
+func retryable(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func apiError(resp *http.Response) error {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+
+	var body problem
+	if json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&body) == nil {
+		apiErr.Code = body.Code
+		apiErr.Message = body.Detail
+		if apiErr.Message == "" {
+			apiErr.Message = body.Title
+		}
+		apiErr.Fields = body.Errors
+	}
+
+	return apiErr
+}
+
+type shortenBody struct {
+	LongURL   string     `json:"longUrl"`
+	Alias     string     `json:"alias,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	TTL       string     `json:"ttl,omitempty"`
+}
+
+type shortenResult struct {
+	ShortURL        string `json:"shortUrl"`
+	ManagementToken string `json:"managementToken"`
+}
+
+type credentialsBody struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type refreshBody struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type listAPIKeysResult struct {
+	Keys []APIKey `json:"keys"`
+}
+
+type workspaceBody struct {
+	Name string `json:"name"`
+}
+
+type roleBody struct {
+	Role string `json:"role"`
+}
+
+type acceptInvitationBody struct {
+	Token string `json:"token"`
+}
+
+type listWorkspacesResult struct {
+	Workspaces []Workspace `json:"workspaces"`
+}
+
+type listMembersResult struct {
+	Members []Member `json:"members"`
+}
+
+// problem is the RFC 9457 problem details body the server answers errors with.
+type problem struct {
+	Title  string       `json:"title"`
+	Detail string       `json:"detail"`
+	Code   string       `json:"code"`
+	Errors []FieldError `json:"errors"`
+}
@@ -0,0 +1,548 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		options []Option
+		wantErr error
+	}{
+		{
+			name:    "when base url has no host",
+			baseURL: "localhost:8080",
+			wantErr: errors.New(`invalid base url: "localhost:8080"`),
+		},
+		{
+			name:    "when retries are negative",
+			baseURL: "http://localhost:8080",
+			options: []Option{WithRetries(-1)},
+			wantErr: errors.New("invalid client options"),
+		},
+		{
+			name:    "when backoff bounds are inverted",
+			baseURL: "http://localhost:8080",
+			options: []Option{WithBackoff(time.Second, time.Millisecond)},
+			wantErr: errors.New("invalid client options"),
+		},
+		{
+			name:    "when options are valid",
+			baseURL: "http://localhost:8080",
+			options: []Option{WithHTTPClient(&http.Client{}), WithRetries(0), WithBackoff(0, 0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.baseURL, tt.options...)
+
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestClient_Shorten(t *testing.T) {
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		req          ShortenRequest
+		status       int
+		responseBody string
+		expectedBody string
+		want         *ShortenResponse
+		wantErr      error
+	}{
+		{
+			name:         "when link is created",
+			req:          ShortenRequest{LongURL: "https://go.dev", Alias: "go-home", TTL: 72 * time.Hour},
+			status:       http.StatusCreated,
+			responseBody: `{"shortUrl":"http://localhost:8080/api/v1/go-home","managementToken":"a-management-token"}`,
+			expectedBody: `{"longUrl":"https://go.dev","alias":"go-home","ttl":"72h0m0s"}`,
+			want:         &ShortenResponse{ShortURL: "http://localhost:8080/api/v1/go-home", EncodedKey: "go-home", Created: true, ManagementToken: "a-management-token"},
+		},
+		{
+			name:         "when link already exists",
+			req:          ShortenRequest{LongURL: "https://go.dev", Alias: "go-home"},
+			status:       http.StatusOK,
+			responseBody: `{"shortUrl":"http://localhost:8080/api/v1/go-home"}`,
+			expectedBody: `{"longUrl":"https://go.dev","alias":"go-home"}`,
+			want:         &ShortenResponse{ShortURL: "http://localhost:8080/api/v1/go-home", EncodedKey: "go-home"},
+		},
+		{
+			name:         "when link expires at a given time",
+			req:          ShortenRequest{LongURL: "https://go.dev", ExpiresAt: &expiresAt},
+			status:       http.StatusCreated,
+			responseBody: `{"shortUrl":"http://localhost:8080/api/v1/abc1234"}`,
+			expectedBody: `{"longUrl":"https://go.dev","expiresAt":"2030-01-01T00:00:00Z"}`,
+			want:         &ShortenResponse{ShortURL: "http://localhost:8080/api/v1/abc1234", EncodedKey: "abc1234", Created: true},
+		},
+		{
+			name:         "when alias is taken",
+			req:          ShortenRequest{LongURL: "https://go.dev", Alias: "go-home"},
+			status:       http.StatusConflict,
+			responseBody: `{"title":"alias already taken","status":409,"code":"alias_taken"}`,
+			expectedBody: `{"longUrl":"https://go.dev","alias":"go-home"}`,
+			wantErr:      &APIError{StatusCode: http.StatusConflict, Code: "alias_taken", Message: "alias already taken"},
+		},
+		{
+			name:         "when destination breaks a policy",
+			req:          ShortenRequest{LongURL: "http://127.0.0.1"},
+			status:       http.StatusUnprocessableEntity,
+			responseBody: `{"title":"url points to a private network address","status":422,"code":"private_destination"}`,
+			expectedBody: `{"longUrl":"http://127.0.0.1"}`,
+			wantErr:      &APIError{StatusCode: http.StatusUnprocessableEntity, Code: "private_destination", Message: "url points to a private network address"},
+		},
+		{
+			name:         "when server is unavailable it is not retried",
+			req:          ShortenRequest{LongURL: "https://go.dev"},
+			status:       http.StatusServiceUnavailable,
+			expectedBody: `{"longUrl":"https://go.dev"}`,
+			wantErr:      &APIError{StatusCode: http.StatusServiceUnavailable},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				body, _ := io.ReadAll(r.Body)
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/api/v1/shorten", r.URL.Path)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				assert.Equal(t, tt.expectedBody, string(body))
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer srv.Close()
+
+			c, err := New(srv.URL, WithBackoff(0, 0))
+			assert.NoError(t, err)
+
+			got, err := c.Shorten(context.Background(), tt.req)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, int32(1), calls.Load())
+		})
+	}
+}
+
+func TestClient_Resolve(t *testing.T) {
+	tests := []struct {
+		name          string
+		statuses      []int
+		retries       int
+		want          *url.URL
+		wantErr       error
+		expectedCalls int32
+	}{
+		{
+			name:          "when link redirects it is not followed",
+			statuses:      []int{http.StatusFound},
+			want:          &url.URL{Scheme: "https", Host: "go.dev", Path: "/doc"},
+			expectedCalls: 1,
+		},
+		{
+			name:          "when link is not found",
+			statuses:      []int{http.StatusNotFound},
+			retries:       2,
+			wantErr:       &APIError{StatusCode: http.StatusNotFound, Code: "not_found", Message: "record not found"},
+			expectedCalls: 1,
+		},
+		{
+			name:          "when server recovers before retries run out",
+			statuses:      []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusFound},
+			retries:       2,
+			want:          &url.URL{Scheme: "https", Host: "go.dev", Path: "/doc"},
+			expectedCalls: 3,
+		},
+		{
+			name:          "when retries run out",
+			statuses:      []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			retries:       1,
+			wantErr:       &APIError{StatusCode: http.StatusServiceUnavailable, Code: "not_found", Message: "record not found"},
+			expectedCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				call := calls.Add(1)
+				assert.Equal(t, "/api/v1/abc1234", r.URL.Path)
+				status := tt.statuses[call-1]
+				if status == http.StatusFound {
+					http.Redirect(w, r, "https://go.dev/doc", status)
+					return
+				}
+				w.WriteHeader(status)
+				w.Write([]byte(`{"title":"record not found","status":404,"code":"not_found"}`))
+			}))
+			defer srv.Close()
+
+			c, err := New(srv.URL, WithRetries(tt.retries), WithBackoff(0, 0))
+			assert.NoError(t, err)
+
+			got, err := c.Resolve(context.Background(), "abc1234")
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.expectedCalls, calls.Load())
+		})
+	}
+}
+
+func TestClient_Resolve_NetworkErrorIsRetried(t *testing.T) {
+	var calls atomic.Int32
+	httpClient := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		return nil, errors.New("connection reset")
+	})}
+
+	c, err := New("http://localhost:8080", WithHTTPClient(httpClient), WithRetries(2), WithBackoff(0, 0))
+	assert.NoError(t, err)
+
+	_, err = c.Resolve(context.Background(), "abc1234")
+
+	assert.ErrorContains(t, err, "failed to GET /api/v1/abc1234 after 3 attempts")
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Nil(t, httpClient.CheckRedirect)
+}
+
+func TestClient_Resolve_StopsWaitingWhenContextIsDone(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithRetries(1), WithBackoff(time.Minute, time.Minute))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = c.Resolve(ctx, "abc1234")
+
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestClient_Stats(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		query         StatsQuery
+		status        int
+		responseBody  string
+		expectedQuery string
+		want          *Stats
+		wantErr       error
+	}{
+		{
+			name:          "when server defaults are used",
+			status:        http.StatusOK,
+			responseBody:  `{"encodedKey":"abc1234","totalClicks":0,"interval":"day","series":[]}`,
+			expectedQuery: "",
+			want:          &Stats{EncodedKey: "abc1234", Interval: "day", Series: []StatsBucket{}},
+		},
+		{
+			name:          "when query is narrowed",
+			query:         StatsQuery{Interval: "hour", From: from, To: from.Add(time.Hour)},
+			status:        http.StatusOK,
+			responseBody:  `{"encodedKey":"abc1234","totalClicks":2,"interval":"hour","series":[{"start":"2026-01-01T00:00:00Z","clicks":2}]}`,
+			expectedQuery: "from=2026-01-01T00%3A00%3A00Z&interval=hour&to=2026-01-01T01%3A00%3A00Z",
+			want:          &Stats{EncodedKey: "abc1234", TotalClicks: 2, Interval: "hour", Series: []StatsBucket{{Start: from, Clicks: 2}}},
+		},
+		{
+			name:          "when query is invalid",
+			query:         StatsQuery{Interval: "minute"},
+			status:        http.StatusBadRequest,
+			responseBody:  `{"title":"invalid request","status":400,"detail":"query parameter \"interval\": value is not one of the allowed values","code":"invalid_request","errors":[{"field":"interval","reason":"value is not one of the allowed values"}]}`,
+			expectedQuery: "interval=minute",
+			wantErr: &APIError{
+				StatusCode: http.StatusBadRequest,
+				Code:       "invalid_request",
+				Message:    `query parameter "interval": value is not one of the allowed values`,
+				Fields:     []FieldError{{Field: "interval", Reason: "value is not one of the allowed values"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/v1/links/abc1234/stats", r.URL.Path)
+				assert.Equal(t, tt.expectedQuery, r.URL.RawQuery)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer srv.Close()
+
+			c, err := New(srv.URL)
+			assert.NoError(t, err)
+
+			got, err := c.Stats(context.Background(), "abc1234", tt.query)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestClient_Health(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/health", r.URL.Path)
+		w.Write([]byte(`{"postgres":true}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL)
+	assert.NoError(t, err)
+
+	got, err := c.Health(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"postgres": true}, got)
+}
+
+func TestClient_APIKeys(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer usk_admin", r.Header.Get("Authorization"))
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/v1/admin/keys":
+			body, _ := io.ReadAll(r.Body)
+			assert.Equal(t, `{"name":"ci","scopes":["create"]}`, string(body))
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"a-key-id","name":"ci","prefix":"usk_aB3dE6gH","scopes":["create"],"createdAt":"2026-01-01T00:00:00Z","key":"usk_aB3dE6gH-secret"}`))
+		case "GET /api/v1/admin/keys":
+			w.Write([]byte(`{"keys":[{"id":"a-key-id","name":"ci","prefix":"usk_aB3dE6gH","scopes":["create"],"createdAt":"2026-01-01T00:00:00Z"}]}`))
+		case "DELETE /api/v1/admin/keys/a-key-id":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"title":"record not found","status":404,"code":"not_found"}`))
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("usk_admin"))
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	minted, err := c.MintAPIKey(ctx, MintAPIKeyRequest{Name: "ci", Scopes: []string{"create"}})
+	assert.NoError(t, err)
+	assert.Equal(t, &APIKey{ID: "a-key-id", Name: "ci", Prefix: "usk_aB3dE6gH", Scopes: []string{"create"}, CreatedAt: createdAt, Key: "usk_aB3dE6gH-secret"}, minted)
+
+	keys, err := c.ListAPIKeys(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []APIKey{{ID: "a-key-id", Name: "ci", Prefix: "usk_aB3dE6gH", Scopes: []string{"create"}, CreatedAt: createdAt}}, keys)
+
+	assert.NoError(t, c.RevokeAPIKey(ctx, "a-key-id"))
+	assert.ErrorIs(t, c.RevokeAPIKey(ctx, "b-key-id"), ErrNotFound)
+}
+
+func TestClient_Sessions(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiresAt := createdAt.Add(15 * time.Minute)
+	refreshExpiresAt := createdAt.Add(720 * time.Hour)
+	session := `"tokenType":"Bearer","expiresAt":"2026-01-01T00:15:00Z","refreshExpiresAt":"2026-01-31T00:00:00Z"`
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+		body, _ := io.ReadAll(r.Body)
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/v1/auth/register":
+			if string(body) == `{"email":"bo@example.com","password":"a-long-password"}` {
+				w.WriteHeader(http.StatusConflict)
+				w.Write([]byte(`{"title":"email is already registered","status":409,"code":"email_taken"}`))
+				return
+			}
+			assert.Equal(t, `{"email":"ana@example.com","password":"a-long-password"}`, string(body))
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"a-user-id","email":"ana@example.com","createdAt":"2026-01-01T00:00:00Z"}`))
+		case "POST /api/v1/auth/login":
+			assert.Equal(t, `{"email":"ana@example.com","password":"a-long-password"}`, string(body))
+			w.Write([]byte(`{"accessToken":"uss_access",` + session + `,"refreshToken":"usr_refresh"}`))
+		case "POST /api/v1/auth/refresh":
+			assert.Equal(t, `{"refreshToken":"usr_refresh"}`, string(body))
+			w.Write([]byte(`{"accessToken":"uss_new-access",` + session + `,"refreshToken":"usr_new-refresh"}`))
+		case "POST /api/v1/auth/logout":
+			assert.Equal(t, `{"refreshToken":"usr_new-refresh"}`, string(body))
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	user, err := c.Register(ctx, "ana@example.com", "a-long-password")
+	assert.NoError(t, err)
+	assert.Equal(t, &User{ID: "a-user-id", Email: "ana@example.com", CreatedAt: createdAt}, user)
+
+	_, err = c.Register(ctx, "bo@example.com", "a-long-password")
+	assert.ErrorIs(t, err, ErrEmailTaken)
+
+	session1, err := c.Login(ctx, "ana@example.com", "a-long-password")
+	assert.NoError(t, err)
+	assert.Equal(t, &Session{AccessToken: "uss_access", ExpiresAt: expiresAt, RefreshToken: "usr_refresh", RefreshExpiresAt: refreshExpiresAt}, session1)
+
+	session2, err := c.RefreshSession(ctx, session1.RefreshToken)
+	assert.NoError(t, err)
+	assert.Equal(t, &Session{AccessToken: "uss_new-access", ExpiresAt: expiresAt, RefreshToken: "usr_new-refresh", RefreshExpiresAt: refreshExpiresAt}, session2)
+
+	assert.NoError(t, c.Logout(ctx, session2.RefreshToken))
+}
+
+func TestClient_Workspaces(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer uss_access", r.Header.Get("Authorization"))
+		assert.Equal(t, "a-workspace-id", r.Header.Get("X-Workspace-ID"))
+		body, _ := io.ReadAll(r.Body)
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/v1/workspaces":
+			assert.Equal(t, `{"name":"Marketing"}`, string(body))
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"a-workspace-id","name":"Marketing","role":"owner","createdAt":"2026-01-01T00:00:00Z"}`))
+		case "GET /api/v1/workspaces":
+			w.Write([]byte(`{"workspaces":[{"id":"a-workspace-id","name":"Marketing","role":"owner","createdAt":"2026-01-01T00:00:00Z"}]}`))
+		case "POST /api/v1/workspaces/a-workspace-id/invitations":
+			assert.Equal(t, `{"role":"editor"}`, string(body))
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"an-invitation-id","workspaceId":"a-workspace-id","role":"editor","expiresAt":"2026-01-08T00:00:00Z","token":"usi_a-token"}`))
+		case "POST /api/v1/invitations/accept":
+			assert.Equal(t, `{"token":"usi_a-token"}`, string(body))
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"title":"already a member of the workspace","status":409,"code":"already_member"}`))
+		case "GET /api/v1/workspaces/a-workspace-id/members":
+			w.Write([]byte(`{"members":[{"workspaceId":"a-workspace-id","userId":"a-user-id","role":"owner","joinedAt":"2026-01-01T00:00:00Z"}]}`))
+		case "PATCH /api/v1/workspaces/a-workspace-id/members/a-user-id":
+			assert.Equal(t, `{"role":"admin"}`, string(body))
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"title":"a workspace needs at least one owner","status":409,"code":"last_owner"}`))
+		case "DELETE /api/v1/workspaces/a-workspace-id/members/b-user-id":
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("uss_access"), WithWorkspace("a-workspace-id"))
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	workspace, err := c.CreateWorkspace(ctx, "Marketing")
+	assert.NoError(t, err)
+	assert.Equal(t, &Workspace{ID: "a-workspace-id", Name: "Marketing", Role: "owner", CreatedAt: createdAt}, workspace)
+
+	workspaces, err := c.ListWorkspaces(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []Workspace{*workspace}, workspaces)
+
+	invitation, err := c.Invite(ctx, "a-workspace-id", "editor")
+	assert.NoError(t, err)
+	assert.Equal(t, &Invitation{ID: "an-invitation-id", WorkspaceID: "a-workspace-id", Role: "editor", ExpiresAt: createdAt.Add(7 * 24 * time.Hour), Token: "usi_a-token"}, invitation)
+
+	_, err = c.AcceptInvitation(ctx, invitation.Token)
+	assert.ErrorIs(t, err, ErrAlreadyMember)
+
+	members, err := c.ListMembers(ctx, "a-workspace-id")
+	assert.NoError(t, err)
+	assert.Equal(t, []Member{{WorkspaceID: "a-workspace-id", UserID: "a-user-id", Role: "owner", JoinedAt: createdAt}}, members)
+
+	assert.ErrorIs(t, c.UpdateMemberRole(ctx, "a-workspace-id", "a-user-id", "admin"), ErrLastOwner)
+	assert.NoError(t, c.RemoveMember(ctx, "a-workspace-id", "b-user-id"))
+}
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *APIError
+		sentinel error
+		message  string
+	}{
+		{
+			name:     "bad request",
+			err:      &APIError{StatusCode: http.StatusBadRequest, Message: "invalid body"},
+			sentinel: ErrBadRequest,
+			message:  "bad request: invalid body",
+		},
+		{
+			name:     "unauthorized",
+			err:      &APIError{StatusCode: http.StatusUnauthorized},
+			sentinel: ErrUnauthorized,
+			message:  "unauthorized: status 401",
+		},
+		{
+			name:     "forbidden",
+			err:      &APIError{StatusCode: http.StatusForbidden, Code: "forbidden", Message: "api key needs the admin scope"},
+			sentinel: ErrForbidden,
+			message:  "forbidden: api key needs the admin scope",
+		},
+		{
+			name:     "email taken",
+			err:      &APIError{StatusCode: http.StatusConflict, Code: "email_taken"},
+			sentinel: ErrEmailTaken,
+			message:  "email already registered: status 409",
+		},
+		{
+			name:     "gone",
+			err:      &APIError{StatusCode: http.StatusGone, Message: "link expired"},
+			sentinel: ErrGone,
+			message:  "link expired or disabled: link expired",
+		},
+		{
+			name:     "rate limited",
+			err:      &APIError{StatusCode: http.StatusTooManyRequests},
+			sentinel: ErrRateLimited,
+			message:  "rate limited: status 429",
+		},
+		{
+			name:     "quota exceeded",
+			err:      &APIError{StatusCode: http.StatusTooManyRequests, Code: "quota_exceeded", Message: "1000 of 1000 links created today"},
+			sentinel: ErrQuotaExceeded,
+			message:  "creation quota exceeded: 1000 of 1000 links created today",
+		},
+		{
+			name:     "server error",
+			err:      &APIError{StatusCode: http.StatusInternalServerError, Message: "unknown database error"},
+			sentinel: ErrServer,
+			message:  "server error: unknown database error",
+		},
+		{
+			name:     "unexpected status",
+			err:      &APIError{StatusCode: http.StatusTeapot},
+			sentinel: ErrUnexpectedResponse,
+			message:  "unexpected response: status 418",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.err, tt.sentinel)
+			assert.Equal(t, tt.message, tt.err.Error())
+		})
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
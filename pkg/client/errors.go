@@ -0,0 +1,83 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	ErrBadRequest         = errors.New("bad request")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrForbidden          = errors.New("forbidden")
+	ErrNotFound           = errors.New("link not found")
+	ErrAliasTaken         = errors.New("alias already taken")
+	ErrEmailTaken         = errors.New("email already registered")
+	ErrAlreadyMember      = errors.New("already a workspace member")
+	ErrLastOwner          = errors.New("workspace needs an owner")
+	ErrGone               = errors.New("link expired or disabled")
+	ErrPolicyViolation    = errors.New("destination not allowed")
+	ErrRateLimited        = errors.New("rate limited")
+	ErrQuotaExceeded      = errors.New("creation quota exceeded")
+	ErrServer             = errors.New("server error")
+	ErrUnexpectedResponse = errors.New("unexpected response")
+)
+
+// APIError is an error answered by the server as problem details. It unwraps to the
+// sentinel error of its status code, so callers can use errors.Is and reach for
+// errors.As only when they need the server's code or message.
+type APIError struct {
+	StatusCode int
+	// Code is the server's stable error code, such as alias_taken or private_destination
+	Code string
+	// Message is the problem's detail, or its title when the server gave no detail
+	Message string
+	// Fields lists the request fields at fault, if the server named any
+	Fields []FieldError
+}
+
+// FieldError is a request field the server rejected and why.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s: status %d", e.Unwrap(), e.StatusCode)
+	}
+	return fmt.Sprintf("%s: %s", e.Unwrap(), e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusBadRequest:
+		return ErrBadRequest
+	case e.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusForbidden:
+		return ErrForbidden
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusConflict && e.Code == "email_taken":
+		return ErrEmailTaken
+	case e.StatusCode == http.StatusConflict && e.Code == "already_member":
+		return ErrAlreadyMember
+	case e.StatusCode == http.StatusConflict && e.Code == "last_owner":
+		return ErrLastOwner
+	case e.StatusCode == http.StatusConflict:
+		return ErrAliasTaken
+	case e.StatusCode == http.StatusGone:
+		return ErrGone
+	case e.StatusCode == http.StatusUnprocessableEntity:
+		return ErrPolicyViolation
+	case e.StatusCode == http.StatusTooManyRequests && e.Code == "quota_exceeded":
+		return ErrQuotaExceeded
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrServer
+	default:
+		return ErrUnexpectedResponse
+	}
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ggoulart/url-shortener/pkg/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyController_Scopes(t *testing.T) {
+	readKey := mintAPIKey(t, "read")
+
+	tests := []struct {
+		name        string
+		apiKey      string
+		expectedErr error
+	}{
+		{
+			name:        "when no key is given",
+			expectedErr: client.ErrUnauthorized,
+		},
+		{
+			name:        "when key is unknown",
+			apiKey:      "usk_unknown",
+			expectedErr: client.ErrUnauthorized,
+		},
+		{
+			name:        "when key lacks the create scope",
+			apiKey:      readKey.Key,
+			expectedErr: client.ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := client.New(baseURL, client.WithAPIKey(tt.apiKey))
+			assert.NoError(t, err)
+
+			_, err = c.Shorten(context.Background(), client.ShortenRequest{LongURL: "https://example.com"})
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestAPIKeyController_Revoke(t *testing.T) {
+	key := mintAPIKey(t, "create", "read")
+	c, err := client.New(baseURL, client.WithAPIKey(key.Key))
+	assert.NoError(t, err)
+
+	shortened, err := c.Shorten(context.Background(), client.ShortenRequest{LongURL: "https://example.com"})
+	assert.NoError(t, err)
+
+	err = newAdminClient(t).RevokeAPIKey(context.Background(), key.ID)
+	assert.NoError(t, err)
+
+	_, err = c.Shorten(context.Background(), client.ShortenRequest{LongURL: "https://example.com"})
+	assert.ErrorIs(t, err, client.ErrUnauthorized)
+
+	anonymous, err := client.New(baseURL)
+	assert.NoError(t, err)
+	_, err = anonymous.Resolve(context.Background(), shortened.EncodedKey)
+	assert.NoError(t, err, "redirects stay public")
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/ggoulart/url-shortener/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthController_Session(t *testing.T) {
+	ctx := context.Background()
+	anonymous, err := client.New(baseURL)
+	assert.NoError(t, err)
+
+	email := gofakeit.UUID() + "@example.com"
+	_, err = anonymous.Register(ctx, email, "correct horse battery")
+	assert.ErrorIs(t, err, client.ErrForbidden, "users are registered with an admin key")
+
+	admin := newAdminClient(t)
+	_, err = admin.Register(ctx, email, "correct horse battery")
+	assert.NoError(t, err)
+
+	_, err = admin.Register(ctx, email, "correct horse battery")
+	assert.ErrorIs(t, err, client.ErrEmailTaken)
+
+	_, err = anonymous.Login(ctx, email, "wrong horse battery")
+	assert.ErrorIs(t, err, client.ErrUnauthorized)
+
+	session, err := anonymous.Login(ctx, email, "correct horse battery")
+	require.NoError(t, err)
+
+	signedIn, err := client.New(baseURL, client.WithAPIKey(session.AccessToken))
+	assert.NoError(t, err)
+	shortened, err := signedIn.Shorten(ctx, client.ShortenRequest{LongURL: "https://example.com/" + gofakeit.UUID()})
+	assert.NoError(t, err)
+	assert.Empty(t, shortened.ManagementToken, "links of a user are managed by signing in")
+
+	refreshed, err := anonymous.RefreshSession(ctx, session.RefreshToken)
+	require.NoError(t, err)
+
+	_, err = anonymous.RefreshSession(ctx, session.RefreshToken)
+	assert.ErrorIs(t, err, client.ErrUnauthorized, "refresh tokens are only honoured once")
+
+	err = anonymous.Logout(ctx, refreshed.RefreshToken)
+	assert.NoError(t, err)
+
+	signedOut, err := client.New(baseURL, client.WithAPIKey(refreshed.AccessToken))
+	assert.NoError(t, err)
+	_, err = signedOut.Shorten(ctx, client.ShortenRequest{LongURL: "https://example.com"})
+	assert.ErrorIs(t, err, client.ErrUnauthorized)
+}
@@ -1,8 +1,7 @@
 package controller
 
 import (
-	"io"
-	"net/http"
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,27 +9,22 @@ import (
 
 func TestHealthController_Health(t *testing.T) {
 	tests := []struct {
-		name                 string
-		expectedStatusCode   int
-		expectedResponseBody string
+		name string
 	}{
 		{
-			name:                 "when health service is successful",
-			expectedStatusCode:   http.StatusOK,
-			expectedResponseBody: `{"postgres":true}`,
+			name: "when health service is successful",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			resp, err := http.Get("http://localhost:8080/api/v1/health")
+			health, err := newClient(t).Health(context.Background())
 			assert.NoError(t, err)
 
-			defer resp.Body.Close()
-			body, err := io.ReadAll(resp.Body)
-			assert.NoError(t, err)
-
-			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
-			assert.Equal(t, tt.expectedResponseBody, string(body))
+			// the storage driver decides which dependency is reported, postgres or memory
+			assert.Len(t, health, 1)
+			for _, healthy := range health {
+				assert.True(t, healthy)
+			}
 		})
 	}
 }
@@ -1,83 +1,285 @@
 package controller
 
 import (
-	"fmt"
-	"io"
+	"context"
 	"net/http"
-	"strings"
+	"os"
+	"regexp"
 	"testing"
+	"time"
 
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/ggoulart/url-shortener/pkg/client"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+const baseURL = "http://localhost:8080"
+
 func TestShortenerController_ShortURL(t *testing.T) {
 	tests := []struct {
-		name                 string
-		longUrl              string
-		expectedStatusCode   int
-		expectedResponseBody string
+		name             string
+		longUrl          string
+		expectedShortURL *regexp.Regexp
+		expectedErr      error
 	}{
 		{
-			name:                 "create short url",
-			longUrl:              "https://dev.to/techschoolguru/load-config-from-file-environment-variables-in-golang-with-viper-2j2d",
-			expectedStatusCode:   http.StatusCreated,
-			expectedResponseBody: `{"shortUrl":"http://localhost:8080/api/v1/NGVmMjk"}`,
+			name:             "create short url",
+			longUrl:          "https://dev.to/techschoolguru/load-config-from-file-environment-variables-in-golang-with-viper-2j2d",
+			expectedShortURL: regexp.MustCompile(`^http://localhost:8080/api/v1/[0-9A-Za-z]{7,12}$`),
 		},
 		{
-			name:                 "invalid body",
-			longUrl:              "\")",
-			expectedStatusCode:   http.StatusBadRequest,
-			expectedResponseBody: `{"error":"invalid body"}`,
+			name:        "invalid long url",
+			longUrl:     "\")",
+			expectedErr: client.ErrBadRequest,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			client := &http.Client{}
+			c := newClient(t)
+
+			got, err := c.Shorten(context.Background(), client.ShortenRequest{LongURL: tt.longUrl})
 
-			reqBody := io.Reader(strings.NewReader(fmt.Sprintf(`{"longUrl": "%s"}`, tt.longUrl)))
-			resp, err := client.Post("http://localhost:8080/api/v1/shorten", "application/json", reqBody)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
 			assert.NoError(t, err)
+			assert.Regexp(t, tt.expectedShortURL, got.ShortURL)
+		})
+	}
+}
+
+func TestShortenerController_RetrieveURL(t *testing.T) {
+	c := newClient(t)
+	shortened, err := c.Shorten(context.Background(), client.ShortenRequest{LongURL: "https://dev.to/techschoolguru/load-config-from-file-environment-variables-in-golang-with-viper-2j2d"})
+	assert.NoError(t, err)
 
-			respBody, _ := io.ReadAll(resp.Body)
+	tests := []struct {
+		name             string
+		encodedKey       string
+		expectedLocation string
+		expectedErr      error
+	}{
+		{
+			name:             "when url is found",
+			encodedKey:       shortened.EncodedKey,
+			expectedLocation: "https://dev.to/techschoolguru/load-config-from-file-environment-variables-in-golang-with-viper-2j2d",
+		},
+		{
+			name:        "when url is not found",
+			encodedKey:  "312jnCa",
+			expectedErr: client.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Resolve(context.Background(), tt.encodedKey)
 
-			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
-			assert.Equal(t, tt.expectedResponseBody, string(respBody))
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedLocation, got.String())
 		})
 	}
 }
 
-func TestShortenerController_RetrieveURL(t *testing.T) {
+// newClient returns a client holding a create and read key, minted with the
+// bootstrap key the server under test was started with.
+func newClient(t *testing.T) *client.Client {
+	key := mintAPIKey(t, "create", "read")
+
+	c, err := client.New(baseURL, client.WithAPIKey(key.Key))
+	assert.NoError(t, err)
+
+	return c
+}
+
+func newAdminClient(t *testing.T) *client.Client {
+	bootstrapKey := os.Getenv("AUTH_BOOTSTRAP_KEY")
+	if bootstrapKey == "" {
+		t.Fatal("AUTH_BOOTSTRAP_KEY must be set to the bootstrap key of the server under test")
+	}
+
+	c, err := client.New(baseURL, client.WithAPIKey(bootstrapKey))
+	assert.NoError(t, err)
+
+	return c
+}
+
+func mintAPIKey(t *testing.T, scopes ...string) *client.APIKey {
+	key, err := newAdminClient(t).MintAPIKey(context.Background(), client.MintAPIKeyRequest{Name: t.Name(), Scopes: scopes})
+	if err != nil {
+		t.Fatalf("failed to mint api key: %v", err)
+	}
+
+	return key
+}
+
+func TestShortenerController_RateLimited(t *testing.T) {
+	ctx := context.Background()
+	c, err := client.New(baseURL, client.WithAPIKey(signIn(t)))
+	assert.NoError(t, err)
+
+	// the default burst is far below this, and every user has a bucket of their own
+	for i := 0; i < 200; i++ {
+		_, err = c.Shorten(ctx, client.ShortenRequest{LongURL: "https://example.com/" + gofakeit.UUID()})
+		if err != nil {
+			break
+		}
+	}
+
+	assert.ErrorIs(t, err, client.ErrRateLimited)
+	var apiErr *client.APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, "rate_limited", apiErr.Code)
+	}
+}
+
+func TestShortenerController_ExpiringLink(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t)
+	longURL := "https://example.com/" + gofakeit.UUID()
+
+	permanent, err := c.Shorten(ctx, client.ShortenRequest{LongURL: longURL})
+	assert.NoError(t, err)
+
+	expiring, err := c.Shorten(ctx, client.ShortenRequest{LongURL: longURL, TTL: time.Second})
+	assert.NoError(t, err, "a link with an expiry never conflicts with the url's permanent link")
+	assert.NotEqual(t, permanent.EncodedKey, expiring.EncodedKey)
+
+	time.Sleep(1100 * time.Millisecond)
+
+	_, err = c.Resolve(ctx, expiring.EncodedKey)
+	assert.ErrorIs(t, err, client.ErrGone, "an expired link answers 410 until the cleanup job removes it")
+
+	got, err := c.Resolve(ctx, permanent.EncodedKey)
+	assert.NoError(t, err)
+	assert.Equal(t, longURL, got.String())
+}
+
+func TestShortenerController_PermanentAfterExpiringLink(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t)
+	longURL := "https://example.com/" + gofakeit.UUID()
+
+	expiring, err := c.Shorten(ctx, client.ShortenRequest{LongURL: longURL, TTL: time.Hour})
+	require.NoError(t, err)
+
+	permanent, err := c.Shorten(ctx, client.ShortenRequest{LongURL: longURL})
+	require.NoError(t, err)
+	assert.True(t, permanent.Created, "an expiring link is never handed out as the url's permanent one")
+	assert.NotEqual(t, expiring.EncodedKey, permanent.EncodedKey)
+}
+
+// TestShortenerController_DeleteLink pins down that the management token proves the
+// link is the caller's on top of an API key with the create scope, not instead of one.
+func TestShortenerController_DeleteLink(t *testing.T) {
+	ctx := context.Background()
+	shortened, err := newClient(t).Shorten(ctx, client.ShortenRequest{LongURL: "https://example.com/" + gofakeit.UUID()})
+	require.NoError(t, err)
+	require.NotEmpty(t, shortened.ManagementToken)
+
 	tests := []struct {
-		name               string
-		shortURL           string
-		expectedStatusCode int
-		expectedHeaders    http.Header
+		name       string
+		apiKey     string
+		token      string
+		wantStatus int
 	}{
 		{
-			name:               "when url is found",
-			shortURL:           "http://localhost:8080/api/v1/NGVmMjk",
-			expectedStatusCode: http.StatusFound,
-			expectedHeaders:    http.Header{"Location": []string{"https://dev.to/techschoolguru/load-config-from-file-environment-variables-in-golang-with-viper-2j2d"}},
+			name:       "when only the management token is given",
+			token:      shortened.ManagementToken,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "when the api key lacks the create scope",
+			apiKey:     mintAPIKey(t, "read").Key,
+			token:      shortened.ManagementToken,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "when the management token is wrong",
+			apiKey:     mintAPIKey(t, "create").Key,
+			token:      "wrong-token",
+			wantStatus: http.StatusUnauthorized,
 		},
 		{
-			name:               "when url is not found",
-			shortURL:           "http://localhost:8080/api/v1/312jnCa",
-			expectedStatusCode: http.StatusNotFound,
+			name:       "when an api key with the create scope brings the management token",
+			apiKey:     mintAPIKey(t, "create").Key,
+			token:      shortened.ManagementToken,
+			wantStatus: http.StatusNoContent,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			client := &http.Client{
-				CheckRedirect: func(req *http.Request, via []*http.Request) error {
-					return http.ErrUseLastResponse
-				},
+			req, err := http.NewRequestWithContext(ctx, http.MethodDelete, baseURL+"/api/v1/links/"+shortened.EncodedKey, nil)
+			require.NoError(t, err)
+			req.Header.Set("X-Management-Token", tt.token)
+			if tt.apiKey != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.apiKey)
 			}
 
-			resp, err := client.Get(tt.shortURL)
-			assert.NoError(t, err)
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
 
-			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
-			assert.Equal(t, tt.expectedHeaders.Get("Location"), resp.Header.Get("Location"))
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
 		})
 	}
+
+	_, err = newClient(t).Resolve(ctx, shortened.EncodedKey)
+	assert.ErrorIs(t, err, client.ErrNotFound)
+}
+
+func TestShortenerController_SeparateLinksPerUser(t *testing.T) {
+	ctx := context.Background()
+	longURL := "https://example.com/" + gofakeit.UUID()
+
+	ada, err := client.New(baseURL, client.WithAPIKey(signIn(t)))
+	require.NoError(t, err)
+	grace, err := client.New(baseURL, client.WithAPIKey(signIn(t)))
+	require.NoError(t, err)
+
+	adaLink, err := ada.Shorten(ctx, client.ShortenRequest{LongURL: longURL})
+	require.NoError(t, err)
+	adaAgain, err := ada.Shorten(ctx, client.ShortenRequest{LongURL: longURL})
+	require.NoError(t, err)
+	assert.Equal(t, adaLink.EncodedKey, adaAgain.EncodedKey, "a user gets their own link back")
+
+	graceLink, err := grace.Shorten(ctx, client.ShortenRequest{LongURL: longURL})
+	require.NoError(t, err)
+	assert.NotEqual(t, adaLink.EncodedKey, graceLink.EncodedKey, "a user is never handed a link they can't manage")
+
+	anonymousLink, err := newClient(t).Shorten(ctx, client.ShortenRequest{LongURL: longURL})
+	require.NoError(t, err)
+	assert.NotEqual(t, adaLink.EncodedKey, anonymousLink.EncodedKey)
+	assert.NotEqual(t, graceLink.EncodedKey, anonymousLink.EncodedKey)
+
+	_, err = ada.Stats(ctx, adaLink.EncodedKey, client.StatsQuery{})
+	assert.NoError(t, err)
+	_, err = grace.Stats(ctx, adaLink.EncodedKey, client.StatsQuery{})
+	assert.ErrorIs(t, err, client.ErrForbidden, "only the owner sees the stats of a user's link")
+
+	alias := client.ShortenRequest{LongURL: longURL, Alias: "user-" + gofakeit.LetterN(10)}
+	_, err = ada.Shorten(ctx, alias)
+	require.NoError(t, err)
+	_, err = grace.Shorten(ctx, alias)
+	assert.ErrorIs(t, err, client.ErrAliasTaken, "another user's alias is taken even for the same url")
+}
+
+func TestShortenerController_AliasPostedAgain(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t)
+	req := client.ShortenRequest{LongURL: "https://example.com/" + gofakeit.UUID(), Alias: "again-" + gofakeit.LetterN(10)}
+
+	created, err := c.Shorten(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, created.Created)
+
+	again, err := c.Shorten(ctx, req)
+	require.NoError(t, err)
+	assert.False(t, again.Created, "the link is handed back with 200")
+	assert.Equal(t, created.ShortURL, again.ShortURL)
 }
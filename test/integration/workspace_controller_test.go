@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/ggoulart/url-shortener/pkg/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkspaceController_SeparateLinksPerWorkspace(t *testing.T) {
+	ctx := context.Background()
+	longURL := "https://example.com/" + gofakeit.UUID()
+
+	ownerToken := signIn(t)
+	owner, err := client.New(baseURL, client.WithAPIKey(ownerToken))
+	assert.NoError(t, err)
+	marketing, err := owner.CreateWorkspace(ctx, "Marketing")
+	assert.NoError(t, err)
+	sales, err := owner.CreateWorkspace(ctx, "Sales")
+	assert.NoError(t, err)
+
+	inMarketing, err := client.New(baseURL, client.WithAPIKey(signIn(t)), client.WithWorkspace(marketing.ID))
+	assert.NoError(t, err)
+	_, err = inMarketing.Shorten(ctx, client.ShortenRequest{LongURL: longURL})
+	assert.ErrorIs(t, err, client.ErrForbidden, "only members create links in a workspace")
+
+	invitation, err := owner.Invite(ctx, marketing.ID, "editor")
+	assert.NoError(t, err)
+	_, err = inMarketing.AcceptInvitation(ctx, invitation.Token)
+	assert.NoError(t, err)
+	_, err = inMarketing.AcceptInvitation(ctx, invitation.Token)
+	assert.ErrorIs(t, err, client.ErrNotFound, "invitations are only accepted once")
+
+	marketingLink, err := inMarketing.Shorten(ctx, client.ShortenRequest{LongURL: longURL})
+	assert.NoError(t, err)
+	marketingAgain, err := inMarketing.Shorten(ctx, client.ShortenRequest{LongURL: longURL})
+	assert.NoError(t, err)
+	assert.Equal(t, marketingLink.EncodedKey, marketingAgain.EncodedKey, "links are shared within a workspace")
+
+	_, err = inMarketing.Stats(ctx, marketingLink.EncodedKey, client.StatsQuery{})
+	assert.NoError(t, err)
+	outsider, err := client.New(baseURL, client.WithAPIKey(signIn(t)))
+	assert.NoError(t, err)
+	_, err = outsider.Stats(ctx, marketingLink.EncodedKey, client.StatsQuery{})
+	assert.ErrorIs(t, err, client.ErrForbidden, "only members see the stats of a workspace link")
+
+	inSales, err := client.New(baseURL, client.WithAPIKey(ownerToken), client.WithWorkspace(sales.ID))
+	assert.NoError(t, err)
+	salesLink, err := inSales.Shorten(ctx, client.ShortenRequest{LongURL: longURL})
+	assert.NoError(t, err)
+	assert.NotEqual(t, marketingLink.EncodedKey, salesLink.EncodedKey, "each workspace gets links of its own")
+}
+
+// signIn registers a new user with the bootstrap key and returns the access token
+// of their session.
+func signIn(t *testing.T) string {
+	ctx := context.Background()
+	anonymous, err := client.New(baseURL)
+	assert.NoError(t, err)
+
+	email := gofakeit.UUID() + "@example.com"
+	_, err = newAdminClient(t).Register(ctx, email, "correct horse battery")
+	assert.NoError(t, err)
+	session, err := anonymous.Login(ctx, email, "correct horse battery")
+	if err != nil {
+		t.Fatalf("failed to sign in: %v", err)
+	}
+
+	return session.AccessToken
+}
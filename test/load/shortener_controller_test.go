@@ -3,6 +3,7 @@ package load
 import (
 	"fmt"
 	"net/http"
+	"os"
 	"testing"
 	"time"
 
@@ -13,10 +14,13 @@ import (
 func TestShortenerController_ShortURL(t *testing.T) {
 	rate := vegeta.Rate{Freq: 50, Per: time.Second}
 	duration := 30 * time.Second
+	// API_KEY needs the create scope, mint one through POST /api/v1/admin/keys
+	authorization := "Bearer " + os.Getenv("API_KEY")
 
 	targeter := func(tgt *vegeta.Target) error {
 		tgt.Method = http.MethodPost
 		tgt.URL = "http://localhost:8080/api/v1/shorten"
+		tgt.Header = http.Header{"Content-Type": []string{"application/json"}, "Authorization": []string{authorization}}
 		tgt.Body = []byte(fmt.Sprintf(`{"longUrl": "%s"}`, gofakeit.URL()))
 		return nil
 	}
@@ -33,6 +37,8 @@ func TestShortenerController_ShortURL(t *testing.T) {
 	// Print summary
 	fmt.Printf("Requests: %d\n", metrics.Requests)
 	fmt.Printf("Success rate: %.2f%%\n", metrics.Success*100)
+	// beyond the create rate limit most requests are answered with 429
+	fmt.Printf("Status codes: %v\n", metrics.StatusCodes)
 	fmt.Printf("Avg latency: %s\n", metrics.Latencies.Mean)
 	fmt.Printf("P99 latency: %s\n", metrics.Latencies.P99)
 }
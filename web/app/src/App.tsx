@@ -15,7 +15,11 @@ function App() {
         try {
             const res = await fetch("http://localhost:8080/api/v1/shorten", {
                 method: "POST",
-                headers: {"Content-Type": "application/json"},
+                headers: {
+                    "Content-Type": "application/json",
+                    // a key with the create scope, see POST /api/v1/admin/keys
+                    "Authorization": `Bearer ${import.meta.env.VITE_API_KEY ?? ""}`,
+                },
                 body: JSON.stringify({"longUrl": url}),
             });
 